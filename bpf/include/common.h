@@ -6,7 +6,11 @@
 #include <bpf/bpf_endian.h>
 
 #define DNS_PORT 53
+#define DHCP_SERVER_PORT 67
+#define DHCP_CLIENT_PORT 68
+#define ETH_ALEN 6
 #define ETH_P_IP 0x0800
+#define ETH_P_ARP 0x0806
 #define TC_ACT_OK 0
 
 struct {
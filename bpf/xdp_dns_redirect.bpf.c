@@ -6,6 +6,43 @@ char LICENSE[] SEC("license") = "Dual BSD/GPL";
 // Magic value to detect reinjected packets
 #define KIDOS_MAGIC 0x4B494453  // "KIDS" in hex
 
+struct mac_key {
+	__u8 addr[ETH_ALEN];
+};
+
+// Paused devices by source MAC and IPv4 address (network byte order); the
+// inspector keeps both in sync with the pause state file.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, 1024);
+	__type(key, struct mac_key);
+	__type(value, __u8);
+} paused_macs SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, 1024);
+	__type(key, __u32);
+	__type(value, __u8);
+} paused_ipv4 SEC(".maps");
+
+// Addresses of kidosserver itself, which paused devices may still reach.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, 64);
+	__type(key, __u32);
+	__type(value, __u8);
+} server_ipv4 SEC(".maps");
+
+static __always_inline bool is_dhcp(struct iphdr *ip, void *data, void *data_end)
+{
+	struct udphdr *udp;
+	if (ip->protocol != IPPROTO_UDP || !parse_udp(&data, &data_end, &udp))
+		return false;
+	__u16 dport = bpf_ntohs(udp->dest);
+	return dport == DHCP_SERVER_PORT || dport == DHCP_CLIENT_PORT;
+}
+
 SEC("xdp")
 int xdp_dns_redirect(struct xdp_md *ctx)
 {
@@ -16,13 +53,26 @@ int xdp_dns_redirect(struct xdp_md *ctx)
 	if (!parse_eth(&data, &data_end, &eth))
 		return XDP_PASS;
 
+	struct mac_key src = {};
+	__builtin_memcpy(src.addr, eth->h_source, ETH_ALEN);
+	bool paused = bpf_map_lookup_elem(&paused_macs, &src) != NULL;
+
 	__u16 h_proto = bpf_ntohs(eth->h_proto);
-	if (h_proto != ETH_P_IP)
+	if (h_proto != ETH_P_IP) {
+		// ARP keeps working so a paused device can still reach kidosserver.
+		if (paused && h_proto != ETH_P_ARP)
+			return XDP_DROP;
 		return XDP_PASS;
+	}
 
 	struct iphdr *ip;
 	if (!parse_ipv4(&data, &data_end, &ip))
-		return XDP_PASS;
+		return paused ? XDP_DROP : XDP_PASS;
+
+	if (!paused)
+		paused = bpf_map_lookup_elem(&paused_ipv4, &ip->saddr) != NULL;
+	if (paused && !bpf_map_lookup_elem(&server_ipv4, &ip->daddr) && !is_dhcp(ip, data, data_end))
+		return XDP_DROP;
 
 	// Check for magic flag in IP identification field
 	__u16 magic_check = bpf_htons((__u16)(KIDOS_MAGIC & 0xFFFF));
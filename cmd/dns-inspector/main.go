@@ -16,6 +16,7 @@ import (
 	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/rlimit"
 
+	"github.com/kidos/kidosserver/pkg/capture"
 	"github.com/kidos/kidosserver/pkg/config"
 	"github.com/kidos/kidosserver/pkg/dns"
 	"github.com/kidos/kidosserver/pkg/events"
@@ -47,11 +48,16 @@ type inspector struct {
 	iface     *net.Interface
 	xskMap    *ebpf.Map
 	socket    *xdp.Socket
-	publisher *events.HTTPPublisher
+	publisher events.Publisher
 	rules     *rules.RuleEngine
+	capture   *capture.Sink
+	filter    capture.Filter
 	frameLen  uint32
 }
 
+// ruleReloadInterval is how often the rules file is checked for edits.
+const ruleReloadInterval = 2 * time.Second
+
 func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
@@ -70,11 +76,15 @@ func main() {
 		logging.Fatalf("lookup interface %s: %v", cfg.Interfaces.Physical, err)
 	}
 
-	publisher := events.NewHTTPPublisher(events.BuildEndpoint(cfg.Web.Listen, "/api/events"))
+	publisher := events.NewPublisher(cfg, "dns-inspector")
 	defer publisher.Close()
 	go publisher.Run(ctx)
 
-	engine := rules.New(cfg.DNS.Blocklist)
+	engine, err := rules.Load(cfg.DNS.RulesFile, cfg.DNS.Blocklist)
+	if err != nil {
+		logging.Errorf("load rules: %v", err)
+	}
+	go engine.Watch(ctx, cfg.DNS.RulesFile, ruleReloadInterval)
 
 	ins, err := newInspector(iface, engine, publisher)
 	if err != nil {
@@ -82,6 +92,20 @@ func main() {
 	}
 	defer ins.Close()
 
+	pauser, err := newPauser(cfg.Pause.ServerAddrs)
+	if err != nil {
+		logging.Errorf("pause disabled: %v", err)
+	} else {
+		defer pauser.Close()
+		go pauser.Run(ctx, cfg.Pause.File)
+	}
+
+	sink, filter, err := capture.FromConfig(cfg.Capture, "dns-inspector", iface.Name)
+	if err != nil {
+		logging.Errorf("capture disabled: %v", err)
+	}
+	ins.capture, ins.filter = sink, filter
+
 	logging.Infof("dns inspector ready on %s", iface.Name)
 
 	if err := ins.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
@@ -89,7 +113,7 @@ func main() {
 	}
 }
 
-func newInspector(iface *net.Interface, engine *rules.RuleEngine, publisher *events.HTTPPublisher) (*inspector, error) {
+func newInspector(iface *net.Interface, engine *rules.RuleEngine, publisher events.Publisher) (*inspector, error) {
 	xskMap, err := findKernelMap(xskMapName)
 	if err != nil {
 		return nil, err
@@ -175,6 +199,28 @@ func (i *inspector) Close() {
 		i.socket.Close()
 		i.socket = nil
 	}
+	if i.capture != nil {
+		if err := i.capture.Close(); err != nil {
+			logging.Errorf("close capture: %v", err)
+		}
+		i.capture = nil
+	}
+}
+
+// captureFrame records the frame and its verdict when it matches the capture filter.
+func (i *inspector) captureFrame(ts time.Time, frame []byte, ev events.Event) {
+	if i.capture == nil || len(frame) < 14 {
+		return
+	}
+	srcMAC := net.HardwareAddr(frame[6:12]).String()
+	dstMAC := net.HardwareAddr(frame[0:6]).String()
+	if !i.filter.Match(ev.Action, ev.SourceIP, ev.DestinationIP, srcMAC, dstMAC) {
+		return
+	}
+	comment := fmt.Sprintf("verdict=%s domain=%s reason=%s", ev.Action, ev.Domain, ev.Reason)
+	if err := i.capture.Write(ts, frame, comment); err != nil {
+		logging.Errorf("capture frame: %v", err)
+	}
 }
 
 // addMagicFlag sets the magic flag in packet metadata to prevent reprocessing
@@ -209,6 +255,14 @@ func (i *inspector) addMagicFlag(desc xdp.Desc) {
 	ipHeader[11] = byte(csum)
 }
 
+// matchQuery returns the rule blocking a DNS query, if any.
+func (i *inspector) matchQuery(pkt *dns.Packet) (string, bool) {
+	if pkt.Direction != "query" || pkt.Domain == "" {
+		return "", false
+	}
+	return i.rules.Match(pkt.Domain, pkt.SourceIP.String())
+}
+
 func (i *inspector) Run(ctx context.Context) error {
 	allow := make([]xdp.Desc, 0, 256)
 	reuse := make([]xdp.Desc, 0, 256)
@@ -269,10 +323,12 @@ func (i *inspector) Run(ctx context.Context) error {
 				Domain:        pkt.Domain,
 			}
 
-			if pkt.Direction == "query" && pkt.Domain != "" && i.rules.ShouldBlock(pkt.Domain) {
+			if rule, blocked := i.matchQuery(pkt); blocked {
 				ev.Action = "block"
 				ev.Reason = "domain blocked"
+				ev.Rule = rule
 				i.publisher.Publish(ev)
+				i.captureFrame(now, frame, ev)
 
 				desc.Len = i.frameLen
 				reuse = append(reuse, desc)
@@ -282,9 +338,16 @@ func (i *inspector) Run(ctx context.Context) error {
 			ev.Action = "allow"
 			ev.Reason = "passed"
 			i.publisher.Publish(ev)
+			i.captureFrame(now, frame, ev)
 			allow = append(allow, desc)
 		}
 
+		if i.capture != nil {
+			if err := i.capture.Flush(); err != nil {
+				logging.Errorf("flush capture: %v", err)
+			}
+		}
+
 		// For allowed packets: add magic flag and retransmit
 		if len(allow) > 0 {
 			for idx := range allow {
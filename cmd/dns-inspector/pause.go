@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"time"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/kidos/kidosserver/pkg/logging"
+	"github.com/kidos/kidosserver/pkg/pause"
+)
+
+const (
+	pausedMACsMap = "paused_macs"
+	pausedIPv4Map = "paused_ipv4"
+	serverIPv4Map = "server_ipv4"
+
+	// pauseSyncInterval bounds how long pausing, resuming and auto-resume
+	// take to reach the XDP program.
+	pauseSyncInterval = time.Second
+)
+
+// pauser mirrors the pause state file into the XDP maps.
+type pauser struct {
+	macs   *ebpf.Map
+	ips    *ebpf.Map
+	server *ebpf.Map
+
+	curMACs map[[6]byte]bool
+	curIPs  map[[4]byte]bool
+}
+
+func newPauser(serverAddrs []string) (*pauser, error) {
+	p := &pauser{curMACs: make(map[[6]byte]bool), curIPs: make(map[[4]byte]bool)}
+	var err error
+	if p.macs, err = findKernelMap(pausedMACsMap); err != nil {
+		return nil, err
+	}
+	if p.ips, err = findKernelMap(pausedIPv4Map); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if p.server, err = findKernelMap(serverIPv4Map); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if err := p.setServerAddrs(serverAddrs); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// setServerAddrs exempts this host's IPv4 addresses and the configured ones
+// from pauses.
+func (p *pauser) setServerAddrs(extra []string) error {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return fmt.Errorf("list local addresses: %w", err)
+	}
+	var ips []netip.Addr
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok {
+			if ip, ok := netip.AddrFromSlice(n.IP); ok && ip.Unmap().Is4() {
+				ips = append(ips, ip.Unmap())
+			}
+		}
+	}
+	for _, s := range extra {
+		ip, err := netip.ParseAddr(s)
+		if err != nil || !ip.Is4() {
+			return fmt.Errorf("pause server address %q is not IPv4", s)
+		}
+		ips = append(ips, ip)
+	}
+	one := uint8(1)
+	for _, ip := range ips {
+		key := ip.As4()
+		if err := p.server.Update(&key, &one, ebpf.UpdateAny); err != nil {
+			return fmt.Errorf("exempt %s from pause: %w", ip, err)
+		}
+	}
+	return nil
+}
+
+// Run applies the pause state at path until ctx is cancelled. Auto-resume
+// times are honoured here as well, so devices come back even while the web
+// server is down.
+func (p *pauser) Run(ctx context.Context, path string) {
+	ticker := time.NewTicker(pauseSyncInterval)
+	defer ticker.Stop()
+	for {
+		entries, err := pause.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			logging.Errorf("pause: %v", err)
+		} else {
+			p.sync(entries, time.Now())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *pauser) sync(entries []pause.Entry, now time.Time) {
+	macs, ips := pause.Targets(entries, now)
+	one := uint8(1)
+	for mac := range macs {
+		if p.curMACs[mac] {
+			continue
+		}
+		if err := p.macs.Update(&mac, &one, ebpf.UpdateAny); err != nil {
+			logging.Errorf("pause %s: %v", net.HardwareAddr(mac[:]), err)
+			continue
+		}
+		p.curMACs[mac] = true
+		logging.Infof("paused %s", net.HardwareAddr(mac[:]))
+	}
+	for mac := range p.curMACs {
+		if macs[mac] {
+			continue
+		}
+		if err := p.macs.Delete(&mac); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			logging.Errorf("resume %s: %v", net.HardwareAddr(mac[:]), err)
+			continue
+		}
+		delete(p.curMACs, mac)
+		logging.Infof("resumed %s", net.HardwareAddr(mac[:]))
+	}
+	for ip := range ips {
+		if p.curIPs[ip] {
+			continue
+		}
+		if err := p.ips.Update(&ip, &one, ebpf.UpdateAny); err != nil {
+			logging.Errorf("pause %s: %v", netip.AddrFrom4(ip), err)
+			continue
+		}
+		p.curIPs[ip] = true
+		logging.Infof("paused %s", netip.AddrFrom4(ip))
+	}
+	for ip := range p.curIPs {
+		if ips[ip] {
+			continue
+		}
+		if err := p.ips.Delete(&ip); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			logging.Errorf("resume %s: %v", netip.AddrFrom4(ip), err)
+			continue
+		}
+		delete(p.curIPs, ip)
+		logging.Infof("resumed %s", netip.AddrFrom4(ip))
+	}
+}
+
+// Close resumes everything the pauser paused and releases the maps, so a
+// stopped inspector never leaves devices cut off.
+func (p *pauser) Close() {
+	if p.macs != nil {
+		for mac := range p.curMACs {
+			_ = p.macs.Delete(&mac)
+		}
+		p.macs.Close()
+	}
+	if p.ips != nil {
+		for ip := range p.curIPs {
+			_ = p.ips.Delete(&ip)
+		}
+		p.ips.Close()
+	}
+	if p.server != nil {
+		p.server.Close()
+	}
+}
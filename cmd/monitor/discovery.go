@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+const (
+	// deviceRefresh is how often an unchanged, active device is reported
+	// again so the inventory's last-seen time stays current.
+	deviceRefresh = 5 * time.Minute
+	// deviceExpiry drops devices from the local table after this much
+	// silence; they are reported as new when they come back.
+	deviceExpiry = time.Hour
+	// maxTrackedDevices bounds the table against spoofed source addresses.
+	maxTrackedDevices = 4096
+	// maxTrackedAddrs bounds the addresses remembered per device.
+	maxTrackedAddrs = 16
+
+	dhcpServerPort = 67
+	dhcpClientPort = 68
+	dhcpMagic      = 0x63825363
+
+	icmpv6RouterSolicit   = 133
+	icmpv6NeighborSolicit = 135
+	icmpv6NeighborAdvert  = 136
+)
+
+// DHCP options read from client messages.
+const (
+	dhcpOptPad         = 0
+	dhcpOptHostname    = 12
+	dhcpOptMsgType     = 53
+	dhcpOptParamList   = 55
+	dhcpOptVendorClass = 60
+	dhcpOptEnd         = 255
+)
+
+// DHCP message types that matter for bindings.
+const (
+	dhcpDecline = 4
+	dhcpAck     = 5
+	dhcpRelease = 7
+)
+
+type trackedDevice struct {
+	info      events.DeviceInfo
+	ips       map[string]time.Time
+	published time.Time
+	seen      time.Time
+}
+
+// discovery passively learns MAC to IP bindings, DHCP host names and vendor
+// classes from the frames mirrored to the monitor.
+type discovery struct {
+	publisher events.Publisher
+	devices   map[string]*trackedDevice
+	lastSweep time.Time
+}
+
+func newDiscovery(publisher events.Publisher) *discovery {
+	return &discovery{publisher: publisher, devices: make(map[string]*trackedDevice)}
+}
+
+// Observe inspects one frame. Discovery protocols update bindings; any
+// other frame from a known device only keeps it alive.
+func (d *discovery) Observe(frame []byte, now time.Time) {
+	if len(frame) < 14 {
+		return
+	}
+	info, ok := parseDiscovery(frame)
+	if ok {
+		d.learn(info, now)
+	} else if src := net.HardwareAddr(frame[6:12]); src[0]&1 == 0 {
+		if t := d.devices[src.String()]; t != nil {
+			t.seen = now
+			if now.Sub(t.published) >= deviceRefresh {
+				d.publish(t, now)
+			}
+		}
+	}
+	if now.Sub(d.lastSweep) >= deviceRefresh {
+		d.sweep(now)
+	}
+}
+
+// learn merges info into the table and reports new addresses and names
+// right away.
+func (d *discovery) learn(info events.DeviceInfo, now time.Time) {
+	t := d.devices[info.MAC]
+	if t == nil {
+		if len(d.devices) >= maxTrackedDevices {
+			return
+		}
+		t = &trackedDevice{info: events.DeviceInfo{MAC: info.MAC}, ips: make(map[string]time.Time)}
+		d.devices[info.MAC] = t
+	}
+	t.seen = now
+	changed := t.published.IsZero()
+	if info.IP != "" {
+		if last, ok := t.ips[info.IP]; !ok || now.Sub(last) >= deviceRefresh {
+			changed = true
+		}
+		t.info.IP = info.IP
+	}
+	if info.Hostname != "" && info.Hostname != t.info.Hostname {
+		t.info.Hostname, changed = info.Hostname, true
+	}
+	if info.VendorClass != "" && info.VendorClass != t.info.VendorClass {
+		t.info.VendorClass, changed = info.VendorClass, true
+	}
+	if info.DHCPParams != "" && info.DHCPParams != t.info.DHCPParams {
+		t.info.DHCPParams, changed = info.DHCPParams, true
+	}
+	t.info.Source = info.Source
+	if changed || now.Sub(t.published) >= deviceRefresh {
+		d.publish(t, now)
+	}
+}
+
+// publish reports t with the address it used last.
+func (d *discovery) publish(t *trackedDevice, now time.Time) {
+	info := t.info
+	t.published = now
+	if info.IP != "" {
+		if len(t.ips) >= maxTrackedAddrs {
+			clear(t.ips)
+		}
+		t.ips[info.IP] = now
+	}
+	d.publisher.Publish(events.Event{
+		Kind:      events.KindDeviceSeen,
+		Timestamp: now.UTC(),
+		SourceIP:  info.IP,
+		Device:    &info,
+	})
+}
+
+// sweep forgets devices that went quiet.
+func (d *discovery) sweep(now time.Time) {
+	d.lastSweep = now
+	for mac, t := range d.devices {
+		if now.Sub(t.seen) >= deviceExpiry {
+			delete(d.devices, mac)
+		}
+	}
+}
+
+// parseDiscovery extracts a binding from ARP, DHCP client or server, and
+// ICMPv6 neighbor discovery frames.
+func parseDiscovery(frame []byte) (events.DeviceInfo, bool) {
+	switch binary.BigEndian.Uint16(frame[12:14]) {
+	case unix.ETH_P_ARP:
+		return parseARP(frame[14:])
+	case unix.ETH_P_IP:
+		return parseDHCP(frame)
+	case unix.ETH_P_IPV6:
+		return parseNDP(frame)
+	}
+	return events.DeviceInfo{}, false
+}
+
+// parseARP learns the sender of Ethernet/IPv4 ARP requests and replies.
+// Probes from the unspecified address carry no binding yet.
+func parseARP(arp []byte) (events.DeviceInfo, bool) {
+	if len(arp) < 28 || binary.BigEndian.Uint16(arp[0:2]) != 1 ||
+		binary.BigEndian.Uint16(arp[2:4]) != unix.ETH_P_IP || arp[4] != 6 || arp[5] != 4 {
+		return events.DeviceInfo{}, false
+	}
+	mac := net.HardwareAddr(arp[8:14])
+	ip := net.IP(arp[14:18])
+	if !usableMAC(mac) || ip.IsUnspecified() {
+		return events.DeviceInfo{}, false
+	}
+	return events.DeviceInfo{MAC: mac.String(), IP: ip.String(), Source: "arp"}, true
+}
+
+// parseDHCP reads client messages for host name and vendor class, and
+// acknowledgements for the address the server handed out.
+func parseDHCP(frame []byte) (events.DeviceInfo, bool) {
+	if len(frame) < 34 || frame[23] != unix.IPPROTO_UDP {
+		return events.DeviceInfo{}, false
+	}
+	udp := 14 + int(frame[14]&0x0F)*4
+	if len(frame) < udp+8 {
+		return events.DeviceInfo{}, false
+	}
+	srcPort := binary.BigEndian.Uint16(frame[udp:])
+	dstPort := binary.BigEndian.Uint16(frame[udp+2:])
+	fromClient := srcPort == dhcpClientPort && dstPort == dhcpServerPort
+	fromServer := srcPort == dhcpServerPort && dstPort == dhcpClientPort
+	if !fromClient && !fromServer {
+		return events.DeviceInfo{}, false
+	}
+	msg := frame[udp+8:]
+	if len(msg) < 240 || msg[1] != 1 || msg[2] != 6 || binary.BigEndian.Uint32(msg[236:240]) != dhcpMagic {
+		return events.DeviceInfo{}, false
+	}
+	mac := net.HardwareAddr(msg[28:34])
+	if !usableMAC(mac) {
+		return events.DeviceInfo{}, false
+	}
+	info := events.DeviceInfo{MAC: mac.String(), Source: "dhcp"}
+	var msgType byte
+	opts := msg[240:]
+	for len(opts) > 0 {
+		code := opts[0]
+		if code == dhcpOptEnd {
+			break
+		}
+		if code == dhcpOptPad {
+			opts = opts[1:]
+			continue
+		}
+		if len(opts) < 2 || len(opts) < 2+int(opts[1]) {
+			break
+		}
+		val := opts[2 : 2+int(opts[1])]
+		switch code {
+		case dhcpOptMsgType:
+			if len(val) == 1 {
+				msgType = val[0]
+			}
+		case dhcpOptHostname:
+			info.Hostname = cleanText(val)
+		case dhcpOptVendorClass:
+			info.VendorClass = cleanText(val)
+		case dhcpOptParamList:
+			info.DHCPParams = paramList(val)
+		}
+		opts = opts[2+len(val):]
+	}
+
+	if fromServer {
+		// Only the server's acknowledgement confirms a lease; its options
+		// describe the server, not the client.
+		info.Hostname, info.VendorClass, info.DHCPParams = "", "", ""
+		if msgType != dhcpAck {
+			return events.DeviceInfo{}, false
+		}
+		if ip := net.IP(msg[16:20]); !ip.IsUnspecified() {
+			info.IP = ip.String()
+		} else if ip := net.IP(msg[12:16]); !ip.IsUnspecified() {
+			info.IP = ip.String()
+		}
+		return info, info.IP != ""
+	}
+	if msgType == dhcpDecline || msgType == dhcpRelease {
+		return events.DeviceInfo{}, false
+	}
+	// A client only fills in ciaddr while it holds the lease; addresses it
+	// merely asks for are confirmed by the server's ACK.
+	if ip := net.IP(msg[12:16]); !ip.IsUnspecified() {
+		info.IP = ip.String()
+	}
+	return info, true
+}
+
+// parseNDP learns IPv6 bindings from router and neighbor solicitations and
+// neighbor advertisements sent directly over ICMPv6.
+func parseNDP(frame []byte) (events.DeviceInfo, bool) {
+	const ipv6 = 14
+	const icmp = ipv6 + 40
+	if len(frame) < icmp+4 || frame[ipv6+6] != unix.IPPROTO_ICMPV6 || frame[ipv6+7] != 255 {
+		return events.DeviceInfo{}, false
+	}
+	mac := net.HardwareAddr(frame[6:12])
+	src := net.IP(frame[ipv6+8 : ipv6+24])
+	var ip net.IP
+	switch frame[icmp] {
+	case icmpv6RouterSolicit, icmpv6NeighborSolicit:
+		// Duplicate address detection solicits from :: on behalf of an
+		// address not yet in use.
+		if src.IsUnspecified() {
+			return events.DeviceInfo{}, false
+		}
+		ip = src
+	case icmpv6NeighborAdvert:
+		if len(frame) < icmp+24 {
+			return events.DeviceInfo{}, false
+		}
+		ip = net.IP(frame[icmp+8 : icmp+24])
+	default:
+		return events.DeviceInfo{}, false
+	}
+	if !usableMAC(mac) || ip.IsMulticast() {
+		return events.DeviceInfo{}, false
+	}
+	return events.DeviceInfo{MAC: mac.String(), IP: ip.String(), Source: "ndp"}, true
+}
+
+// usableMAC rejects group and all-zero addresses.
+func usableMAC(mac net.HardwareAddr) bool {
+	if len(mac) != 6 || mac[0]&1 != 0 {
+		return false
+	}
+	for _, b := range mac {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// paramList renders the DHCP parameter request list in order, the way DHCP
+// fingerprints are usually written.
+func paramList(codes []byte) string {
+	parts := make([]string, len(codes))
+	for i, c := range codes {
+		parts[i] = strconv.Itoa(int(c))
+	}
+	return strings.Join(parts, ",")
+}
+
+// cleanText keeps the printable ASCII of a DHCP string option.
+func cleanText(b []byte) string {
+	s := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return -1
+		}
+		return r
+	}, string(b))
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+var (
+	clientMAC = net.HardwareAddr{0x3c, 0x22, 0xfb, 0x12, 0x34, 0x56}
+	serverMAC = net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	broadcast = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+)
+
+// arpRequest is a captured who-has from 192.168.50.23 for the gateway.
+const arpRequest = "ffffffffffff3c22fb1234560806" +
+	"0001080006040001" + "3c22fb123456c0a83217" + "000000000000c0a83201"
+
+func ether(dst, src net.HardwareAddr, typ uint16, payload []byte) []byte {
+	f := append(append([]byte{}, dst...), src...)
+	f = binary.BigEndian.AppendUint16(f, typ)
+	return append(f, payload...)
+}
+
+type dhcpMsg struct {
+	src, dst       net.HardwareAddr
+	sport, dport   uint16
+	chaddr         net.HardwareAddr
+	ciaddr, yiaddr net.IP
+	options        []byte
+}
+
+// frame builds the Ethernet/IPv4/UDP/BOOTP frame for m.
+func (m dhcpMsg) frame() []byte {
+	bootp := make([]byte, 240)
+	bootp[0], bootp[1], bootp[2] = 1, 1, 6
+	if m.ciaddr != nil {
+		copy(bootp[12:16], m.ciaddr.To4())
+	}
+	if m.yiaddr != nil {
+		copy(bootp[16:20], m.yiaddr.To4())
+	}
+	copy(bootp[28:34], m.chaddr)
+	binary.BigEndian.PutUint32(bootp[236:], dhcpMagic)
+	bootp = append(bootp, m.options...)
+
+	udp := make([]byte, 8, 8+len(bootp))
+	binary.BigEndian.PutUint16(udp[0:], m.sport)
+	binary.BigEndian.PutUint16(udp[2:], m.dport)
+	binary.BigEndian.PutUint16(udp[4:], uint16(8+len(bootp)))
+	udp = append(udp, bootp...)
+
+	ip := make([]byte, 20, 20+len(udp))
+	ip[0], ip[8], ip[9] = 0x45, 64, 17
+	binary.BigEndian.PutUint16(ip[2:], uint16(20+len(udp)))
+	return ether(m.dst, m.src, 0x0800, append(ip, udp...))
+}
+
+func clientDHCP(options ...byte) dhcpMsg {
+	return dhcpMsg{src: clientMAC, dst: broadcast, sport: dhcpClientPort, dport: dhcpServerPort, chaddr: clientMAC, options: options}
+}
+
+func serverDHCP(options ...byte) dhcpMsg {
+	return dhcpMsg{src: serverMAC, dst: clientMAC, sport: dhcpServerPort, dport: dhcpClientPort, chaddr: clientMAC, options: options}
+}
+
+// ndp builds an ICMPv6 frame from src with the given type and target.
+func ndp(mac net.HardwareAddr, src string, typ byte, target string, hops byte) []byte {
+	ip := make([]byte, 40)
+	ip[0], ip[6], ip[7] = 0x60, 58, hops
+	copy(ip[8:24], net.ParseIP(src).To16())
+	copy(ip[24:40], net.ParseIP("ff02::1").To16())
+	icmp := make([]byte, 24)
+	icmp[0] = typ
+	if target != "" {
+		copy(icmp[8:24], net.ParseIP(target).To16())
+	}
+	binary.BigEndian.PutUint16(ip[4:], uint16(len(icmp)))
+	return ether(net.HardwareAddr{0x33, 0x33, 0, 0, 0, 1}, mac, 0x86dd, append(ip, icmp...))
+}
+
+func TestParseDiscovery(t *testing.T) {
+	captured, err := hex.DecodeString(arpRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arpFrom := func(mac net.HardwareAddr, ip net.IP) []byte {
+		f := append([]byte{}, captured...)
+		copy(f[22:28], mac)
+		copy(f[28:32], ip.To4())
+		return f
+	}
+	request := clientDHCP(53, 1, 3, 12, 6, 'k', 'i', 'd', '-', 'p', 'c', 60, 8, 'M', 'S', 'F', 'T', ' ', '5', '.', '0',
+		55, 4, 1, 3, 6, 15, 255)
+
+	for _, tc := range []struct {
+		name  string
+		frame []byte
+		want  *events.DeviceInfo
+	}{
+		{
+			name:  "arp request",
+			frame: captured,
+			want:  &events.DeviceInfo{MAC: "3c:22:fb:12:34:56", IP: "192.168.50.23", Source: "arp"},
+		},
+		{name: "arp probe", frame: arpFrom(clientMAC, net.IPv4zero)},
+		{name: "arp zero mac", frame: arpFrom(make(net.HardwareAddr, 6), net.ParseIP("192.168.50.23"))},
+		{name: "arp multicast mac", frame: arpFrom(net.HardwareAddr{0x01, 0, 0x5e, 0, 0, 1}, net.ParseIP("192.168.50.23"))},
+		{name: "arp truncated", frame: captured[:14+27]},
+		{
+			name:  "dhcp request options",
+			frame: request.frame(),
+			want:  &events.DeviceInfo{MAC: "3c:22:fb:12:34:56", Hostname: "kid-pc", VendorClass: "MSFT 5.0", DHCPParams: "1,3,6,15", Source: "dhcp"},
+		},
+		{
+			name: "dhcp pads and control characters",
+			frame: clientDHCP(0, 0, 53, 1, 3, 0, 12, 5, 'p', 'c', 0x07, '1', 0xff, 255,
+				12, 3, 'x', 'x', 'x').frame(),
+			want: &events.DeviceInfo{MAC: "3c:22:fb:12:34:56", Hostname: "pc1", Source: "dhcp"},
+		},
+		{
+			name:  "dhcp option overruns the frame",
+			frame: clientDHCP(53, 1, 3, 12, 40, 'p', 'c').frame(),
+			want:  &events.DeviceInfo{MAC: "3c:22:fb:12:34:56", Source: "dhcp"},
+		},
+		{
+			name: "dhcp renewal with ciaddr",
+			frame: func() []byte {
+				m := clientDHCP(53, 1, 3, 255)
+				m.ciaddr = net.ParseIP("192.168.50.23")
+				return m.frame()
+			}(),
+			want: &events.DeviceInfo{MAC: "3c:22:fb:12:34:56", IP: "192.168.50.23", Source: "dhcp"},
+		},
+		{
+			name: "dhcp release",
+			frame: func() []byte {
+				m := clientDHCP(53, 1, 7, 255)
+				m.ciaddr = net.ParseIP("192.168.50.23")
+				return m.frame()
+			}(),
+		},
+		{
+			name: "dhcp server ack",
+			frame: func() []byte {
+				m := serverDHCP(53, 1, 5, 12, 6, 'r', 'o', 'u', 't', 'e', 'r', 255)
+				m.yiaddr = net.ParseIP("192.168.50.23")
+				return m.frame()
+			}(),
+			want: &events.DeviceInfo{MAC: "3c:22:fb:12:34:56", IP: "192.168.50.23", Source: "dhcp"},
+		},
+		{
+			name: "dhcp server ack to inform uses ciaddr",
+			frame: func() []byte {
+				m := serverDHCP(53, 1, 5, 255)
+				m.ciaddr = net.ParseIP("192.168.50.24")
+				return m.frame()
+			}(),
+			want: &events.DeviceInfo{MAC: "3c:22:fb:12:34:56", IP: "192.168.50.24", Source: "dhcp"},
+		},
+		{
+			name: "dhcp server offer",
+			frame: func() []byte {
+				m := serverDHCP(53, 1, 2, 255)
+				m.yiaddr = net.ParseIP("192.168.50.23")
+				return m.frame()
+			}(),
+		},
+		{
+			name: "dhcp zero chaddr",
+			frame: func() []byte {
+				m := clientDHCP(53, 1, 3, 255)
+				m.chaddr = make(net.HardwareAddr, 6)
+				return m.frame()
+			}(),
+		},
+		{
+			name: "dhcp wrong ports",
+			frame: func() []byte {
+				m := clientDHCP(53, 1, 3, 255)
+				m.sport = 5353
+				return m.frame()
+			}(),
+		},
+		{name: "dhcp truncated", frame: request.frame()[:14+20+8+200]},
+		{
+			name:  "ndp neighbor solicitation",
+			frame: ndp(clientMAC, "fe80::3e22:fbff:fe12:3456", icmpv6NeighborSolicit, "fe80::1", 255),
+			want:  &events.DeviceInfo{MAC: "3c:22:fb:12:34:56", IP: "fe80::3e22:fbff:fe12:3456", Source: "ndp"},
+		},
+		{
+			name:  "ndp router solicitation",
+			frame: ndp(clientMAC, "2001:db8::23", icmpv6RouterSolicit, "", 255),
+			want:  &events.DeviceInfo{MAC: "3c:22:fb:12:34:56", IP: "2001:db8::23", Source: "ndp"},
+		},
+		{name: "ndp duplicate address detection", frame: ndp(clientMAC, "::", icmpv6NeighborSolicit, "2001:db8::23", 255)},
+		{
+			name:  "ndp neighbor advertisement",
+			frame: ndp(clientMAC, "fe80::3e22:fbff:fe12:3456", icmpv6NeighborAdvert, "2001:db8::23", 255),
+			want:  &events.DeviceInfo{MAC: "3c:22:fb:12:34:56", IP: "2001:db8::23", Source: "ndp"},
+		},
+		{name: "ndp advertisement of a multicast target", frame: ndp(clientMAC, "fe80::1", icmpv6NeighborAdvert, "ff02::1", 255)},
+		{name: "ndp routed", frame: ndp(clientMAC, "2001:db8::23", icmpv6NeighborSolicit, "2001:db8::1", 64)},
+		{name: "ndp zero mac", frame: ndp(make(net.HardwareAddr, 6), "2001:db8::23", icmpv6RouterSolicit, "", 255)},
+		{name: "ndp truncated advertisement", frame: ndp(clientMAC, "fe80::1", icmpv6NeighborAdvert, "2001:db8::23", 255)[:14+40+20]},
+	} {
+		got, ok := parseDiscovery(tc.frame)
+		if tc.want == nil {
+			if ok {
+				t.Errorf("%s: parsed %+v", tc.name, got)
+			}
+			continue
+		}
+		if !ok || got != *tc.want {
+			t.Errorf("%s: got %+v, %v; want %+v", tc.name, got, ok, *tc.want)
+		}
+	}
+}
+
+type recorder struct{ events []events.Event }
+
+func (r *recorder) Publish(ev events.Event) { r.events = append(r.events, ev) }
+
+func TestLearn(t *testing.T) {
+	rec := &recorder{}
+	d := newDiscovery(rec)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	mac := clientMAC.String()
+
+	d.learn(events.DeviceInfo{MAC: mac, Hostname: "kid-pc", Source: "dhcp"}, now)
+	d.learn(events.DeviceInfo{MAC: mac, IP: "192.168.50.23", Source: "arp"}, now.Add(time.Second))
+	// Nothing new within the refresh period is not reported again.
+	d.learn(events.DeviceInfo{MAC: mac, IP: "192.168.50.23", Source: "arp"}, now.Add(2*time.Second))
+	if len(rec.events) != 2 {
+		t.Fatalf("published %d events, want 2", len(rec.events))
+	}
+	last := rec.events[1]
+	if last.Kind != events.KindDeviceSeen || last.SourceIP != "192.168.50.23" || last.Device.Hostname != "kid-pc" {
+		t.Fatalf("event = %+v, device %+v", last, last.Device)
+	}
+
+	// Other traffic keeps the device alive and refreshes it now and then.
+	other := ether(broadcast, clientMAC, 0x0800, make([]byte, 40))
+	d.Observe(other, now.Add(time.Minute))
+	if len(rec.events) != 2 {
+		t.Fatal("plain traffic reported the device early")
+	}
+	d.Observe(other, now.Add(deviceRefresh+time.Second))
+	if len(rec.events) != 3 {
+		t.Fatal("plain traffic did not refresh the device")
+	}
+
+	// Quiet devices are forgotten and reported as new when they return.
+	later := now.Add(deviceRefresh + deviceExpiry + time.Minute)
+	d.sweep(later)
+	if len(d.devices) != 0 {
+		t.Fatalf("table holds %d devices after expiry", len(d.devices))
+	}
+	d.learn(events.DeviceInfo{MAC: mac, IP: "192.168.50.23", Source: "arp"}, later)
+	if len(rec.events) != 4 {
+		t.Fatalf("returning device not reported")
+	}
+
+	// The table is bounded.
+	for i := 0; len(d.devices) < maxTrackedDevices; i++ {
+		d.devices[strings.Repeat("x", i+1)] = &trackedDevice{ips: map[string]time.Time{}}
+	}
+	d.learn(events.DeviceInfo{MAC: "02:00:00:00:00:99", Source: "arp"}, later)
+	if _, ok := d.devices["02:00:00:00:00:99"]; ok || len(rec.events) != 4 {
+		t.Fatal("device added beyond the table limit")
+	}
+}
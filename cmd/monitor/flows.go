@@ -0,0 +1,426 @@
+package main
+
+import (
+	"container/list"
+	"encoding/binary"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/kidos/kidosserver/pkg/config"
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+// dnsPort is the UDP port whose flows are aggregated per client and server.
+const dnsPort = 53
+
+// closeGrace keeps a closed or reset TCP flow around briefly so trailing
+// ACKs and retransmissions do not open a new one.
+const closeGrace = 10 * time.Second
+
+// Flow end reasons.
+const (
+	flowEndIdle    = "idle"
+	flowEndActive  = "active-timeout"
+	flowEndClosed  = "closed"
+	flowEndReset   = "reset"
+	flowEndEvicted = "evicted"
+	flowEndStopped = "shutdown"
+)
+
+// TCP states tracked per flow.
+const (
+	tcpSynSent     = "syn_sent"
+	tcpSynReceived = "syn_received"
+	tcpEstablished = "established"
+	tcpClosing     = "closing"
+	tcpClosed      = "closed"
+	tcpReset       = "reset"
+)
+
+const (
+	tcpFIN = 1 << iota
+	tcpSYN
+	tcpRST
+	tcpPSH
+	tcpACK
+	tcpURG
+	tcpECE
+	tcpCWR
+)
+
+// Timeout classes. Each has its own LRU list, so every list is ordered by
+// expiry and a sweep stops at the first flow that has not timed out.
+const (
+	classIdle   = iota // non-TCP flows
+	classTCP           // open TCP connections
+	classClosed        // closed or reset connections within closeGrace
+	numClasses
+)
+
+// packetInfo is the part of a frame the flow table needs.
+type packetInfo struct {
+	proto    uint8
+	src, dst netip.AddrPort
+	length   uint64
+	tcpFlags uint8
+}
+
+// flowKey identifies a flow regardless of direction: a is the lesser
+// endpoint.
+type flowKey struct {
+	proto uint8
+	a, b  netip.AddrPort
+}
+
+func makeFlowKey(proto uint8, x, y netip.AddrPort) flowKey {
+	if y.Compare(x) < 0 {
+		x, y = y, x
+	}
+	return flowKey{proto: proto, a: x, b: y}
+}
+
+type flow struct {
+	key        flowKey
+	client     netip.AddrPort
+	server     netip.AddrPort
+	packetsOut uint64
+	packetsIn  uint64
+	bytesOut   uint64
+	bytesIn    uint64
+	flags      uint8
+	finOut     bool
+	finIn      bool
+	state      string
+	first      time.Time
+	last       time.Time
+	class      int
+	elem       *list.Element
+}
+
+// timeoutClass returns the timeout class of f's current state.
+func (f *flow) timeoutClass() int {
+	switch f.state {
+	case tcpClosed, tcpReset:
+		return classClosed
+	case "":
+		return classIdle
+	}
+	return classTCP
+}
+
+// flowTable tracks 5-tuple flows with idle and active timeouts. Memory is
+// bounded by evicting the least recently seen flow; every flow that leaves
+// the table is reported as a flow_end event.
+type flowTable struct {
+	flows    map[flowKey]*flow
+	lrus     [numClasses]*list.List
+	timeouts [numClasses]time.Duration
+	max      int
+	active   time.Duration
+	emit     func(events.Event)
+	domain   func(ip string) string
+}
+
+func newFlowTable(cfg config.MonitorConfig, emit func(events.Event), domain func(string) string) *flowTable {
+	t := &flowTable{
+		flows:  make(map[flowKey]*flow),
+		max:    cfg.MaxFlows,
+		active: time.Duration(cfg.ActiveSec) * time.Second,
+		emit:   emit,
+		domain: domain,
+		timeouts: [numClasses]time.Duration{
+			classIdle:   time.Duration(cfg.IdleSec) * time.Second,
+			classTCP:    time.Duration(cfg.TCPIdleSec) * time.Second,
+			classClosed: closeGrace,
+		},
+	}
+	for c := range t.lrus {
+		t.lrus[c] = list.New()
+	}
+	if t.max <= 0 {
+		t.max = 65536
+	}
+	if t.timeouts[classIdle] <= 0 {
+		t.timeouts[classIdle] = time.Minute
+	}
+	if t.timeouts[classTCP] <= 0 {
+		t.timeouts[classTCP] = 5 * time.Minute
+	}
+	return t
+}
+
+// Observe accounts one packet.
+func (t *flowTable) Observe(p packetInfo, now time.Time) {
+	p = aggregateDNS(p)
+	key := makeFlowKey(p.proto, p.src, p.dst)
+	f := t.flows[key]
+	if f != nil && (f.state == tcpClosed || f.state == tcpReset) && p.tcpFlags&tcpSYN != 0 && p.tcpFlags&tcpACK == 0 {
+		// A fresh connection reusing the ports of a finished one.
+		t.end(f, flowEndClosed, now)
+		f = nil
+	}
+	if f == nil {
+		if len(t.flows) >= t.max {
+			if oldest := t.oldest(); oldest != nil {
+				t.end(oldest, flowEndEvicted, now)
+			}
+		}
+		f = &flow{key: key, client: p.src, server: p.dst, first: now}
+		// A SYN-ACK first means the handshake began before we looked.
+		if p.proto == unix.IPPROTO_TCP && p.tcpFlags&(tcpSYN|tcpACK) == tcpSYN|tcpACK {
+			f.client, f.server = p.dst, p.src
+		}
+		t.flows[key] = f
+	} else if t.active > 0 && now.Sub(f.first) >= t.active {
+		t.report(f, flowEndActive, now)
+		f.packetsOut, f.packetsIn, f.bytesOut, f.bytesIn = 0, 0, 0, 0
+		f.first = now
+	}
+
+	out := p.src == f.client
+	if out {
+		f.packetsOut++
+		f.bytesOut += p.length
+	} else {
+		f.packetsIn++
+		f.bytesIn += p.length
+	}
+	f.last = now
+	if p.proto == unix.IPPROTO_TCP {
+		f.flags |= p.tcpFlags
+		f.advance(p.tcpFlags, out)
+	}
+	t.touch(f)
+}
+
+// touch moves f to the front of the LRU list of its timeout class.
+func (t *flowTable) touch(f *flow) {
+	class := f.timeoutClass()
+	if f.elem != nil && class == f.class {
+		t.lrus[class].MoveToFront(f.elem)
+		return
+	}
+	if f.elem != nil {
+		t.lrus[f.class].Remove(f.elem)
+	}
+	f.class = class
+	f.elem = t.lrus[class].PushFront(f)
+}
+
+// oldest returns the least recently seen flow.
+func (t *flowTable) oldest() *flow {
+	var oldest *flow
+	for _, l := range t.lrus {
+		if back := l.Back(); back != nil {
+			if f := back.Value.(*flow); oldest == nil || f.last.Before(oldest.last) {
+				oldest = f
+			}
+		}
+	}
+	return oldest
+}
+
+// aggregateDNS clears the client port of a UDP DNS packet. Each query comes
+// from a fresh port, so a client's lookups through one resolver form a single
+// flow instead of one flow_end per query.
+func aggregateDNS(p packetInfo) packetInfo {
+	if p.proto != unix.IPPROTO_UDP {
+		return p
+	}
+	switch {
+	case p.dst.Port() == dnsPort:
+		p.src = netip.AddrPortFrom(p.src.Addr(), 0)
+	case p.src.Port() == dnsPort:
+		p.dst = netip.AddrPortFrom(p.dst.Addr(), 0)
+	}
+	return p
+}
+
+// advance moves the TCP state machine for a segment in direction out.
+func (f *flow) advance(flags uint8, out bool) {
+	switch {
+	case flags&tcpRST != 0:
+		f.state = tcpReset
+		return
+	case flags&tcpSYN != 0 && flags&tcpACK == 0:
+		if f.state == "" {
+			f.state = tcpSynSent
+		}
+	case flags&tcpSYN != 0:
+		if f.state == "" || f.state == tcpSynSent {
+			f.state = tcpSynReceived
+		}
+	case flags&tcpACK != 0 && f.state == tcpSynReceived && out:
+		f.state = tcpEstablished
+	case f.state == "":
+		// Picked up mid-stream.
+		f.state = tcpEstablished
+	}
+	if flags&tcpFIN != 0 {
+		if out {
+			f.finOut = true
+		} else {
+			f.finIn = true
+		}
+		f.state = tcpClosing
+		if f.finOut && f.finIn {
+			f.state = tcpClosed
+		}
+	}
+}
+
+// Expire ends the flows that timed out by now. Each class list is ordered
+// by last packet, so its scan stops at the first flow still within the
+// class timeout.
+func (t *flowTable) Expire(now time.Time) {
+	for class, l := range t.lrus {
+		for e := l.Back(); e != nil; {
+			f := e.Value.(*flow)
+			if now.Sub(f.last) < t.timeouts[class] {
+				break
+			}
+			prev := e.Prev()
+			reason := flowEndIdle
+			switch f.state {
+			case tcpClosed:
+				reason = flowEndClosed
+			case tcpReset:
+				reason = flowEndReset
+			}
+			t.end(f, reason, now)
+			e = prev
+		}
+	}
+}
+
+// Flush ends every flow, e.g. on shutdown.
+func (t *flowTable) Flush(now time.Time) {
+	for f := t.oldest(); f != nil; f = t.oldest() {
+		t.end(f, flowEndStopped, now)
+	}
+}
+
+func (t *flowTable) end(f *flow, reason string, now time.Time) {
+	t.report(f, reason, now)
+	t.lrus[f.class].Remove(f.elem)
+	delete(t.flows, f.key)
+}
+
+// report publishes the counters of f.
+func (t *flowTable) report(f *flow, reason string, now time.Time) {
+	if f.packetsOut+f.packetsIn == 0 {
+		return
+	}
+	ev := events.Event{
+		Kind:            events.KindFlowEnd,
+		Timestamp:       now.UTC(),
+		SourceIP:        f.client.Addr().String(),
+		SourcePort:      f.client.Port(),
+		DestinationIP:   f.server.Addr().String(),
+		DestinationPort: f.server.Port(),
+		Transport:       protoName(f.key.proto),
+		Reason:          reason,
+		Flow: &events.FlowInfo{
+			PacketsOut: f.packetsOut,
+			PacketsIn:  f.packetsIn,
+			BytesOut:   f.bytesOut,
+			BytesIn:    f.bytesIn,
+			TCPFlags:   flagString(f.flags),
+			State:      f.state,
+			FirstSeen:  f.first.UTC(),
+			LastSeen:   f.last.UTC(),
+		},
+	}
+	if t.domain != nil {
+		ev.Domain = t.domain(ev.DestinationIP)
+		if ev.Domain == "" {
+			ev.Domain = t.domain(ev.SourceIP)
+		}
+	}
+	t.emit(ev)
+}
+
+// parsePacket extracts the 5-tuple, IP length and TCP flags of an IPv4 or
+// IPv6 frame. Ports stay zero for protocols without them, non-first
+// fragments and IPv6 packets with extension headers.
+func parsePacket(frame []byte) (packetInfo, bool) {
+	if len(frame) < 14 {
+		return packetInfo{}, false
+	}
+	var p packetInfo
+	var l4 []byte
+	switch binary.BigEndian.Uint16(frame[12:14]) {
+	case unix.ETH_P_IP:
+		ip := frame[14:]
+		if len(ip) < 20 {
+			return packetInfo{}, false
+		}
+		ihl := int(ip[0]&0x0F) * 4
+		if ihl < 20 || len(ip) < ihl {
+			return packetInfo{}, false
+		}
+		p.proto = ip[9]
+		p.length = uint64(binary.BigEndian.Uint16(ip[2:4]))
+		src, dst := netip.AddrFrom4([4]byte(ip[12:16])), netip.AddrFrom4([4]byte(ip[16:20]))
+		p.src, p.dst = netip.AddrPortFrom(src, 0), netip.AddrPortFrom(dst, 0)
+		if binary.BigEndian.Uint16(ip[6:8])&0x1FFF == 0 {
+			l4 = ip[ihl:]
+		}
+	case unix.ETH_P_IPV6:
+		ip := frame[14:]
+		if len(ip) < 40 {
+			return packetInfo{}, false
+		}
+		p.proto = ip[6]
+		p.length = uint64(binary.BigEndian.Uint16(ip[4:6])) + 40
+		src, dst := netip.AddrFrom16([16]byte(ip[8:24])), netip.AddrFrom16([16]byte(ip[24:40]))
+		p.src, p.dst = netip.AddrPortFrom(src, 0), netip.AddrPortFrom(dst, 0)
+		l4 = ip[40:]
+	default:
+		return packetInfo{}, false
+	}
+	if p.length == 0 {
+		p.length = uint64(len(frame) - 14)
+	}
+	switch p.proto {
+	case unix.IPPROTO_TCP, unix.IPPROTO_UDP:
+		if len(l4) < 4 {
+			break
+		}
+		p.src = netip.AddrPortFrom(p.src.Addr(), binary.BigEndian.Uint16(l4[0:2]))
+		p.dst = netip.AddrPortFrom(p.dst.Addr(), binary.BigEndian.Uint16(l4[2:4]))
+		if p.proto == unix.IPPROTO_TCP && len(l4) >= 14 {
+			p.tcpFlags = l4[13]
+		}
+	}
+	return p, true
+}
+
+func protoName(proto uint8) string {
+	switch proto {
+	case unix.IPPROTO_TCP:
+		return "tcp"
+	case unix.IPPROTO_UDP:
+		return "udp"
+	case unix.IPPROTO_ICMP:
+		return "icmp"
+	case unix.IPPROTO_ICMPV6:
+		return "icmpv6"
+	}
+	return strconv.Itoa(int(proto))
+}
+
+// flagString renders TCP flags as letters in header bit order.
+func flagString(flags uint8) string {
+	const letters = "FSRPAUEC"
+	var out []byte
+	for i := 0; i < len(letters); i++ {
+		if flags&(1<<i) != 0 {
+			out = append(out, letters[i])
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/kidos/kidosserver/pkg/config"
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+var (
+	kid    = netip.MustParseAddr("192.168.50.23")
+	remote = netip.MustParseAddr("203.0.113.7")
+)
+
+func newTestFlows(max, activeSec int) (*flowTable, *recorder) {
+	rec := &recorder{}
+	cfg := config.MonitorConfig{MaxFlows: max, IdleSec: 60, TCPIdleSec: 300, ActiveSec: activeSec}
+	return newFlowTable(cfg, rec.Publish, nil), rec
+}
+
+func tcp(src, dst netip.AddrPort, flags uint8) packetInfo {
+	return packetInfo{proto: unix.IPPROTO_TCP, src: src, dst: dst, length: 60, tcpFlags: flags}
+}
+
+func udp(src, dst netip.AddrPort) packetInfo {
+	return packetInfo{proto: unix.IPPROTO_UDP, src: src, dst: dst, length: 80}
+}
+
+func reasons(evs []events.Event) []string {
+	out := make([]string, len(evs))
+	for i, ev := range evs {
+		out[i] = ev.Reason
+	}
+	return out
+}
+
+func TestAdvance(t *testing.T) {
+	type seg struct {
+		flags uint8
+		out   bool
+	}
+	for _, tc := range []struct {
+		name string
+		segs []seg
+		want string
+	}{
+		{"syn", []seg{{tcpSYN, true}}, tcpSynSent},
+		{"syn-ack", []seg{{tcpSYN, true}, {tcpSYN | tcpACK, false}}, tcpSynReceived},
+		{"handshake", []seg{{tcpSYN, true}, {tcpSYN | tcpACK, false}, {tcpACK, true}}, tcpEstablished},
+		{"ack from the server does not finish the handshake", []seg{{tcpSYN, true}, {tcpSYN | tcpACK, false}, {tcpACK, false}}, tcpSynReceived},
+		{"mid-stream", []seg{{tcpACK | tcpPSH, false}}, tcpEstablished},
+		{"half closed", []seg{{tcpACK, true}, {tcpFIN | tcpACK, true}}, tcpClosing},
+		{"closed", []seg{{tcpACK, true}, {tcpFIN | tcpACK, true}, {tcpFIN | tcpACK, false}}, tcpClosed},
+		{"fin twice from one side", []seg{{tcpFIN | tcpACK, true}, {tcpFIN | tcpACK, true}}, tcpClosing},
+		{"reset", []seg{{tcpSYN, true}, {tcpRST | tcpACK, false}}, tcpReset},
+	} {
+		f := &flow{}
+		for _, s := range tc.segs {
+			f.advance(s.flags, s.out)
+		}
+		if f.state != tc.want {
+			t.Errorf("%s: state %q, want %q", tc.name, f.state, tc.want)
+		}
+	}
+}
+
+func TestAggregateDNS(t *testing.T) {
+	resolver := netip.AddrPortFrom(remote, dnsPort)
+	q := aggregateDNS(udp(netip.AddrPortFrom(kid, 51000), resolver))
+	if q.src != netip.AddrPortFrom(kid, 0) || q.dst != resolver {
+		t.Fatalf("query = %v -> %v", q.src, q.dst)
+	}
+	a := aggregateDNS(udp(resolver, netip.AddrPortFrom(kid, 51001)))
+	if a.dst != netip.AddrPortFrom(kid, 0) || a.src != resolver {
+		t.Fatalf("answer = %v -> %v", a.src, a.dst)
+	}
+	// TCP and other UDP ports are left alone.
+	for _, p := range []packetInfo{
+		tcp(netip.AddrPortFrom(kid, 51000), resolver, tcpSYN),
+		udp(netip.AddrPortFrom(kid, 51000), netip.AddrPortFrom(remote, 443)),
+	} {
+		if got := aggregateDNS(p); got != p {
+			t.Fatalf("aggregateDNS changed %+v to %+v", p, got)
+		}
+	}
+
+	ft, rec := newTestFlows(0, 0)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	for port := uint16(50000); port < 50010; port++ {
+		ft.Observe(udp(netip.AddrPortFrom(kid, port), resolver), now)
+		ft.Observe(udp(resolver, netip.AddrPortFrom(kid, port)), now)
+	}
+	ft.Flush(now)
+	if len(rec.events) != 1 || rec.events[0].Flow.PacketsOut != 10 || rec.events[0].Flow.PacketsIn != 10 || rec.events[0].SourcePort != 0 {
+		t.Fatalf("DNS lookups reported as %d flows: %+v", len(rec.events), rec.events)
+	}
+}
+
+func TestFlowExpiry(t *testing.T) {
+	ft, rec := newTestFlows(0, 0)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	client := func(port uint16) netip.AddrPort { return netip.AddrPortFrom(kid, port) }
+	server := netip.AddrPortFrom(remote, 443)
+
+	ft.Observe(udp(client(40000), server), now)
+	ft.Observe(tcp(client(40001), server, tcpSYN), now)
+	ft.Observe(tcp(server, client(40001), tcpSYN|tcpACK), now)
+	ft.Observe(tcp(client(40001), server, tcpACK), now)
+	ft.Observe(tcp(client(40002), server, tcpSYN), now)
+	ft.Observe(tcp(server, client(40002), tcpRST|tcpACK), now)
+	ft.Observe(tcp(client(40003), server, tcpFIN|tcpACK), now)
+	ft.Observe(tcp(server, client(40003), tcpFIN|tcpACK), now)
+
+	ft.Expire(now.Add(closeGrace - time.Second))
+	if len(rec.events) != 0 {
+		t.Fatalf("expired early: %v", reasons(rec.events))
+	}
+	ft.Expire(now.Add(closeGrace))
+	if got := reasons(rec.events); len(got) != 2 || !slices.Contains(got, flowEndReset) || !slices.Contains(got, flowEndClosed) {
+		t.Fatalf("after the close grace: %v", got)
+	}
+	ft.Expire(now.Add(time.Minute))
+	if got := reasons(rec.events); len(got) != 3 || got[2] != flowEndIdle || rec.events[2].Transport != "udp" {
+		t.Fatalf("after the idle timeout: %v", got)
+	}
+	ft.Expire(now.Add(5*time.Minute - time.Second))
+	if len(rec.events) != 3 {
+		t.Fatal("open TCP flow ended before its idle timeout")
+	}
+	ft.Expire(now.Add(5 * time.Minute))
+	last := rec.events[len(rec.events)-1]
+	if len(rec.events) != 4 || last.Reason != flowEndIdle || last.Flow.State != tcpEstablished || last.Flow.TCPFlags != "SA" {
+		t.Fatalf("after the TCP idle timeout: %+v", last)
+	}
+	if len(ft.flows) != 0 {
+		t.Fatalf("%d flows left", len(ft.flows))
+	}
+}
+
+func TestFlowExpiryFollowsStateChanges(t *testing.T) {
+	ft, rec := newTestFlows(0, 0)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	c, s := netip.AddrPortFrom(kid, 40000), netip.AddrPortFrom(remote, 443)
+	// A younger flow in the close grace must not hide an older one that
+	// is still open.
+	ft.Observe(tcp(c, s, tcpACK), now)
+	ft.Observe(tcp(netip.AddrPortFrom(kid, 40001), s, tcpRST), now.Add(time.Minute))
+	ft.Observe(tcp(c, s, tcpRST), now.Add(2*time.Minute))
+	ft.Expire(now.Add(2*time.Minute + closeGrace))
+	if got := reasons(rec.events); len(got) != 2 || got[0] != flowEndReset || got[1] != flowEndReset {
+		t.Fatalf("ended %v, want both resets", got)
+	}
+}
+
+func TestFlowEviction(t *testing.T) {
+	ft, rec := newTestFlows(3, 0)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	s := netip.AddrPortFrom(remote, 443)
+	for i := uint16(0); i < 3; i++ {
+		ft.Observe(tcp(netip.AddrPortFrom(kid, 40000+i), s, tcpACK), now.Add(time.Duration(i)*time.Second))
+	}
+	// Touching the first flow makes the second the least recently seen,
+	// even across timeout classes.
+	ft.Observe(tcp(netip.AddrPortFrom(kid, 40000), s, tcpACK), now.Add(3*time.Second))
+	ft.Observe(udp(netip.AddrPortFrom(kid, 40003), s), now.Add(4*time.Second))
+	if len(rec.events) != 1 || rec.events[0].Reason != flowEndEvicted || rec.events[0].SourcePort != 40001 {
+		t.Fatalf("evicted %+v", rec.events)
+	}
+	if len(ft.flows) != 3 {
+		t.Fatalf("table holds %d flows, want 3", len(ft.flows))
+	}
+	ft.Flush(now.Add(5 * time.Second))
+	if got := reasons(rec.events); len(got) != 4 || got[1] != flowEndStopped {
+		t.Fatalf("flush ended %v", got)
+	}
+	if rec.events[1].SourcePort != 40002 || rec.events[3].SourcePort != 40003 {
+		t.Fatal("flush did not end the oldest flows first")
+	}
+}
+
+func TestActiveTimeout(t *testing.T) {
+	ft, rec := newTestFlows(0, 60)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	c, s := netip.AddrPortFrom(kid, 40000), netip.AddrPortFrom(remote, 443)
+	for i := 0; i < 5; i++ {
+		ft.Observe(tcp(c, s, tcpACK), now.Add(time.Duration(i)*15*time.Second))
+		ft.Observe(tcp(s, c, tcpACK), now.Add(time.Duration(i)*15*time.Second))
+	}
+	if len(rec.events) != 1 {
+		t.Fatalf("reported %d times, want once", len(rec.events))
+	}
+	ev := rec.events[0]
+	if ev.Reason != flowEndActive || ev.Flow.PacketsOut != 4 || ev.Flow.PacketsIn != 4 || !ev.Flow.LastSeen.Equal(now.Add(45*time.Second)) {
+		t.Fatalf("active report %+v %+v", ev, ev.Flow)
+	}
+	// The counters restart with the packet that crossed the timeout.
+	ft.Flush(now.Add(61 * time.Second))
+	ev = rec.events[1]
+	if ev.Reason != flowEndStopped || ev.Flow.PacketsOut != 1 || ev.Flow.PacketsIn != 1 || !ev.Flow.FirstSeen.Equal(now.Add(time.Minute)) {
+		t.Fatalf("after the active report %+v %+v", ev, ev.Flow)
+	}
+}
+
+func TestSynReusesClosedFlow(t *testing.T) {
+	ft, rec := newTestFlows(0, 0)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	c, s := netip.AddrPortFrom(kid, 40000), netip.AddrPortFrom(remote, 443)
+	ft.Observe(tcp(c, s, tcpSYN), now)
+	ft.Observe(tcp(s, c, tcpSYN|tcpACK), now)
+	ft.Observe(tcp(c, s, tcpFIN|tcpACK), now)
+	ft.Observe(tcp(s, c, tcpFIN|tcpACK), now)
+	// A trailing ACK stays with the closed flow.
+	ft.Observe(tcp(c, s, tcpACK), now.Add(time.Second))
+	if len(rec.events) != 0 {
+		t.Fatalf("trailing ACK ended the flow: %v", reasons(rec.events))
+	}
+	ft.Observe(tcp(c, s, tcpSYN), now.Add(2*time.Second))
+	if len(rec.events) != 1 || rec.events[0].Reason != flowEndClosed || rec.events[0].Flow.PacketsOut != 3 {
+		t.Fatalf("reuse ended %+v", rec.events)
+	}
+	f := ft.flows[makeFlowKey(unix.IPPROTO_TCP, c, s)]
+	if f == nil || f.state != tcpSynSent || f.packetsOut != 1 || f.class != classTCP {
+		t.Fatalf("new flow = %+v", f)
+	}
+	// A SYN-ACK seen first makes its receiver the client.
+	ft.Observe(tcp(s, netip.AddrPortFrom(kid, 40001), tcpSYN|tcpACK), now)
+	if f := ft.flows[makeFlowKey(unix.IPPROTO_TCP, s, netip.AddrPortFrom(kid, 40001))]; f.client.Port() != 40001 {
+		t.Fatalf("client = %v", f.client)
+	}
+}
@@ -17,17 +17,35 @@ import (
 	"github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
 
+	"github.com/kidos/kidosserver/pkg/capture"
 	"github.com/kidos/kidosserver/pkg/config"
 	"github.com/kidos/kidosserver/pkg/events"
 	"github.com/kidos/kidosserver/pkg/logging"
 )
 
+const (
+	pollTimeoutMS     = 1000
+	flowSweepInterval = time.Second
+	// pairRetention drops pairs once they leave the longest window.
+	pairRetention = time.Hour
+	// idleRefresh is how often a pair without new packets is still reported
+	// so readers see its windows drain; active pairs go out every summary.
+	idleRefresh = time.Minute
+	// maxSummaryPairs keeps summaries within what the web server accepts.
+	maxSummaryPairs = 10000
+)
+
+// pairStats counts the packets of one address pair: since the last summary
+// and in sliding windows.
 type pairStats struct {
 	internal string
 	external string
 	category string
 	incoming uint64
 	outgoing uint64
+	window   slidingCounter
+	lastSeen time.Time
+	reported time.Time
 	domain   string
 }
 
@@ -43,16 +61,24 @@ func main() {
 		logging.Fatalf("load config: %v", err)
 	}
 
-	publisher := events.NewHTTPPublisher(events.BuildEndpoint(cfg.Web.Listen, "/api/events"))
+	publisher := events.NewPublisher(cfg, "monitor")
 	defer publisher.Close()
 	go publisher.Run(ctx)
 
-	if err := monitorLoop(ctx, *ifaceName, publisher); err != nil && !errors.Is(err, context.Canceled) {
+	sink, filter, err := capture.FromConfig(cfg.Capture, "monitor", *ifaceName)
+	if err != nil {
+		logging.Errorf("capture disabled: %v", err)
+	}
+	if sink != nil {
+		defer sink.Close()
+	}
+
+	if err := monitorLoop(ctx, *ifaceName, cfg.Monitor, publisher, sink, filter); err != nil && !errors.Is(err, context.Canceled) {
 		logging.Fatalf("monitor error: %v", err)
 	}
 }
 
-func monitorLoop(ctx context.Context, iface string, publisher *events.HTTPPublisher) error {
+func monitorLoop(ctx context.Context, iface string, mcfg config.MonitorConfig, publisher events.Publisher, sink *capture.Sink, filter capture.Filter) error {
 	link, err := netlink.LinkByName(iface)
 	if err != nil {
 		return err
@@ -77,23 +103,47 @@ func monitorLoop(ctx context.Context, iface string, publisher *events.HTTPPublis
 	pairCounts := make(map[string]*pairStats)
 	dnsCache := make(map[string]string)
 	lastPublish := time.Now()
+	publishInterval := time.Duration(mcfg.PublishIntervalMS) * time.Millisecond
+	if publishInterval <= 0 {
+		publishInterval = 2 * time.Second
+	}
+	pollTimeout := min(pollTimeoutMS, int(publishInterval/time.Millisecond))
+	disco := newDiscovery(publisher)
+	flows := newFlowTable(mcfg, publisher.Publish, func(ip string) string { return dnsCache[ip] })
+	lastSweep := time.Now()
 
 	logging.Infof("monitor reading packets on %s (ifindex=%d)", iface, link.Attrs().Index)
 
 	for {
 		select {
 		case <-ctx.Done():
+			flows.Flush(time.Now())
 			return ctx.Err()
 		default:
 		}
 
+		// Wake up regularly so flows expire and summaries go out without
+		// traffic.
 		pfds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
-		if _, err := unix.Poll(pfds, -1); err != nil {
+		if _, err := unix.Poll(pfds, pollTimeout); err != nil {
 			if errors.Is(err, syscall.EINTR) {
 				continue
 			}
 			return err
 		}
+		if now := time.Now(); now.Sub(lastSweep) >= flowSweepInterval {
+			flows.Expire(now)
+			lastSweep = now
+		}
+		if now := time.Now(); now.Sub(lastPublish) >= publishInterval {
+			if sink != nil {
+				if err := sink.Flush(); err != nil {
+					logging.Errorf("flush capture: %v", err)
+				}
+			}
+			publishPairCounts(publisher, pairCounts, now)
+			lastPublish = now
+		}
 		if pfds[0].Revents&unix.POLLIN == 0 {
 			continue
 		}
@@ -110,26 +160,112 @@ func monitorLoop(ctx context.Context, iface string, publisher *events.HTTPPublis
 		}
 
 		frame := buf[:n]
+		now := time.Now()
 		maybeCacheDNS(frame, dnsCache)
+		disco.Observe(frame, now)
+		if p, ok := parsePacket(frame); ok {
+			flows.Observe(p, now)
+		}
 
 		src, dst := extractIPs(frame)
 		if src != "" && dst != "" {
-			updatePairCounts(pairCounts, src, dst, dnsCache)
+			updatePairCounts(pairCounts, src, dst, dnsCache, now)
 		}
 
-		if time.Since(lastPublish) >= 2*time.Second {
-			publishPairCounts(publisher, pairCounts)
-			lastPublish = time.Now()
+		if sink != nil {
+			captureFrame(sink, filter, frame, src, dst)
 		}
 	}
 }
 
-func publishPairCounts(publisher *events.HTTPPublisher, counts map[string]*pairStats) {
-	if len(counts) == 0 {
+// captureFrame writes mirrored frames that look like resolver bypass attempts or
+// involve a watched device.
+func captureFrame(sink *capture.Sink, filter capture.Filter, frame []byte, src, dst string) {
+	if len(frame) < 14 {
 		return
 	}
+	action, reason := "mirror", ""
+	if r := bypassReason(frame); r != "" {
+		action, reason = "bypass", r
+	}
+	srcMAC := net.HardwareAddr(frame[6:12]).String()
+	dstMAC := net.HardwareAddr(frame[0:6]).String()
+	if !filter.Match(action, src, dst, srcMAC, dstMAC) {
+		return
+	}
+	comment := "verdict=" + action
+	if reason != "" {
+		comment += " reason=" + reason
+	}
+	if err := sink.Write(time.Now(), frame, comment); err != nil {
+		logging.Errorf("capture frame: %v", err)
+	}
+}
+
+// bypassReason flags encrypted DNS transports that sidestep the inspector.
+func bypassReason(frame []byte) string {
+	proto, srcPort, dstPort, ok := transportPorts(frame)
+	if !ok {
+		return ""
+	}
+	if srcPort != 853 && dstPort != 853 {
+		return ""
+	}
+	switch proto {
+	case unix.IPPROTO_TCP:
+		return "dns-over-tls"
+	case unix.IPPROTO_UDP:
+		return "dns-over-quic"
+	}
+	return ""
+}
+
+// transportPorts returns the L4 protocol and ports for IPv4/IPv6 TCP or UDP frames.
+func transportPorts(frame []byte) (proto uint8, srcPort, dstPort uint16, ok bool) {
+	if len(frame) < 14 {
+		return 0, 0, 0, false
+	}
+	var offset int
+	switch binary.BigEndian.Uint16(frame[12:14]) {
+	case unix.ETH_P_IP:
+		if len(frame) < 34 {
+			return 0, 0, 0, false
+		}
+		offset = 14 + int(frame[14]&0x0F)*4
+		proto = frame[23]
+	case unix.ETH_P_IPV6:
+		if len(frame) < 54 {
+			return 0, 0, 0, false
+		}
+		offset = 14 + 40
+		proto = frame[20]
+	default:
+		return 0, 0, 0, false
+	}
+	if proto != unix.IPPROTO_TCP && proto != unix.IPPROTO_UDP {
+		return 0, 0, 0, false
+	}
+	if len(frame) < offset+4 {
+		return 0, 0, 0, false
+	}
+	return proto, binary.BigEndian.Uint16(frame[offset:]), binary.BigEndian.Uint16(frame[offset+2:]), true
+}
+
+// publishPairCounts reports each pair with packets since the previous
+// summary, and every idleRefresh each other pair seen within the last hour,
+// with those packets and its sliding windows. It then starts a new interval
+// and forgets pairs idle for longer than the longest window.
+func publishPairCounts(publisher events.Publisher, counts map[string]*pairStats, now time.Time) {
 	out := make([]events.PairCount, 0, len(counts))
-	for _, stats := range counts {
+	for key, stats := range counts {
+		if now.Sub(stats.lastSeen) >= pairRetention {
+			delete(counts, key)
+			continue
+		}
+		if stats.incoming+stats.outgoing == 0 && now.Sub(stats.reported) < idleRefresh {
+			continue
+		}
+		stats.reported = now
 		out = append(out, events.PairCount{
 			Category:       stats.category,
 			Internal:       stats.internal,
@@ -137,25 +273,35 @@ func publishPairCounts(publisher *events.HTTPPublisher, counts map[string]*pairS
 			ExternalDomain: stats.domain,
 			Incoming:       stats.incoming,
 			Outgoing:       stats.outgoing,
+			Last10s:        stats.window.window(now, 10*time.Second),
+			Last1m:         stats.window.window(now, time.Minute),
+			Last1h:         stats.window.window(now, time.Hour),
 		})
+		stats.incoming, stats.outgoing = 0, 0
+	}
+	if len(out) == 0 {
+		return
 	}
 	sort.Slice(out, func(i, j int) bool {
-		totalI := out[i].Incoming + out[i].Outgoing
-		totalJ := out[j].Incoming + out[j].Outgoing
+		totalI := out[i].Last1m.Incoming + out[i].Last1m.Outgoing
+		totalJ := out[j].Last1m.Incoming + out[j].Last1m.Outgoing
 		if totalI == totalJ {
 			return out[i].Internal+"->"+out[i].External < out[j].Internal+"->"+out[j].External
 		}
 		return totalI > totalJ
 	})
+	if len(out) > maxSummaryPairs {
+		out = out[:maxSummaryPairs]
+	}
 
 	publisher.Publish(events.Event{
 		Kind:       "ip_pair_summary",
-		Timestamp:  time.Now().UTC(),
+		Timestamp:  now.UTC(),
 		PairCounts: out,
 	})
 }
 
-func updatePairCounts(counts map[string]*pairStats, src, dst string, dnsCache map[string]string) {
+func updatePairCounts(counts map[string]*pairStats, src, dst string, dnsCache map[string]string, now time.Time) {
 	key, internalIP, externalIP, category, direction := canonicalPair(src, dst)
 	if key == "" {
 		return
@@ -168,9 +314,12 @@ func updatePairCounts(counts map[string]*pairStats, src, dst string, dnsCache ma
 	switch direction {
 	case "incoming":
 		stats.incoming++
+		stats.window.add(now, true)
 	case "outgoing":
 		stats.outgoing++
+		stats.window.add(now, false)
 	}
+	stats.lastSeen = now
 	if domain := dnsCache[externalIP]; domain != "" {
 		stats.domain = domain
 	}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+// bucket holds the packets of one second or one minute, identified by at in
+// units of the bucket's span since the epoch.
+type bucket struct {
+	at       int64
+	incoming uint32
+	outgoing uint32
+}
+
+// slidingCounter counts packets per second over the last minute and per
+// minute over the last hour, so windows of up to an hour can be summed
+// without keeping every packet. The hour window is accurate to the minute.
+type slidingCounter struct {
+	seconds [60]bucket
+	minutes [60]bucket
+}
+
+func (c *slidingCounter) add(now time.Time, incoming bool) {
+	sec := now.Unix()
+	for _, b := range []*bucket{bumpBucket(&c.seconds, sec), bumpBucket(&c.minutes, sec/60)} {
+		if incoming {
+			b.incoming++
+		} else {
+			b.outgoing++
+		}
+	}
+}
+
+// bumpBucket returns the bucket for at, clearing it if it last held an
+// older span.
+func bumpBucket(ring *[60]bucket, at int64) *bucket {
+	b := &ring[at%int64(len(ring))]
+	if b.at != at {
+		*b = bucket{at: at}
+	}
+	return b
+}
+
+// window sums the packets of the last span, which is at most an hour.
+func (c *slidingCounter) window(now time.Time, span time.Duration) events.Traffic {
+	ring, unit := &c.seconds, time.Second
+	if span > time.Minute {
+		ring, unit = &c.minutes, time.Minute
+	}
+	cur := now.Unix() / int64(unit/time.Second)
+	oldest := cur - int64(span/unit)
+	var t events.Traffic
+	for _, b := range ring {
+		if b.at > oldest && b.at <= cur {
+			t.Incoming += uint64(b.incoming)
+			t.Outgoing += uint64(b.outgoing)
+		}
+	}
+	return t
+}
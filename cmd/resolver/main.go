@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"github.com/kidos/kidosserver/pkg/config"
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/logging"
+	"github.com/kidos/kidosserver/pkg/resolver"
+	"github.com/kidos/kidosserver/pkg/rules"
+)
+
+// ruleReloadInterval is how often the rules file is checked for edits.
+const ruleReloadInterval = 2 * time.Second
+
+func main() {
+	listenFlag := flag.String("listen", "", "override dns.resolverListen")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	cfg, err := config.Load(filepath.Join("data", "config.json"))
+	if err != nil {
+		logging.Fatalf("load config: %v", err)
+	}
+
+	listen := cfg.DNS.ResolverListen
+	if *listenFlag != "" {
+		listen = *listenFlag
+	}
+
+	publisher := events.NewPublisher(cfg, "resolver")
+	defer publisher.Close()
+	go publisher.Run(ctx)
+
+	timeout := time.Duration(cfg.DNS.TimeoutMS) * time.Millisecond
+	upstreams := make([]resolver.Upstream, 0, len(cfg.DNS.Upstreams))
+	for _, upCfg := range cfg.DNS.Upstreams {
+		up, err := resolver.NewUpstream(upCfg, timeout)
+		if err != nil {
+			logging.Errorf("skip upstream: %v", err)
+			continue
+		}
+		upstreams = append(upstreams, up)
+	}
+	if len(upstreams) == 0 {
+		logging.Fatalf("no upstream resolvers configured")
+	}
+	pool := resolver.NewPool(upstreams)
+	go pool.RunHealthChecks(ctx, time.Duration(cfg.DNS.HealthCheckSec)*time.Second, timeout)
+
+	engine, err := rules.Load(cfg.DNS.RulesFile, cfg.DNS.Blocklist)
+	if err != nil {
+		logging.Errorf("load rules: %v", err)
+	}
+	go engine.Watch(ctx, cfg.DNS.RulesFile, ruleReloadInterval)
+	cache := resolver.NewCache(cfg.DNS.CacheSize, time.Duration(cfg.DNS.CacheMaxTTL)*time.Second)
+	handler := resolver.NewHandler(engine, cache, pool, publisher, timeout)
+	if bp := cfg.BlockPage; bp.Sinkhole != "" {
+		v4 := net.ParseIP(bp.Sinkhole)
+		if v4 == nil || v4.To4() == nil {
+			logging.Fatalf("invalid sinkhole address %q", bp.Sinkhole)
+		}
+		var v6 net.IP
+		if bp.SinkholeV6 != "" {
+			if v6 = net.ParseIP(bp.SinkholeV6); v6 == nil || v6.To4() != nil {
+				logging.Fatalf("invalid sinkhole address %q", bp.SinkholeV6)
+			}
+		}
+		handler.SetSinkhole(v4, v6)
+		logging.Infof("blocked names resolve to %s", bp.Sinkhole)
+	}
+
+	servers := []*mdns.Server{
+		{Addr: listen, Net: "udp", Handler: handler},
+		{Addr: listen, Net: "tcp", Handler: handler},
+	}
+	for _, srv := range servers {
+		srv := srv
+		go func() {
+			logging.Infof("resolver listening on %s/%s", srv.Addr, srv.Net)
+			if err := srv.ListenAndServe(); err != nil && ctx.Err() == nil {
+				logging.Fatalf("dns server %s: %v", srv.Net, err)
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	logging.Infof("shutdown requested")
+	for _, srv := range servers {
+		if err := srv.Shutdown(); err != nil {
+			logging.Errorf("dns server %s shutdown: %v", srv.Net, err)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kidos/kidosserver/pkg/audit"
+	"github.com/kidos/kidosserver/pkg/auth"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+// maxFormBytes bounds account and token request bodies.
+const maxFormBytes = 16 << 10
+
+type credentialsRequest struct {
+	Name     string    `json:"name"`
+	Password string    `json:"password"`
+	Role     auth.Role `json:"role,omitempty"`
+}
+
+// userView is the public form of an account, without its password hash.
+type userView struct {
+	Name    string    `json:"name"`
+	Role    auth.Role `json:"role"`
+	Created time.Time `json:"created"`
+}
+
+type tokenView struct {
+	ID       string    `json:"id"`
+	Label    string    `json:"label"`
+	User     string    `json:"user"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"lastUsed,omitempty"`
+}
+
+func viewUser(u auth.User) userView {
+	return userView{Name: u.Name, Role: u.Role, Created: u.Created}
+}
+
+func viewToken(t auth.Token) tokenView {
+	return tokenView{ID: t.ID, Label: t.Label, User: t.User, Created: t.Created, LastUsed: t.LastUsed}
+}
+
+// decodeCredentials parses a login or setup body. Requiring a JSON content
+// type keeps plain cross-site form posts out.
+func decodeCredentials(w http.ResponseWriter, r *http.Request) (credentialsRequest, bool) {
+	var req credentialsRequest
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "expected application/json")
+		return req, false
+	}
+	return req, decodeBody(w, r, maxFormBytes, &req)
+}
+
+func (a *apiServer) handleSetupStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"needsSetup": a.users.NeedsSetup()})
+}
+
+// handleSetup creates the first admin account and signs it in.
+func (a *apiServer) handleSetup(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeCredentials(w, r)
+	if !ok {
+		return
+	}
+	user, err := a.users.Setup(req.Name, req.Password)
+	if errors.Is(err, auth.ErrAlreadySetUp) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	logging.Infof("created initial admin %q from %s", user.Name, clientIP(r))
+	a.recordAudit(r, audit.Entry{User: user.Name, Role: string(user.Role), Action: "user.setup", Target: user.Name})
+	a.startSession(w, r, user)
+}
+
+func (a *apiServer) handleLogin(w http.ResponseWriter, r *http.Request) {
+	ip := clientIP(r)
+	if !a.logins.Allow(ip) {
+		writeError(w, http.StatusTooManyRequests, "too many failed logins, try again later")
+		return
+	}
+	req, ok := decodeCredentials(w, r)
+	if !ok {
+		return
+	}
+	user, err := a.users.Authenticate(req.Name, req.Password)
+	if err != nil {
+		a.logins.Fail(ip)
+		logging.Infof("failed login for %q from %s", req.Name, ip)
+		a.recordAudit(r, audit.Entry{User: req.Name, Action: "session.login-failed"})
+		writeError(w, http.StatusUnauthorized, "invalid name or password")
+		return
+	}
+	a.logins.Reset(ip)
+	a.recordAudit(r, audit.Entry{User: user.Name, Role: string(user.Role), Action: "session.login"})
+	a.startSession(w, r, user)
+}
+
+func (a *apiServer) startSession(w http.ResponseWriter, r *http.Request, user auth.User) {
+	a.sessions.Prune()
+	sess, err := a.sessions.Create(user.Name)
+	if err != nil {
+		logging.Errorf("create session: %v", err)
+		writeError(w, http.StatusInternalServerError, "create session failed")
+		return
+	}
+	setSessionCookies(w, r, sess)
+	writeJSON(w, http.StatusOK, map[string]any{"user": viewUser(user), "csrfToken": sess.CSRF})
+}
+
+func (a *apiServer) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		a.sessions.Delete(cookie.Value)
+	}
+	clearSessionCookies(w)
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+func (a *apiServer) handleMe(w http.ResponseWriter, r *http.Request) {
+	user, sess, _ := a.identify(r)
+	resp := map[string]any{"user": viewUser(user)}
+	if sess != nil {
+		resp["csrfToken"] = sess.CSRF
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (a *apiServer) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	users := a.users.Users()
+	out := make([]userView, 0, len(users))
+	for _, u := range users {
+		out = append(out, viewUser(u))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"users": out})
+}
+
+func (a *apiServer) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if !decodeBody(w, r, maxFormBytes, &req) {
+		return
+	}
+	if req.Role == "" {
+		req.Role = auth.RoleViewer
+	}
+	user, err := a.users.AddUser(req.Name, req.Password, req.Role)
+	if errors.Is(err, auth.ErrUserExists) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	a.recordAudit(r, audit.Entry{Action: "user.create", Target: user.Name, After: viewUser(user)})
+	writeJSON(w, http.StatusCreated, viewUser(user))
+}
+
+func (a *apiServer) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	before, _ := a.users.User(name)
+	err := a.users.DeleteUser(name)
+	switch {
+	case errors.Is(err, auth.ErrUserNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, auth.ErrLastAdmin):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		logging.Errorf("delete user: %v", err)
+		writeError(w, http.StatusInternalServerError, "delete user failed")
+		return
+	}
+	a.sessions.DeleteUser(name)
+	a.recordAudit(r, audit.Entry{Action: "user.delete", Target: name, Before: viewUser(before)})
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+// handleSetPassword lets admins reset any password and everyone else change
+// their own. Changing one's own password needs the current one, so a stolen
+// session or token cannot lock the owner out; wrong guesses count towards
+// the login lockout.
+func (a *apiServer) handleSetPassword(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	caller := currentUser(r)
+	if caller.Name != name && !caller.Role.Allows(auth.RoleAdmin) {
+		writeError(w, http.StatusForbidden, "insufficient role")
+		return
+	}
+	var req struct {
+		Password        string `json:"password"`
+		CurrentPassword string `json:"currentPassword"`
+	}
+	if !decodeBody(w, r, maxFormBytes, &req) {
+		return
+	}
+	if caller.Name == name {
+		ip := clientIP(r)
+		if !a.logins.Allow(ip) {
+			writeError(w, http.StatusTooManyRequests, "too many failed logins, try again later")
+			return
+		}
+		if _, err := a.users.Authenticate(name, req.CurrentPassword); err != nil {
+			a.logins.Fail(ip)
+			a.recordAudit(r, audit.Entry{Action: "user.password-failed", Target: name})
+			writeError(w, http.StatusForbidden, "current password is incorrect")
+			return
+		}
+		a.logins.Reset(ip)
+	}
+	err := a.users.SetPassword(name, req.Password)
+	if errors.Is(err, auth.ErrUserNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	a.sessions.DeleteUser(name)
+	a.recordAudit(r, audit.Entry{Action: "user.password", Target: name})
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+// handleListTokens shows the caller's API tokens; admins see all of them.
+func (a *apiServer) handleListTokens(w http.ResponseWriter, r *http.Request) {
+	caller := currentUser(r)
+	owner := caller.Name
+	if caller.Role.Allows(auth.RoleAdmin) {
+		owner = ""
+	}
+	tokens := a.users.Tokens(owner)
+	out := make([]tokenView, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, viewToken(t))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tokens": out})
+}
+
+// handleCreateToken issues an API token acting with the caller's role. The
+// secret is only returned here.
+func (a *apiServer) handleCreateToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Label string `json:"label"`
+	}
+	if !decodeBody(w, r, maxFormBytes, &req) {
+		return
+	}
+	secret, token, err := a.users.CreateToken(currentUser(r).Name, req.Label)
+	if err != nil {
+		logging.Errorf("create token: %v", err)
+		writeError(w, http.StatusInternalServerError, "create token failed")
+		return
+	}
+	a.recordAudit(r, audit.Entry{Action: "token.create", Target: token.ID, After: viewToken(token)})
+	writeJSON(w, http.StatusCreated, map[string]any{"token": secret, "info": viewToken(token)})
+}
+
+func (a *apiServer) handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	caller := currentUser(r)
+	owner := caller.Name
+	if caller.Role.Allows(auth.RoleAdmin) {
+		owner = ""
+	}
+	id := mux.Vars(r)["id"]
+	err := a.users.RevokeToken(id, owner)
+	if errors.Is(err, auth.ErrTokenNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		logging.Errorf("revoke token: %v", err)
+		writeError(w, http.StatusInternalServerError, "revoke token failed")
+		return
+	}
+	a.recordAudit(r, audit.Entry{Action: "token.revoke", Target: id})
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
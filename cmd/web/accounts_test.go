@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kidos/kidosserver/pkg/auth"
+)
+
+func TestSetPasswordNeedsCurrentForSelf(t *testing.T) {
+	a := newTestServer(t)
+	users, err := auth.Open(filepath.Join(t.TempDir(), "users.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.users, a.sessions, a.logins = users, auth.NewSessions(), newLoginLimiter()
+	admin, err := users.Setup("admin", "admin-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := users.AddUser("parent", "parent-password", auth.RoleParent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setPassword := func(caller auth.User, name, body string) int {
+		r := httptest.NewRequest(http.MethodPut, "/api/users/"+name+"/password", strings.NewReader(body))
+		r = mux.SetURLVars(r, map[string]string{"name": name})
+		r = r.WithContext(context.WithValue(r.Context(), userKey{}, caller))
+		w := httptest.NewRecorder()
+		a.handleSetPassword(w, r)
+		return w.Code
+	}
+
+	if code := setPassword(parent, "parent", `{"password":"new-password-1"}`); code != http.StatusForbidden {
+		t.Fatalf("without current password: %d", code)
+	}
+	if code := setPassword(parent, "parent", `{"password":"new-password-1","currentPassword":"wrong"}`); code != http.StatusForbidden {
+		t.Fatalf("wrong current password: %d", code)
+	}
+	if code := setPassword(parent, "parent", `{"password":"new-password-1","currentPassword":"parent-password"}`); code != http.StatusOK {
+		t.Fatalf("right current password: %d", code)
+	}
+	if _, err := users.Authenticate("parent", "new-password-1"); err != nil {
+		t.Fatal("password not changed")
+	}
+	if code := setPassword(parent, "admin", `{"password":"new-password-2"}`); code != http.StatusForbidden {
+		t.Fatalf("parent changed another account: %d", code)
+	}
+	// Admins reset other accounts without knowing their password, but not
+	// their own.
+	if code := setPassword(admin, "parent", `{"password":"new-password-3"}`); code != http.StatusOK {
+		t.Fatalf("admin reset: %d", code)
+	}
+	if code := setPassword(admin, "admin", `{"password":"new-password-4"}`); code != http.StatusForbidden {
+		t.Fatalf("admin self change without current password: %d", code)
+	}
+
+	// Wrong guesses count towards the login lockout.
+	for i := 0; i < loginFailures; i++ {
+		setPassword(admin, "admin", `{"password":"new-password-4","currentPassword":"guess"}`)
+	}
+	if code := setPassword(admin, "admin", `{"password":"new-password-4","currentPassword":"admin-password"}`); code != http.StatusTooManyRequests {
+		t.Fatalf("after repeated guesses: %d", code)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kidos/kidosserver/pkg/audit"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+)
+
+// recordAudit logs a change made by the caller of r. Failures are logged but
+// do not undo the change.
+func (a *apiServer) recordAudit(r *http.Request, e audit.Entry) {
+	if e.User == "" {
+		user := currentUser(r)
+		e.User, e.Role = user.Name, string(user.Role)
+	}
+	e.ClientIP = clientIP(r)
+	if err := a.audit.Record(e); err != nil {
+		logging.Errorf("audit %s: %v", e.Action, err)
+	}
+}
+
+// auditSystem logs a change the server made on its own, such as retiring an
+// expired rule.
+func (a *apiServer) auditSystem(e audit.Entry) {
+	e.User = "system"
+	if err := a.audit.Record(e); err != nil {
+		logging.Errorf("audit %s: %v", e.Action, err)
+	}
+}
+
+func (a *apiServer) handleAudit(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	q := audit.Query{
+		User:   values.Get("user"),
+		Action: values.Get("action"),
+		Limit:  auditDefaultLimit,
+	}
+	var err error
+	if q.Since, err = parseTime(values.Get("since")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid since")
+		return
+	}
+	if q.Until, err = parseTime(values.Get("until")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid until")
+		return
+	}
+	if q.Cursor, err = audit.ParseCursor(values.Get("cursor")); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if raw := values.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		q.Limit = min(n, auditMaxLimit)
+	}
+
+	page, err := a.audit.Query(q)
+	if err != nil {
+		logging.Errorf("query audit: %v", err)
+		writeError(w, http.StatusInternalServerError, "read audit log failed")
+		return
+	}
+	resp := map[string]any{"entries": page.Entries}
+	if !page.NextCursor.IsZero() {
+		resp["nextCursor"] = page.NextCursor.String()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
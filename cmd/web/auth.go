@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/auth"
+)
+
+const (
+	sessionCookie = "kidos_session"
+	csrfCookie    = "kidos_csrf"
+	csrfHeader    = "X-CSRF-Token"
+
+	loginFailures = 5
+	loginLockout  = 15 * time.Minute
+)
+
+type userKey struct{}
+
+// identify resolves the caller from an API token or a session cookie. The
+// session is nil for token callers, which are exempt from CSRF checks.
+func (a *apiServer) identify(r *http.Request) (auth.User, *auth.Session, bool) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		user, ok := a.users.VerifyToken(token)
+		return user, nil, ok
+	}
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return auth.User{}, nil, false
+	}
+	sess, ok := a.sessions.Get(cookie.Value)
+	if !ok {
+		return auth.User{}, nil, false
+	}
+	user, ok := a.users.User(sess.User)
+	if !ok {
+		a.sessions.Delete(sess.ID)
+		return auth.User{}, nil, false
+	}
+	return user, &sess, true
+}
+
+// authorize wraps h so it only runs for callers holding at least role min,
+// and for cookie sessions only when mutating requests carry the CSRF token.
+func (a *apiServer) authorize(min auth.Role, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, sess, ok := a.identify(r)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		if !user.Role.Allows(min) {
+			writeError(w, http.StatusForbidden, "insufficient role")
+			return
+		}
+		if sess != nil && !safeMethod(r.Method) &&
+			subtle.ConstantTimeCompare([]byte(r.Header.Get(csrfHeader)), []byte(sess.CSRF)) != 1 {
+			writeError(w, http.StatusForbidden, "missing or invalid csrf token")
+			return
+		}
+		h(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	}
+}
+
+// requireLogin redirects unauthenticated browsers to the login page.
+func (a *apiServer) requireLogin(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" || r.URL.Path == "/index.html" {
+			if _, _, ok := a.identify(r); !ok {
+				http.Redirect(w, r, "/login.html", http.StatusFound)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// currentUser returns the caller stored by authorize.
+func currentUser(r *http.Request) auth.User {
+	user, _ := r.Context().Value(userKey{}).(auth.User)
+	return user
+}
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// sameOrigin rejects cross-site websocket upgrades; clients sending no Origin
+// header (non-browsers) still need credentials to get past authorize.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func setSessionCookies(w http.ResponseWriter, r *http.Request, sess auth.Session) {
+	secure := r.TLS != nil
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    sess.ID,
+		Path:     "/",
+		MaxAge:   int(auth.SessionMax / time.Second),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	// Readable by the SPA so it can echo the value in the CSRF header.
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    sess.CSRF,
+		Path:     "/",
+		MaxAge:   int(auth.SessionMax / time.Second),
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func clearSessionCookies(w http.ResponseWriter) {
+	for _, name := range []string{sessionCookie, csrfCookie} {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: "", Path: "/", MaxAge: -1})
+	}
+}
+
+// clientIP returns the remote address of r without its port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// loginLimiter locks out a client address after repeated failed logins.
+type loginLimiter struct {
+	mu       sync.Mutex
+	failures map[string]*loginFailure
+}
+
+type loginFailure struct {
+	count int
+	first time.Time
+}
+
+func newLoginLimiter() *loginLimiter {
+	return &loginLimiter{failures: make(map[string]*loginFailure)}
+}
+
+// Allow reports whether ip may attempt another login.
+func (l *loginLimiter) Allow(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, ok := l.failures[ip]
+	if !ok {
+		return true
+	}
+	if time.Since(f.first) > loginLockout {
+		delete(l.failures, ip)
+		return true
+	}
+	return f.count < loginFailures
+}
+
+// Fail records a failed attempt from ip.
+func (l *loginLimiter) Fail(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, ok := l.failures[ip]
+	if !ok || time.Since(f.first) > loginLockout {
+		f = &loginFailure{first: time.Now()}
+		l.failures[ip] = f
+	}
+	f.count++
+}
+
+// Reset forgets failures from ip after a successful login.
+func (l *loginLimiter) Reset(ip string) {
+	l.mu.Lock()
+	delete(l.failures, ip)
+	l.mu.Unlock()
+}
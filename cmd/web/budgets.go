@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/audit"
+	"github.com/kidos/kidosserver/pkg/budget"
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/logging"
+	"github.com/kidos/kidosserver/pkg/rules"
+)
+
+const (
+	// budgetInterval is how often used-up budgets are turned into blocks.
+	budgetInterval = 15 * time.Second
+
+	// budgetAuthor marks the rules created for used-up budgets.
+	budgetAuthor = "budget"
+)
+
+// enforceBudgets blocks a category for a device once its budget is used up,
+// until ctx is cancelled. The blocks are temporary device rules that expire
+// at the next reset.
+func (a *apiServer) enforceBudgets(ctx context.Context) {
+	ticker := time.NewTicker(budgetInterval)
+	defer ticker.Stop()
+	for {
+		for _, ex := range a.budgets.Exhausted() {
+			a.blockCategory(ex)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// blockCategory adds the rules for one used-up budget. Rules that already
+// exist, whether set by a parent or left from before a restart, are kept.
+func (a *apiServer) blockCategory(ex budget.Exhaustion) {
+	until := ex.Until.UTC()
+	var add []rules.Rule
+	for _, d := range ex.Domains {
+		rule := rules.Rule{
+			Domain:     d,
+			Action:     rules.ActionBlock,
+			Device:     ex.Device,
+			Subdomains: true,
+			Note:       fmt.Sprintf("%s budget used up", ex.Category),
+			CreatedBy:  budgetAuthor,
+			ExpiresAt:  &until,
+		}
+		if _, ok := a.rules.Get(rule.Ref()); !ok {
+			add = append(add, rule)
+		}
+	}
+	if len(add) == 0 {
+		return
+	}
+	ch, err := a.rules.Apply(0, add, nil)
+	if err != nil {
+		logging.Errorf("block %s for %s: %v", ex.Category, ex.Device, err)
+		return
+	}
+	reason := fmt.Sprintf("%s budget used up until %s", ex.Category, until.Format(time.RFC3339))
+	a.recordEvent(events.Event{
+		Kind:      "control",
+		Timestamp: time.Now().UTC(),
+		SourceIP:  ex.Device,
+		Action:    "budget-exhausted",
+		Reason:    reason,
+	})
+	a.auditSystem(audit.Entry{
+		Action:  "rules.budget",
+		Target:  ex.Device,
+		Summary: reason,
+		After:   map[string]any{"version": ch.Version},
+		Diff:    &audit.Diff{Added: refStrings(ch.Added)},
+	})
+	logging.Infof("%s: %s", ex.Device, reason)
+}
+
+func (a *apiServer) handleBudgets(w http.ResponseWriter, r *http.Request) {
+	start, next := a.budgets.Period()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"periodStart": start,
+		"nextReset":   next,
+		"budgets":     a.budgets.Status(r.URL.Query().Get("device")),
+	})
+}
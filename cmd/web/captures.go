@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kidos/kidosserver/pkg/capture"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+func (a *apiServer) handleListCaptures(w http.ResponseWriter, r *http.Request) {
+	files, err := capture.List(a.cfg.Capture.Dir)
+	if err != nil {
+		logging.Errorf("list captures: %v", err)
+		writeError(w, http.StatusInternalServerError, "list captures failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"captures": files})
+}
+
+func (a *apiServer) handleDownloadCapture(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	path, err := capture.Path(a.cfg.Capture.Dir, name)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid capture name")
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			writeError(w, http.StatusNotFound, "capture not found")
+			return
+		}
+		logging.Errorf("open capture: %v", err)
+		writeError(w, http.StatusInternalServerError, "open capture failed")
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "stat capture failed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pcapng")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kidos/kidosserver/pkg/audit"
+	"github.com/kidos/kidosserver/pkg/devices"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+// deviceFlushInterval is how often learned device bindings are written to
+// the inventory file.
+const deviceFlushInterval = 30 * time.Second
+
+// deviceRequest names a device or assigns it to a profile; omitted fields
+// stay unchanged and empty strings clear them.
+type deviceRequest struct {
+	Name    *string `json:"name"`
+	Profile *string `json:"profile"`
+}
+
+// profiles merges the configured profiles with the device assignments.
+func (a *apiServer) profiles() map[string][]string {
+	out := make(map[string][]string, len(a.cfg.Profiles))
+	for name, addrs := range a.cfg.Profiles {
+		out[name] = append([]string(nil), addrs...)
+	}
+	for name, macs := range a.devices.Profiles() {
+		for _, mac := range macs {
+			if !slices.Contains(out[name], mac) {
+				out[name] = append(out[name], mac)
+			}
+		}
+	}
+	return out
+}
+
+// profileNames lists the known profiles.
+func (a *apiServer) profileNames() []string {
+	all := a.profiles()
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (a *apiServer) handleListDevices(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"devices":  a.devices.List(r.URL.Query().Get("profile")),
+		"profiles": a.profileNames(),
+	})
+}
+
+func (a *apiServer) handleGetDevice(w http.ResponseWriter, r *http.Request) {
+	d, ok := a.devices.Get(mux.Vars(r)["mac"])
+	if !ok {
+		writeError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+func (a *apiServer) handleUpdateDevice(w http.ResponseWriter, r *http.Request) {
+	var req deviceRequest
+	if !decodeBody(w, r, maxFormBytes, &req) {
+		return
+	}
+	before, after, err := a.devices.Update(mux.Vars(r)["mac"], req.Name, req.Profile)
+	if err != nil {
+		a.writeDeviceError(w, err)
+		return
+	}
+	a.recordAudit(r, audit.Entry{
+		Action: "devices.update",
+		Target: after.MAC,
+		Before: map[string]string{"name": before.Name, "profile": before.Profile},
+		After:  map[string]string{"name": after.Name, "profile": after.Profile},
+	})
+	writeJSON(w, http.StatusOK, after)
+}
+
+func (a *apiServer) handleDeleteDevice(w http.ResponseWriter, r *http.Request) {
+	d, err := a.devices.Delete(mux.Vars(r)["mac"])
+	if err != nil {
+		a.writeDeviceError(w, err)
+		return
+	}
+	a.recordAudit(r, audit.Entry{Action: "devices.delete", Target: d.MAC, Before: d})
+	writeJSON(w, http.StatusOK, map[string]any{"status": "deleted", "mac": d.MAC})
+}
+
+func (a *apiServer) writeDeviceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, devices.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, devices.ErrInvalidMAC), errors.Is(err, devices.ErrNameSize):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		logging.Errorf("update devices: %v", err)
+		writeError(w, http.StatusInternalServerError, "update devices failed")
+	}
+}
+
+// flushDevices periodically persists learned bindings until ctx is
+// cancelled.
+func (a *apiServer) flushDevices(ctx context.Context) {
+	ticker := time.NewTicker(deviceFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.devices.Flush(); err != nil {
+				logging.Errorf("save devices: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+const (
+	// maxBatchEvents bounds a single batch from a collector.
+	maxBatchEvents = 1000
+	maxEventBytes  = 64 << 10
+	maxBatchBytes  = 8 << 20
+)
+
+// authenticateCollector resolves the bearer token of r to a collector name.
+func (a *apiServer) authenticateCollector(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", false
+	}
+	for name, want := range a.cfg.Events.Tokens {
+		if want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (a *apiServer) handlePostEvent(w http.ResponseWriter, r *http.Request) {
+	collector, ok := a.authenticateCollector(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid collector token")
+		return
+	}
+	var ev events.Event
+	if !decodeBody(w, r, maxEventBytes, &ev) {
+		return
+	}
+	if err := a.ingest(collector, []events.Event{ev}); err != nil {
+		writeIngestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]any{"status": "ok"})
+}
+
+func (a *apiServer) handlePostEventBatch(w http.ResponseWriter, r *http.Request) {
+	collector, ok := a.authenticateCollector(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid collector token")
+		return
+	}
+	var batch []events.Event
+	if !decodeBody(w, r, maxBatchBytes, &batch) {
+		return
+	}
+	if len(batch) > maxBatchEvents {
+		writeError(w, http.StatusRequestEntityTooLarge, "too many events")
+		return
+	}
+	if err := a.ingest(collector, batch); err != nil {
+		writeIngestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]any{"status": "ok", "accepted": len(batch)})
+}
+
+// handleSocketBatch records a batch delivered over the collector socket.
+func (a *apiServer) handleSocketBatch(peer events.Peer, batch []events.Event) error {
+	if len(batch) > maxBatchEvents {
+		return fmt.Errorf("batch of %d events from %s exceeds limit", len(batch), peer)
+	}
+	return a.ingest(peer.Collector, batch)
+}
+
+// ingest validates a collector batch as a whole, then records it tagged
+// with the collector that submitted it. The batch is stored in one append, so
+// when the store cannot take it nothing is kept and events.ErrRetryLater
+// tells the collector to spool and resend the whole batch.
+func (a *apiServer) ingest(collector string, batch []events.Event) error {
+	now := time.Now().UTC()
+	for i, ev := range batch {
+		if err := events.Validate(ev, now); err != nil {
+			logging.Errorf("reject batch from %s: event %d: %v", collector, i, err)
+			return fmt.Errorf("event %d: %w", i, err)
+		}
+	}
+	evs := make([]*events.Event, len(batch))
+	for i := range batch {
+		ev := &batch[i]
+		ev.Collector = collector
+		if ev.Timestamp.IsZero() {
+			ev.Timestamp = now
+		}
+		evs[i] = ev
+	}
+	if err := a.store.AppendBatch(evs); err != nil {
+		return fmt.Errorf("store batch of %d: %w: %w", len(batch), events.ErrRetryLater, err)
+	}
+	for _, ev := range batch {
+		a.publishEvent(ev)
+	}
+	return nil
+}
+
+// writeIngestError answers a refused batch, telling the collector to retry
+// when the refusal is temporary.
+func writeIngestError(w http.ResponseWriter, err error) {
+	if errors.Is(err, events.ErrRetryLater) {
+		logging.Errorf("ingest: %v", err)
+		w.Header().Set("Retry-After", "5")
+		writeError(w, http.StatusServiceUnavailable, "event store unavailable")
+		return
+	}
+	writeError(w, http.StatusBadRequest, err.Error())
+}
+
+// decodeBody parses a JSON body of at most limit bytes, writing the error
+// response itself when it fails.
+func decodeBody(w http.ResponseWriter, r *http.Request, limit int64, v any) bool {
+	defer r.Body.Close()
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit)).Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, "body too large")
+	} else {
+		writeError(w, http.StatusBadRequest, "invalid json")
+	}
+	return false
+}
@@ -1,13 +1,13 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
-	"sync"
 	"syscall"
 	"time"
 
@@ -16,23 +16,43 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 
+	"github.com/kidos/kidosserver/pkg/audit"
+	"github.com/kidos/kidosserver/pkg/auth"
+	"github.com/kidos/kidosserver/pkg/budget"
+	"github.com/kidos/kidosserver/pkg/certs"
 	"github.com/kidos/kidosserver/pkg/config"
+	"github.com/kidos/kidosserver/pkg/devices"
 	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/eventstore"
 	"github.com/kidos/kidosserver/pkg/logging"
+	"github.com/kidos/kidosserver/pkg/pause"
 	"github.com/kidos/kidosserver/pkg/rules"
+	"github.com/kidos/kidosserver/pkg/stats"
+	"github.com/kidos/kidosserver/pkg/unblock"
 )
 
 type apiServer struct {
-	cfgPath string
-	cfg     config.Config
-	rules   *rules.RuleEngine
-	bus     *events.Bus
-	history []events.Event
-	mu      sync.RWMutex
+	cfgPath  string
+	cfg      config.Config
+	rules    *rules.RuleEngine
+	bus      *events.Bus
+	top      *stats.TopCounter
+	store    *eventstore.Store
+	users    *auth.Store
+	sessions *auth.Sessions
+	logins   *loginLimiter
+	ca       *certs.Authority
+	audit    *audit.Log
+	budgets  *budget.Engine
+	pauses   *pause.State
+	unblock  *unblock.Queue
+	devices  *devices.Inventory
+	// sinkholeHits counts block page hits on the sinkhole listeners.
+	sinkholeHits *sinkholeCounter
 }
 
 var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+	CheckOrigin: sameOrigin,
 }
 
 func main() {
@@ -50,37 +70,209 @@ func main() {
 	_ = mime.AddExtensionType(".mjs", "application/javascript")
 	_ = mime.AddExtensionType(".css", "text/css")
 
-	ruleEngine := rules.New(cfg.DNS.Blocklist)
-	bus := events.NewBus()
+	ruleEngine, err := rules.Load(cfg.DNS.RulesFile, cfg.DNS.Blocklist)
+	if err != nil {
+		logging.Fatalf("load rules: %v", err)
+	}
+	if err := ruleEngine.SaveToFile(cfg.DNS.RulesFile); err != nil {
+		logging.Errorf("save rules: %v", err)
+	}
+	ruleEngine.Persist(cfg.DNS.RulesFile)
+	bus := events.NewBusWithOptions(events.BusOptions{
+		BufferSize:   cfg.Events.BusBuffer,
+		BlockTimeout: time.Duration(cfg.Events.BusBlockMS) * time.Millisecond,
+	})
 	defer bus.Close()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, err := eventstore.Open(eventstore.Options{
+		Dir:             cfg.History.Dir,
+		SegmentBytes:    int64(cfg.History.SegmentMB) << 20,
+		SegmentDuration: time.Duration(cfg.History.SegmentMinutes) * time.Minute,
+		MaxAge:          time.Duration(cfg.History.MaxAgeHours) * time.Hour,
+		MaxBytes:        int64(cfg.History.MaxMB) << 20,
+	})
+	if err != nil {
+		logging.Fatalf("open event store: %v", err)
+	}
+	defer store.Close()
+	go store.Run(ctx)
+
+	users, err := auth.Open(cfg.Web.UsersFile)
+	if err != nil {
+		logging.Fatalf("open users: %v", err)
+	}
+	if users.NeedsSetup() {
+		logging.Infof("no accounts yet; open the web UI to create the initial admin")
+	}
+
+	auditLog, err := audit.Open(cfg.Audit.Dir, cfg.Audit.RetentionMonths)
+	if err != nil {
+		logging.Fatalf("open audit log: %v", err)
+	}
+	defer auditLog.Close()
+	go auditLog.Run(ctx)
+
+	budgets, err := budget.New(cfg.Budgets, nil)
+	if err != nil {
+		logging.Fatalf("budgets: %v", err)
+	}
+
+	pauses, err := pause.Open(cfg.Pause.File)
+	if err != nil {
+		logging.Fatalf("open pause state: %v", err)
+	}
+
+	unblocks, err := unblock.Open(cfg.Web.UnblockFile)
+	if err != nil {
+		logging.Fatalf("open unblock requests: %v", err)
+	}
+
+	var oui devices.OUI
+	if cfg.Web.OUIFile != "" {
+		if oui, err = devices.LoadOUI(cfg.Web.OUIFile); err != nil {
+			logging.Errorf("using bundled vendor list: %v", err)
+		}
+	}
+	inventory, err := devices.Open(cfg.Web.DevicesFile, oui)
+	if err != nil {
+		logging.Fatalf("open device inventory: %v", err)
+	}
+
+	var tlsConfig *tls.Config
+	var ca *certs.Authority
+	if cfg.Web.TLS.Enabled {
+		tlsConfig, ca, err = loadTLS(cfg.Web.TLS)
+		if err != nil {
+			logging.Fatalf("tls: %v", err)
+		}
+	}
+
 	api := &apiServer{
-		cfgPath: cfgPath,
-		cfg:     cfg,
-		rules:   ruleEngine,
-		bus:     bus,
-		history: make([]events.Event, 0, 256),
+		cfgPath:  cfgPath,
+		cfg:      cfg,
+		rules:    ruleEngine,
+		bus:      bus,
+		top:      stats.NewTopCounter(topBucket, topRetention),
+		store:    store,
+		users:    users,
+		sessions: auth.NewSessions(),
+		logins:   newLoginLimiter(),
+		ca:       ca,
+		audit:    auditLog,
+		budgets:  budgets,
+		pauses:   pauses,
+		unblock:  unblocks,
+		devices:  inventory,
+
+		sinkholeHits: newSinkholeCounter(),
+	}
+	api.replayStats()
+	go api.expireRules(ctx)
+	go api.enforceBudgets(ctx)
+	go api.expirePauses(ctx)
+	go api.flushDevices(ctx)
+	if cfg.BlockPage.Sinkhole != "" {
+		go api.serveSinkhole(ctx)
+	}
+
+	if cfg.Events.Socket != "" {
+		sock, err := events.ListenSocket(cfg.Events.Socket, cfg.Events.SocketUIDs, api.handleSocketBatch)
+		if err != nil {
+			logging.Fatalf("event socket: %v", err)
+		}
+		defer sock.Close()
+		go func() {
+			logging.Infof("event socket listening on %s", cfg.Events.Socket)
+			if err := sock.Serve(ctx); err != nil {
+				logging.Errorf("event socket: %v", err)
+			}
+		}()
 	}
 
 	r := mux.NewRouter()
-	r.HandleFunc("/api/rules", api.handleListRules).Methods(http.MethodGet)
-	r.HandleFunc("/api/rules", api.handleSetRules).Methods(http.MethodPost)
-	r.HandleFunc("/api/events", api.handleListEvents).Methods(http.MethodGet)
+	r.HandleFunc("/api/setup", api.handleSetupStatus).Methods(http.MethodGet)
+	r.HandleFunc("/api/setup", api.handleSetup).Methods(http.MethodPost)
+	r.HandleFunc("/api/login", api.handleLogin).Methods(http.MethodPost)
+	r.HandleFunc("/api/logout", api.authorize(auth.RoleViewer, api.handleLogout)).Methods(http.MethodPost)
+	r.HandleFunc("/api/me", api.authorize(auth.RoleViewer, api.handleMe)).Methods(http.MethodGet)
+	r.HandleFunc("/api/users", api.authorize(auth.RoleAdmin, api.handleListUsers)).Methods(http.MethodGet)
+	r.HandleFunc("/api/users", api.authorize(auth.RoleAdmin, api.handleCreateUser)).Methods(http.MethodPost)
+	r.HandleFunc("/api/users/{name}", api.authorize(auth.RoleAdmin, api.handleDeleteUser)).Methods(http.MethodDelete)
+	r.HandleFunc("/api/users/{name}/password", api.authorize(auth.RoleViewer, api.handleSetPassword)).Methods(http.MethodPut)
+	r.HandleFunc("/api/tokens", api.authorize(auth.RoleViewer, api.handleListTokens)).Methods(http.MethodGet)
+	r.HandleFunc("/api/tokens", api.authorize(auth.RoleViewer, api.handleCreateToken)).Methods(http.MethodPost)
+	r.HandleFunc("/api/tokens/{id}", api.authorize(auth.RoleViewer, api.handleRevokeToken)).Methods(http.MethodDelete)
+
+	r.HandleFunc("/api/rules", api.authorize(auth.RoleViewer, api.handleListRules)).Methods(http.MethodGet)
+	r.HandleFunc("/api/rules", api.authorize(auth.RoleParent, api.handleSetRules)).Methods(http.MethodPost)
+	r.HandleFunc("/api/rules/bulk", api.authorize(auth.RoleParent, api.handleBulkRules)).Methods(http.MethodPost)
+	r.HandleFunc("/api/rules/{domain}", api.authorize(auth.RoleViewer, api.handleGetRule)).Methods(http.MethodGet)
+	r.HandleFunc("/api/rules/{domain}", api.authorize(auth.RoleParent, api.handlePutRule)).Methods(http.MethodPut)
+	r.HandleFunc("/api/rules/{domain}", api.authorize(auth.RoleParent, api.handleDeleteRule)).Methods(http.MethodDelete)
+	r.HandleFunc("/api/events", api.authorize(auth.RoleViewer, api.handleListEvents)).Methods(http.MethodGet)
+	r.HandleFunc("/api/events/stream", api.authorize(auth.RoleViewer, api.handleEventStream)).Methods(http.MethodGet)
+	// Collector ingestion authenticates with per-collector tokens instead.
 	r.HandleFunc("/api/events", api.handlePostEvent).Methods(http.MethodPost)
-	r.HandleFunc("/ws/dns", api.handleDNSStream)
+	r.HandleFunc("/api/events/batch", api.handlePostEventBatch).Methods(http.MethodPost)
+	r.HandleFunc("/api/stats/top", api.authorize(auth.RoleViewer, api.handleTopStats)).Methods(http.MethodGet)
+	r.HandleFunc("/api/stats/events", api.authorize(auth.RoleViewer, api.handleEventStats)).Methods(http.MethodGet)
+	r.HandleFunc("/api/captures", api.authorize(auth.RoleParent, api.handleListCaptures)).Methods(http.MethodGet)
+	r.HandleFunc("/api/captures/{name}", api.authorize(auth.RoleParent, api.handleDownloadCapture)).Methods(http.MethodGet)
+	r.HandleFunc("/api/budgets", api.authorize(auth.RoleViewer, api.handleBudgets)).Methods(http.MethodGet)
+	r.HandleFunc("/api/pause", api.authorize(auth.RoleViewer, api.handleListPauses)).Methods(http.MethodGet)
+	r.HandleFunc("/api/pause", api.authorize(auth.RoleParent, api.handlePause)).Methods(http.MethodPost)
+	r.HandleFunc("/api/pause", api.authorize(auth.RoleParent, api.handleResume)).Methods(http.MethodDelete)
+	r.HandleFunc("/api/unblock-requests", api.authorize(auth.RoleViewer, api.handleListUnblockRequests)).Methods(http.MethodGet)
+	r.HandleFunc("/api/unblock-requests/{id}/approve", api.authorize(auth.RoleParent, api.handleApproveUnblock)).Methods(http.MethodPost)
+	r.HandleFunc("/api/unblock-requests/{id}/deny", api.authorize(auth.RoleParent, api.handleDenyUnblock)).Methods(http.MethodPost)
+	r.HandleFunc("/api/devices", api.authorize(auth.RoleViewer, api.handleListDevices)).Methods(http.MethodGet)
+	r.HandleFunc("/api/devices/{mac}", api.authorize(auth.RoleViewer, api.handleGetDevice)).Methods(http.MethodGet)
+	r.HandleFunc("/api/devices/{mac}", api.authorize(auth.RoleParent, api.handleUpdateDevice)).Methods(http.MethodPut)
+	r.HandleFunc("/api/devices/{mac}", api.authorize(auth.RoleParent, api.handleDeleteDevice)).Methods(http.MethodDelete)
+	r.HandleFunc("/api/blockpage", api.authorize(auth.RoleViewer, api.handleBlockPageStats)).Methods(http.MethodGet)
+	r.HandleFunc("/api/audit", api.authorize(auth.RoleParent, api.handleAudit)).Methods(http.MethodGet)
+	r.HandleFunc("/api/tls", api.authorize(auth.RoleViewer, api.handleTLSInfo)).Methods(http.MethodGet)
+	r.HandleFunc("/ca.crt", api.handleCACert).Methods(http.MethodGet)
+	// The block page is public: children reach it from blocked devices.
+	r.HandleFunc("/blocked", api.handleBlockPage).Methods(http.MethodGet)
+	r.HandleFunc("/blocked", api.handleBlockPageSubmit).Methods(http.MethodPost)
+	r.HandleFunc("/ws/dns", api.authorize(auth.RoleViewer, api.handleDNSStream))
 
 	// Serve static assets with proper base path
 	staticHandler := http.StripPrefix("/static/", http.FileServer(http.Dir("public")))
 	r.PathPrefix("/static/").Handler(staticHandler)
 
-	// Serve main page
-	r.PathPrefix("/").Handler(http.FileServer(http.Dir("public")))
+	// Serve main page; the SPA itself requires a session.
+	r.PathPrefix("/").Handler(api.requireLogin(http.FileServer(http.Dir("public"))))
 
 	srv := &http.Server{
 		Addr:    cfg.Web.Listen,
 		Handler: r,
 	}
 
+	if cfg.Web.TLS.Enabled {
+		tlsSrv := &http.Server{
+			Addr:      cfg.Web.TLS.Listen,
+			Handler:   r,
+			TLSConfig: tlsConfig,
+		}
+		srv.Handler = api.redirectToTLS(r)
+		go func() {
+			logging.Infof("web server listening on %s (https)", cfg.Web.TLS.Listen)
+			if err := tlsSrv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+				logging.Fatalf("https server: %v", err)
+			}
+		}()
+		defer func() {
+			if err := tlsSrv.Close(); err != nil {
+				logging.Errorf("https server close: %v", err)
+			}
+		}()
+	}
+
 	go func() {
 		logging.Infof("web server listening on %s", cfg.Web.Listen)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -90,105 +282,48 @@ func main() {
 
 	<-sigCh
 	logging.Infof("shutdown requested")
+	cancel()
+	if err := api.devices.Flush(); err != nil {
+		logging.Errorf("save devices: %v", err)
+	}
 	if err := srv.Close(); err != nil {
 		logging.Errorf("http server close: %v", err)
 	}
 }
 
-func (a *apiServer) handleListRules(w http.ResponseWriter, r *http.Request) {
-	resp := map[string]any{
-		"domains": a.rules.List(),
-	}
-	writeJSON(w, http.StatusOK, resp)
-}
-
-type setRulesRequest struct {
-	Domains []string `json:"domains"`
-}
-
-func (a *apiServer) handleSetRules(w http.ResponseWriter, r *http.Request) {
-	var req setRulesRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid json")
+func (a *apiServer) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	if len(r.URL.Query()) > 0 {
+		a.handleQueryEvents(w, r)
 		return
 	}
-	a.rules.Set(req.Domains)
-	a.cfg.DNS.Blocklist = req.Domains
-	if err := config.Save(a.cfgPath, a.cfg); err != nil {
-		logging.Errorf("save config: %v", err)
-	}
-	a.recordEvent(events.Event{
-		Kind:      "control",
-		Timestamp: time.Now().UTC(),
-		Action:    "rules-update",
-		Reason:    fmt.Sprintf("%d domains", len(req.Domains)),
-	})
-	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
-}
-
-func (a *apiServer) handleDNSStream(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	snapshot, err := a.snapshot()
 	if err != nil {
-		logging.Errorf("upgrade websocket: %v", err)
-		return
-	}
-
-	ch := a.bus.Subscribe()
-	defer a.bus.Unsubscribe(ch)
-	defer conn.Close()
-
-	snapshot := a.snapshot()
-	if err := conn.WriteJSON(map[string]any{"kind": "snapshot", "events": snapshot}); err != nil {
-		logging.Errorf("ws snapshot: %v", err)
+		logging.Errorf("list events: %v", err)
+		writeError(w, http.StatusInternalServerError, "read events failed")
 		return
 	}
-
-	for ev := range ch {
-		if err := conn.WriteJSON(ev); err != nil {
-			logging.Errorf("ws write: %v", err)
-			return
-		}
-	}
-}
-
-func (a *apiServer) handleListEvents(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]any{"events": a.snapshot()})
-}
-
-func (a *apiServer) handlePostEvent(w http.ResponseWriter, r *http.Request) {
-	defer r.Body.Close()
-	var ev events.Event
-	if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid json")
-		return
-	}
-	if ev.Timestamp.IsZero() {
-		ev.Timestamp = time.Now().UTC()
-	}
-	a.recordEvent(ev)
-	writeJSON(w, http.StatusAccepted, map[string]any{"status": "ok"})
+	writeJSON(w, http.StatusOK, map[string]any{"events": snapshot})
 }
 
 func (a *apiServer) recordEvent(ev events.Event) {
-	a.mu.Lock()
-	const limit = 512
-	a.history = append(a.history, ev)
-	if len(a.history) > limit {
-		a.history = a.history[len(a.history)-limit:]
+	if err := a.store.Append(&ev); err != nil {
+		logging.Errorf("store event: %v", err)
 	}
-	a.mu.Unlock()
+	a.publishEvent(ev)
+}
 
+// publishEvent updates live state and subscribers with an event already
+// offered to the store.
+func (a *apiServer) publishEvent(ev events.Event) {
+	a.observe(ev)
 	if a.bus != nil {
 		a.bus.Publish(ev)
 	}
 }
 
-func (a *apiServer) snapshot() []events.Event {
-	a.mu.RLock()
-	defer a.mu.RUnlock()
-	out := make([]events.Event, len(a.history))
-	copy(out, a.history)
-	return out
+// snapshot returns the most recent events for the live views.
+func (a *apiServer) snapshot() ([]events.Event, error) {
+	return a.filteredSnapshot(liveFilter(events.Filter{}))
 }
 
 func writeJSON(w http.ResponseWriter, status int, payload any) {
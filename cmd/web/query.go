@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/eventstore"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+const maxQueryLimit = 5000
+
+// parseEventFilter reads the shared event filter parameters: since, until,
+// kind, action, device, domain and direction. List parameters accept repeated
+// values or comma-separated lists.
+func parseEventFilter(q url.Values) (events.Filter, error) {
+	var f events.Filter
+	var err error
+	if f.Since, err = parseTime(q.Get("since")); err != nil {
+		return f, fmt.Errorf("invalid since")
+	}
+	if f.Until, err = parseTime(q.Get("until")); err != nil {
+		return f, fmt.Errorf("invalid until")
+	}
+	f.Kinds = listParam(q, "kind")
+	f.Actions = listParam(q, "action")
+	f.Devices = listParam(q, "device")
+	f.Domain = strings.TrimSpace(q.Get("domain"))
+	f.Direction = strings.TrimSpace(q.Get("direction"))
+	return f, nil
+}
+
+// parseEventQuery adds cursor, limit and order to the filter parameters.
+func parseEventQuery(q url.Values) (eventstore.Query, error) {
+	filter, err := parseEventFilter(q)
+	if err != nil {
+		return eventstore.Query{}, err
+	}
+	query := eventstore.Query{Filter: filter, Limit: 100, Descending: true}
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid cursor")
+		}
+		query.Cursor = cursor
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxQueryLimit {
+			return query, fmt.Errorf("invalid limit")
+		}
+		query.Limit = n
+	}
+	switch q.Get("order") {
+	case "", "desc":
+	case "asc":
+		query.Descending = false
+	default:
+		return query, fmt.Errorf("invalid order")
+	}
+	return query, nil
+}
+
+func (a *apiServer) handleQueryEvents(w http.ResponseWriter, r *http.Request) {
+	query, err := parseEventQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := a.store.Query(query)
+	if err != nil {
+		logging.Errorf("query events: %v", err)
+		writeError(w, http.StatusInternalServerError, "query events failed")
+		return
+	}
+	resp := map[string]any{"events": page.Events}
+	if page.NextCursor != 0 {
+		resp["nextCursor"] = strconv.FormatUint(page.NextCursor, 10)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+func listParam(q url.Values, key string) []string {
+	var out []string
+	for _, v := range q[key] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kidos/kidosserver/pkg/audit"
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/logging"
+	"github.com/kidos/kidosserver/pkg/rules"
+)
+
+const (
+	maxRuleBodyBytes = 8 << 20
+	maxBulkRules     = 50000
+
+	// ruleExpiryInterval is how often temporary rules are checked for
+	// retirement. Collectors stop applying them at the exact expiry anyway.
+	ruleExpiryInterval = 5 * time.Second
+)
+
+// ruleRequest describes a rule. A temporary rule sets either ExpiresAt or
+// Duration (e.g. "30m").
+type ruleRequest struct {
+	Domain     string     `json:"domain,omitempty"`
+	Action     string     `json:"action,omitempty"`
+	Device     string     `json:"device,omitempty"`
+	Subdomains bool       `json:"subdomains"`
+	Note       string     `json:"note"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	Duration   string     `json:"duration,omitempty"`
+}
+
+// ruleView is a rule as returned by the API, with the time it has left.
+type ruleView struct {
+	rules.Rule
+	RemainingSeconds int64 `json:"remainingSeconds,omitempty"`
+}
+
+type setRulesRequest struct {
+	Domains []string `json:"domains"`
+}
+
+type bulkRulesRequest struct {
+	Add    []ruleRequest   `json:"add"`
+	Remove []rules.RuleRef `json:"remove"`
+}
+
+// ruleETag renders a rule set version as a strong entity tag.
+func ruleETag(version uint64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// expectedVersion parses If-Match into the version a mutation requires; zero
+// means the request is unconditional.
+func expectedVersion(r *http.Request) (uint64, error) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" || raw == "*" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(strings.Trim(strings.TrimPrefix(raw, "W/"), `"`), 10, 64)
+	if err != nil || v == 0 {
+		return 0, errors.New("invalid If-Match")
+	}
+	return v, nil
+}
+
+// ruleRef names the rule addressed by the {domain} route; the action
+// (default block) and device come from the query string.
+func ruleRef(r *http.Request) rules.RuleRef {
+	q := r.URL.Query()
+	return rules.RuleRef{Domain: mux.Vars(r)["domain"], Action: q.Get("action"), Device: q.Get("device")}
+}
+
+// toRule checks a rule request and fills in the caller as creator.
+func (a *apiServer) toRule(r *http.Request, req ruleRequest) (rules.Rule, error) {
+	expires, err := parseExpiry(req.ExpiresAt, req.Duration, a.rules.Now())
+	if err != nil {
+		return rules.Rule{}, fmt.Errorf("rule for %s: %w", req.Domain, err)
+	}
+	if len(req.Note) > 1024 {
+		return rules.Rule{}, fmt.Errorf("note for %s is too long", req.Domain)
+	}
+	rule := rules.Rule{
+		Domain:     req.Domain,
+		Action:     req.Action,
+		Device:     req.Device,
+		Subdomains: req.Subdomains,
+		Note:       req.Note,
+		CreatedBy:  currentUser(r).Name,
+		ExpiresAt:  expires,
+	}
+	return rule, nil
+}
+
+// parseExpiry turns an absolute end time or a duration such as "30m" into
+// an expiry in UTC; neither means no expiry.
+func parseExpiry(at *time.Time, duration string, now time.Time) (*time.Time, error) {
+	if duration != "" {
+		if at != nil {
+			return nil, errors.New("set either an end time or a duration")
+		}
+		d, err := time.ParseDuration(duration)
+		if err != nil || d <= 0 {
+			return nil, errors.New("invalid duration")
+		}
+		t := now.Add(d)
+		at = &t
+	}
+	if at == nil {
+		return nil, nil
+	}
+	if !at.After(now) {
+		return nil, errors.New("end time is in the past")
+	}
+	t := at.UTC().Truncate(time.Second)
+	return &t, nil
+}
+
+// viewRules annotates rules with their remaining time.
+func (a *apiServer) viewRules(list ...rules.Rule) []ruleView {
+	now := a.rules.Now()
+	out := make([]ruleView, len(list))
+	for i, rule := range list {
+		out[i] = ruleView{Rule: rule, RemainingSeconds: int64(rule.Remaining(now).Round(time.Second) / time.Second)}
+	}
+	return out
+}
+
+// refStrings renders rule refs for audit diffs.
+func refStrings(refs []rules.RuleRef) []string {
+	out := make([]string, len(refs))
+	for i, ref := range refs {
+		out[i] = ref.String()
+	}
+	return out
+}
+
+// writeRuleError maps rule engine errors onto HTTP statuses.
+func (a *apiServer) writeRuleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, rules.ErrVersionMismatch):
+		w.Header().Set("ETag", ruleETag(a.rules.Version()))
+		writeError(w, http.StatusPreconditionFailed, "rule set changed; reload and retry")
+	case errors.Is(err, rules.ErrInvalidDomain), errors.Is(err, rules.ErrInvalidAction):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		logging.Errorf("update rules: %v", err)
+		writeError(w, http.StatusInternalServerError, "update rules failed")
+	}
+}
+
+// rulesChanged records a persisted rule change as a control event and in
+// the audit log.
+func (a *apiServer) rulesChanged(w http.ResponseWriter, r *http.Request, action string, ch rules.Change) {
+	summary := fmt.Sprintf("%d added, %d updated, %d removed", len(ch.Added), len(ch.Updated), len(ch.Removed))
+	a.recordEvent(events.Event{
+		Kind:      "control",
+		Timestamp: time.Now().UTC(),
+		Action:    "rules-update",
+		Reason:    summary,
+	})
+	a.recordAudit(r, audit.Entry{
+		Action:  action,
+		Target:  "blocklist",
+		Summary: summary,
+		After:   map[string]any{"version": ch.Version, "updated": refStrings(ch.Updated)},
+		Diff:    &audit.Diff{Added: refStrings(ch.Added), Removed: refStrings(ch.Removed)},
+	})
+	w.Header().Set("ETag", ruleETag(ch.Version))
+}
+
+func (a *apiServer) handleListRules(w http.ResponseWriter, r *http.Request) {
+	version := a.rules.Version()
+	w.Header().Set("ETag", ruleETag(version))
+	resp := map[string]any{
+		"version": version,
+		"domains": a.rules.List(),
+		"rules":   a.rules.Hits(),
+		"entries": a.viewRules(a.rules.Rules()...),
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleSetRules replaces the whole blocklist.
+func (a *apiServer) handleSetRules(w http.ResponseWriter, r *http.Request) {
+	expect, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var req setRulesRequest
+	if !decodeBody(w, r, maxRuleBodyBytes, &req) {
+		return
+	}
+	ch, err := a.rules.Replace(expect, req.Domains, currentUser(r).Name)
+	if err != nil {
+		a.writeRuleError(w, err)
+		return
+	}
+	if !ch.Empty() {
+		a.rulesChanged(w, r, "rules.replace", ch)
+	} else {
+		w.Header().Set("ETag", ruleETag(ch.Version))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "version": ch.Version})
+}
+
+func (a *apiServer) handleGetRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := a.rules.Get(ruleRef(r))
+	if !ok {
+		writeError(w, http.StatusNotFound, "rule not found")
+		return
+	}
+	w.Header().Set("ETag", ruleETag(a.rules.Version()))
+	resp := map[string]any{"rule": a.viewRules(rule)[0]}
+	if rule.Action == rules.ActionBlock {
+		resp["hits"] = a.rules.RuleHitsFor(rule.Ref())
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handlePutRule adds or updates a single rule.
+func (a *apiServer) handlePutRule(w http.ResponseWriter, r *http.Request) {
+	expect, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var req ruleRequest
+	if !decodeBody(w, r, maxFormBytes, &req) {
+		return
+	}
+	ref := ruleRef(r)
+	req.Domain = ref.Domain
+	if req.Action == "" {
+		req.Action = ref.Action
+	}
+	if req.Device == "" {
+		req.Device = ref.Device
+	}
+	rule, err := a.toRule(r, req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ch, err := a.rules.Apply(expect, []rules.Rule{rule}, nil)
+	if err != nil {
+		a.writeRuleError(w, err)
+		return
+	}
+	a.rulesChanged(w, r, "rules.put", ch)
+	status := http.StatusOK
+	if len(ch.Added) > 0 {
+		status = http.StatusCreated
+	}
+	stored, _ := a.rules.Get(rule.Ref())
+	writeJSON(w, status, map[string]any{"rule": a.viewRules(stored)[0], "version": ch.Version})
+}
+
+func (a *apiServer) handleDeleteRule(w http.ResponseWriter, r *http.Request) {
+	expect, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ch, err := a.rules.Apply(expect, nil, []rules.RuleRef{ruleRef(r)})
+	if err != nil {
+		a.writeRuleError(w, err)
+		return
+	}
+	if len(ch.Removed) == 0 {
+		writeError(w, http.StatusNotFound, "rule not found")
+		return
+	}
+	a.rulesChanged(w, r, "rules.delete", ch)
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "version": ch.Version})
+}
+
+// handleBulkRules adds, updates and removes many rules as one version step.
+func (a *apiServer) handleBulkRules(w http.ResponseWriter, r *http.Request) {
+	expect, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var req bulkRulesRequest
+	if !decodeBody(w, r, maxRuleBodyBytes, &req) {
+		return
+	}
+	if len(req.Add)+len(req.Remove) > maxBulkRules {
+		writeError(w, http.StatusRequestEntityTooLarge, "too many rules")
+		return
+	}
+	add := make([]rules.Rule, 0, len(req.Add))
+	for _, item := range req.Add {
+		rule, err := a.toRule(r, item)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		add = append(add, rule)
+	}
+	ch, err := a.rules.Apply(expect, add, req.Remove)
+	if err != nil {
+		a.writeRuleError(w, err)
+		return
+	}
+	if !ch.Empty() {
+		a.rulesChanged(w, r, "rules.bulk", ch)
+	} else {
+		w.Header().Set("ETag", ruleETag(ch.Version))
+	}
+	writeJSON(w, http.StatusOK, ch)
+}
+
+// expireRules retires temporary rules once their time is up, until ctx is
+// cancelled. Each retirement is persisted, announced as a control event and
+// audited as a system change; one that cannot be saved is retried on the
+// next tick.
+func (a *apiServer) expireRules(ctx context.Context) {
+	ticker := time.NewTicker(ruleExpiryInterval)
+	defer ticker.Stop()
+	for {
+		retired, version, err := a.rules.Expire()
+		if err != nil {
+			logging.Errorf("expire rules: %v", err)
+		}
+		if len(retired) > 0 {
+			for _, rule := range retired {
+				a.recordEvent(events.Event{
+					Kind:      "control",
+					Timestamp: time.Now().UTC(),
+					Domain:    rule.Domain,
+					Action:    "rule-expired",
+					Rule:      rule.Ref().String(),
+					Reason:    fmt.Sprintf("%s rule expired at %s", rule.Action, rule.ExpiresAt.Format(time.RFC3339)),
+				})
+				a.auditSystem(audit.Entry{
+					Action:  "rules.expire",
+					Target:  "blocklist",
+					Summary: fmt.Sprintf("%s expired", rule.Ref()),
+					Before:  rule,
+					After:   map[string]any{"version": version},
+					Diff:    &audit.Diff{Removed: []string{rule.Ref().String()}},
+				})
+			}
+			logging.Infof("retired %d expired rules (version %d)", len(retired), version)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
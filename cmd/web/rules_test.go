@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/audit"
+	"github.com/kidos/kidosserver/pkg/eventstore"
+	"github.com/kidos/kidosserver/pkg/rules"
+)
+
+// newTestServer wires the parts of apiServer that record events and audit
+// entries to a temporary directory.
+func newTestServer(t *testing.T) *apiServer {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := eventstore.Open(eventstore.Options{Dir: filepath.Join(dir, "events")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	auditLog, err := audit.Open(filepath.Join(dir, "audit"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = auditLog.Close() })
+	a := &apiServer{rules: rules.New(nil), store: store, audit: auditLog}
+	a.cfg.DNS.RulesFile = filepath.Join(dir, "rules.json")
+	a.rules.Persist(a.cfg.DNS.RulesFile)
+	return a
+}
+
+func TestExpireRulesRetiresTemporaryRules(t *testing.T) {
+	a := newTestServer(t)
+	past := time.Now().Add(-time.Second).UTC().Truncate(time.Second)
+	future := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	if _, err := a.rules.Apply(0, []rules.Rule{
+		{Domain: "games.example", Action: rules.ActionBlock, ExpiresAt: &past},
+		{Domain: "video.example", Action: rules.ActionBlock, ExpiresAt: &future},
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// With a cancelled context expireRules makes a single pass.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a.expireRules(ctx)
+
+	if _, ok := a.rules.Get(rules.RuleRef{Domain: "games.example"}); ok {
+		t.Fatal("expired rule still present")
+	}
+	if _, ok := a.rules.Get(rules.RuleRef{Domain: "video.example"}); !ok {
+		t.Fatal("rule retired before its expiry")
+	}
+	evs, err := a.store.Last(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 1 || evs[0].Kind != "control" || evs[0].Action != "rule-expired" || evs[0].Rule != "games.example" {
+		t.Fatalf("events = %+v", evs)
+	}
+	page, err := a.audit.Query(audit.Query{Action: "rules.expire"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := page.Entries
+	if len(entries) != 1 || entries[0].User != "system" || entries[0].Target != "blocklist" {
+		t.Fatalf("audit entries = %+v", entries)
+	}
+	loaded, err := rules.Load(a.cfg.DNS.RulesFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Rules(); len(got) != 1 || got[0].Domain != "video.example" || !got[0].ExpiresAt.Equal(future) {
+		t.Fatalf("rules file holds %+v", got)
+	}
+}
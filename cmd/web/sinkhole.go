@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/logging"
+	"github.com/kidos/kidosserver/pkg/rules"
+)
+
+const (
+	// kindBlockPage marks hits on the sinkhole listeners.
+	kindBlockPage = "blockpage"
+	// sinkholeTLSTimeout bounds how long a refused TLS client may take to
+	// send its ClientHello.
+	sinkholeTLSTimeout = 5 * time.Second
+	// maxSinkholeDomains caps the per-domain hit counters; further domains
+	// are only counted in the totals.
+	maxSinkholeDomains = 10000
+	// sinkholeTopDomains is how many domains /api/blockpage reports.
+	sinkholeTopDomains = 50
+)
+
+var errSinkholed = errors.New("domain is blocked")
+
+// sinkholeHits counts block page hits per scheme.
+type sinkholeHits struct {
+	Domain string `json:"domain,omitempty"`
+	HTTP   uint64 `json:"http"`
+	HTTPS  uint64 `json:"https"`
+}
+
+// sinkholeCounter aggregates block page events by domain.
+type sinkholeCounter struct {
+	mu      sync.Mutex
+	total   sinkholeHits
+	domains map[string]*sinkholeHits
+}
+
+func newSinkholeCounter() *sinkholeCounter {
+	return &sinkholeCounter{domains: make(map[string]*sinkholeHits)}
+}
+
+// Record counts a block page event.
+func (c *sinkholeCounter) Record(ev events.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hits := []*sinkholeHits{&c.total}
+	if ev.Domain != "" {
+		d, ok := c.domains[ev.Domain]
+		if !ok && len(c.domains) < maxSinkholeDomains {
+			d = &sinkholeHits{Domain: ev.Domain}
+			c.domains[ev.Domain] = d
+		}
+		if d != nil {
+			hits = append(hits, d)
+		}
+	}
+	for _, h := range hits {
+		if ev.Transport == "https" {
+			h.HTTPS++
+		} else {
+			h.HTTP++
+		}
+	}
+}
+
+// Top returns the totals and the most hit domains.
+func (c *sinkholeCounter) Top(n int) (sinkholeHits, []sinkholeHits) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]sinkholeHits, 0, len(c.domains))
+	for _, d := range c.domains {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ti, tj := out[i].HTTP+out[i].HTTPS, out[j].HTTP+out[j].HTTPS
+		if ti != tj {
+			return ti > tj
+		}
+		return out[i].Domain < out[j].Domain
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return c.total, out
+}
+
+func (a *apiServer) handleBlockPageStats(w http.ResponseWriter, r *http.Request) {
+	total, top := a.sinkholeHits.Top(sinkholeTopDomains)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"sinkhole": a.cfg.BlockPage.Sinkhole,
+		"http":     total.HTTP,
+		"https":    total.HTTPS,
+		"domains":  top,
+	})
+}
+
+// sinkholeHandler serves the block page for whatever host a blocked name
+// was sent to.
+func (a *apiServer) sinkholeHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/favicon.ico", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/blocked" {
+			a.handleBlockPageSubmit(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		host := r.Host
+		if r.URL.Path == "/blocked" && r.URL.Query().Get("domain") != "" {
+			host = r.URL.Query().Get("domain")
+		}
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		device := clientIP(r)
+		page := blockPage{Error: "This site is blocked."}
+		domain, err := rules.ValidateDomain(host)
+		if err != nil || net.ParseIP(domain) != nil {
+			domain = ""
+		} else {
+			page = a.blockInfo(domain, device)
+		}
+		if r.URL.Path != "/blocked" {
+			a.recordEvent(blockPageEvent(device, r.Host, domain, "http", "served", page.Rule))
+		}
+		status := http.StatusOK
+		if page.Blocked || domain == "" {
+			status = http.StatusForbidden
+		}
+		renderBlockPage(w, status, page)
+	})
+	return mux
+}
+
+// serveSinkhole runs the block page listeners until ctx is cancelled. A
+// listener that cannot start only disables itself.
+func (a *apiServer) serveSinkhole(ctx context.Context) {
+	bp := a.cfg.BlockPage
+	httpAddr, httpsAddr := bp.HTTPListen, bp.HTTPSListen
+	if httpAddr == "" {
+		httpAddr = net.JoinHostPort(bp.Sinkhole, "80")
+	}
+	if httpsAddr == "" {
+		httpsAddr = net.JoinHostPort(bp.Sinkhole, "443")
+	}
+
+	srv := &http.Server{
+		Addr:              httpAddr,
+		Handler:           a.sinkholeHandler(),
+		ReadHeaderTimeout: sinkholeTLSTimeout,
+	}
+	go func() {
+		logging.Infof("block page listening on %s", httpAddr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("block page disabled: %v", err)
+		}
+	}()
+
+	ln, err := net.Listen("tcp", httpsAddr)
+	if err != nil {
+		logging.Errorf("block page https refusal disabled: %v", err)
+	} else {
+		go a.refuseTLS(ln)
+	}
+
+	<-ctx.Done()
+	if err := srv.Close(); err != nil {
+		logging.Errorf("block page close: %v", err)
+	}
+	if ln != nil {
+		ln.Close()
+	}
+}
+
+// refuseTLS reads the server name from each TLS ClientHello, records the hit
+// and aborts the handshake with an alert: without a certificate for the
+// blocked site the page could not be shown anyway.
+func (a *apiServer) refuseTLS(ln net.Listener) {
+	logging.Infof("block page refusing tls on %s", ln.Addr())
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			logging.Errorf("block page accept: %v", err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		go func() {
+			defer conn.Close()
+			_ = conn.SetDeadline(time.Now().Add(sinkholeTLSTimeout))
+			var sni string
+			cfg := &tls.Config{GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+				sni = hello.ServerName
+				return nil, errSinkholed
+			}}
+			if err := tls.Server(conn, cfg).Handshake(); !errors.Is(err, errSinkholed) {
+				return
+			}
+			device, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+			domain, err := rules.ValidateDomain(sni)
+			if err != nil {
+				domain = ""
+			}
+			var rule string
+			if domain != "" {
+				rule, _ = a.rules.Match(domain, device)
+			}
+			a.recordEvent(blockPageEvent(device, sni, domain, "https", "refused", rule))
+		}()
+	}
+}
+
+func blockPageEvent(device, host, domain, transport, action, rule string) events.Event {
+	ev := events.Event{
+		Kind:      kindBlockPage,
+		Timestamp: time.Now().UTC(),
+		SourceIP:  device,
+		Domain:    domain,
+		Transport: transport,
+		Action:    action,
+		Rule:      rule,
+	}
+	if rule != "" {
+		ev.Reason = "domain blocked"
+	}
+	if domain == "" {
+		ev.Info = strings.ToValidUTF8(host, "")
+		if len(ev.Info) > 253 {
+			ev.Info = ev.Info[:253]
+		}
+	}
+	return ev
+}
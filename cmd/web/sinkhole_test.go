@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+func blockPageHit(domain, transport string) events.Event {
+	return events.Event{Kind: kindBlockPage, Domain: domain, Transport: transport}
+}
+
+func TestSinkholeCounterCap(t *testing.T) {
+	c := newSinkholeCounter()
+	for i := 0; i < maxSinkholeDomains; i++ {
+		c.Record(blockPageHit(fmt.Sprintf("site%d.example", i), "http"))
+	}
+	// Past the cap new domains only count in the totals, while domains
+	// already tracked keep counting.
+	c.Record(blockPageHit("late.example", "https"))
+	c.Record(blockPageHit("site7.example", "https"))
+	c.Record(blockPageHit("site7.example", "http"))
+	c.Record(blockPageHit("", "http"))
+
+	if len(c.domains) != maxSinkholeDomains {
+		t.Fatalf("tracking %d domains, cap is %d", len(c.domains), maxSinkholeDomains)
+	}
+	if _, ok := c.domains["late.example"]; ok {
+		t.Fatal("domain past the cap was tracked")
+	}
+	total, top := c.Top(2)
+	if total.HTTP != maxSinkholeDomains+2 || total.HTTPS != 2 {
+		t.Fatalf("totals %+v", total)
+	}
+	if len(top) != 2 || top[0] != (sinkholeHits{Domain: "site7.example", HTTP: 2, HTTPS: 1}) {
+		t.Fatalf("top %+v", top)
+	}
+	// Ties are ordered by name.
+	if top[1].Domain != "site0.example" {
+		t.Fatalf("second %+v", top[1])
+	}
+}
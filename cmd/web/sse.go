@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/eventstore"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+const (
+	sseKeepAlive  = 15 * time.Second
+	sseRetryMS    = 3000
+	sseReplayPage = 500
+)
+
+// handleEventStream serves events as Server-Sent Events. Clients resuming with
+// Last-Event-ID (or ?lastEventId=) first receive the matching events they
+// missed from history, then the live stream. Like the websocket, it leaves
+// out bulk kinds unless they are asked for, in the replay as well.
+func (a *apiServer) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := parseEventFilter(q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter = liveFilter(filter)
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = q.Get("lastEventId")
+	}
+	var resumeFrom uint64
+	if lastID != "" {
+		resumeFrom, err = strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	live := filter
+	live.Since, live.Until = time.Time{}, time.Time{}
+	// Subscribe before replaying so nothing published meanwhile is missed.
+	sub := a.bus.SubscribeWith(live, nil)
+	defer sub.Unsubscribe()
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetryMS); err != nil {
+		return
+	}
+
+	sent := resumeFrom
+	if resumeFrom > 0 || !filter.Since.IsZero() {
+		sent, err = a.replaySSE(w, rc, filter, resumeFrom)
+		if err != nil {
+			logging.Errorf("sse replay: %v", err)
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+	if !filter.Until.IsZero() && !time.Now().Before(filter.Until) {
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	lag := lagMeter{sub: sub}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			_ = rc.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case ev, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if ev.ID != 0 && ev.ID <= sent {
+				continue
+			}
+			if !filter.Until.IsZero() && !ev.Timestamp.Before(filter.Until) {
+				return
+			}
+			_ = rc.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := writeSSE(w, ev); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			if ev.ID > sent {
+				sent = ev.ID
+			}
+			// A lagging client is cut off; it resumes from history via Last-Event-ID.
+			if lag.tooSlow() {
+				logging.Errorf("sse client %s too slow, disconnecting", r.RemoteAddr)
+				return
+			}
+		}
+	}
+}
+
+// replaySSE writes stored events after cursor matching filter and returns the
+// last ID written.
+func (a *apiServer) replaySSE(w http.ResponseWriter, rc *http.ResponseController, filter events.Filter, cursor uint64) (uint64, error) {
+	last := cursor
+	query := eventstore.Query{Filter: filter, Cursor: cursor, Limit: sseReplayPage}
+	for {
+		page, err := a.store.Query(query)
+		if err != nil {
+			return last, err
+		}
+		for _, ev := range page.Events {
+			_ = rc.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := writeSSE(w, ev); err != nil {
+				return last, err
+			}
+			last = ev.ID
+		}
+		if page.NextCursor == 0 {
+			return last, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+func writeSSE(w http.ResponseWriter, ev events.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if ev.ID != 0 {
+		_, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", ev.ID, data)
+	} else {
+		_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+func TestEventStreamLeavesOutBulkKinds(t *testing.T) {
+	a := newTestServer(t)
+	a.bus = events.NewBus()
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{"", "dns"},
+		{"?kind=" + events.KindFlowEnd, events.KindFlowEnd},
+	} {
+		t.Run(tc.want, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv := httptest.NewServer(http.HandlerFunc(a.handleEventStream))
+			defer srv.Close()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+tc.query, nil)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			// The handler has subscribed once the retry line is written.
+			lines := bufio.NewScanner(resp.Body)
+			if !lines.Scan() || !strings.HasPrefix(lines.Text(), "retry:") {
+				t.Fatalf("first line %q", lines.Text())
+			}
+			a.bus.Publish(events.Event{Kind: events.KindFlowEnd, Flow: &events.FlowInfo{}})
+			a.bus.Publish(events.Event{Kind: "dns", Action: "allow", Domain: "example.com"})
+			for lines.Scan() {
+				data, ok := strings.CutPrefix(lines.Text(), "data: ")
+				if !ok {
+					continue
+				}
+				var ev events.Event
+				if err := json.Unmarshal([]byte(data), &ev); err != nil {
+					t.Fatal(err)
+				}
+				if ev.Kind != tc.want {
+					t.Fatalf("first event kind %q, want %q", ev.Kind, tc.want)
+				}
+				return
+			}
+			t.Fatalf("stream ended: %v", lines.Err())
+		})
+	}
+}
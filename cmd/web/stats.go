@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/logging"
+	"github.com/kidos/kidosserver/pkg/stats"
+)
+
+const (
+	topBucket    = time.Hour
+	topRetention = 30 * 24 * time.Hour
+	topDefault   = 24 * time.Hour
+)
+
+// observe updates rule hit counters, top-domain statistics and budget usage
+// from an event.
+func (a *apiServer) observe(ev events.Event) {
+	if a.budgets != nil {
+		a.budgets.Observe(ev)
+	}
+	if ev.Kind == kindBlockPage && a.sinkholeHits != nil {
+		a.sinkholeHits.Record(ev)
+	}
+	if ev.Kind == events.KindDeviceSeen && ev.Device != nil && a.devices != nil {
+		a.devices.Observe(*ev.Device, ev.Timestamp)
+	}
+	if ev.Kind != "dns" {
+		return
+	}
+	if a.devices != nil && (ev.Direction == "" || ev.Direction == "query") {
+		a.devices.ObserveQuery(ev.SourceIP, ev.Domain)
+	}
+	if ev.Action == "block" {
+		rule := ev.Rule
+		if rule == "" {
+			rule, _ = a.rules.Match(ev.Domain, ev.SourceIP)
+		}
+		if rule != "" {
+			a.rules.RecordHit(rule, ev.SourceIP, ev.Timestamp)
+		}
+	}
+	if a.top != nil {
+		a.top.Record(ev)
+	}
+}
+
+// replayStats rebuilds in-memory statistics from persisted history.
+func (a *apiServer) replayStats() {
+	since := time.Now().Add(-topRetention)
+	err := a.store.ScanSince(since, func(ev events.Event) bool {
+		a.observe(ev)
+		return true
+	})
+	if err != nil {
+		logging.Errorf("replay stats: %v", err)
+	}
+}
+
+func (a *apiServer) handleTopStats(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	now := time.Now().UTC()
+	query := stats.TopQuery{
+		Device: q.Get("device"),
+		Since:  now.Add(-topDefault),
+		Until:  now,
+		Limit:  10,
+	}
+	if v := q.Get("since"); v != "" {
+		ts, err := parseTime(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since")
+			return
+		}
+		query.Since = ts
+	}
+	if v := q.Get("until"); v != "" {
+		ts, err := parseTime(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid until")
+			return
+		}
+		query.Until = ts
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 1000 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		query.Limit = n
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"since":   query.Since,
+		"until":   query.Until,
+		"devices": a.top.Top(query),
+	})
+}
+
+// handleEventStats reports subscriber backlogs, drops and store usage.
+func (a *apiServer) handleEventStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"subscribers": a.bus.Stats(),
+		"store":       a.store.Stats(),
+	})
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/eventstore"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	wsReadLimit  = 8 << 10
+	// wsMaxDropped disconnects clients that miss this many events between
+	// two successful writes.
+	wsMaxDropped = 1024
+)
+
+// bulkKinds are high-volume kinds the live views leave out unless a client
+// asks for them by kind.
+var bulkKinds = []string{events.KindFlowEnd, events.KindDeviceSeen}
+
+// liveFilter applies the live-view defaults to filter.
+func liveFilter(filter events.Filter) events.Filter {
+	if len(filter.Kinds) == 0 {
+		filter.ExcludeKinds = bulkKinds
+	}
+	return filter
+}
+
+// subscribeMessage lets a websocket client change what it receives mid-stream.
+type subscribeMessage struct {
+	Type      string             `json:"type"`
+	Kinds     []string           `json:"kinds"`
+	Actions   []string           `json:"actions"`
+	Devices   []string           `json:"devices"`
+	Domain    string             `json:"domain"`
+	Direction string             `json:"direction"`
+	Sample    map[string]float64 `json:"sample"`
+}
+
+func (m subscribeMessage) filter() events.Filter {
+	return events.Filter{
+		Kinds:     m.Kinds,
+		Actions:   m.Actions,
+		Devices:   m.Devices,
+		Domain:    m.Domain,
+		Direction: m.Direction,
+	}
+}
+
+func (a *apiServer) handleDNSStream(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Only attribute filters apply to a live stream.
+	filter.Since, filter.Until = time.Time{}, time.Time{}
+	filter = liveFilter(filter)
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logging.Errorf("upgrade websocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	sub := a.bus.SubscribeWith(filter, nil)
+	defer sub.Unsubscribe()
+
+	snapshot, err := a.filteredSnapshot(filter)
+	if err != nil {
+		logging.Errorf("ws snapshot: %v", err)
+		return
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	if err := conn.WriteJSON(map[string]any{"kind": "snapshot", "events": snapshot}); err != nil {
+		logging.Errorf("ws snapshot: %v", err)
+		return
+	}
+
+	done := make(chan struct{})
+	go a.readSubscriptions(conn, sub, done)
+	lag := lagMeter{sub: sub}
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case ev, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(ev); err != nil {
+				logging.Errorf("ws write: %v", err)
+				return
+			}
+			if lag.tooSlow() {
+				logging.Errorf("ws client %s too slow, disconnecting", r.RemoteAddr)
+				msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow")
+				_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteWait))
+				return
+			}
+		}
+	}
+}
+
+// lagMeter tells whether a subscriber fell too far behind since the previous
+// write. Clients that catch up after a burst start over, so drops do not
+// add up over a long-lived connection.
+type lagMeter struct {
+	sub  *events.Subscription
+	seen uint64
+}
+
+func (m *lagMeter) tooSlow() bool {
+	n := m.sub.Dropped()
+	lag := n - m.seen
+	m.seen = n
+	return lag > wsMaxDropped
+}
+
+// readSubscriptions applies subscription messages and tracks pong keepalives
+// until the connection fails; it closes done on exit.
+func (a *apiServer) readSubscriptions(conn *websocket.Conn, sub *events.Subscription, done chan<- struct{}) {
+	defer close(done)
+	conn.SetReadLimit(wsReadLimit)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		var msg subscribeMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "subscribe" {
+			continue
+		}
+		sub.SetFilter(liveFilter(msg.filter()), msg.Sample)
+	}
+}
+
+// filteredSnapshot returns recent events matching filter in ascending order.
+func (a *apiServer) filteredSnapshot(filter events.Filter) ([]events.Event, error) {
+	if filter.IsZero() {
+		return a.store.Last(a.cfg.History.SnapshotEvents)
+	}
+	page, err := a.store.Query(eventstore.Query{
+		Filter:     filter,
+		Limit:      a.cfg.History.SnapshotEvents,
+		Descending: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := page.Events
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/kidos/kidosserver/pkg/certs"
+	"github.com/kidos/kidosserver/pkg/config"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+// loadTLS returns the server TLS config and, when the certificate is
+// self-issued, the local CA that signed it.
+func loadTLS(cfg config.TLSConfig) (*tls.Config, *certs.Authority, error) {
+	certFile, keyFile := cfg.CertFile, cfg.KeyFile
+	var ca *certs.Authority
+	if certFile == "" || keyFile == "" {
+		hosts := append(append([]string{}, cfg.Hosts...), certs.DefaultHosts()...)
+		var err error
+		ca, err = certs.LoadOrCreateCA(cfg.Dir, hosts)
+		if err != nil {
+			return nil, nil, err
+		}
+		var skipped []string
+		certFile, keyFile, skipped, err = certs.EnsureServerCert(cfg.Dir, ca, hosts)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(skipped) > 0 {
+			logging.Errorf("tls: local CA cannot sign for %s; remove %s and %s to create a new CA and reinstall it on devices",
+				strings.Join(skipped, ", "), filepath.Join(cfg.Dir, certs.CAFile), filepath.Join(cfg.Dir, certs.CAKeyFile))
+		}
+	}
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load certificate: %w", err)
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{pair},
+	}, ca, nil
+}
+
+// plainAllowed lists requests still served over plain HTTP when TLS is on:
+// collector ingestion, the CA download needed to trust HTTPS at all and the
+// block page children land on from sinkholed sites.
+func plainAllowed(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/api/events", "/api/events/batch":
+		return r.Method == http.MethodPost
+	case "/ca.crt", "/blocked":
+		return true
+	}
+	return false
+}
+
+// redirectToTLS sends browsers on the plain listener to the HTTPS one.
+func (a *apiServer) redirectToTLS(next http.Handler) http.Handler {
+	_, port, _ := net.SplitHostPort(a.cfg.Web.TLS.Listen)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if plainAllowed(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		target := "https://" + net.JoinHostPort(host, port)
+		if port == "443" || port == "" {
+			target = "https://" + host
+			if strings.Contains(host, ":") {
+				target = "https://[" + host + "]"
+			}
+		}
+		// Temporary so browsers do not cache it if TLS is turned off later.
+		http.Redirect(w, r, target+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+	})
+}
+
+// handleCACert serves the local CA for installation on phones and laptops,
+// DER-encoded by default or PEM with ?format=pem.
+func (a *apiServer) handleCACert(w http.ResponseWriter, r *http.Request) {
+	if a.ca == nil {
+		writeError(w, http.StatusNotFound, "no local certificate authority")
+		return
+	}
+	if r.URL.Query().Get("format") == "pem" {
+		w.Header().Set("Content-Type", "application/x-pem-file")
+		w.Header().Set("Content-Disposition", `attachment; filename="kidos-ca.pem"`)
+		w.Write(a.ca.PEM())
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-x509-ca-cert")
+	w.Header().Set("Content-Disposition", `attachment; filename="kidos-ca.crt"`)
+	w.Write(a.ca.Cert.Raw)
+}
+
+// handleTLSInfo reports the CA fingerprint so parents can verify the
+// certificate they installed.
+func (a *apiServer) handleTLSInfo(w http.ResponseWriter, r *http.Request) {
+	resp := map[string]any{"enabled": a.cfg.Web.TLS.Enabled}
+	if a.ca != nil {
+		resp["caFingerprint"] = a.ca.Fingerprint()
+		resp["caNotAfter"] = a.ca.Cert.NotAfter
+		resp["caDownload"] = "/ca.crt"
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kidos/kidosserver/pkg/audit"
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/logging"
+	"github.com/kidos/kidosserver/pkg/rules"
+	"github.com/kidos/kidosserver/pkg/unblock"
+)
+
+// blockPage is what the block page shows a child about one domain.
+type blockPage struct {
+	Domain     string
+	Blocked    bool
+	Rule       string
+	Note       string
+	Categories []string
+	Until      *time.Time
+	Request    *unblock.Request
+	Error      string
+}
+
+var blockPageTmpl = template.Must(template.New("blocked").Parse(`<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1.0" />
+    <title>Blocked – {{.Domain}}</title>
+    <style>
+      body { font-family: system-ui, sans-serif; background: #f4f5f7; display: flex; justify-content: center; padding-top: 10vh; }
+      main { background: #fff; padding: 2rem; border-radius: 8px; box-shadow: 0 1px 4px rgba(0,0,0,.15); width: 24rem; }
+      textarea { width: 100%; box-sizing: border-box; margin-top: .5rem; min-height: 5rem; }
+      button { margin-top: 1rem; width: 100%; padding: .6rem; }
+      .muted { color: #555; font-size: .9rem; }
+      .error { color: #b00020; }
+    </style>
+  </head>
+  <body>
+    <main>
+      {{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+      {{if .Blocked}}
+      <h2>{{.Domain}} is blocked</h2>
+      {{if .Categories}}<p>Category: {{range $i, $c := .Categories}}{{if $i}}, {{end}}{{$c}}{{end}}</p>{{end}}
+      <p class="muted">Rule: {{.Rule}}{{if .Note}} – {{.Note}}{{end}}{{if .Until}} (until {{.Until.Local.Format "Mon 15:04"}}){{end}}</p>
+      {{with .Request}}
+        {{if eq .Status "pending"}}<p>Your request was sent on {{.Created.Local.Format "Mon 15:04"}}. A parent will look at it soon.</p>
+        {{else if eq .Status "denied"}}<p>Your last request was denied{{if .Note}}: {{.Note}}{{end}}.</p>
+        {{end}}
+      {{end}}
+      {{if or (not .Request) (ne .Request.Status "pending")}}
+      <form method="post" action="/blocked">
+        <input type="hidden" name="domain" value="{{.Domain}}" />
+        <label>Why do you need this site?<textarea name="reason" maxlength="500" required></textarea></label>
+        <button type="submit">Ask a parent</button>
+      </form>
+      {{end}}
+      {{else if .Domain}}
+      <h2>{{.Domain}} is not blocked</h2>
+      <p><a href="http://{{.Domain}}/">Try again</a></p>
+      {{end}}
+    </main>
+  </body>
+</html>
+`))
+
+// blockInfo describes how domain is blocked for device.
+func (a *apiServer) blockInfo(domain, device string) blockPage {
+	page := blockPage{Domain: domain}
+	name, blocked := a.rules.Match(domain, device)
+	if !blocked {
+		return page
+	}
+	page.Blocked, page.Rule = true, name
+	if rule, ok := a.rules.Get(rules.ParseRef(name)); ok {
+		page.Note, page.Until = rule.Note, rule.ExpiresAt
+	}
+	page.Categories = a.budgets.Categories(domain)
+	if req, ok := a.unblock.Latest(domain, device); ok {
+		page.Request = &req
+	}
+	return page
+}
+
+func renderBlockPage(w http.ResponseWriter, status int, page blockPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := blockPageTmpl.Execute(w, page); err != nil {
+		logging.Errorf("render block page: %v", err)
+	}
+}
+
+// handleBlockPage shows the child why a domain is blocked and lets them ask
+// for access.
+func (a *apiServer) handleBlockPage(w http.ResponseWriter, r *http.Request) {
+	domain, err := rules.ValidateDomain(r.URL.Query().Get("domain"))
+	if err != nil {
+		renderBlockPage(w, http.StatusBadRequest, blockPage{Error: "Unknown site."})
+		return
+	}
+	renderBlockPage(w, http.StatusOK, a.blockInfo(domain, clientIP(r)))
+}
+
+// handleBlockPageSubmit queues an unblock request from the block page form.
+func (a *apiServer) handleBlockPageSubmit(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		writeError(w, http.StatusForbidden, "cross-origin request refused")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxFormBytes)
+	if err := r.ParseForm(); err != nil {
+		renderBlockPage(w, http.StatusBadRequest, blockPage{Error: "Invalid request."})
+		return
+	}
+	domain, err := rules.ValidateDomain(r.PostForm.Get("domain"))
+	if err != nil {
+		renderBlockPage(w, http.StatusBadRequest, blockPage{Error: "Unknown site."})
+		return
+	}
+	device := clientIP(r)
+	page := a.blockInfo(domain, device)
+	if !page.Blocked {
+		renderBlockPage(w, http.StatusOK, page)
+		return
+	}
+	reason := strings.TrimSpace(r.PostForm.Get("reason"))
+	req, created, err := a.unblock.Submit(domain, device, page.Rule, reason)
+	switch {
+	case errors.Is(err, unblock.ErrTooMany):
+		page.Error = "You already have many open requests. Please wait for a parent."
+		renderBlockPage(w, http.StatusTooManyRequests, page)
+		return
+	case errors.Is(err, unblock.ErrReasonSize):
+		page.Error = "Please keep the reason shorter."
+		renderBlockPage(w, http.StatusBadRequest, page)
+		return
+	case err != nil:
+		logging.Errorf("unblock request: %v", err)
+		page.Error = "The request could not be sent."
+		renderBlockPage(w, http.StatusInternalServerError, page)
+		return
+	}
+	if created {
+		a.recordEvent(events.Event{
+			Kind:      "control",
+			Timestamp: time.Now().UTC(),
+			SourceIP:  device,
+			Domain:    domain,
+			Action:    "unblock-request",
+			Rule:      page.Rule,
+			Reason:    reason,
+			Info:      req.ID,
+		})
+	}
+	http.Redirect(w, r, "/blocked?domain="+url.QueryEscape(domain), http.StatusSeeOther)
+}
+
+// decisionRequest approves or denies an unblock request. Approvals allow the
+// domain for the requesting device unless scope is "all", optionally until a
+// time or for a duration.
+type decisionRequest struct {
+	Note       string     `json:"note"`
+	Scope      string     `json:"scope"`
+	Subdomains bool       `json:"subdomains"`
+	Until      *time.Time `json:"until"`
+	Duration   string     `json:"duration"`
+}
+
+func (a *apiServer) handleListUnblockRequests(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"requests": a.unblock.List(r.URL.Query().Get("status"))})
+}
+
+func (a *apiServer) handleApproveUnblock(w http.ResponseWriter, r *http.Request) {
+	a.decideUnblock(w, r, unblock.StatusApproved)
+}
+
+func (a *apiServer) handleDenyUnblock(w http.ResponseWriter, r *http.Request) {
+	a.decideUnblock(w, r, unblock.StatusDenied)
+}
+
+func (a *apiServer) decideUnblock(w http.ResponseWriter, r *http.Request, status string) {
+	var dec decisionRequest
+	if !decodeBody(w, r, maxFormBytes, &dec) {
+		return
+	}
+	if len(dec.Note) > 1024 {
+		writeError(w, http.StatusBadRequest, "note is too long")
+		return
+	}
+	// Claim the request first so two parents deciding at once cannot both
+	// act on it; the claim is dropped again if no decision is recorded.
+	id := mux.Vars(r)["id"]
+	req, err := a.unblock.Claim(id)
+	switch {
+	case errors.Is(err, unblock.ErrNotFound):
+		writeError(w, http.StatusNotFound, "request not found")
+		return
+	case errors.Is(err, unblock.ErrDecided):
+		writeError(w, http.StatusConflict, "request already "+req.Status)
+		return
+	case err != nil:
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	decided := false
+	defer func() {
+		if !decided {
+			a.unblock.Release(id)
+		}
+	}()
+
+	var expires *time.Time
+	if status == unblock.StatusApproved {
+		if expires, err = parseExpiry(dec.Until, dec.Duration, a.rules.Now()); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		rule := rules.Rule{
+			Domain:     req.Domain,
+			Action:     rules.ActionAllow,
+			Device:     req.Device,
+			Subdomains: dec.Subdomains,
+			Note:       fmt.Sprintf("unblock request %s: %s", req.ID, req.Reason),
+			CreatedBy:  currentUser(r).Name,
+			ExpiresAt:  expires,
+		}
+		switch dec.Scope {
+		case "", "device":
+		case "all":
+			rule.Device = ""
+		default:
+			writeError(w, http.StatusBadRequest, "scope must be device or all")
+			return
+		}
+		ch, err := a.rules.Apply(0, []rules.Rule{rule}, nil)
+		if err != nil {
+			a.writeRuleError(w, err)
+			return
+		}
+		a.rulesChanged(w, r, "rules.unblock", ch)
+	}
+
+	req, err = a.unblock.Decide(id, status, currentUser(r).Name, dec.Note, expires)
+	decided = true
+	if err != nil {
+		if errors.Is(err, unblock.ErrDecided) {
+			writeError(w, http.StatusConflict, "request already "+req.Status)
+			return
+		}
+		logging.Errorf("decide unblock request %s: %v", id, err)
+		writeError(w, http.StatusInternalServerError, "update request failed")
+		return
+	}
+	action, auditAction := "unblock-approved", "unblock.approve"
+	if status == unblock.StatusDenied {
+		action, auditAction = "unblock-denied", "unblock.deny"
+	}
+	a.recordEvent(events.Event{
+		Kind:      "control",
+		Timestamp: time.Now().UTC(),
+		SourceIP:  req.Device,
+		Domain:    req.Domain,
+		Action:    action,
+		Reason:    dec.Note,
+		Info:      req.ID,
+	})
+	a.recordAudit(r, audit.Entry{
+		Action:  auditAction,
+		Target:  req.Domain,
+		Summary: fmt.Sprintf("%s request %s from %s", status, req.ID, req.Device),
+		After:   req,
+	})
+	writeJSON(w, http.StatusOK, req)
+}
@@ -0,0 +1,345 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	filePrefix  = "audit-"
+	fileSuffix  = ".jsonl"
+	monthLayout = "2006-01"
+)
+
+// Entry records one change: who made it, from where, and what it looked like
+// before and after.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user,omitempty"`
+	Role     string    `json:"role,omitempty"`
+	ClientIP string    `json:"clientIp,omitempty"`
+	Action   string    `json:"action"`
+	Target   string    `json:"target,omitempty"`
+	Summary  string    `json:"summary,omitempty"`
+	Before   any       `json:"before,omitempty"`
+	After    any       `json:"after,omitempty"`
+	Diff     *Diff     `json:"diff,omitempty"`
+}
+
+// Diff lists the members added to and removed from a set-valued setting.
+type Diff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// DiffStrings compares two string sets; it returns nil when they are equal.
+func DiffStrings(before, after []string) *Diff {
+	old := make(map[string]bool, len(before))
+	for _, s := range before {
+		old[s] = true
+	}
+	seen := make(map[string]bool, len(after))
+	d := &Diff{}
+	for _, s := range after {
+		seen[s] = true
+		if !old[s] {
+			d.Added = append(d.Added, s)
+		}
+	}
+	for _, s := range before {
+		if !seen[s] {
+			d.Removed = append(d.Removed, s)
+		}
+	}
+	if len(d.Added) == 0 && len(d.Removed) == 0 {
+		return nil
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	return d
+}
+
+// Query selects entries, newest first. Until is exclusive. Cursor, taken
+// from the previous Page, continues after the last entry it returned.
+type Query struct {
+	Since  time.Time
+	Until  time.Time
+	User   string
+	Action string
+	Limit  int
+	Cursor Cursor
+}
+
+// Page is one result page; NextCursor is zero when no more entries match.
+type Page struct {
+	Entries    []Entry
+	NextCursor Cursor
+}
+
+// Cursor is the position of an entry: its time, which names its month file,
+// and its line in that file. Entries sharing a timestamp still differ.
+type Cursor struct {
+	Time time.Time
+	Seq  int
+}
+
+// IsZero reports whether c is the start of the log.
+func (c Cursor) IsZero() bool {
+	return c.Time.IsZero()
+}
+
+// String encodes c for use in URLs.
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(c.Time.UnixNano(), 10) + "-" + strconv.Itoa(c.Seq)
+}
+
+// ParseCursor decodes a cursor produced by Cursor.String.
+func ParseCursor(s string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+	ns, seq, ok := strings.Cut(s, "-")
+	t, err1 := strconv.ParseInt(ns, 10, 64)
+	n, err2 := strconv.Atoi(seq)
+	if !ok || err1 != nil || err2 != nil || t <= 0 || n < 0 {
+		return Cursor{}, fmt.Errorf("invalid audit cursor %q", s)
+	}
+	return Cursor{Time: time.Unix(0, t).UTC(), Seq: n}, nil
+}
+
+func (q Query) match(e Entry) bool {
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Time.Before(q.Until) {
+		return false
+	}
+	if q.User != "" && e.User != q.User {
+		return false
+	}
+	if q.Action != "" && e.Action != q.Action && !strings.HasPrefix(e.Action, q.Action+".") {
+		return false
+	}
+	return true
+}
+
+// Log appends entries to one JSONL file per month and drops months older
+// than the retention.
+type Log struct {
+	dir       string
+	retention int
+
+	mu   sync.Mutex
+	file *os.File
+	name string
+}
+
+// Open prepares dir for appending. A retention of zero months keeps
+// everything.
+func Open(dir string, retentionMonths int) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create audit dir: %w", err)
+	}
+	l := &Log{dir: dir, retention: retentionMonths}
+	if err := l.Prune(time.Now()); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Record appends e, stamping it with the current time when unset, and syncs
+// it to disk.
+func (l *Log) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+	buf = append(buf, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	name := fileName(e.Time)
+	if l.file == nil || l.name != name {
+		if l.file != nil {
+			l.file.Close()
+		}
+		f, err := os.OpenFile(filepath.Join(l.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			l.file = nil
+			return fmt.Errorf("open audit file: %w", err)
+		}
+		l.file, l.name = f, name
+	}
+	if _, err := l.file.Write(buf); err != nil {
+		return fmt.Errorf("write audit entry: %w", err)
+	}
+	return l.file.Sync()
+}
+
+// Query returns matching entries newest first.
+func (l *Log) Query(q Query) (Page, error) {
+	months, err := l.months()
+	if err != nil {
+		return Page{}, err
+	}
+	var cursorMonth time.Time
+	if !q.Cursor.IsZero() {
+		cursorMonth = monthStart(q.Cursor.Time.UTC())
+	}
+	p := Page{Entries: make([]Entry, 0)}
+	for i := len(months) - 1; i >= 0; i-- {
+		m := months[i]
+		if !q.Since.IsZero() && m.AddDate(0, 1, 0).Before(q.Since) {
+			break
+		}
+		if !q.Until.IsZero() && !m.Before(q.Until) {
+			continue
+		}
+		if !cursorMonth.IsZero() && m.After(cursorMonth) {
+			continue
+		}
+		lines, err := l.readMonth(m)
+		if err != nil {
+			return Page{}, err
+		}
+		for j := len(lines) - 1; j >= 0; j-- {
+			e := lines[j].entry
+			if m.Equal(cursorMonth) && lines[j].seq >= q.Cursor.Seq {
+				continue
+			}
+			if !q.match(e) {
+				continue
+			}
+			p.Entries = append(p.Entries, e)
+			if q.Limit > 0 && len(p.Entries) >= q.Limit {
+				p.NextCursor = Cursor{Time: e.Time, Seq: lines[j].seq}
+				return p, nil
+			}
+		}
+	}
+	return p, nil
+}
+
+// Run prunes expired months daily until ctx is cancelled.
+func (l *Log) Run(ctx context.Context) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.Prune(now)
+		}
+	}
+}
+
+// Prune deletes the files of months that ended more than the retention ago.
+func (l *Log) Prune(now time.Time) error {
+	if l.retention <= 0 {
+		return nil
+	}
+	months, err := l.months()
+	if err != nil {
+		return err
+	}
+	cutoff := monthStart(now.UTC()).AddDate(0, -l.retention, 0)
+	for _, m := range months {
+		if !m.Before(cutoff) {
+			break
+		}
+		if err := os.Remove(filepath.Join(l.dir, fileName(m))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("prune audit: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close releases the current file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// months lists the months with an audit file, oldest first.
+func (l *Log) months() ([]time.Time, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("list audit files: %w", err)
+	}
+	var months []time.Time
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		m, err := time.Parse(monthLayout, strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix))
+		if err != nil {
+			continue
+		}
+		months = append(months, m)
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Before(months[j]) })
+	return months, nil
+}
+
+// line is an entry with its line number in its month's file.
+type line struct {
+	entry Entry
+	seq   int
+}
+
+// readMonth decodes one month's file, skipping a torn final line. Skipped
+// lines still count, so line numbers stay stable as the file grows.
+func (l *Log) readMonth(m time.Time) ([]line, error) {
+	f, err := os.Open(filepath.Join(l.dir, fileName(m)))
+	if err != nil {
+		return nil, fmt.Errorf("open audit file: %w", err)
+	}
+	defer f.Close()
+
+	var lines []line
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	for seq := 0; sc.Scan(); seq++ {
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+		lines = append(lines, line{entry: e, seq: seq})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read audit file: %w", err)
+	}
+	return lines, nil
+}
+
+func fileName(t time.Time) string {
+	return filePrefix + t.UTC().Format(monthLayout) + fileSuffix
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,180 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openLog(t *testing.T, retention int) (*Log, string) {
+	t.Helper()
+	dir := t.TempDir()
+	l, err := Open(dir, retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l, dir
+}
+
+func record(t *testing.T, l *Log, entries ...Entry) {
+	t.Helper()
+	for _, e := range entries {
+		if err := l.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func targets(entries []Entry) []string {
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.Target)
+	}
+	return out
+}
+
+func TestMonthlyFiles(t *testing.T) {
+	l, dir := openLog(t, 0)
+	jan := time.Date(2026, 1, 31, 23, 59, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	record(t, l,
+		Entry{Time: jan, User: "admin", Action: "rules.replace", Target: "a"},
+		Entry{Time: feb, User: "parent", Action: "rules.add", Target: "b"},
+		Entry{Time: feb.Add(time.Hour), User: "admin", Action: "user.password", Target: "c"},
+		// A late entry for January goes to January's file.
+		Entry{Time: jan.Add(30 * time.Second), User: "admin", Action: "rules.remove", Target: "d"},
+	)
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "audit-2026-01.jsonl"), filepath.Join(dir, "audit-2026-02.jsonl")}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("files %v, want %v", files, want)
+	}
+
+	for _, tc := range []struct {
+		name string
+		q    Query
+		want []string
+	}{
+		{"all", Query{}, []string{"c", "b", "d", "a"}},
+		{"user", Query{User: "admin"}, []string{"c", "d", "a"}},
+		{"action prefix", Query{Action: "rules"}, []string{"b", "d", "a"}},
+		{"exact action", Query{Action: "rules.add"}, []string{"b"}},
+		{"partial segment", Query{Action: "rule"}, []string{}},
+		{"since", Query{Since: feb}, []string{"c", "b"}},
+		{"until exclusive", Query{Until: feb.Add(time.Hour)}, []string{"b", "d", "a"}},
+		{"limit", Query{Limit: 2}, []string{"c", "b"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := l.Query(tc.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := targets(page.Entries); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQueryPagesThroughSharedTimestamps(t *testing.T) {
+	l, _ := openLog(t, 0)
+	jan := time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 3, 8, 0, 0, 0, time.UTC)
+	// Bulk changes stamp several entries with the same time, across pages.
+	var want []string
+	for _, e := range []Entry{
+		{Time: jan, Target: "1"},
+		{Time: jan, Target: "2"},
+		{Time: jan, Target: "3"},
+		{Time: feb, Target: "4"},
+		{Time: feb, Target: "5"},
+		{Time: feb, Target: "6"},
+		{Time: feb, Target: "7"},
+	} {
+		e.Action = "rules.add"
+		record(t, l, e)
+		want = append([]string{e.Target}, want...)
+	}
+
+	var got []string
+	q := Query{Action: "rules", Limit: 3}
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("paging does not terminate")
+		}
+		page, err := l.Query(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, targets(page.Entries)...)
+		if page.NextCursor.IsZero() {
+			break
+		}
+		// The cursor survives the round trip through the API.
+		q.Cursor, err = ParseCursor(page.NextCursor.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("paged %v, want %v", got, want)
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	c := Cursor{Time: time.Date(2026, 3, 2, 12, 0, 0, 5, time.UTC), Seq: 17}
+	got, err := ParseCursor(c.String())
+	if err != nil || !got.Time.Equal(c.Time) || got.Seq != c.Seq {
+		t.Fatalf("round trip = %+v, %v", got, err)
+	}
+	if got, err := ParseCursor(""); err != nil || !got.IsZero() {
+		t.Fatalf("empty cursor = %+v, %v", got, err)
+	}
+	for _, bad := range []string{"x", "12", "12-x", "-1-2", "12--1"} {
+		if _, err := ParseCursor(bad); err == nil {
+			t.Errorf("ParseCursor(%q) accepted", bad)
+		}
+	}
+}
+
+func TestTornLineIsSkipped(t *testing.T) {
+	l, dir := openLog(t, 0)
+	at := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	record(t, l, Entry{Time: at, Action: "rules.add", Target: "before"})
+	path := filepath.Join(dir, fileName(at))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2026-03-02T12:00:00Z","act`)
+	f.Close()
+
+	page, err := l.Query(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := targets(page.Entries); !reflect.DeepEqual(got, []string{"before"}) {
+		t.Fatalf("entries %v", got)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	l, dir := openLog(t, 2)
+	for _, m := range []time.Month{1, 2, 3, 4} {
+		record(t, l, Entry{Time: time.Date(2026, m, 15, 0, 0, 0, 0, time.UTC), Action: "rules.add"})
+	}
+	if err := l.Prune(time.Date(2026, 4, 20, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	want := []string{filepath.Join(dir, "audit-2026-02.jsonl"), filepath.Join(dir, "audit-2026-03.jsonl"), filepath.Join(dir, "audit-2026-04.jsonl")}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("files after prune %v, want %v", files, want)
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Password hashes are stored as "pbkdf2-sha256$<iterations>$<salt>$<key>"
+// with base64 (raw, standard alphabet) salt and key.
+const (
+	hashScheme     = "pbkdf2-sha256"
+	hashIterations = 310000
+	saltLen        = 16
+	keyLen         = 32
+)
+
+var errBadHash = errors.New("malformed password hash")
+
+// HashPassword derives a salted PBKDF2-HMAC-SHA256 hash of password.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key := pbkdf2([]byte(password), salt, hashIterations, keyLen)
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", hashScheme, hashIterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// CheckPassword reports whether password matches the stored hash.
+func CheckPassword(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return false, errBadHash
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false, errBadHash
+	}
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return false, errBadHash
+	}
+	want, err := enc.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false, errBadHash
+	}
+	got := pbkdf2([]byte(password), salt, iter, len(want))
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
+
+// pbkdf2 implements RFC 8018 PBKDF2 with HMAC-SHA256 as the PRF.
+func pbkdf2(password, salt []byte, iter, size int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (size + hashLen - 1) / hashLen
+
+	out := make([]byte, 0, blocks*hashLen)
+	var counter [4]byte
+	u := make([]byte, hashLen)
+	t := make([]byte, hashLen)
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter[:])
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		for i := 1; i < iter; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:size]
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// The RFC 6070 inputs with their PBKDF2-HMAC-SHA256 outputs, followed by the
+// RFC 7914 section 11 vectors.
+var pbkdf2Vectors = []struct {
+	password, salt string
+	iter, size     int
+	want           string
+}{
+	{"password", "salt", 1, 32, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+	{"password", "salt", 2, 32, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+	{"password", "salt", 4096, 32, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	{"passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 40,
+		"348c89dbcbd32b2f32d814b8116e84cf2b17347ebc1800181c4e2a1fb8dd53e1c635518c7dac47e9"},
+	{"pass\x00word", "sa\x00lt", 4096, 16, "89b69d0516f829893c696226650a8687"},
+	{"passwd", "salt", 1, 64,
+		"55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+			"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+	{"Password", "NaCl", 80000, 64,
+		"4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56" +
+			"a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+}
+
+func TestPBKDF2Vectors(t *testing.T) {
+	for _, v := range pbkdf2Vectors {
+		got := hex.EncodeToString(pbkdf2([]byte(v.password), []byte(v.salt), v.iter, v.size))
+		if got != v.want {
+			t.Errorf("pbkdf2(%q, %q, %d, %d) = %s, want %s", v.password, v.salt, v.iter, v.size, got, v.want)
+		}
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, hashScheme+"$") {
+		t.Fatalf("hash = %q", hash)
+	}
+	if ok, err := CheckPassword(hash, "correct horse"); !ok || err != nil {
+		t.Fatalf("right password: %v, %v", ok, err)
+	}
+	if ok, err := CheckPassword(hash, "wrong horse"); ok || err != nil {
+		t.Fatalf("wrong password: %v, %v", ok, err)
+	}
+	for _, bad := range []string{"", "md5$1$a$b", hashScheme + "$0$c2FsdA$a2V5", hashScheme + "$1$!!$a2V5"} {
+		if _, err := CheckPassword(bad, "x"); err != errBadHash {
+			t.Errorf("CheckPassword(%q) error = %v, want errBadHash", bad, err)
+		}
+	}
+}
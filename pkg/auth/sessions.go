@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// Session lengths: idle sessions expire after SessionIdle, and every session
+// ends SessionMax after login.
+const (
+	SessionIdle = 12 * time.Hour
+	SessionMax  = 7 * 24 * time.Hour
+)
+
+// Session is a signed-in browser. CSRF must accompany every mutating request
+// authenticated by the session cookie.
+type Session struct {
+	ID      string
+	CSRF    string
+	User    string
+	Created time.Time
+	Seen    time.Time
+}
+
+// Sessions keeps browser sessions in memory; restarting the server signs
+// everyone out.
+type Sessions struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+	now      func() time.Time
+}
+
+// NewSessions creates an empty session table.
+func NewSessions() *Sessions {
+	return &Sessions{sessions: make(map[string]*Session), now: time.Now}
+}
+
+// Create starts a session for user.
+func (s *Sessions) Create(user string) (Session, error) {
+	id, err := randomString(32)
+	if err != nil {
+		return Session{}, err
+	}
+	csrf, err := randomString(32)
+	if err != nil {
+		return Session{}, err
+	}
+	now := s.now()
+	sess := &Session{ID: id, CSRF: csrf, User: user, Created: now, Seen: now}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[id] = sess
+	return *sess, nil
+}
+
+// Get returns the live session with id and refreshes its idle timer.
+func (s *Sessions) Get(id string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return Session{}, false
+	}
+	now := s.now()
+	if now.Sub(sess.Seen) > SessionIdle || now.Sub(sess.Created) > SessionMax {
+		delete(s.sessions, id)
+		return Session{}, false
+	}
+	sess.Seen = now
+	return *sess, true
+}
+
+// Delete ends the session with id.
+func (s *Sessions) Delete(id string) {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+}
+
+// DeleteUser ends every session of user.
+func (s *Sessions) DeleteUser(user string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, sess := range s.sessions {
+		if sess.User == user {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// Prune drops expired sessions.
+func (s *Sessions) Prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for id, sess := range s.sessions {
+		if now.Sub(sess.Seen) > SessionIdle || now.Sub(sess.Created) > SessionMax {
+			delete(s.sessions, id)
+		}
+	}
+}
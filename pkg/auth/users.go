@@ -0,0 +1,378 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/fsutil"
+)
+
+// Role grants a fixed set of permissions; each role includes the ones below it.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleParent Role = "parent"
+	RoleAdmin  Role = "admin"
+)
+
+var roleRank = map[Role]int{RoleViewer: 1, RoleParent: 2, RoleAdmin: 3}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	return roleRank[r] > 0
+}
+
+// Allows reports whether r carries at least the permissions of min.
+func (r Role) Allows(min Role) bool {
+	return r.Valid() && roleRank[r] >= roleRank[min]
+}
+
+// Errors returned by Store.
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrUserExists         = errors.New("user already exists")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrTokenNotFound      = errors.New("token not found")
+	ErrLastAdmin          = errors.New("cannot remove the last admin")
+	ErrAlreadySetUp       = errors.New("initial admin already exists")
+)
+
+const minPasswordLen = 8
+
+// User is an account allowed to sign in to the web UI.
+type User struct {
+	Name         string    `json:"name"`
+	Role         Role      `json:"role"`
+	PasswordHash string    `json:"passwordHash"`
+	Created      time.Time `json:"created"`
+}
+
+// Token is a long-lived API credential for scripts. Only a SHA-256 of the
+// secret is kept.
+type Token struct {
+	ID       string    `json:"id"`
+	Label    string    `json:"label"`
+	User     string    `json:"user"`
+	Hash     string    `json:"hash"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"lastUsed,omitempty"`
+}
+
+type storeFile struct {
+	Users  []User  `json:"users"`
+	Tokens []Token `json:"tokens"`
+}
+
+// Store persists accounts and API tokens in a JSON file readable only by
+// the server.
+type Store struct {
+	path string
+
+	mu     sync.RWMutex
+	users  map[string]*User
+	tokens map[string]*Token
+}
+
+// Open loads the account file at path, starting empty if it does not exist.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path:   path,
+		users:  make(map[string]*User),
+		tokens: make(map[string]*Token),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read users: %w", err)
+	}
+	var f storeFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse users: %w", err)
+	}
+	for i := range f.Users {
+		u := f.Users[i]
+		s.users[u.Name] = &u
+	}
+	for i := range f.Tokens {
+		t := f.Tokens[i]
+		s.tokens[t.Hash] = &t
+	}
+	return s, nil
+}
+
+// NeedsSetup reports whether no account exists yet.
+func (s *Store) NeedsSetup() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.users) == 0
+}
+
+// Setup creates the initial admin; it fails once any account exists.
+func (s *Store) Setup(name, password string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.users) > 0 {
+		return User{}, ErrAlreadySetUp
+	}
+	return s.addUser(name, password, RoleAdmin)
+}
+
+// AddUser creates an account with the given role.
+func (s *Store) AddUser(name, password string, role Role) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addUser(name, password, role)
+}
+
+func (s *Store) addUser(name, password string, role Role) (User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 64 {
+		return User{}, errors.New("user name must be 1-64 characters")
+	}
+	if !role.Valid() {
+		return User{}, fmt.Errorf("unknown role %q", role)
+	}
+	if len(password) < minPasswordLen {
+		return User{}, fmt.Errorf("password must be at least %d characters", minPasswordLen)
+	}
+	if _, ok := s.users[name]; ok {
+		return User{}, ErrUserExists
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return User{}, err
+	}
+	u := &User{Name: name, Role: role, PasswordHash: hash, Created: time.Now().UTC()}
+	s.users[name] = u
+	if err := s.save(); err != nil {
+		delete(s.users, name)
+		return User{}, err
+	}
+	return *u, nil
+}
+
+// SetPassword replaces the password of an existing account.
+func (s *Store) SetPassword(name, password string) error {
+	if len(password) < minPasswordLen {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLen)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[name]
+	if !ok {
+		return ErrUserNotFound
+	}
+	prev := u.PasswordHash
+	u.PasswordHash = hash
+	if err := s.save(); err != nil {
+		u.PasswordHash = prev
+		return err
+	}
+	return nil
+}
+
+// DeleteUser removes an account and its API tokens. The last admin cannot
+// be removed.
+func (s *Store) DeleteUser(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[name]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if u.Role == RoleAdmin && s.countRole(RoleAdmin) == 1 {
+		return ErrLastAdmin
+	}
+	delete(s.users, name)
+	for hash, t := range s.tokens {
+		if t.User == name {
+			delete(s.tokens, hash)
+		}
+	}
+	return s.save()
+}
+
+func (s *Store) countRole(role Role) int {
+	n := 0
+	for _, u := range s.users {
+		if u.Role == role {
+			n++
+		}
+	}
+	return n
+}
+
+// Authenticate checks a name and password. Unknown users and wrong passwords
+// yield the same error.
+func (s *Store) Authenticate(name, password string) (User, error) {
+	s.mu.RLock()
+	u, ok := s.users[name]
+	var user User
+	if ok {
+		user = *u
+	}
+	s.mu.RUnlock()
+	if !ok {
+		// Spend the same time as a real check so names cannot be probed.
+		CheckPassword(dummyHash(), password)
+		return User{}, ErrInvalidCredentials
+	}
+	match, err := CheckPassword(user.PasswordHash, password)
+	if err != nil || !match {
+		return User{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// dummyHash is checked for unknown users to equalize login timing.
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("kidos-dummy-password")
+	return hash
+})
+
+// User looks up an account by name.
+func (s *Store) User(name string) (User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.users[name]
+	if !ok {
+		return User{}, false
+	}
+	return *u, true
+}
+
+// Users lists accounts sorted by name.
+func (s *Store) Users() []User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]User, 0, len(s.users))
+	for _, u := range s.users {
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// CreateToken issues an API token for user and returns its secret, which is
+// not retrievable afterwards.
+func (s *Store) CreateToken(user, label string) (string, Token, error) {
+	secret, err := randomString(32)
+	if err != nil {
+		return "", Token{}, err
+	}
+	id, err := randomString(6)
+	if err != nil {
+		return "", Token{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[user]; !ok {
+		return "", Token{}, ErrUserNotFound
+	}
+	t := &Token{ID: id, Label: label, User: user, Hash: hashToken(secret), Created: time.Now().UTC()}
+	s.tokens[t.Hash] = t
+	if err := s.save(); err != nil {
+		delete(s.tokens, t.Hash)
+		return "", Token{}, err
+	}
+	return secret, *t, nil
+}
+
+// Tokens lists the API tokens owned by user, or all tokens when user is empty.
+func (s *Store) Tokens(user string) []Token {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Token, 0)
+	for _, t := range s.tokens {
+		if user == "" || t.User == user {
+			out = append(out, *t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.Before(out[j].Created) })
+	return out
+}
+
+// RevokeToken deletes the token with id. A non-empty owner restricts the
+// deletion to that user's tokens.
+func (s *Store) RevokeToken(id, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, t := range s.tokens {
+		if t.ID == id && (owner == "" || t.User == owner) {
+			delete(s.tokens, hash)
+			return s.save()
+		}
+	}
+	return ErrTokenNotFound
+}
+
+// VerifyToken resolves an API token secret to its owner.
+func (s *Store) VerifyToken(secret string) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[hashToken(secret)]
+	if !ok {
+		return User{}, false
+	}
+	u, ok := s.users[t.User]
+	if !ok {
+		return User{}, false
+	}
+	// LastUsed is only persisted with the next change to keep requests cheap.
+	t.LastUsed = time.Now().UTC()
+	return *u, true
+}
+
+func (s *Store) save() error {
+	f := storeFile{Users: make([]User, 0, len(s.users)), Tokens: make([]Token, 0, len(s.tokens))}
+	for _, u := range s.users {
+		f.Users = append(f.Users, *u)
+	}
+	for _, t := range s.tokens {
+		f.Tokens = append(f.Tokens, *t)
+	}
+	sort.Slice(f.Users, func(i, j int) bool { return f.Users[i].Name < f.Users[j].Name })
+	sort.Slice(f.Tokens, func(i, j int) bool { return f.Tokens[i].Created.Before(f.Tokens[j].Created) })
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialize users: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create users dir: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("write users: %w", err)
+	}
+	return nil
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate random: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,285 @@
+package budget
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/config"
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/rules"
+)
+
+// Slot is the accounting granularity: a device is active in a category for a
+// whole slot as soon as one event attributes activity to it.
+const Slot = time.Minute
+
+// Status reports the budget of one device and category in the current period.
+type Status struct {
+	Device           string `json:"device"`
+	Category         string `json:"category"`
+	LimitMinutes     int    `json:"limitMinutes"`
+	UsedMinutes      int    `json:"usedMinutes"`
+	RemainingMinutes int    `json:"remainingMinutes"`
+	Exhausted        bool   `json:"exhausted"`
+}
+
+// Exhaustion tells the caller to block Domains for Device until the next
+// reset.
+type Exhaustion struct {
+	Device   string
+	Category string
+	Domains  []string
+	Until    time.Time
+}
+
+type category struct {
+	name    string
+	domains []string
+}
+
+type key struct {
+	device   string
+	category string
+}
+
+// Engine attributes active time to categories per device and tracks it
+// against the configured daily limits.
+type Engine struct {
+	mu         sync.Mutex
+	categories []category
+	limits     map[key]int
+	resetAt    time.Duration
+	now        func() time.Time
+
+	start    time.Time
+	next     time.Time
+	usage    map[key]map[int64]struct{}
+	enforced map[key]bool
+}
+
+// New creates an engine from cfg. A nil now uses the wall clock.
+func New(cfg config.BudgetConfig, now func() time.Time) (*Engine, error) {
+	if now == nil {
+		now = time.Now
+	}
+	e := &Engine{
+		limits:   make(map[key]int, len(cfg.Limits)),
+		now:      now,
+		usage:    make(map[key]map[int64]struct{}),
+		enforced: make(map[key]bool),
+	}
+	if cfg.ResetAt != "" {
+		t, err := time.Parse("15:04", cfg.ResetAt)
+		if err != nil {
+			return nil, fmt.Errorf("budget reset time %q: %w", cfg.ResetAt, err)
+		}
+		e.resetAt = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	for name, suffixes := range cfg.Categories {
+		c := category{name: name}
+		for _, s := range suffixes {
+			d, err := rules.ValidateDomain(s)
+			if err != nil {
+				return nil, fmt.Errorf("budget category %s: %w", name, err)
+			}
+			c.domains = append(c.domains, d)
+		}
+		sort.Strings(c.domains)
+		e.categories = append(e.categories, c)
+	}
+	sort.Slice(e.categories, func(i, j int) bool { return e.categories[i].name < e.categories[j].name })
+	for _, l := range cfg.Limits {
+		if _, ok := e.category(l.Category); !ok {
+			return nil, fmt.Errorf("budget limit for unknown category %q", l.Category)
+		}
+		if l.Minutes < 0 {
+			return nil, fmt.Errorf("budget limit for %s: negative minutes", l.Category)
+		}
+		e.limits[key{device: l.Device, category: l.Category}] = l.Minutes
+	}
+	e.roll(now())
+	return e, nil
+}
+
+// Observe accounts the activity in ev: allowed DNS queries by their source
+// and traffic summaries by the internal address and resolved external domain.
+// Summary counts are deltas since the previous summary, so a pair is only
+// active when it moved packets in that interval.
+func (e *Engine) Observe(ev events.Event) {
+	switch ev.Kind {
+	case "dns":
+		if ev.Action == "allow" && (ev.Direction == "" || ev.Direction == "query") {
+			e.record(ev.SourceIP, ev.Domain, ev.Timestamp)
+		}
+	case "ip_pair_summary":
+		for _, pc := range ev.PairCounts {
+			if pc.Incoming+pc.Outgoing > 0 {
+				e.record(pc.Internal, pc.ExternalDomain, ev.Timestamp)
+			}
+		}
+	}
+}
+
+func (e *Engine) record(device, domain string, ts time.Time) {
+	if device == "" || domain == "" {
+		return
+	}
+	cats := e.Categories(domain)
+	if len(cats) == 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	e.roll(now)
+	if ts.IsZero() {
+		ts = now
+	}
+	if ts.Before(e.start) || ts.After(now.Add(Slot)) {
+		return
+	}
+	slot := ts.Unix() / int64(Slot/time.Second)
+	for _, c := range cats {
+		k := key{device: device, category: c}
+		if _, ok := e.limitFor(k); !ok {
+			continue
+		}
+		slots := e.usage[k]
+		if slots == nil {
+			slots = make(map[int64]struct{})
+			e.usage[k] = slots
+		}
+		slots[slot] = struct{}{}
+	}
+}
+
+// Status returns the budgets of device, or of every device when empty,
+// sorted by device and category.
+func (e *Engine) Status(device string) []Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.roll(e.now())
+	out := make([]Status, 0)
+	for _, k := range e.keys() {
+		if device != "" && k.device != device {
+			continue
+		}
+		limit, _ := e.limitFor(k)
+		used := len(e.usage[k])
+		out = append(out, Status{
+			Device:           k.device,
+			Category:         k.category,
+			LimitMinutes:     limit,
+			UsedMinutes:      used,
+			RemainingMinutes: max(limit-used, 0),
+			Exhausted:        used >= limit,
+		})
+	}
+	return out
+}
+
+// Period returns the start of the current accounting period and the next
+// reset.
+func (e *Engine) Period() (time.Time, time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.roll(e.now())
+	return e.start, e.next
+}
+
+// Exhausted returns the budgets used up since the last call. Each is
+// reported once per period.
+func (e *Engine) Exhausted() []Exhaustion {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.roll(e.now())
+	var out []Exhaustion
+	for _, k := range e.keys() {
+		limit, _ := e.limitFor(k)
+		if e.enforced[k] || len(e.usage[k]) < limit {
+			continue
+		}
+		e.enforced[k] = true
+		c, _ := e.category(k.category)
+		out = append(out, Exhaustion{
+			Device:   k.device,
+			Category: k.category,
+			Domains:  c.domains,
+			Until:    e.next,
+		})
+	}
+	return out
+}
+
+// keys lists the device budgets with usage or a device-specific limit.
+func (e *Engine) keys() []key {
+	seen := make(map[key]bool, len(e.usage)+len(e.limits))
+	var out []key
+	for k := range e.usage {
+		seen[k] = true
+		out = append(out, k)
+	}
+	for k := range e.limits {
+		if k.device != "" && !seen[k] {
+			out = append(out, k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].device == out[j].device {
+			return out[i].category < out[j].category
+		}
+		return out[i].device < out[j].device
+	})
+	return out
+}
+
+// limitFor returns the device's own limit or the one for every device.
+func (e *Engine) limitFor(k key) (int, bool) {
+	if m, ok := e.limits[k]; ok {
+		return m, true
+	}
+	m, ok := e.limits[key{category: k.category}]
+	return m, ok
+}
+
+func (e *Engine) category(name string) (category, bool) {
+	for _, c := range e.categories {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return category{}, false
+}
+
+// Categories returns the categories whose suffixes cover domain.
+func (e *Engine) Categories(domain string) []string {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	var out []string
+	for _, c := range e.categories {
+		for _, s := range c.domains {
+			if domain == s || strings.HasSuffix(domain, "."+s) {
+				out = append(out, c.name)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// roll starts a new period once the reset time has passed.
+func (e *Engine) roll(now time.Time) {
+	if !e.next.IsZero() && now.Before(e.next) {
+		return
+	}
+	y, m, d := now.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, now.Location()).Add(e.resetAt)
+	if start.After(now) {
+		start = start.AddDate(0, 0, -1)
+	}
+	e.start, e.next = start, start.AddDate(0, 0, 1)
+	e.usage = make(map[key]map[int64]struct{})
+	e.enforced = make(map[key]bool)
+}
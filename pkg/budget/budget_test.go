@@ -0,0 +1,193 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/config"
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+// clock is a settable time source for New.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestEngine(t *testing.T, c *clock, limits ...config.BudgetLimit) *Engine {
+	t.Helper()
+	e, err := New(config.BudgetConfig{
+		Categories: map[string][]string{
+			"games": {"games.example"},
+			"video": {"video.example", "clips.example"},
+		},
+		Limits:  limits,
+		ResetAt: "04:00",
+	}, c.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func query(device, domain string, ts time.Time) events.Event {
+	return events.Event{Kind: "dns", Action: "allow", Direction: "query", SourceIP: device, Domain: domain, Timestamp: ts}
+}
+
+func status(t *testing.T, e *Engine, device, category string) Status {
+	t.Helper()
+	for _, st := range e.Status(device) {
+		if st.Category == category {
+			return st
+		}
+	}
+	t.Fatalf("no status for %s %s", device, category)
+	return Status{}
+}
+
+func TestSlotAccounting(t *testing.T) {
+	c := &clock{t: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)}
+	e := newTestEngine(t, c, config.BudgetLimit{Category: "video", Minutes: 30})
+
+	// Several events within one minute use a single slot.
+	start := c.t
+	c.t = c.t.Add(2 * time.Minute)
+	for i := 0; i < 5; i++ {
+		e.Observe(query("10.0.0.2", "www.video.example", start.Add(time.Duration(i)*10*time.Second)))
+	}
+	e.Observe(query("10.0.0.2", "clips.example.", start.Add(30*time.Second)))
+	e.Observe(query("10.0.0.2", "cdn.clips.example", start.Add(90*time.Second)))
+	if st := status(t, e, "10.0.0.2", "video"); st.UsedMinutes != 2 || st.RemainingMinutes != 28 || st.Exhausted {
+		t.Fatalf("status = %+v, want 2 minutes used", st)
+	}
+
+	// Blocked queries, answers, unrelated domains and other devices do not count.
+	blocked := query("10.0.0.2", "video.example", c.t)
+	blocked.Action = "block"
+	answer := query("10.0.0.2", "video.example", c.t)
+	answer.Direction = "response"
+	e.Observe(blocked)
+	e.Observe(answer)
+	e.Observe(query("10.0.0.2", "news.example", c.t))
+	e.Observe(query("10.0.0.2", "notvideo.example", c.t))
+	if st := status(t, e, "10.0.0.2", "video"); st.UsedMinutes != 2 {
+		t.Fatalf("status = %+v after events that should not count", st)
+	}
+	if len(e.Status("10.0.0.3")) != 0 {
+		t.Fatalf("idle device has status %+v", e.Status("10.0.0.3"))
+	}
+
+	// Events from before the period or too far ahead are ignored.
+	e.Observe(query("10.0.0.2", "video.example", c.t.Add(-12*time.Hour)))
+	e.Observe(query("10.0.0.2", "video.example", c.t.Add(time.Hour)))
+	if st := status(t, e, "10.0.0.2", "video"); st.UsedMinutes != 2 {
+		t.Fatalf("status = %+v after out-of-period events", st)
+	}
+}
+
+func TestSummaryCountsOnlyNewTraffic(t *testing.T) {
+	c := &clock{t: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)}
+	e := newTestEngine(t, c, config.BudgetLimit{Category: "games", Minutes: 60})
+	summary := func(in, out uint64) events.Event {
+		return events.Event{Kind: "ip_pair_summary", Timestamp: c.t, PairCounts: []events.PairCount{{
+			Internal: "10.0.0.2", ExternalDomain: "play.games.example", Incoming: in, Outgoing: out,
+		}}}
+	}
+
+	e.Observe(summary(10, 4))
+	// A pair still listed for its sliding windows but idle since the
+	// previous summary is not activity.
+	for i := 0; i < 5; i++ {
+		c.t = c.t.Add(time.Minute)
+		e.Observe(summary(0, 0))
+	}
+	c.t = c.t.Add(time.Minute)
+	e.Observe(summary(0, 1))
+	if st := status(t, e, "10.0.0.2", "games"); st.UsedMinutes != 2 {
+		t.Fatalf("status = %+v, want the 2 minutes with traffic", st)
+	}
+}
+
+func TestResetRollover(t *testing.T) {
+	// 03:30 is still in the period that started at 04:00 the day before.
+	c := &clock{t: time.Date(2026, 3, 2, 3, 30, 0, 0, time.UTC)}
+	e := newTestEngine(t, c, config.BudgetLimit{Category: "video", Minutes: 10})
+	start, next := e.Period()
+	if want := time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC); !start.Equal(want) || !next.Equal(want.AddDate(0, 0, 1)) {
+		t.Fatalf("period = %v..%v", start, next)
+	}
+
+	e.Observe(query("10.0.0.2", "video.example", c.t))
+	if st := status(t, e, "10.0.0.2", "video"); st.UsedMinutes != 1 {
+		t.Fatalf("status = %+v", st)
+	}
+
+	c.t = time.Date(2026, 3, 2, 4, 0, 0, 0, time.UTC)
+	if start, _ := e.Period(); !start.Equal(c.t) {
+		t.Fatalf("period starts %v after the reset, want %v", start, c.t)
+	}
+	if got := e.Status("10.0.0.2"); len(got) != 0 {
+		t.Fatalf("usage survived the reset: %+v", got)
+	}
+	// A late event from the previous period does not count in the new one.
+	e.Observe(query("10.0.0.2", "video.example", c.t.Add(-time.Minute)))
+	if got := e.Status("10.0.0.2"); len(got) != 0 {
+		t.Fatalf("late event counted: %+v", got)
+	}
+}
+
+func TestExhaustedOncePerPeriod(t *testing.T) {
+	c := &clock{t: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)}
+	e := newTestEngine(t, c,
+		config.BudgetLimit{Category: "games", Minutes: 3},
+		config.BudgetLimit{Device: "10.0.0.9", Category: "games", Minutes: 60},
+	)
+	use := func(device string, minutes int) {
+		for i := 0; i < minutes; i++ {
+			e.Observe(query(device, "games.example", c.t))
+			c.t = c.t.Add(time.Minute)
+		}
+	}
+
+	use("10.0.0.2", 2)
+	if got := e.Exhausted(); len(got) != 0 {
+		t.Fatalf("exhausted early: %+v", got)
+	}
+	use("10.0.0.2", 1)
+	use("10.0.0.9", 3)
+	got := e.Exhausted()
+	if len(got) != 1 || got[0].Device != "10.0.0.2" || got[0].Category != "games" {
+		t.Fatalf("exhausted = %+v, want only the device on the default limit", got)
+	}
+	if got[0].Domains[0] != "games.example" || !got[0].Until.Equal(time.Date(2026, 3, 3, 4, 0, 0, 0, time.UTC)) {
+		t.Fatalf("exhaustion = %+v", got[0])
+	}
+
+	// Further use in the same period is not reported again.
+	use("10.0.0.2", 5)
+	if got := e.Exhausted(); len(got) != 0 {
+		t.Fatalf("reported twice: %+v", got)
+	}
+	if st := status(t, e, "10.0.0.2", "games"); !st.Exhausted || st.RemainingMinutes != 0 {
+		t.Fatalf("status = %+v", st)
+	}
+
+	// The next period starts fresh and reports again.
+	c.t = time.Date(2026, 3, 3, 4, 0, 0, 0, time.UTC)
+	use("10.0.0.2", 3)
+	if got := e.Exhausted(); len(got) != 1 {
+		t.Fatalf("exhausted after reset = %+v", got)
+	}
+}
+
+func TestNewRejectsBadConfig(t *testing.T) {
+	for _, cfg := range []config.BudgetConfig{
+		{ResetAt: "25:00"},
+		{Categories: map[string][]string{"x": {"bad domain"}}},
+		{Limits: []config.BudgetLimit{{Category: "missing", Minutes: 1}}},
+		{Categories: map[string][]string{"x": {"x.example"}}, Limits: []config.BudgetLimit{{Category: "x", Minutes: -1}}},
+	} {
+		if _, err := New(cfg, nil); err == nil {
+			t.Errorf("New(%+v) succeeded", cfg)
+		}
+	}
+}
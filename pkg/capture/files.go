@@ -0,0 +1,81 @@
+package capture
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/config"
+)
+
+// ErrInvalidName is returned for capture names outside the capture directory.
+var ErrInvalidName = errors.New("invalid capture name")
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]+\` + FileExt + `$`)
+
+// FileInfo describes a capture file on disk.
+type FileInfo struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// FromConfig builds a sink and filter for a collector; the sink is nil when
+// capturing is disabled.
+func FromConfig(cfg config.CaptureConfig, prefix, iface string) (*Sink, Filter, error) {
+	filter := NewFilter(cfg.Actions, cfg.Devices)
+	if !cfg.Enabled {
+		return nil, filter, nil
+	}
+	sink, err := NewSink(Options{
+		Dir:          cfg.Dir,
+		Prefix:       prefix,
+		Interface:    iface,
+		SnapLen:      cfg.SnapLen,
+		MaxFileBytes: int64(cfg.MaxFileMB) << 20,
+		MaxFileAge:   time.Duration(cfg.RotateMinutes) * time.Minute,
+		MaxFiles:     cfg.MaxFiles,
+	})
+	if err != nil {
+		return nil, filter, err
+	}
+	return sink, filter, nil
+}
+
+// List returns capture files in dir, newest first.
+func List(dir string) ([]FileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []FileInfo{}, nil
+		}
+		return nil, fmt.Errorf("read capture dir: %w", err)
+	}
+	out := make([]FileInfo, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !validName.MatchString(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, FileInfo{Name: e.Name(), Size: info.Size(), Modified: info.ModTime().UTC()})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Modified.After(out[j].Modified)
+	})
+	return out, nil
+}
+
+// Path resolves a capture name to a file path, rejecting traversal attempts.
+func Path(dir, name string) (string, error) {
+	if !validName.MatchString(name) || filepath.Base(name) != name {
+		return "", ErrInvalidName
+	}
+	return filepath.Join(dir, name), nil
+}
@@ -0,0 +1,43 @@
+package capture
+
+import "strings"
+
+// Filter selects frames by verdict or by the device addresses involved.
+type Filter struct {
+	actions map[string]struct{}
+	devices map[string]struct{}
+}
+
+// NewFilter builds a filter from verdicts (e.g. "block", "bypass") and
+// device IP or MAC addresses.
+func NewFilter(actions, devices []string) Filter {
+	f := Filter{
+		actions: make(map[string]struct{}, len(actions)),
+		devices: make(map[string]struct{}, len(devices)),
+	}
+	for _, a := range actions {
+		f.actions[strings.ToLower(a)] = struct{}{}
+	}
+	for _, d := range devices {
+		f.devices[strings.ToLower(d)] = struct{}{}
+	}
+	return f
+}
+
+// Match reports whether a frame with the given verdict and addresses is captured.
+func (f Filter) Match(action string, addrs ...string) bool {
+	if action != "" {
+		if _, ok := f.actions[action]; ok {
+			return true
+		}
+	}
+	if len(f.devices) == 0 {
+		return false
+	}
+	for _, addr := range addrs {
+		if _, ok := f.devices[strings.ToLower(addr)]; ok {
+			return true
+		}
+	}
+	return false
+}
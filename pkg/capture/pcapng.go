@@ -0,0 +1,104 @@
+package capture
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// pcapng block types and option codes used by the writer.
+const (
+	blockSectionHeader    = 0x0A0D0D0A
+	blockInterfaceDesc    = 0x00000001
+	blockEnhancedPacket   = 0x00000006
+	byteOrderMagic        = 0x1A2B3C4D
+	optEndOfOpt           = 0
+	optComment            = 1
+	optIfName             = 2
+	optShbUserAppl        = 4
+	linkTypeEthernet      = 1
+	defaultSnapLen        = 65535
+	blockHeaderAndTrailer = 12
+)
+
+// writeSectionHeader emits the pcapng section header block.
+func writeSectionHeader(w io.Writer, app string) (int64, error) {
+	opts := encodeOptions(option{optShbUserAppl, []byte(app)})
+	body := make([]byte, 16, 16+len(opts))
+	binary.LittleEndian.PutUint32(body[0:4], byteOrderMagic)
+	binary.LittleEndian.PutUint16(body[4:6], 1)
+	binary.LittleEndian.PutUint16(body[6:8], 0)
+	// Section length is unknown while streaming.
+	binary.LittleEndian.PutUint64(body[8:16], ^uint64(0))
+	body = append(body, opts...)
+	return writeBlock(w, blockSectionHeader, body)
+}
+
+// writeInterfaceDesc emits an Ethernet interface description block.
+func writeInterfaceDesc(w io.Writer, name string, snapLen uint32) (int64, error) {
+	var opts []byte
+	if name != "" {
+		opts = encodeOptions(option{optIfName, []byte(name)})
+	} else {
+		opts = encodeOptions()
+	}
+	body := make([]byte, 8, 8+len(opts))
+	binary.LittleEndian.PutUint16(body[0:2], linkTypeEthernet)
+	binary.LittleEndian.PutUint32(body[4:8], snapLen)
+	body = append(body, opts...)
+	return writeBlock(w, blockInterfaceDesc, body)
+}
+
+// writeEnhancedPacket emits one frame with an optional comment. Timestamps use
+// the default microsecond resolution.
+func writeEnhancedPacket(w io.Writer, ts time.Time, frame []byte, origLen int, comment string) (int64, error) {
+	var opts []byte
+	if comment != "" {
+		opts = encodeOptions(option{optComment, []byte(comment)})
+	}
+	padded := pad4(len(frame))
+	body := make([]byte, 20+padded, 20+padded+len(opts))
+	micros := uint64(ts.UnixMicro())
+	binary.LittleEndian.PutUint32(body[0:4], 0)
+	binary.LittleEndian.PutUint32(body[4:8], uint32(micros>>32))
+	binary.LittleEndian.PutUint32(body[8:12], uint32(micros))
+	binary.LittleEndian.PutUint32(body[12:16], uint32(len(frame)))
+	binary.LittleEndian.PutUint32(body[16:20], uint32(origLen))
+	copy(body[20:], frame)
+	body = append(body, opts...)
+	return writeBlock(w, blockEnhancedPacket, body)
+}
+
+func writeBlock(w io.Writer, blockType uint32, body []byte) (int64, error) {
+	total := uint32(blockHeaderAndTrailer + len(body))
+	buf := make([]byte, 0, total)
+	buf = binary.LittleEndian.AppendUint32(buf, blockType)
+	buf = binary.LittleEndian.AppendUint32(buf, total)
+	buf = append(buf, body...)
+	buf = binary.LittleEndian.AppendUint32(buf, total)
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+type option struct {
+	code  uint16
+	value []byte
+}
+
+// encodeOptions serializes options followed by opt_endofopt.
+func encodeOptions(opts ...option) []byte {
+	var out []byte
+	for _, opt := range opts {
+		out = binary.LittleEndian.AppendUint16(out, opt.code)
+		out = binary.LittleEndian.AppendUint16(out, uint16(len(opt.value)))
+		out = append(out, opt.value...)
+		out = append(out, make([]byte, pad4(len(opt.value))-len(opt.value))...)
+	}
+	out = binary.LittleEndian.AppendUint16(out, optEndOfOpt)
+	out = binary.LittleEndian.AppendUint16(out, 0)
+	return out
+}
+
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
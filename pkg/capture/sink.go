@@ -0,0 +1,163 @@
+package capture
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileExt is the extension used for capture files.
+const FileExt = ".pcapng"
+
+// Options controls where captures are written and when files rotate.
+type Options struct {
+	Dir          string
+	Prefix       string
+	Interface    string
+	SnapLen      int
+	MaxFileBytes int64
+	MaxFileAge   time.Duration
+	MaxFiles     int
+}
+
+// Sink writes frames into size- and time-rotated pcapng files.
+type Sink struct {
+	mu     sync.Mutex
+	opts   Options
+	file   *os.File
+	w      *bufio.Writer
+	size   int64
+	opened time.Time
+	now    func() time.Time
+}
+
+// NewSink prepares the capture directory; files are created lazily on the first write.
+func NewSink(opts Options) (*Sink, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("capture dir required")
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "capture"
+	}
+	if opts.SnapLen <= 0 || opts.SnapLen > defaultSnapLen {
+		opts.SnapLen = defaultSnapLen
+	}
+	if err := os.MkdirAll(opts.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create capture dir: %w", err)
+	}
+	return &Sink{opts: opts, now: time.Now}, nil
+}
+
+// Write appends a frame with a per-packet comment, rotating the file when needed.
+func (s *Sink) Write(ts time.Time, frame []byte, comment string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.rotateIfNeeded(); err != nil {
+		return err
+	}
+
+	origLen := len(frame)
+	if len(frame) > s.opts.SnapLen {
+		frame = frame[:s.opts.SnapLen]
+	}
+	if len(comment) > 0xFFFF {
+		comment = comment[:0xFFFF]
+	}
+	n, err := writeEnhancedPacket(s.w, ts, frame, origLen, comment)
+	s.size += n
+	if err != nil {
+		return fmt.Errorf("write capture: %w", err)
+	}
+	return nil
+}
+
+// Flush pushes buffered frames to disk.
+func (s *Sink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w == nil {
+		return nil
+	}
+	return s.w.Flush()
+}
+
+// Close flushes and closes the current file.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeFile()
+}
+
+func (s *Sink) rotateIfNeeded() error {
+	if s.file != nil {
+		tooBig := s.opts.MaxFileBytes > 0 && s.size >= s.opts.MaxFileBytes
+		tooOld := s.opts.MaxFileAge > 0 && s.now().Sub(s.opened) >= s.opts.MaxFileAge
+		if !tooBig && !tooOld {
+			return nil
+		}
+		if err := s.closeFile(); err != nil {
+			return err
+		}
+	}
+	return s.openFile()
+}
+
+func (s *Sink) openFile() error {
+	now := s.now().UTC()
+	name := fmt.Sprintf("%s-%s%s", s.opts.Prefix, now.Format("20060102T150405.000Z"), FileExt)
+	f, err := os.OpenFile(filepath.Join(s.opts.Dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("open capture: %w", err)
+	}
+	w := bufio.NewWriterSize(f, 64*1024)
+	size, err := writeSectionHeader(w, "kidosserver "+s.opts.Prefix)
+	if err == nil {
+		var n int64
+		n, err = writeInterfaceDesc(w, s.opts.Interface, uint32(s.opts.SnapLen))
+		size += n
+	}
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("write capture header: %w", err)
+	}
+	s.file, s.w, s.size, s.opened = f, w, size, now
+	s.prune()
+	return nil
+}
+
+func (s *Sink) closeFile() error {
+	if s.file == nil {
+		return nil
+	}
+	flushErr := s.w.Flush()
+	closeErr := s.file.Close()
+	s.file, s.w = nil, nil
+	if flushErr != nil {
+		return fmt.Errorf("flush capture: %w", flushErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("close capture: %w", closeErr)
+	}
+	return nil
+}
+
+// prune removes the oldest files of this sink's prefix beyond MaxFiles.
+func (s *Sink) prune() {
+	if s.opts.MaxFiles <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(filepath.Join(s.opts.Dir, s.opts.Prefix+"-*"+FileExt))
+	if err != nil || len(matches) <= s.opts.MaxFiles {
+		return
+	}
+	// Names embed a sortable UTC timestamp.
+	sort.Strings(matches)
+	for _, path := range matches[:len(matches)-s.opts.MaxFiles] {
+		_ = os.Remove(path)
+	}
+}
@@ -0,0 +1,258 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// block is one parsed pcapng block.
+type block struct {
+	typ  uint32
+	body []byte
+}
+
+// readBlocks splits a little-endian pcapng file into blocks, checking that
+// each block's trailing length matches its header.
+func readBlocks(t *testing.T, data []byte) []block {
+	t.Helper()
+	var out []block
+	for len(data) > 0 {
+		if len(data) < blockHeaderAndTrailer {
+			t.Fatalf("truncated block header: %d bytes left", len(data))
+		}
+		typ := binary.LittleEndian.Uint32(data[0:4])
+		total := int(binary.LittleEndian.Uint32(data[4:8]))
+		if total%4 != 0 || total > len(data) {
+			t.Fatalf("block %#x has bad length %d", typ, total)
+		}
+		if trailer := int(binary.LittleEndian.Uint32(data[total-4 : total])); trailer != total {
+			t.Fatalf("block %#x trailer %d != %d", typ, trailer, total)
+		}
+		out = append(out, block{typ: typ, body: data[8 : total-4]})
+		data = data[total:]
+	}
+	return out
+}
+
+// readOptions decodes an option list up to opt_endofopt.
+func readOptions(t *testing.T, data []byte) map[uint16]string {
+	t.Helper()
+	opts := make(map[uint16]string)
+	for len(data) >= 4 {
+		code := binary.LittleEndian.Uint16(data[0:2])
+		n := int(binary.LittleEndian.Uint16(data[2:4]))
+		if code == optEndOfOpt {
+			return opts
+		}
+		if 4+pad4(n) > len(data) {
+			t.Fatalf("option %d overruns the block", code)
+		}
+		opts[code] = string(data[4 : 4+n])
+		data = data[4+pad4(n):]
+	}
+	t.Fatal("options not terminated")
+	return nil
+}
+
+func newTestSink(t *testing.T, opts Options) (*Sink, *time.Time) {
+	t.Helper()
+	if opts.Dir == "" {
+		opts.Dir = t.TempDir()
+	}
+	s, err := NewSink(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	t.Cleanup(func() { s.Close() })
+	return s, &now
+}
+
+func TestSinkWritesPcapng(t *testing.T) {
+	s, now := newTestSink(t, Options{Prefix: "dns", Interface: "eth0", SnapLen: 8})
+	frame := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	ts := now.Add(1500 * time.Microsecond)
+	if err := s.Write(ts, frame, "block ads.example"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(ts, frame[:3], ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := List(s.opts.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "dns-20260302T120000.000Z.pcapng" {
+		t.Fatalf("files = %+v", files)
+	}
+	data, err := os.ReadFile(filepath.Join(s.opts.Dir, files[0].Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := readBlocks(t, data)
+	if len(blocks) != 4 {
+		t.Fatalf("got %d blocks, want 4", len(blocks))
+	}
+
+	shb := blocks[0]
+	if shb.typ != blockSectionHeader || binary.LittleEndian.Uint32(shb.body[0:4]) != byteOrderMagic {
+		t.Fatalf("section header = %#x", shb.typ)
+	}
+	if major, minor := binary.LittleEndian.Uint16(shb.body[4:6]), binary.LittleEndian.Uint16(shb.body[6:8]); major != 1 || minor != 0 {
+		t.Fatalf("version %d.%d", major, minor)
+	}
+	if app := readOptions(t, shb.body[16:])[optShbUserAppl]; app != "kidosserver dns" {
+		t.Fatalf("user application %q", app)
+	}
+
+	idb := blocks[1]
+	if idb.typ != blockInterfaceDesc || binary.LittleEndian.Uint16(idb.body[0:2]) != linkTypeEthernet {
+		t.Fatalf("interface block = %#x", idb.typ)
+	}
+	if snap := binary.LittleEndian.Uint32(idb.body[4:8]); snap != 8 {
+		t.Fatalf("snaplen %d", snap)
+	}
+	if name := readOptions(t, idb.body[8:])[optIfName]; name != "eth0" {
+		t.Fatalf("interface name %q", name)
+	}
+
+	for i, want := range []struct {
+		captured, orig int
+		comment        string
+	}{
+		{8, 10, "block ads.example"},
+		{3, 3, ""},
+	} {
+		epb := blocks[2+i]
+		if epb.typ != blockEnhancedPacket {
+			t.Fatalf("block %d type %#x", 2+i, epb.typ)
+		}
+		b := epb.body
+		micros := uint64(binary.LittleEndian.Uint32(b[4:8]))<<32 | uint64(binary.LittleEndian.Uint32(b[8:12]))
+		if got := time.UnixMicro(int64(micros)); !got.Equal(ts) {
+			t.Fatalf("packet %d timestamp %v, want %v", i, got, ts)
+		}
+		captured, orig := int(binary.LittleEndian.Uint32(b[12:16])), int(binary.LittleEndian.Uint32(b[16:20]))
+		if captured != want.captured || orig != want.orig {
+			t.Fatalf("packet %d lengths %d/%d, want %d/%d", i, captured, orig, want.captured, want.orig)
+		}
+		if !bytes.Equal(b[20:20+captured], frame[:captured]) {
+			t.Fatalf("packet %d data %v", i, b[20:20+captured])
+		}
+		opts := b[20+pad4(captured):]
+		if want.comment == "" {
+			if len(opts) != 0 {
+				t.Fatalf("packet %d has options without a comment", i)
+			}
+			continue
+		}
+		if got := readOptions(t, opts)[optComment]; got != want.comment {
+			t.Fatalf("packet %d comment %q", i, got)
+		}
+	}
+}
+
+func TestSinkRotation(t *testing.T) {
+	frame := make([]byte, 100)
+	t.Run("size", func(t *testing.T) {
+		s, now := newTestSink(t, Options{Prefix: "mon", MaxFileBytes: 300})
+		for i := 0; i < 4; i++ {
+			// Distinct names need distinct opening times.
+			*now = now.Add(time.Millisecond)
+			if err := s.Write(*now, frame, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s.Close()
+		// Headers plus two 132-byte packets pass 300 bytes.
+		if files, _ := List(s.opts.Dir); len(files) != 2 {
+			t.Fatalf("got %d files, want 2", len(files))
+		}
+	})
+	t.Run("age", func(t *testing.T) {
+		s, now := newTestSink(t, Options{Prefix: "mon", MaxFileAge: time.Minute})
+		for _, step := range []time.Duration{0, 30 * time.Second, 30 * time.Second, time.Second} {
+			*now = now.Add(step)
+			if err := s.Write(*now, frame, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s.Close()
+		matches, _ := filepath.Glob(filepath.Join(s.opts.Dir, "*"+FileExt))
+		want := []string{
+			filepath.Join(s.opts.Dir, "mon-20260302T120000.000Z.pcapng"),
+			filepath.Join(s.opts.Dir, "mon-20260302T120100.000Z.pcapng"),
+		}
+		if !reflect.DeepEqual(matches, want) {
+			t.Fatalf("files %v, want %v", matches, want)
+		}
+	})
+	t.Run("max files", func(t *testing.T) {
+		dir := t.TempDir()
+		// Another collector's files in the same directory are left alone.
+		other := filepath.Join(dir, "dns-20260101T000000.000Z.pcapng")
+		if err := os.WriteFile(other, nil, 0o640); err != nil {
+			t.Fatal(err)
+		}
+		s, now := newTestSink(t, Options{Dir: dir, Prefix: "mon", MaxFileAge: time.Minute, MaxFiles: 2})
+		for i := 0; i < 5; i++ {
+			if err := s.Write(*now, frame, ""); err != nil {
+				t.Fatal(err)
+			}
+			*now = now.Add(time.Minute)
+		}
+		s.Close()
+		matches, _ := filepath.Glob(filepath.Join(dir, "*"+FileExt))
+		want := []string{
+			other,
+			filepath.Join(dir, "mon-20260302T120300.000Z.pcapng"),
+			filepath.Join(dir, "mon-20260302T120400.000Z.pcapng"),
+		}
+		if !reflect.DeepEqual(matches, want) {
+			t.Fatalf("files %v, want %v", matches, want)
+		}
+	})
+}
+
+func TestPath(t *testing.T) {
+	if p, err := Path("/data/captures", "dns-20260302T120000.000Z.pcapng"); err != nil || p != "/data/captures/dns-20260302T120000.000Z.pcapng" {
+		t.Fatalf("Path = %q, %v", p, err)
+	}
+	for _, name := range []string{"../etc/passwd.pcapng", "a/b.pcapng", "capture.txt", ".pcapng", ""} {
+		if _, err := Path("/data/captures", name); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Path(%q) err = %v", name, err)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f := NewFilter([]string{"Block"}, []string{"AA:BB:CC:DD:EE:FF", "10.0.0.2"})
+	for _, tc := range []struct {
+		action string
+		addrs  []string
+		want   bool
+	}{
+		{"block", nil, true},
+		{"allow", []string{"10.0.0.3"}, false},
+		{"allow", []string{"10.0.0.3", "10.0.0.2"}, true},
+		{"", []string{"aa:bb:cc:dd:ee:ff"}, true},
+	} {
+		if got := f.Match(tc.action, tc.addrs...); got != tc.want {
+			t.Errorf("Match(%q, %v) = %v", tc.action, tc.addrs, got)
+		}
+	}
+	if NewFilter(nil, nil).Match("block", "10.0.0.2") {
+		t.Error("empty filter matched")
+	}
+}
@@ -0,0 +1,328 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/fsutil"
+)
+
+// File names inside the certificate directory.
+const (
+	CAFile     = "ca.pem"
+	CAKeyFile  = "ca-key.pem"
+	CertFile   = "server.pem"
+	KeyFile    = "server-key.pem"
+	caLifetime = 10 * 365 * 24 * time.Hour
+	// Browsers and iOS refuse leaf certificates valid for more than 825 days.
+	certLifetime = 825 * 24 * time.Hour
+	renewBefore  = 30 * 24 * time.Hour
+)
+
+// Authority is the local CA that signs the server certificate. Parents
+// install it on their devices once to trust the web UI.
+type Authority struct {
+	Cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// Fingerprint returns the SHA-256 fingerprint of the CA certificate.
+func (a *Authority) Fingerprint() string {
+	sum := sha256.Sum256(a.Cert.Raw)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// PEM returns the CA certificate PEM-encoded.
+func (a *Authority) PEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: a.Cert.Raw})
+}
+
+// LoadOrCreateCA reads the CA from dir, generating and persisting a new one
+// when neither its certificate nor its key exists. A new CA is name
+// constrained to hosts, so a leaked key cannot sign for other sites.
+func LoadOrCreateCA(dir string, hosts []string) (*Authority, error) {
+	certPath := filepath.Join(dir, CAFile)
+	keyPath := filepath.Join(dir, CAKeyFile)
+	cert, key, err := loadPair(certPath, keyPath)
+	if err == nil {
+		return &Authority{Cert: cert, key: key}, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("load ca: %w", err)
+	}
+	// A new CA would silently invalidate the one installed on devices, so
+	// only create it when both halves are gone.
+	for _, p := range []string{certPath, keyPath} {
+		if _, err := os.Stat(p); err == nil {
+			return nil, fmt.Errorf("load ca: %s exists but its pair is missing; restore it or remove %s to create a new CA", p, p)
+		}
+	}
+
+	key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate ca key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "Kidos local CA " + host, Organization: []string{"Kidos"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caLifetime),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	constrain(tmpl, normalizeHosts(hosts))
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("create ca: %w", err)
+	}
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	if err := writePair(certPath, keyPath, der, key); err != nil {
+		return nil, err
+	}
+	return &Authority{Cert: cert, key: key}, nil
+}
+
+// Permits reports whether the CA's name constraints allow it to sign for
+// host. CAs created before constraints were added permit every host.
+func (a *Authority) Permits(host string) bool {
+	c := a.Cert
+	if ip := net.ParseIP(host); ip != nil {
+		for _, n := range c.ExcludedIPRanges {
+			if n.Contains(ip) {
+				return false
+			}
+		}
+		if len(c.PermittedIPRanges) == 0 {
+			return true
+		}
+		for _, n := range c.PermittedIPRanges {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(c.PermittedDNSDomains) == 0 {
+		return true
+	}
+	for _, d := range c.PermittedDNSDomains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureServerCert returns the paths of a server certificate signed by ca
+// for hosts, reissuing it when missing, close to expiry, signed by another
+// CA or lacking one of the hosts. Hosts outside the CA's name constraints
+// are left out and returned as skipped.
+func EnsureServerCert(dir string, ca *Authority, hosts []string) (certPath, keyPath string, skipped []string, err error) {
+	certPath = filepath.Join(dir, CertFile)
+	keyPath = filepath.Join(dir, KeyFile)
+	var permitted []string
+	for _, h := range normalizeHosts(hosts) {
+		if ca.Permits(h) {
+			permitted = append(permitted, h)
+		} else {
+			skipped = append(skipped, h)
+		}
+	}
+	if len(permitted) == 0 {
+		return "", "", skipped, fmt.Errorf("server cert: the ca permits none of %s", strings.Join(skipped, ", "))
+	}
+	hosts = permitted
+
+	cert, _, err := loadPair(certPath, keyPath)
+	if err == nil && !needsRenewal(cert, ca, hosts) {
+		return certPath, keyPath, skipped, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", "", skipped, fmt.Errorf("load server cert: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", skipped, fmt.Errorf("generate server key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return "", "", skipped, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: hosts[0], Organization: []string{"Kidos"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return "", "", skipped, fmt.Errorf("create server cert: %w", err)
+	}
+	if err := writePair(certPath, keyPath, der, key); err != nil {
+		return "", "", skipped, err
+	}
+	return certPath, keyPath, skipped, nil
+}
+
+// DefaultHosts lists the names and addresses a LAN client may use to reach
+// this machine: its hostname, localhost and every interface address.
+func DefaultHosts() []string {
+	hosts := []string{"localhost"}
+	if h, err := os.Hostname(); err == nil && h != "" {
+		hosts = append(hosts, h, h+".local")
+	}
+	addrs, _ := net.InterfaceAddrs()
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLinkLocalUnicast() {
+			hosts = append(hosts, ipnet.IP.String())
+		}
+	}
+	return hosts
+}
+
+// constrain limits tmpl to signing for hosts: names and their subdomains,
+// and the exact addresses. Without an address host every address is
+// excluded, since an empty permitted list would allow them all.
+func constrain(tmpl *x509.Certificate, hosts []string) {
+	tmpl.PermittedDNSDomainsCritical = true
+	for _, h := range hosts {
+		ip := net.ParseIP(h)
+		switch {
+		case ip == nil:
+			tmpl.PermittedDNSDomains = append(tmpl.PermittedDNSDomains, h)
+		case ip.To4() != nil:
+			tmpl.PermittedIPRanges = append(tmpl.PermittedIPRanges, &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)})
+		default:
+			tmpl.PermittedIPRanges = append(tmpl.PermittedIPRanges, &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)})
+		}
+	}
+	if len(tmpl.PermittedIPRanges) == 0 {
+		tmpl.ExcludedIPRanges = []*net.IPNet{
+			{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)},
+			{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)},
+		}
+	}
+}
+
+func needsRenewal(cert *x509.Certificate, ca *Authority, hosts []string) bool {
+	if time.Until(cert.NotAfter) < renewBefore {
+		return true
+	}
+	if !bytes.Equal(cert.RawIssuer, ca.Cert.RawSubject) || cert.CheckSignatureFrom(ca.Cert) != nil {
+		return true
+	}
+	for _, h := range hosts {
+		if cert.VerifyHostname(h) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeHosts(hosts []string) []string {
+	out := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h != "" {
+			out = append(out, h)
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, "localhost")
+	}
+	first := out[0]
+	sort.Strings(out[1:])
+	return append([]string{first}, slices.Compact(out[1:])...)
+}
+
+func loadPair(certPath, keyPath string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, nil, fmt.Errorf("%s: no certificate", certPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", certPath, err)
+	}
+	block, _ = pem.Decode(keyPEM)
+	if block == nil {
+		return nil, nil, fmt.Errorf("%s: no private key", keyPath)
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", keyPath, err)
+	}
+	return cert, key, nil
+}
+
+func writePair(certPath, keyPath string, der []byte, key *ecdsa.PrivateKey) error {
+	if err := os.MkdirAll(filepath.Dir(certPath), 0o700); err != nil {
+		return fmt.Errorf("create cert dir: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return fmt.Errorf("write key: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		return fmt.Errorf("write cert: %w", err)
+	}
+	return nil
+}
+
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial: %w", err)
+	}
+	return serial, nil
+}
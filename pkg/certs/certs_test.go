@@ -0,0 +1,209 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCAIsNameConstrained(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := LoadOrCreateCA(dir, []string{"Kidos.lan", "10.0.0.1", "fd00::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ca.Cert.PermittedDNSDomainsCritical || !reflect.DeepEqual(ca.Cert.PermittedDNSDomains, []string{"kidos.lan"}) {
+		t.Fatalf("permitted names %v", ca.Cert.PermittedDNSDomains)
+	}
+	for host, want := range map[string]bool{
+		"kidos.lan":      true,
+		"www.kidos.lan":  true,
+		"evilkidos.lan":  false,
+		"bank.example":   false,
+		"10.0.0.1":       true,
+		"10.0.0.2":       false,
+		"fd00::1":        true,
+		"2001:db8::1":    false,
+		"localhost":      false,
+		"kidos.lan.evil": false,
+	} {
+		if got := ca.Permits(host); got != want {
+			t.Errorf("Permits(%q) = %v, want %v", host, got, want)
+		}
+	}
+
+	// The constraints hold when a client verifies a leaf, not just in Permits.
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	certPath, keyPath, skipped, err := EnsureServerCert(dir, ca, []string{"kidos.lan", "bank.example", "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(skipped, []string{"bank.example"}) {
+		t.Fatalf("skipped %v", skipped)
+	}
+	leaf := loadLeaf(t, certPath, keyPath)
+	if leaf.VerifyHostname("bank.example") == nil {
+		t.Fatal("server cert covers a host outside the CA")
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "kidos.lan", Roots: roots}); err != nil {
+		t.Fatalf("verify permitted leaf: %v", err)
+	}
+
+	if _, _, _, err := EnsureServerCert(t.TempDir(), ca, []string{"bank.example"}); err == nil {
+		t.Fatal("issued a certificate for a host outside the CA")
+	}
+
+	// A leaf for another site signed directly with the CA key is rejected
+	// by clients.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"bank.example"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := forged.Verify(x509.VerifyOptions{DNSName: "bank.example", Roots: roots}); err == nil {
+		t.Fatal("forged leaf verified")
+	}
+}
+
+func TestCAWithoutAddressesExcludesAll(t *testing.T) {
+	ca, err := LoadOrCreateCA(t.TempDir(), []string{"kidos.lan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ca.Permits("10.0.0.1") || ca.Permits("::1") {
+		t.Fatal("CA without address hosts permits addresses")
+	}
+}
+
+func TestLoadOrCreateCAReloads(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := LoadOrCreateCA(dir, []string{"kidos.lan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := LoadOrCreateCA(dir, []string{"other.lan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Fingerprint() != ca.Fingerprint() {
+		t.Fatal("existing CA was replaced")
+	}
+	info, err := os.Stat(filepath.Join(dir, CAKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("ca key mode %o", perm)
+	}
+}
+
+func TestLoadOrCreateCAHalfMissing(t *testing.T) {
+	for _, missing := range []string{CAKeyFile, CAFile} {
+		t.Run(missing, func(t *testing.T) {
+			dir := t.TempDir()
+			ca, err := LoadOrCreateCA(dir, []string{"kidos.lan"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Remove(filepath.Join(dir, missing)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadOrCreateCA(dir, []string{"kidos.lan"}); err == nil || !strings.Contains(err.Error(), "pair is missing") {
+				t.Fatalf("err = %v", err)
+			}
+			// Nothing was regenerated behind the caller's back.
+			if _, err := os.Stat(filepath.Join(dir, missing)); !os.IsNotExist(err) {
+				t.Fatalf("%s recreated: %v", missing, err)
+			}
+			if missing == CAKeyFile {
+				pem, err := os.ReadFile(filepath.Join(dir, CAFile))
+				if err != nil || string(pem) != string(ca.PEM()) {
+					t.Fatalf("ca certificate changed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestEnsureServerCertRenewal(t *testing.T) {
+	dir := t.TempDir()
+	hosts := []string{"kidos.lan", "10.0.0.1"}
+	ca, err := LoadOrCreateCA(dir, append(hosts, "10.0.0.2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath, keyPath, _, err := EnsureServerCert(dir, ca, hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := loadLeaf(t, certPath, keyPath)
+	if first.Subject.CommonName != "kidos.lan" {
+		t.Fatalf("common name %q", first.Subject.CommonName)
+	}
+
+	// Same hosts in another order: the certificate is kept.
+	if _, _, _, err := EnsureServerCert(dir, ca, []string{"kidos.lan", "10.0.0.1", "KIDOS.lan"}); err != nil {
+		t.Fatal(err)
+	}
+	if kept := loadLeaf(t, certPath, keyPath); !kept.Equal(first) {
+		t.Fatal("certificate reissued without a change")
+	}
+
+	// A new host forces a reissue that covers it.
+	if _, _, _, err := EnsureServerCert(dir, ca, append(hosts, "10.0.0.2")); err != nil {
+		t.Fatal(err)
+	}
+	renewed := loadLeaf(t, certPath, keyPath)
+	if renewed.Equal(first) || renewed.VerifyHostname("10.0.0.2") != nil {
+		t.Fatal("certificate not reissued for the new host")
+	}
+
+	// A certificate from another CA is replaced.
+	otherCA, err := LoadOrCreateCA(t.TempDir(), hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := EnsureServerCert(dir, otherCA, hosts); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadLeaf(t, certPath, keyPath).CheckSignatureFrom(otherCA.Cert); err != nil {
+		t.Fatalf("certificate not reissued by the new CA: %v", err)
+	}
+}
+
+func loadLeaf(t *testing.T, certPath, keyPath string) *x509.Certificate {
+	t.Helper()
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+
+	"github.com/kidos/kidosserver/pkg/fsutil"
 )
 
 // Config represents runtime configuration persisted on disk.
@@ -12,6 +14,17 @@ type Config struct {
 	Interfaces InterfaceConfig `json:"interfaces"`
 	DNS        DNSConfig       `json:"dns"`
 	Web        WebConfig       `json:"web"`
+	Capture    CaptureConfig   `json:"capture"`
+	History    HistoryConfig   `json:"history"`
+	Events     EventsConfig    `json:"events"`
+	Audit      AuditConfig     `json:"audit"`
+	Budgets    BudgetConfig    `json:"budgets"`
+	Pause      PauseConfig     `json:"pause"`
+	BlockPage  BlockPageConfig `json:"blockPage"`
+	Monitor    MonitorConfig   `json:"monitor"`
+	// Profiles groups devices (MAC or IPv4 addresses) under a name, e.g. one
+	// per child.
+	Profiles map[string][]string `json:"profiles"`
 }
 
 // InterfaceConfig describes NIC and veth names.
@@ -22,20 +35,222 @@ type InterfaceConfig struct {
 
 // DNSConfig holds DNS policy settings.
 type DNSConfig struct {
-	Blocklist []string `json:"blocklist"`
+	Blocklist      []string         `json:"blocklist"`
+	RulesFile      string           `json:"rulesFile"`
+	ResolverListen string           `json:"resolverListen"`
+	Upstreams      []UpstreamConfig `json:"upstreams"`
+	CacheSize      int              `json:"cacheSize"`
+	CacheMaxTTL    int              `json:"cacheMaxTtl"`
+	TimeoutMS      int              `json:"timeoutMs"`
+	HealthCheckSec int              `json:"healthCheckSec"`
+}
+
+// UpstreamConfig describes a recursive resolver used by the forwarder.
+// Address accepts "1.1.1.1", "udp://host:port", "tls://host" or an https URL;
+// Bootstrap lists fixed IPs used to reach encrypted upstreams by hostname.
+type UpstreamConfig struct {
+	Address    string   `json:"address"`
+	Bootstrap  []string `json:"bootstrap,omitempty"`
+	ServerName string   `json:"serverName,omitempty"`
+	CAFile     string   `json:"caFile,omitempty"`
 }
 
-// WebConfig holds HTTP API config.
+// WebConfig holds HTTP API config. OUIFile optionally names an IEEE oui.txt
+// used instead of the bundled subset to look up device vendors.
 type WebConfig struct {
-	Listen string `json:"listen"`
+	Listen      string    `json:"listen"`
+	UsersFile   string    `json:"usersFile"`
+	UnblockFile string    `json:"unblockFile"`
+	DevicesFile string    `json:"devicesFile"`
+	OUIFile     string    `json:"ouiFile"`
+	TLS         TLSConfig `json:"tls"`
+}
+
+// TLSConfig enables HTTPS on Listen; the plain listener then redirects
+// browsers there. Without CertFile/KeyFile a local CA and server certificate
+// for Hosts (plus this machine's names and addresses) are kept under Dir.
+type TLSConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Listen   string   `json:"listen"`
+	CertFile string   `json:"certFile"`
+	KeyFile  string   `json:"keyFile"`
+	Dir      string   `json:"dir"`
+	Hosts    []string `json:"hosts"`
+}
+
+// CaptureConfig controls the optional pcapng capture sink of the collectors.
+type CaptureConfig struct {
+	Enabled       bool     `json:"enabled"`
+	Dir           string   `json:"dir"`
+	Actions       []string `json:"actions"`
+	Devices       []string `json:"devices"`
+	SnapLen       int      `json:"snapLen"`
+	MaxFileMB     int      `json:"maxFileMb"`
+	RotateMinutes int      `json:"rotateMinutes"`
+	MaxFiles      int      `json:"maxFiles"`
+}
+
+// HistoryConfig controls the on-disk event store used by the web server.
+type HistoryConfig struct {
+	Dir            string `json:"dir"`
+	MaxAgeHours    int    `json:"maxAgeHours"`
+	MaxMB          int    `json:"maxMb"`
+	SegmentMB      int    `json:"segmentMb"`
+	SegmentMinutes int    `json:"segmentMinutes"`
+	SnapshotEvents int    `json:"snapshotEvents"`
+}
+
+// AuditConfig controls the audit trail of configuration changes, kept in
+// monthly files under Dir independently of the event history.
+type AuditConfig struct {
+	Dir             string `json:"dir"`
+	RetentionMonths int    `json:"retentionMonths"`
+}
+
+// BudgetConfig defines daily screen-time budgets. Categories map a name to
+// the domain suffixes that belong to it; a device is active in a category
+// during every minute it looks up or exchanges traffic with such a domain.
+// Usage resets every day at ResetAt ("HH:MM", local time).
+type BudgetConfig struct {
+	Categories map[string][]string `json:"categories"`
+	Limits     []BudgetLimit       `json:"limits"`
+	ResetAt    string              `json:"resetAt"`
+}
+
+// BudgetLimit allows Device (every device when empty) Minutes of activity
+// in Category per day.
+type BudgetLimit struct {
+	Device   string `json:"device,omitempty"`
+	Category string `json:"category"`
+	Minutes  int    `json:"minutes"`
+}
+
+// PauseConfig locates the pause state shared by the web server and the
+// inspector. Paused devices may still reach ServerAddrs in addition to the
+// inspector's own addresses.
+type PauseConfig struct {
+	File        string   `json:"file"`
+	ServerAddrs []string `json:"serverAddrs"`
+}
+
+// BlockPageConfig makes the resolver answer blocked names with the Sinkhole
+// (and SinkholeV6) address instead of NXDOMAIN. The web server then serves
+// the block page for any host on HTTPListen and refuses TLS on HTTPSListen;
+// both default to ports 80 and 443 on Sinkhole, which should be an address
+// of its own.
+type BlockPageConfig struct {
+	Sinkhole    string `json:"sinkhole"`
+	SinkholeV6  string `json:"sinkholeV6"`
+	HTTPListen  string `json:"httpListen"`
+	HTTPSListen string `json:"httpsListen"`
+}
+
+// MonitorConfig bounds the monitor's flow table. Flows end after IdleSec
+// without packets (TCPIdleSec for open TCP connections), and long-lived flows
+// are reported every ActiveSec. The least recently used flow is evicted
+// once MaxFlows are tracked. Traffic summaries go out every
+// PublishIntervalMS.
+type MonitorConfig struct {
+	MaxFlows          int `json:"maxFlows"`
+	IdleSec           int `json:"idleSec"`
+	TCPIdleSec        int `json:"tcpIdleSec"`
+	ActiveSec         int `json:"activeSec"`
+	PublishIntervalMS int `json:"publishIntervalMs"`
+}
+
+// EventsConfig tunes event buffering. Block timeouts of zero drop events
+// immediately when a buffer is full; positive values wait that long first.
+// Collectors deliver in batches and spool undeliverable batches under
+// SpoolDir/<collector> until the web server is reachable again. Tokens maps
+// collector names to the bearer tokens they present when posting over HTTP.
+type EventsConfig struct {
+	BusBuffer        int               `json:"busBuffer"`
+	BusBlockMS       int               `json:"busBlockMs"`
+	PublisherQueue   int               `json:"publisherQueue"`
+	PublisherBlockMS int               `json:"publisherBlockMs"`
+	BatchSize        int               `json:"batchSize"`
+	FlushMS          int               `json:"flushMs"`
+	SpoolDir         string            `json:"spoolDir"`
+	SpoolMB          int               `json:"spoolMb"`
+	Socket           string            `json:"socket"`
+	SocketUIDs       []int             `json:"socketUids"`
+	Tokens           map[string]string `json:"tokens"`
 }
 
 // Default returns a sane default configuration for fresh setups.
 func Default() Config {
 	return Config{
 		Interfaces: InterfaceConfig{Physical: "eth0", Veth: "kidos"},
-		DNS:        DNSConfig{Blocklist: []string{}},
-		Web:        WebConfig{Listen: ":8080"},
+		DNS: DNSConfig{
+			Blocklist:      []string{},
+			RulesFile:      "data/rules.json",
+			ResolverListen: ":53",
+			Upstreams: []UpstreamConfig{
+				{Address: "tls://one.one.one.one", Bootstrap: []string{"1.1.1.1", "1.0.0.1"}},
+				{Address: "https://dns.quad9.net/dns-query", Bootstrap: []string{"9.9.9.9", "149.112.112.112"}},
+			},
+			CacheSize:      10000,
+			CacheMaxTTL:    3600,
+			TimeoutMS:      2000,
+			HealthCheckSec: 30,
+		},
+		Web: WebConfig{
+			Listen:      ":8080",
+			UsersFile:   "data/users.json",
+			UnblockFile: "data/unblock-requests.json",
+			DevicesFile: "data/devices.json",
+			TLS: TLSConfig{
+				Enabled: true,
+				Listen:  ":8443",
+				Dir:     "data/tls",
+			},
+		},
+		Capture: CaptureConfig{
+			Dir:           "data/captures",
+			Actions:       []string{"block", "bypass"},
+			Devices:       []string{},
+			MaxFileMB:     16,
+			RotateMinutes: 60,
+			MaxFiles:      20,
+		},
+		History: HistoryConfig{
+			Dir:            "data/events",
+			MaxAgeHours:    30 * 24,
+			MaxMB:          512,
+			SegmentMB:      16,
+			SegmentMinutes: 60,
+			SnapshotEvents: 512,
+		},
+		Events: EventsConfig{
+			BusBuffer:      64,
+			PublisherQueue: 256,
+			BatchSize:      100,
+			FlushMS:        250,
+			SpoolDir:       "data/spool",
+			SpoolMB:        64,
+			Socket:         "data/run/events.sock",
+		},
+		Audit: AuditConfig{
+			Dir:             "data/audit",
+			RetentionMonths: 24,
+		},
+		Budgets: BudgetConfig{
+			Categories: map[string][]string{},
+			Limits:     []BudgetLimit{},
+			ResetAt:    "04:00",
+		},
+		Pause: PauseConfig{
+			File:        "data/pause.json",
+			ServerAddrs: []string{},
+		},
+		Monitor: MonitorConfig{
+			MaxFlows:          65536,
+			IdleSec:           60,
+			TCPIdleSec:        300,
+			ActiveSec:         1800,
+			PublishIntervalMS: 2000,
+		},
+		Profiles: map[string][]string{},
 	}
 }
 
@@ -55,13 +270,14 @@ func Load(path string) (Config, error) {
 	return cfg, nil
 }
 
-// Save writes config to disk.
+// Save atomically writes config to disk, readable only by its owner since
+// it holds the collector tokens.
 func Save(path string, cfg Config) error {
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return fmt.Errorf("serialize config: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	if err := fsutil.WriteFileAtomic(path, data, 0o600); err != nil {
 		return fmt.Errorf("write config: %w", err)
 	}
 	return nil
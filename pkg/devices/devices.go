@@ -0,0 +1,379 @@
+package devices
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/fsutil"
+)
+
+const (
+	// maxAddresses bounds the addresses remembered per device.
+	maxAddresses = 8
+	// maxDevices caps the inventory so spoofed frames cannot grow it
+	// without bound.
+	maxDevices = 4096
+	// MaxNameLen bounds parent-chosen device and profile names.
+	MaxNameLen = 64
+	// maxDHCPParams bounds the stored DHCP parameter request list.
+	maxDHCPParams = 256
+)
+
+// Errors returned by the inventory.
+var (
+	ErrNotFound   = errors.New("device not found")
+	ErrInvalidMAC = errors.New("invalid mac address")
+	ErrNameSize   = errors.New("name is too long")
+)
+
+// Address is an IP a device used, and when it was last seen with it.
+type Address struct {
+	IP       string    `json:"ip"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// Device is one physical device, keyed by its MAC address. Name and Profile
+// are set by parents; the rest is learned from the network. Vendor, OS and
+// Type are derived: the vendor from the MAC prefix, OS and type
+// heuristically from the DHCP fingerprint and DNSHints, the connectivity
+// checks the device was seen running.
+type Device struct {
+	MAC           string    `json:"mac"`
+	Name          string    `json:"name,omitempty"`
+	Profile       string    `json:"profile,omitempty"`
+	Hostname      string    `json:"hostname,omitempty"`
+	VendorClass   string    `json:"vendorClass,omitempty"`
+	DHCPParams    string    `json:"dhcpParams,omitempty"`
+	DNSHints      []string  `json:"dnsHints,omitempty"`
+	Vendor        string    `json:"vendor,omitempty"`
+	RandomizedMAC bool      `json:"randomizedMac,omitempty"`
+	OS            string    `json:"os,omitempty"`
+	Type          string    `json:"type,omitempty"`
+	Addresses     []Address `json:"addresses,omitempty"`
+	FirstSeen     time.Time `json:"firstSeen"`
+	LastSeen      time.Time `json:"lastSeen"`
+}
+
+// IPs lists the device's addresses, most recently seen first.
+func (d Device) IPs() []string {
+	out := make([]string, len(d.Addresses))
+	for i, a := range d.Addresses {
+		out[i] = a.IP
+	}
+	return out
+}
+
+func (d *Device) clone() Device {
+	c := *d
+	c.Addresses = append([]Address(nil), d.Addresses...)
+	c.DNSHints = append([]string(nil), d.DNSHints...)
+	return c
+}
+
+// Inventory keeps the known devices in a JSON file. Observations only mark
+// it dirty; Flush writes them out, while parent edits are saved at once.
+type Inventory struct {
+	mu    sync.Mutex
+	path  string
+	oui   OUI
+	devs  map[string]*Device
+	dirty bool
+}
+
+// Open loads the inventory stored at path; a missing file is empty. Vendors
+// are looked up in oui, or in the embedded registry when nil.
+func Open(path string, oui OUI) (*Inventory, error) {
+	if oui == nil {
+		oui = EmbeddedOUI()
+	}
+	inv := &Inventory{path: path, oui: oui, devs: make(map[string]*Device)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return inv, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read devices: %w", err)
+	}
+	var list []*Device
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parse devices: %w", err)
+	}
+	for _, d := range list {
+		// Hints saved by older versions may no longer be signatures.
+		d.DNSHints = slices.DeleteFunc(d.DNSHints, func(h string) bool {
+			_, ok := DNSHint(h)
+			return !ok
+		})
+		inv.enrich(d)
+		inv.devs[d.MAC] = d
+	}
+	return inv, nil
+}
+
+// Canonical normalizes a MAC address to lower-case colon form.
+func Canonical(mac string) (string, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) != 6 {
+		return "", ErrInvalidMAC
+	}
+	return hw.String(), nil
+}
+
+// Observe folds a device_seen report at time at into the inventory. An
+// address moves to the device that announced it last.
+func (inv *Inventory) Observe(info events.DeviceInfo, at time.Time) {
+	mac, err := Canonical(info.MAC)
+	if err != nil {
+		return
+	}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	d, ok := inv.devs[mac]
+	if !ok {
+		if len(inv.devs) >= maxDevices {
+			return
+		}
+		d = &Device{MAC: mac, FirstSeen: at}
+		inv.devs[mac] = d
+	}
+	if at.Before(d.FirstSeen) {
+		d.FirstSeen = at
+	}
+	if at.After(d.LastSeen) {
+		d.LastSeen = at
+		if info.Hostname != "" {
+			d.Hostname = info.Hostname
+		}
+		if info.VendorClass != "" {
+			d.VendorClass = info.VendorClass
+		}
+		if info.DHCPParams != "" && len(info.DHCPParams) <= maxDHCPParams {
+			d.DHCPParams = info.DHCPParams
+		}
+	}
+	if info.IP != "" {
+		inv.bind(d, info.IP, at)
+	}
+	inv.enrich(d)
+	inv.dirty = true
+}
+
+// ObserveQuery notes a DNS lookup by ip that hints at the device type.
+func (inv *Inventory) ObserveQuery(ip, domain string) {
+	hint, ok := DNSHint(domain)
+	if !ok {
+		return
+	}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	d := inv.byIP(ip)
+	if d == nil || slices.Contains(d.DNSHints, hint) {
+		return
+	}
+	d.DNSHints = append(d.DNSHints, hint)
+	sort.Strings(d.DNSHints)
+	inv.enrich(d)
+	inv.dirty = true
+}
+
+// enrich recomputes the derived fields of d.
+func (inv *Inventory) enrich(d *Device) {
+	d.Vendor = inv.oui.Vendor(d.MAC)
+	d.RandomizedMAC = Randomized(d.MAC)
+	fingerprint(d)
+}
+
+// bind records ip for d and drops it from any device that had it before.
+func (inv *Inventory) bind(d *Device, ip string, at time.Time) {
+	for _, other := range inv.devs {
+		if other == d {
+			continue
+		}
+		for i, a := range other.Addresses {
+			if a.IP == ip && !a.LastSeen.After(at) {
+				other.Addresses = append(other.Addresses[:i], other.Addresses[i+1:]...)
+				break
+			}
+		}
+	}
+	found := false
+	for i := range d.Addresses {
+		if d.Addresses[i].IP == ip {
+			if at.After(d.Addresses[i].LastSeen) {
+				d.Addresses[i].LastSeen = at
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		d.Addresses = append(d.Addresses, Address{IP: ip, LastSeen: at})
+	}
+	sort.SliceStable(d.Addresses, func(i, j int) bool { return d.Addresses[i].LastSeen.After(d.Addresses[j].LastSeen) })
+	if len(d.Addresses) > maxAddresses {
+		d.Addresses = d.Addresses[:maxAddresses]
+	}
+}
+
+// Update sets the name and profile of a device; nil leaves a field as is and
+// an empty string clears it.
+func (inv *Inventory) Update(mac string, name, profile *string) (before, after Device, err error) {
+	mac, err = Canonical(mac)
+	if err != nil {
+		return Device{}, Device{}, err
+	}
+	for _, s := range []*string{name, profile} {
+		if s != nil {
+			*s = strings.TrimSpace(*s)
+			if len(*s) > MaxNameLen {
+				return Device{}, Device{}, ErrNameSize
+			}
+		}
+	}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	d, ok := inv.devs[mac]
+	if !ok {
+		return Device{}, Device{}, ErrNotFound
+	}
+	before = d.clone()
+	if name != nil {
+		d.Name = *name
+	}
+	if profile != nil {
+		d.Profile = *profile
+	}
+	return before, d.clone(), inv.save()
+}
+
+// Delete forgets a device; it reappears once it is seen again.
+func (inv *Inventory) Delete(mac string) (Device, error) {
+	mac, err := Canonical(mac)
+	if err != nil {
+		return Device{}, err
+	}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	d, ok := inv.devs[mac]
+	if !ok {
+		return Device{}, ErrNotFound
+	}
+	delete(inv.devs, mac)
+	return *d, inv.save()
+}
+
+// Get returns the device with mac.
+func (inv *Inventory) Get(mac string) (Device, bool) {
+	mac, err := Canonical(mac)
+	if err != nil {
+		return Device{}, false
+	}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	d, ok := inv.devs[mac]
+	if !ok {
+		return Device{}, false
+	}
+	return d.clone(), true
+}
+
+// ByIP returns the device currently bound to ip.
+func (inv *Inventory) ByIP(ip string) (Device, bool) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	d := inv.byIP(ip)
+	if d == nil {
+		return Device{}, false
+	}
+	return d.clone(), true
+}
+
+func (inv *Inventory) byIP(ip string) *Device {
+	var best *Device
+	var seen time.Time
+	for _, d := range inv.devs {
+		for _, a := range d.Addresses {
+			if a.IP == ip && (best == nil || a.LastSeen.After(seen)) {
+				best, seen = d, a.LastSeen
+			}
+		}
+	}
+	return best
+}
+
+// List returns the devices of profile, or all when empty, most recently seen
+// first.
+func (inv *Inventory) List(profile string) []Device {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	out := make([]Device, 0, len(inv.devs))
+	for _, d := range inv.devs {
+		if profile == "" || d.Profile == profile {
+			out = append(out, d.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].LastSeen.Equal(out[j].LastSeen) {
+			return out[i].LastSeen.After(out[j].LastSeen)
+		}
+		return out[i].MAC < out[j].MAC
+	})
+	return out
+}
+
+// Profiles maps each assigned profile to the MACs of its devices.
+func (inv *Inventory) Profiles() map[string][]string {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	out := make(map[string][]string)
+	for _, d := range inv.devs {
+		if d.Profile != "" {
+			out[d.Profile] = append(out[d.Profile], d.MAC)
+		}
+	}
+	for _, macs := range out {
+		sort.Strings(macs)
+	}
+	return out
+}
+
+// Flush writes observations made since the last save.
+func (inv *Inventory) Flush() error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if !inv.dirty {
+		return nil
+	}
+	return inv.save()
+}
+
+// save writes the inventory atomically.
+func (inv *Inventory) save() error {
+	list := make([]*Device, 0, len(inv.devs))
+	for _, d := range inv.devs {
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].MAC < list[j].MAC })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialize devices: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(inv.path), 0o755); err != nil {
+		return fmt.Errorf("create devices dir: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(inv.path, data, 0o644); err != nil {
+		return fmt.Errorf("write devices: %w", err)
+	}
+	inv.dirty = false
+	return nil
+}
@@ -0,0 +1,125 @@
+package devices
+
+import (
+	"slices"
+	"strings"
+)
+
+// Device types reported by fingerprinting.
+const (
+	TypeComputer = "computer"
+	TypePhone    = "phone or tablet"
+	TypeConsole  = "game console"
+	TypeTV       = "tv or streaming"
+	TypeSpeaker  = "smart speaker"
+	TypeIoT      = "iot"
+)
+
+// signature is one heuristic: a match suggests an operating system and/or a
+// device type. Later, stronger signals override earlier ones.
+type signature struct {
+	match string
+	os    string
+	typ   string
+}
+
+// dhcpSignatures match the DHCP parameter request list (option 55) by
+// prefix; the order of options is characteristic of the DHCP client.
+var dhcpSignatures = []signature{
+	{match: "1,3,6,15,31,33,43,44,46,47,119,121,249,252", os: "Windows", typ: TypeComputer},
+	{match: "1,121,3,6,15,119,252,95,44,46", os: "macOS", typ: TypeComputer},
+	{match: "1,121,3,6,15,119,252", os: "iOS", typ: TypePhone},
+	{match: "1,3,6,15,119,252", os: "iOS", typ: TypePhone},
+	{match: "1,121,33,3,6,12,15,26,28,42,51,54,58,59,119", os: "ChromeOS", typ: TypeComputer},
+	{match: "1,3,6,15,26,28,51,58,59", os: "Android", typ: TypePhone},
+	{match: "1,33,3,6,15,28,51,58,59", os: "Android", typ: TypePhone},
+	{match: "1,28,2,3,15,6,119,12", os: "Linux"},
+}
+
+// vendorClassSignatures match the DHCP vendor class (option 60) by prefix.
+var vendorClassSignatures = []signature{
+	{match: "MSFT", os: "Windows", typ: TypeComputer},
+	{match: "android-dhcp", os: "Android", typ: TypePhone},
+	{match: "dhcpcd", os: "Linux"},
+	{match: "udhcp", os: "Linux", typ: TypeIoT},
+}
+
+// dnsSignatures match the connectivity checks systems run on their own, by
+// domain suffix. Apps look up service domains on any platform, so those are
+// not signatures.
+var dnsSignatures = []signature{
+	{match: "connectivitycheck.gstatic.com", os: "Android"},
+	{match: "connectivitycheck.android.com", os: "Android"},
+	{match: "captive.apple.com", os: "Apple"},
+	{match: "msftconnecttest.com", os: "Windows"},
+	{match: "msftncsi.com", os: "Windows"},
+	{match: "conntest.nintendowifi.net", os: "Nintendo", typ: TypeConsole},
+	{match: "ctest.cdn.nintendo.net", os: "Nintendo", typ: TypeConsole},
+}
+
+// vendorSignatures guess a type from the OUI vendor when nothing else did.
+var vendorSignatures = []signature{
+	{match: "Nintendo", typ: TypeConsole},
+	{match: "Sony Interactive", typ: TypeConsole},
+	{match: "Roku", typ: TypeTV},
+	{match: "Sonos", typ: TypeSpeaker},
+	{match: "Espressif", typ: TypeIoT},
+	{match: "Raspberry Pi", os: "Linux", typ: TypeComputer},
+}
+
+// DNSHint returns the signature domain matching a looked-up name, if any.
+func DNSHint(domain string) (string, bool) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	for _, s := range dnsSignatures {
+		if domain == s.match || strings.HasSuffix(domain, "."+s.match) {
+			return s.match, true
+		}
+	}
+	return "", false
+}
+
+// fingerprint derives OS and type from what is known about d, strongest
+// signal last: vendor, vendor class, DHCP parameters. DNS lookups only fill
+// in what those left open.
+func fingerprint(d *Device) {
+	d.OS, d.Type = "", ""
+	apply := func(s signature) {
+		if s.os != "" {
+			d.OS = s.os
+		}
+		if s.typ != "" {
+			d.Type = s.typ
+		}
+	}
+	for _, s := range vendorSignatures {
+		if strings.Contains(d.Vendor, s.match) {
+			apply(s)
+			break
+		}
+	}
+	for _, s := range vendorClassSignatures {
+		if strings.HasPrefix(d.VendorClass, s.match) {
+			apply(s)
+			break
+		}
+	}
+	for _, s := range dhcpSignatures {
+		if d.DHCPParams == s.match || strings.HasPrefix(d.DHCPParams, s.match+",") {
+			apply(s)
+			break
+		}
+	}
+	// Connectivity checks pin down the platform family but not which of
+	// its systems it is, so they never override the DHCP fingerprint.
+	for _, s := range dnsSignatures {
+		if !slices.Contains(d.DNSHints, s.match) {
+			continue
+		}
+		if d.OS == "" {
+			d.OS = s.os
+		}
+		if d.Type == "" {
+			d.Type = s.typ
+		}
+	}
+}
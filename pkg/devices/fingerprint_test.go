@@ -0,0 +1,78 @@
+package devices
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	const windowsParams = "1,3,6,15,31,33,43,44,46,47,119,121,249,252"
+	for _, tc := range []struct {
+		name    string
+		dev     Device
+		os, typ string
+	}{
+		{
+			name: "dhcp",
+			dev:  Device{DHCPParams: windowsParams},
+			os:   "Windows", typ: TypeComputer,
+		},
+		{
+			name: "dhcp beats connectivity check",
+			dev:  Device{DHCPParams: windowsParams, DNSHints: []string{"conntest.nintendowifi.net"}},
+			os:   "Windows", typ: TypeComputer,
+		},
+		{
+			name: "service domains are not hints",
+			dev:  Device{DHCPParams: "1,3,6,15,26,28,51,58,59", DNSHints: []string{"roku.com", "xboxlive.com"}},
+			os:   "Android", typ: TypePhone,
+		},
+		{
+			name: "check fills in what dhcp left open",
+			dev:  Device{DHCPParams: "1,28,2,3,15,6,119,12", DNSHints: []string{"ctest.cdn.nintendo.net"}},
+			os:   "Linux", typ: TypeConsole,
+		},
+		{
+			name: "check alone",
+			dev:  Device{DNSHints: []string{"captive.apple.com"}},
+			os:   "Apple",
+		},
+		{
+			name: "vendor",
+			dev:  Device{Vendor: "Sonos, Inc."},
+			typ:  TypeSpeaker,
+		},
+	} {
+		d := tc.dev
+		fingerprint(&d)
+		if d.OS != tc.os || d.Type != tc.typ {
+			t.Errorf("%s: got %q/%q, want %q/%q", tc.name, d.OS, d.Type, tc.os, tc.typ)
+		}
+	}
+}
+
+func TestDNSHint(t *testing.T) {
+	for domain, want := range map[string]string{
+		"connectivitycheck.gstatic.com.": "connectivitycheck.gstatic.com",
+		"www.msftconnecttest.com":        "msftconnecttest.com",
+		"CAPTIVE.APPLE.COM":              "captive.apple.com",
+		"roku.com":                       "",
+		"api.playstation.net":            "",
+		"notmsftncsi.com":                "",
+	} {
+		got, ok := DNSHint(domain)
+		if got != want || ok != (want != "") {
+			t.Errorf("DNSHint(%q) = %q, %v; want %q", domain, got, ok, want)
+		}
+	}
+}
+
+func TestEmbeddedOUI(t *testing.T) {
+	oui := EmbeddedOUI()
+	if len(oui) == 0 {
+		t.Fatal("embedded registry is empty")
+	}
+	if v := oui.Vendor("00:00:0c:12:34:56"); v != "Cisco Systems, Inc" {
+		t.Fatalf("vendor = %q", v)
+	}
+	if oui.Vendor("not a mac") != "" {
+		t.Fatal("vendor for an invalid MAC")
+	}
+}
@@ -0,0 +1,76 @@
+package devices
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+//go:generate ../../scripts/gen-oui.sh
+//go:embed oui.txt
+var embeddedOUI []byte
+
+// OUI maps the first three bytes of a MAC address to the vendor they were
+// assigned to.
+type OUI map[[3]byte]string
+
+// EmbeddedOUI returns the bundled registry; scripts/gen-oui.sh regenerates it
+// from the IEEE MA-L list.
+func EmbeddedOUI() OUI {
+	o, _ := parseOUI(bytes.NewReader(embeddedOUI))
+	return o
+}
+
+// LoadOUI reads a registry in the IEEE oui.txt format.
+func LoadOUI(path string) (OUI, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open oui file: %w", err)
+	}
+	defer f.Close()
+	o, err := parseOUI(f)
+	if err != nil {
+		return nil, fmt.Errorf("read oui file: %w", err)
+	}
+	return o, nil
+}
+
+// parseOUI reads the "XX-XX-XX   (hex)    Vendor" lines and skips the rest.
+func parseOUI(r io.Reader) (OUI, error) {
+	o := make(OUI)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		prefix, vendor, ok := strings.Cut(sc.Text(), "(hex)")
+		if !ok {
+			continue
+		}
+		raw, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(prefix), "-", ""))
+		if err != nil || len(raw) != 3 {
+			continue
+		}
+		o[[3]byte(raw)] = strings.TrimSpace(vendor)
+	}
+	return o, sc.Err()
+}
+
+// Vendor returns the vendor assigned the MAC's prefix, if known.
+func (o OUI) Vendor(mac string) string {
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) < 3 {
+		return ""
+	}
+	return o[[3]byte(hw[:3])]
+}
+
+// Randomized reports whether mac is locally administered, as the private
+// addresses phones and laptops use per network are.
+func Randomized(mac string) bool {
+	hw, err := net.ParseMAC(mac)
+	return err == nil && len(hw) > 0 && hw[0]&0x02 != 0
+}
@@ -1,13 +1,17 @@
 package events
 
 import (
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Event models a network-related event surfaced to the UI.
 type Event struct {
+	ID              uint64      `json:"id,omitempty"`
 	Kind            string      `json:"kind"`
+	Collector       string      `json:"collector,omitempty"`
 	Timestamp       time.Time   `json:"timestamp"`
 	SourceIP        string      `json:"sourceIp,omitempty"`
 	DestinationIP   string      `json:"destinationIp,omitempty"`
@@ -15,47 +19,158 @@ type Event struct {
 	Direction       string      `json:"direction,omitempty"`
 	Domain          string      `json:"domain,omitempty"`
 	Action          string      `json:"action,omitempty"`
+	Rule            string      `json:"rule,omitempty"`
 	Reason          string      `json:"reason,omitempty"`
 	Info            string      `json:"info,omitempty"`
 	SourcePort      uint16      `json:"sourcePort,omitempty"`
 	DestinationPort uint16      `json:"destinationPort,omitempty"`
 	Bytes           uint32      `json:"bytes,omitempty"`
+	Dropped         uint64      `json:"dropped,omitempty"`
 	PairCounts      []PairCount `json:"pairCounts,omitempty"`
+	Device          *DeviceInfo `json:"device,omitempty"`
+	Flow            *FlowInfo   `json:"flow,omitempty"`
 }
 
-// PairCount captures packet totals between a source and destination IP.
+// PairCount captures packet counts between a source and destination IP.
+// Incoming and Outgoing count the packets since the previous summary; the
+// Last windows slide over the span ending at the summary's timestamp.
 type PairCount struct {
-    Category string `json:"category"`
-    Internal string `json:"internal"`
-    External string `json:"external"`
-    ExternalDomain string `json:"externalDomain,omitempty"`
-    Incoming uint64 `json:"incoming"`
-    Outgoing uint64 `json:"outgoing"`
+	Category       string  `json:"category"`
+	Internal       string  `json:"internal"`
+	External       string  `json:"external"`
+	ExternalDomain string  `json:"externalDomain,omitempty"`
+	Incoming       uint64  `json:"incoming"`
+	Outgoing       uint64  `json:"outgoing"`
+	Last10s        Traffic `json:"last10s"`
+	Last1m         Traffic `json:"last1m"`
+	Last1h         Traffic `json:"last1h"`
+}
+
+// Traffic is a packet count in each direction.
+type Traffic struct {
+	Incoming uint64 `json:"incoming"`
+	Outgoing uint64 `json:"outgoing"`
+}
+
+// DeviceInfo describes a device the monitor learned from DHCP, ARP or IPv6
+// neighbor discovery (Source). DHCPParams is the client's parameter request
+// list (option 55) as comma-separated option codes.
+type DeviceInfo struct {
+	MAC         string `json:"mac"`
+	IP          string `json:"ip,omitempty"`
+	Hostname    string `json:"hostname,omitempty"`
+	VendorClass string `json:"vendorClass,omitempty"`
+	DHCPParams  string `json:"dhcpParams,omitempty"`
+	Source      string `json:"source,omitempty"`
+}
+
+// FlowInfo summarizes a finished flow. Out counts the direction from the
+// flow's initiator (the event's source) to its responder, In the reverse.
+// TCPFlags lists the flags seen in either direction, e.g. "SAFP".
+type FlowInfo struct {
+	PacketsOut uint64    `json:"packetsOut"`
+	PacketsIn  uint64    `json:"packetsIn"`
+	BytesOut   uint64    `json:"bytesOut"`
+	BytesIn    uint64    `json:"bytesIn"`
+	TCPFlags   string    `json:"tcpFlags,omitempty"`
+	State      string    `json:"state,omitempty"`
+	FirstSeen  time.Time `json:"firstSeen"`
+	LastSeen   time.Time `json:"lastSeen"`
+}
+
+// KindFlowEnd reports a flow that ended, timed out or was evicted; Reason
+// says which.
+const KindFlowEnd = "flow_end"
+
+// KindDeviceSeen reports a device binding; the monitor repeats it
+// periodically while the device is active.
+const KindDeviceSeen = "device_seen"
+
+// KindDropped marks synthetic events reporting how many events a consumer missed.
+const KindDropped = "dropped"
+
+// DefaultBufferSize is the per-subscriber channel capacity.
+const DefaultBufferSize = 64
+
+// BusOptions tunes subscriber buffering. With a positive BlockTimeout,
+// Publish waits up to that long in total for full subscribers, dropping the
+// event for those still full when it runs out.
+type BusOptions struct {
+	BufferSize   int
+	BlockTimeout time.Duration
 }
 
 // Bus is a simple pub/sub for events.
 type Bus struct {
 	mu     sync.RWMutex
-	subs   map[chan Event]struct{}
+	opts   BusOptions
+	subs   map[chan Event]*Subscription
 	closed bool
 }
 
+// SubscriberStats reports the backlog and losses of one subscriber.
+type SubscriberStats struct {
+	Buffered int    `json:"buffered"`
+	Capacity int    `json:"capacity"`
+	Dropped  uint64 `json:"dropped"`
+}
+
+// Subscription is a bus subscriber with an optional filter and per-kind
+// sampling rates, both of which may change while it is active.
+type Subscription struct {
+	C <-chan Event
+
+	bus     *Bus
+	ch      chan Event
+	done    chan struct{}
+	closeMu sync.RWMutex // held by senders; closing C takes it exclusively
+	closed  bool
+	mu      sync.Mutex
+	filter  Filter
+	sample  map[string]float64
+	acc     map[string]float64
+	dropped atomic.Uint64
+	pending atomic.Uint64
+}
+
 // NewBus creates a new event bus.
 func NewBus() *Bus {
-	return &Bus{subs: make(map[chan Event]struct{})}
+	return NewBusWithOptions(BusOptions{})
+}
+
+// NewBusWithOptions creates a bus with custom buffering and drop policy.
+func NewBusWithOptions(opts BusOptions) *Bus {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = DefaultBufferSize
+	}
+	return &Bus{opts: opts, subs: make(map[chan Event]*Subscription)}
 }
 
 // Subscribe returns a channel to receive events.
 func (b *Bus) Subscribe() chan Event {
-	ch := make(chan Event, 64)
+	return b.add(Filter{}, nil).ch
+}
+
+// SubscribeWith returns a subscription receiving only events matching f.
+// sample maps event kinds to the fraction (0-1] of matching events delivered.
+func (b *Bus) SubscribeWith(f Filter, sample map[string]float64) *Subscription {
+	return b.add(f, sample)
+}
+
+func (b *Bus) add(f Filter, sample map[string]float64) *Subscription {
+	ch := make(chan Event, b.opts.BufferSize)
+	sub := &Subscription{C: ch, bus: b, ch: ch, done: make(chan struct{})}
+	sub.SetFilter(f, sample)
 	b.mu.Lock()
-	if b.closed {
-		close(ch)
-	} else {
-		b.subs[ch] = struct{}{}
+	closed := b.closed
+	if !closed {
+		b.subs[ch] = sub
 	}
 	b.mu.Unlock()
-	return ch
+	if closed {
+		sub.close()
+	}
+	return sub
 }
 
 // Unsubscribe removes and closes the channel.
@@ -64,36 +179,183 @@ func (b *Bus) Unsubscribe(ch chan Event) {
 		return
 	}
 	b.mu.Lock()
-	if _, ok := b.subs[ch]; ok {
-		delete(b.subs, ch)
-		close(ch)
-	}
+	sub, ok := b.subs[ch]
+	delete(b.subs, ch)
 	b.mu.Unlock()
+	if ok {
+		sub.close()
+	}
 }
 
-// Publish sends an event to all subscribers.
+// Publish sends an event to all subscribers. A subscriber that missed events
+// receives a KindDropped event as soon as its buffer has room again. The
+// subscribers are delivered to outside the bus lock, so waiting on a full
+// one holds up neither Subscribe nor Unsubscribe.
 func (b *Bus) Publish(ev Event) {
 	b.mu.RLock()
-	for ch := range b.subs {
-		select {
-		case ch <- ev:
-		default:
-		}
+	subs := make([]*Subscription, 0, len(b.subs))
+	for _, sub := range b.subs {
+		subs = append(subs, sub)
 	}
 	b.mu.RUnlock()
+	var deadline time.Time
+	if b.opts.BlockTimeout > 0 {
+		deadline = time.Now().Add(b.opts.BlockTimeout)
+	}
+	for _, sub := range subs {
+		if !sub.accept(ev) {
+			continue
+		}
+		sub.deliver(ev, deadline)
+	}
+}
+
+// Stats returns per-subscriber backlog and drop counters.
+func (b *Bus) Stats() []SubscriberStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := make([]SubscriberStats, 0, len(b.subs))
+	for ch, sub := range b.subs {
+		out = append(out, SubscriberStats{Buffered: len(ch), Capacity: cap(ch), Dropped: sub.Dropped()})
+	}
+	return out
 }
 
 // Close shuts down and closes all subscriber channels.
 func (b *Bus) Close() {
 	b.mu.Lock()
-	if b.closed {
-		b.mu.Unlock()
-		return
-	}
-	for ch := range b.subs {
-		close(ch)
-	}
+	subs := b.subs
 	b.subs = nil
 	b.closed = true
 	b.mu.Unlock()
+	for _, sub := range subs {
+		sub.close()
+	}
+}
+
+// SetFilter replaces the subscription's filter and sampling rates.
+func (s *Subscription) SetFilter(f Filter, sample map[string]float64) {
+	rates := make(map[string]float64, len(sample))
+	for kind, rate := range sample {
+		if rate > 0 && rate < 1 {
+			rates[kind] = rate
+		}
+	}
+	s.mu.Lock()
+	s.filter = f
+	s.sample = rates
+	s.acc = make(map[string]float64, len(rates))
+	s.mu.Unlock()
+}
+
+// Filter returns the current filter.
+func (s *Subscription) Filter() Filter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.filter
+}
+
+// Dropped reports how many matching events were discarded because the
+// subscriber's buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe removes the subscription from its bus and closes C.
+func (s *Subscription) Unsubscribe() {
+	s.bus.Unsubscribe(s.ch)
+}
+
+// close wakes publishers waiting on the subscription, then closes C once
+// none is sending.
+func (s *Subscription) close() {
+	close(s.done)
+	s.closeMu.Lock()
+	s.closed = true
+	close(s.ch)
+	s.closeMu.Unlock()
+}
+
+// deliver sends ev, waiting for buffer space until deadline when it is set.
+func (s *Subscription) deliver(ev Event, deadline time.Time) {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return
+	}
+	sendDropped(s.ch, &s.pending)
+	var wait time.Duration
+	if !deadline.IsZero() {
+		wait = time.Until(deadline)
+	}
+	if sendOrDone(s.ch, ev, wait, s.done) {
+		return
+	}
+	s.dropped.Add(1)
+	s.pending.Add(1)
+}
+
+// sendDropped reports the drops counted in pending with one KindDropped event
+// if ch has room. Publishers run concurrently, so the count is claimed with a
+// swap and handed back when the marker does not fit.
+func sendDropped(ch chan Event, pending *atomic.Uint64) {
+	if n := pending.Swap(0); n > 0 && !send(ch, DroppedEvent(n), 0) {
+		pending.Add(n)
+	}
+}
+
+// send delivers ev without blocking, or waiting up to timeout when positive.
+func send(ch chan Event, ev Event, timeout time.Duration) bool {
+	return sendOrDone(ch, ev, timeout, nil)
+}
+
+// sendOrDone is send that also gives up once done is closed.
+func sendOrDone(ch chan Event, ev Event, timeout time.Duration, done <-chan struct{}) bool {
+	select {
+	case ch <- ev:
+		return true
+	default:
+	}
+	if timeout <= 0 {
+		return false
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case ch <- ev:
+		return true
+	case <-timer.C:
+		return false
+	case <-done:
+		return false
+	}
+}
+
+// DroppedEvent builds the synthetic event reporting n lost events.
+func DroppedEvent(n uint64) Event {
+	return Event{
+		Kind:      KindDropped,
+		Timestamp: time.Now().UTC(),
+		Dropped:   n,
+		Info:      fmt.Sprintf("dropped %d events", n),
+	}
+}
+
+// accept applies the filter and deterministic per-kind sampling.
+func (s *Subscription) accept(ev Event) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.filter.Match(ev) {
+		return false
+	}
+	rate, ok := s.sample[ev.Kind]
+	if !ok {
+		return true
+	}
+	s.acc[ev.Kind] += rate
+	if s.acc[ev.Kind] < 1 {
+		return false
+	}
+	s.acc[ev.Kind]--
+	return true
 }
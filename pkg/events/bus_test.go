@@ -0,0 +1,206 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func drain(ch <-chan Event) []Event {
+	var out []Event
+	for {
+		select {
+		case ev := <-ch:
+			out = append(out, ev)
+		default:
+			return out
+		}
+	}
+}
+
+func TestBusCountsDrops(t *testing.T) {
+	bus := NewBusWithOptions(BusOptions{BufferSize: 2})
+	sub := bus.SubscribeWith(Filter{}, nil)
+
+	for i := 0; i < 5; i++ {
+		bus.Publish(Event{Kind: "dns", Domain: "a.example"})
+	}
+	if got := sub.Dropped(); got != 3 {
+		t.Fatalf("dropped = %d, want 3", got)
+	}
+	if got := len(drain(sub.C)); got != 2 {
+		t.Fatalf("buffered = %d, want 2", got)
+	}
+	stats := bus.Stats()
+	if len(stats) != 1 || stats[0].Dropped != 3 || stats[0].Capacity != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestBusDroppedMarker(t *testing.T) {
+	bus := NewBusWithOptions(BusOptions{BufferSize: 2})
+	sub := bus.SubscribeWith(Filter{Kinds: []string{"dns"}}, nil)
+
+	for i := 0; i < 4; i++ {
+		bus.Publish(Event{Kind: "dns"})
+	}
+	// Events the filter rejects are not losses.
+	bus.Publish(Event{Kind: "control"})
+	drain(sub.C)
+
+	bus.Publish(Event{Kind: "dns", Domain: "after.example"})
+	got := drain(sub.C)
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want the marker and the event", len(got))
+	}
+	if got[0].Kind != KindDropped || got[0].Dropped != 2 {
+		t.Fatalf("marker = %+v, want %s reporting 2", got[0], KindDropped)
+	}
+	if got[1].Domain != "after.example" {
+		t.Fatalf("event after marker = %+v", got[1])
+	}
+
+	// The loss is reported once.
+	bus.Publish(Event{Kind: "dns"})
+	if got := drain(sub.C); len(got) != 1 || got[0].Kind != "dns" {
+		t.Fatalf("got %+v, want only the new event", got)
+	}
+	if sub.Dropped() != 2 {
+		t.Fatalf("dropped = %d, want 2", sub.Dropped())
+	}
+}
+
+func TestBusBlockTimeout(t *testing.T) {
+	bus := NewBusWithOptions(BusOptions{BufferSize: 1, BlockTimeout: 200 * time.Millisecond})
+	sub := bus.SubscribeWith(Filter{}, nil)
+	bus.Publish(Event{Kind: "dns"})
+
+	// A consumer that frees space within the timeout loses nothing.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		<-sub.C
+	}()
+	bus.Publish(Event{Kind: "dns"})
+	if sub.Dropped() != 0 {
+		t.Fatalf("dropped = %d with a consumer within the timeout", sub.Dropped())
+	}
+
+	// One that does not is given up on after the timeout.
+	start := time.Now()
+	bus.Publish(Event{Kind: "dns"})
+	if waited := time.Since(start); waited < 200*time.Millisecond {
+		t.Fatalf("publish returned after %v, want it to wait the timeout", waited)
+	}
+	if sub.Dropped() != 1 {
+		t.Fatalf("dropped = %d, want 1", sub.Dropped())
+	}
+}
+
+// TestBusSlowConsumerConcurrent publishes from several goroutines to a slow
+// consumer and checks the markers account for every drop exactly once.
+func TestBusSlowConsumerConcurrent(t *testing.T) {
+	bus := NewBusWithOptions(BusOptions{BufferSize: 4})
+	sub := bus.SubscribeWith(Filter{}, nil)
+
+	var reported, received uint64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range sub.C {
+			if ev.Kind == KindDropped {
+				if ev.Dropped > 1<<32 {
+					t.Errorf("marker reports %d drops", ev.Dropped)
+				}
+				reported += ev.Dropped
+				continue
+			}
+			received++
+			// Drain in bursts so that several publishers find room for
+			// a marker at once.
+			if received%8 == 0 {
+				time.Sleep(100 * time.Microsecond)
+			}
+		}
+	}()
+
+	const publishers, each = 16, 2000
+	var wg sync.WaitGroup
+	for i := 0; i < publishers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < each; j++ {
+				bus.Publish(Event{Kind: "dns"})
+			}
+		}()
+	}
+	wg.Wait()
+	bus.Close()
+	<-done
+
+	dropped := sub.Dropped()
+	if dropped == 0 {
+		t.Fatal("slow consumer dropped nothing; the test did not exercise drops")
+	}
+	if received+dropped != publishers*each {
+		t.Fatalf("received %d + dropped %d != published %d", received, dropped, publishers*each)
+	}
+	if pending := sub.pending.Load(); reported+pending != dropped {
+		t.Fatalf("markers reported %d + pending %d != dropped %d", reported, pending, dropped)
+	}
+}
+
+func TestBusBlockTimeoutIsShared(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+	bus := NewBusWithOptions(BusOptions{BufferSize: 1, BlockTimeout: timeout})
+	var subs []*Subscription
+	for i := 0; i < 4; i++ {
+		subs = append(subs, bus.SubscribeWith(Filter{}, nil))
+	}
+	bus.Publish(Event{Kind: "dns"})
+
+	start := time.Now()
+	bus.Publish(Event{Kind: "dns"})
+	if waited := time.Since(start); waited >= 2*timeout {
+		t.Fatalf("publish waited %v for 4 full subscribers, want about %v in total", waited, timeout)
+	}
+	for i, sub := range subs {
+		if sub.Dropped() != 1 {
+			t.Fatalf("subscriber %d dropped %d, want 1", i, sub.Dropped())
+		}
+	}
+}
+
+func TestBusUnsubscribeDuringBlockedPublish(t *testing.T) {
+	bus := NewBusWithOptions(BusOptions{BufferSize: 1, BlockTimeout: time.Minute})
+	full := bus.SubscribeWith(Filter{}, nil)
+	bus.Publish(Event{Kind: "dns"})
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		bus.Publish(Event{Kind: "dns"})
+	}()
+	// Wait until the publisher is blocked on the full subscriber.
+	time.Sleep(20 * time.Millisecond)
+
+	// The bus lock is free: others can subscribe and leave meanwhile.
+	other := bus.SubscribeWith(Filter{}, nil)
+	other.Unsubscribe()
+
+	full.Unsubscribe()
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish still blocked after the subscriber left")
+	}
+	// Ranging ends only once C is closed.
+	var held int
+	for range full.C {
+		held++
+	}
+	if held != 1 {
+		t.Fatalf("closed subscriber held %d events, want 1", held)
+	}
+	bus.Publish(Event{Kind: "dns"})
+}
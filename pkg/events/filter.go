@@ -0,0 +1,80 @@
+package events
+
+import (
+	"strings"
+	"time"
+)
+
+// Filter selects events by time range and attributes. Empty fields match
+// everything; list fields match any of their values, except ExcludeKinds,
+// which rejects its kinds.
+type Filter struct {
+	Since        time.Time `json:"since,omitempty"`
+	Until        time.Time `json:"until,omitempty"`
+	Kinds        []string  `json:"kinds,omitempty"`
+	ExcludeKinds []string  `json:"excludeKinds,omitempty"`
+	Actions      []string  `json:"actions,omitempty"`
+	Devices      []string  `json:"devices,omitempty"`
+	Domain       string    `json:"domain,omitempty"`
+	Direction    string    `json:"direction,omitempty"`
+}
+
+// Match reports whether ev satisfies every populated criterion.
+func (f Filter) Match(ev Event) bool {
+	if !f.Since.IsZero() && ev.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !ev.Timestamp.Before(f.Until) {
+		return false
+	}
+	if len(f.Kinds) > 0 && !contains(f.Kinds, ev.Kind) {
+		return false
+	}
+	if contains(f.ExcludeKinds, ev.Kind) {
+		return false
+	}
+	if len(f.Actions) > 0 && !contains(f.Actions, ev.Action) {
+		return false
+	}
+	if f.Direction != "" && ev.Direction != f.Direction {
+		return false
+	}
+	if f.Domain != "" && !strings.Contains(strings.ToLower(ev.Domain), strings.ToLower(f.Domain)) {
+		return false
+	}
+	if len(f.Devices) > 0 && !f.matchDevice(ev) {
+		return false
+	}
+	return true
+}
+
+// IsZero reports whether the filter matches every event.
+func (f Filter) IsZero() bool {
+	return f.Since.IsZero() && f.Until.IsZero() && len(f.Kinds) == 0 && len(f.ExcludeKinds) == 0 &&
+		len(f.Actions) == 0 &&
+		len(f.Devices) == 0 && f.Domain == "" && f.Direction == ""
+}
+
+func (f Filter) matchDevice(ev Event) bool {
+	if contains(f.Devices, ev.SourceIP) || contains(f.Devices, ev.DestinationIP) {
+		return true
+	}
+	for _, pc := range ev.PairCounts {
+		if contains(f.Devices, pc.Internal) {
+			return true
+		}
+	}
+	return ev.Device != nil && contains(f.Devices, ev.Device.MAC)
+}
+
+func contains(list []string, v string) bool {
+	if v == "" {
+		return false
+	}
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
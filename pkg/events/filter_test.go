@@ -0,0 +1,21 @@
+package events
+
+import "testing"
+
+func TestFilterExcludeKinds(t *testing.T) {
+	f := Filter{ExcludeKinds: []string{KindFlowEnd}}
+	if f.IsZero() {
+		t.Fatal("filter with excluded kinds reports zero")
+	}
+	if f.Match(Event{Kind: KindFlowEnd}) {
+		t.Fatal("excluded kind matched")
+	}
+	if !f.Match(Event{Kind: "dns"}) {
+		t.Fatal("other kind rejected")
+	}
+	// Asking for a kind by name wins only if it is not also excluded.
+	f.Kinds = []string{KindFlowEnd, "dns"}
+	if f.Match(Event{Kind: KindFlowEnd}) || !f.Match(Event{Kind: "dns"}) {
+		t.Fatal("kinds and excluded kinds combined wrongly")
+	}
+}
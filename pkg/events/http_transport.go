@@ -0,0 +1,69 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// httpTransport posts batches as a JSON array to an HTTP endpoint.
+type httpTransport struct {
+	client   *http.Client
+	endpoint string
+	token    string
+}
+
+// NewHTTPTransport creates a transport targeting the given batch endpoint,
+// authenticating with token as a bearer credential.
+func NewHTTPTransport(endpoint, token string) Transport {
+	return &httpTransport{
+		client:   &http.Client{Timeout: 5 * time.Second},
+		endpoint: endpoint,
+		token:    token,
+	}
+}
+
+func (t *httpTransport) Send(batch []Event) error {
+	buf, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("event publish status %s", resp.Status)
+		if resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusTooManyRequests {
+			return fmt.Errorf("%w: %w", err, ErrRetryLater)
+		}
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+			resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return &rejectedError{err: err}
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *httpTransport) Close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+func (t *httpTransport) String() string {
+	return t.endpoint
+}
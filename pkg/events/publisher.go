@@ -0,0 +1,325 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+// DefaultQueueSize is the publisher's default in-memory queue capacity.
+const DefaultQueueSize = 256
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = 250 * time.Millisecond
+	sendAttempts         = 3
+	retryBase            = 100 * time.Millisecond
+	replayBase           = time.Second
+	replayMax            = time.Minute
+	replayPerTick        = 16
+)
+
+// PublisherOptions tunes the delivery queue. With a positive BlockTimeout,
+// Publish waits up to that long for queue space before dropping. When
+// SpoolDir is set, batches that cannot be delivered are kept on disk (up to
+// SpoolBytes) and replayed once the endpoint is reachable again.
+type PublisherOptions struct {
+	QueueSize     int
+	BlockTimeout  time.Duration
+	BatchSize     int
+	FlushInterval time.Duration
+	SpoolDir      string
+	SpoolBytes    int64
+}
+
+// Transport delivers one batch of events to the web server.
+type Transport interface {
+	Send(batch []Event) error
+	Close() error
+	String() string
+}
+
+// Forwarder asynchronously delivers batches of events over a Transport,
+// retrying with backoff and spooling to disk.
+type Forwarder struct {
+	transport Transport
+	queue     chan Event
+	opts      PublisherOptions
+	spool     *spool
+	dropped   atomic.Uint64
+	pending   atomic.Uint64
+
+	mu      sync.RWMutex
+	closed  bool
+	started atomic.Bool
+	done    chan struct{}
+
+	closeOnce  sync.Once
+	replayWait time.Duration
+	nextReplay time.Time
+}
+
+// NewForwarder creates a publisher delivering over t with the given queue
+// policy. Spool errors are logged and leave it running without a spool.
+func NewForwarder(t Transport, opts PublisherOptions) *Forwarder {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	p := &Forwarder{
+		transport:  t,
+		queue:      make(chan Event, opts.QueueSize),
+		opts:       opts,
+		done:       make(chan struct{}),
+		replayWait: replayBase,
+	}
+	if opts.SpoolDir != "" {
+		sp, err := openSpool(opts.SpoolDir, opts.SpoolBytes)
+		if err != nil {
+			logging.Errorf("event spool disabled: %v", err)
+		} else {
+			p.spool = sp
+		}
+	}
+	return p
+}
+
+// Publish enqueues an event for delivery. Events that do not fit are counted,
+// and a KindDropped event reporting them is queued once space frees up.
+func (p *Forwarder) Publish(ev Event) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		p.dropped.Add(1)
+		return
+	}
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now().UTC()
+	}
+	sendDropped(p.queue, &p.pending)
+	if send(p.queue, ev, p.opts.BlockTimeout) {
+		return
+	}
+	p.countDropped(1)
+}
+
+// Dropped reports how many events were discarded: queue overflow, spool
+// eviction, or delivery failure without a spool.
+func (p *Forwarder) Dropped() uint64 {
+	return p.dropped.Load()
+}
+
+// Run batches queued events and delivers them until Close is called.
+// Cancelling ctx stops retries and backoff waits, but queued events are still
+// flushed (or spooled) by Close rather than abandoned.
+func (p *Forwarder) Run(ctx context.Context) {
+	if !p.started.CompareAndSwap(false, true) {
+		return
+	}
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.opts.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]Event, 0, p.opts.BatchSize)
+
+	for {
+		select {
+		case ev, ok := <-p.queue:
+			if !ok {
+				p.flush(batch)
+				return
+			}
+			batch = append(batch, ev)
+			if len(batch) >= p.opts.BatchSize {
+				p.deliver(ctx, batch)
+				batch = make([]Event, 0, p.opts.BatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				p.deliver(ctx, batch)
+				batch = make([]Event, 0, p.opts.BatchSize)
+			}
+			p.replay(ctx)
+		}
+	}
+}
+
+// Close stops accepting events, flushes everything still queued and waits
+// for delivery (or spooling) to finish.
+func (p *Forwarder) Close() {
+	p.closeOnce.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		close(p.queue)
+		p.mu.Unlock()
+
+		if p.started.CompareAndSwap(false, true) {
+			// Run was never started; drain synchronously.
+			p.flush(nil)
+			close(p.done)
+		}
+		<-p.done
+		if err := p.transport.Close(); err != nil {
+			logging.Errorf("close %s: %v", p.transport, err)
+		}
+	})
+}
+
+// deliver sends a batch with short retries, spooling it when the endpoint
+// stays unreachable. While a spool backlog exists new batches join it so
+// ordering is preserved.
+func (p *Forwarder) deliver(ctx context.Context, batch []Event) {
+	if p.spool != nil && p.spool.Len() > 0 {
+		p.toSpool(batch)
+		return
+	}
+	wait := retryBase
+	var err error
+	for attempt := 0; attempt < sendAttempts; attempt++ {
+		if err = p.send(batch); err == nil {
+			return
+		}
+		if isRejected(err) {
+			logging.Errorf("publish %d events: %v", len(batch), err)
+			p.countDropped(uint64(len(batch)))
+			return
+		}
+		// The server asked for the batch later; retrying at once won't help.
+		if errors.Is(err, ErrRetryLater) {
+			break
+		}
+		if attempt == sendAttempts-1 || !sleepCtx(ctx, wait) {
+			break
+		}
+		wait *= 2
+	}
+	logging.Errorf("publish %d events via %s: %v", len(batch), p.transport, err)
+	p.nextReplay = time.Now().Add(p.replayWait)
+	p.toSpool(batch)
+}
+
+// replay resends spooled batches oldest first with exponential backoff.
+func (p *Forwarder) replay(ctx context.Context) {
+	if p.spool == nil || ctx.Err() != nil || time.Now().Before(p.nextReplay) {
+		return
+	}
+	for i := 0; i < replayPerTick; i++ {
+		batch, ok, err := p.spool.Peek()
+		if err != nil {
+			logging.Errorf("replay spool: %v", err)
+			var damaged *damagedBatchError
+			if !errors.As(err, &damaged) {
+				return
+			}
+			p.countDropped(uint64(damaged.Count))
+			continue
+		}
+		if !ok {
+			return
+		}
+		if err := p.send(batch); err != nil {
+			if isRejected(err) {
+				logging.Errorf("replay %d events: %v", len(batch), err)
+				p.spool.Pop()
+				p.countDropped(uint64(len(batch)))
+				continue
+			}
+			p.nextReplay = time.Now().Add(p.replayWait)
+			p.replayWait *= 2
+			if p.replayWait > replayMax {
+				p.replayWait = replayMax
+			}
+			return
+		}
+		p.spool.Pop()
+		p.replayWait = replayBase
+	}
+}
+
+// flush drains the closed queue, sending what it can with a single attempt
+// and spooling the rest.
+func (p *Forwarder) flush(batch []Event) {
+	for ev := range p.queue {
+		batch = append(batch, ev)
+	}
+	failed := p.spool != nil && p.spool.Len() > 0
+	for len(batch) > 0 {
+		n := len(batch)
+		if n > p.opts.BatchSize {
+			n = p.opts.BatchSize
+		}
+		chunk := batch[:n]
+		batch = batch[n:]
+		if !failed {
+			err := p.send(chunk)
+			if err == nil {
+				continue
+			}
+			if isRejected(err) {
+				p.countDropped(uint64(len(chunk)))
+				continue
+			}
+			failed = true
+		}
+		p.toSpool(chunk)
+	}
+}
+
+func (p *Forwarder) toSpool(batch []Event) {
+	if p.spool == nil {
+		p.countDropped(uint64(len(batch)))
+		return
+	}
+	evicted, err := p.spool.Append(batch)
+	if evicted > 0 {
+		p.countDropped(uint64(evicted))
+	}
+	if err != nil {
+		logging.Errorf("spool events: %v", err)
+		p.countDropped(uint64(len(batch)))
+	}
+}
+
+func (p *Forwarder) countDropped(n uint64) {
+	p.dropped.Add(n)
+	p.pending.Add(n)
+}
+
+func (p *Forwarder) send(batch []Event) error {
+	return p.transport.Send(batch)
+}
+
+// rejectedError marks batches the server refused; retrying them cannot succeed.
+type rejectedError struct {
+	err error
+}
+
+func (e *rejectedError) Error() string { return e.err.Error() }
+
+func (e *rejectedError) Unwrap() error { return e.err }
+
+func isRejected(err error) bool {
+	var rejected *rejectedError
+	return errors.As(err, &rejected)
+}
+
+// sleepCtx waits for d and reports false if ctx ended first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,336 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+// Frames on the socket are a 4-byte big-endian length followed by a JSON
+// payload. A connection opens with a hello frame naming the collector; each
+// batch frame that follows is answered with a single status byte.
+const (
+	maxFrameSize  = 8 << 20
+	socketTimeout = 5 * time.Second
+
+	ackOK       byte = 0
+	ackRejected byte = 1
+	ackRetry    byte = 2
+)
+
+// ErrRetryLater tells a socket peer to resend the batch rather than drop it.
+var ErrRetryLater = errors.New("retry later")
+
+type hello struct {
+	Collector string `json:"collector"`
+}
+
+func writeFrame(w io.Writer, v any) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if len(buf) > maxFrameSize {
+		return &rejectedError{err: fmt.Errorf("frame of %d bytes exceeds limit", len(buf))}
+	}
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(len(buf)))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+func readFrame(r io.Reader, v any) error {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return err
+	}
+	n := binary.BigEndian.Uint32(hdr[:])
+	if n > maxFrameSize {
+		return fmt.Errorf("frame of %d bytes exceeds limit", n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, v)
+}
+
+// socketTransport sends batches over a persistent Unix socket connection,
+// reconnecting after any error.
+type socketTransport struct {
+	path      string
+	collector string
+	conn      net.Conn
+	w         *bufio.Writer
+}
+
+// NewSocketTransport creates a transport to the web server's event socket.
+func NewSocketTransport(path, collector string) Transport {
+	return &socketTransport{path: path, collector: collector}
+}
+
+func (t *socketTransport) Send(batch []Event) error {
+	if t.conn == nil {
+		if err := t.dial(); err != nil {
+			return err
+		}
+	}
+	t.conn.SetDeadline(time.Now().Add(socketTimeout))
+	if err := writeFrame(t.w, batch); err != nil {
+		if !isRejected(err) {
+			t.Close()
+		}
+		return err
+	}
+	var ack [1]byte
+	err := t.w.Flush()
+	if err == nil {
+		_, err = io.ReadFull(t.conn, ack[:])
+	}
+	if err != nil {
+		t.Close()
+		return err
+	}
+	switch ack[0] {
+	case ackOK:
+		return nil
+	case ackRejected:
+		return &rejectedError{err: errors.New("event batch rejected")}
+	default:
+		return fmt.Errorf("event batch deferred by server: %w", ErrRetryLater)
+	}
+}
+
+func (t *socketTransport) dial() error {
+	conn, err := net.DialTimeout("unix", t.path, socketTimeout)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(conn)
+	conn.SetDeadline(time.Now().Add(socketTimeout))
+	if err := writeFrame(w, hello{Collector: t.collector}); err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	t.conn, t.w = conn, w
+	return nil
+}
+
+func (t *socketTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn, t.w = nil, nil
+	return err
+}
+
+func (t *socketTransport) String() string {
+	return "unix:" + t.path
+}
+
+// Peer identifies the process on the other end of a socket connection.
+type Peer struct {
+	UID       uint32
+	GID       uint32
+	PID       int32
+	Collector string
+}
+
+func (p Peer) String() string {
+	return fmt.Sprintf("%s (pid %d, uid %d)", p.Collector, p.PID, p.UID)
+}
+
+// BatchHandler stores a batch received from peer. Returning ErrRetryLater
+// asks the peer to resend; any other error rejects the batch.
+type BatchHandler func(peer Peer, batch []Event) error
+
+// SocketServer accepts event batches from local collectors over a Unix
+// socket, admitting only peers whose uid is root, the server's own, or listed.
+type SocketServer struct {
+	path    string
+	ln      *net.UnixListener
+	allowed map[uint32]bool
+	handler BatchHandler
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// ListenSocket binds path, replacing a stale socket file, and restricts it to
+// mode 0660.
+func ListenSocket(path string, allowedUIDs []int, handler BatchHandler) (*SocketServer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create socket dir: %w", err)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remove stale socket: %w", err)
+	}
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("listen %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o660); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("chmod socket: %w", err)
+	}
+
+	allowed := map[uint32]bool{0: true, uint32(os.Geteuid()): true}
+	for _, uid := range allowedUIDs {
+		allowed[uint32(uid)] = true
+	}
+	return &SocketServer{
+		path:    path,
+		ln:      ln,
+		allowed: allowed,
+		handler: handler,
+		conns:   make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Serve accepts connections until ctx is cancelled or Close is called.
+func (s *SocketServer) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		s.Close()
+	}()
+	for {
+		conn, err := s.ln.AcceptUnix()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		peer, err := peerCredentials(conn)
+		if err != nil {
+			logging.Errorf("event socket: %v", err)
+			conn.Close()
+			continue
+		}
+		if !s.allowed[peer.UID] {
+			logging.Errorf("event socket: refusing pid %d uid %d", peer.PID, peer.UID)
+			conn.Close()
+			continue
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return nil
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.untrack(conn)
+			s.serveConn(conn, peer)
+		}()
+	}
+}
+
+// Close stops accepting, disconnects peers and removes the socket file.
+func (s *SocketServer) Close() error {
+	s.mu.Lock()
+	if s.conns == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+	s.mu.Unlock()
+
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *SocketServer) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conns == nil {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *SocketServer) untrack(conn net.Conn) {
+	s.mu.Lock()
+	if s.conns != nil {
+		delete(s.conns, conn)
+	}
+	s.mu.Unlock()
+	conn.Close()
+}
+
+func (s *SocketServer) serveConn(conn *net.UnixConn, peer Peer) {
+	r := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(socketTimeout))
+	var h hello
+	if err := readFrame(r, &h); err != nil || h.Collector == "" {
+		logging.Errorf("event socket: bad hello from pid %d", peer.PID)
+		return
+	}
+	peer.Collector = h.Collector
+	conn.SetReadDeadline(time.Time{})
+
+	for {
+		var batch []Event
+		if err := readFrame(r, &batch); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				logging.Errorf("event socket %s: %v", peer, err)
+			}
+			return
+		}
+		ack := ackOK
+		if err := s.handler(peer, batch); err != nil {
+			ack = ackRejected
+			if errors.Is(err, ErrRetryLater) {
+				ack = ackRetry
+			}
+			logging.Errorf("event socket %s: %v", peer, err)
+		}
+		conn.SetWriteDeadline(time.Now().Add(socketTimeout))
+		if _, err := conn.Write([]byte{ack}); err != nil {
+			return
+		}
+	}
+}
+
+func peerCredentials(conn *net.UnixConn) (Peer, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return Peer{}, err
+	}
+	var cred *unix.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return Peer{}, err
+	}
+	if credErr != nil {
+		return Peer{}, fmt.Errorf("peer credentials: %w", credErr)
+	}
+	return Peer{UID: cred.Uid, GID: cred.Gid, PID: cred.Pid}, nil
+}
@@ -0,0 +1,83 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func startSocket(t *testing.T, handler BatchHandler) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "events.sock")
+	srv, err := ListenSocket(path, nil, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = srv.Serve(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return path
+}
+
+func TestSocketRetryLater(t *testing.T) {
+	var calls atomic.Int32
+	path := startSocket(t, func(_ Peer, batch []Event) error {
+		if calls.Add(1) == 1 {
+			return ErrRetryLater
+		}
+		return nil
+	})
+
+	tr := NewSocketTransport(path, "test")
+	defer tr.Close()
+	batch := []Event{{Kind: "dns", Domain: "a.example"}}
+	err := tr.Send(batch)
+	if !errors.Is(err, ErrRetryLater) || isRejected(err) {
+		t.Fatalf("first send = %v, want a retryable deferral", err)
+	}
+	if err := tr.Send(batch); err != nil {
+		t.Fatalf("resend: %v", err)
+	}
+}
+
+func TestSocketRejected(t *testing.T) {
+	path := startSocket(t, func(Peer, []Event) error { return errors.New("invalid") })
+	tr := NewSocketTransport(path, "test")
+	defer tr.Close()
+	if err := tr.Send([]Event{{Kind: "dns"}}); !isRejected(err) {
+		t.Fatalf("send = %v, want a rejection", err)
+	}
+}
+
+// TestForwarderSpoolsDeferredBatch checks a deferred batch goes straight to
+// the spool and is delivered by the next replay.
+func TestForwarderSpoolsDeferredBatch(t *testing.T) {
+	var calls atomic.Int32
+	path := startSocket(t, func(Peer, []Event) error {
+		if calls.Add(1) == 1 {
+			return ErrRetryLater
+		}
+		return nil
+	})
+	p := NewForwarder(NewSocketTransport(path, "test"), PublisherOptions{SpoolDir: t.TempDir()})
+	ctx := context.Background()
+
+	p.deliver(ctx, []Event{{Kind: "dns", Domain: "a.example"}})
+	if calls.Load() != 1 || p.spool.Len() != 1 {
+		t.Fatalf("calls = %d, spooled = %d; want one attempt then spool", calls.Load(), p.spool.Len())
+	}
+	p.nextReplay = p.nextReplay.Add(-p.replayWait)
+	p.replay(ctx)
+	if calls.Load() != 2 || p.spool.Len() != 0 || p.Dropped() != 0 {
+		t.Fatalf("calls = %d, spooled = %d, dropped = %d", calls.Load(), p.spool.Len(), p.Dropped())
+	}
+}
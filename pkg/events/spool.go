@@ -0,0 +1,171 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kidos/kidosserver/pkg/fsutil"
+)
+
+// spool is a bounded on-disk FIFO of event batches awaiting delivery.
+type spool struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	files    []spoolFile
+	size     int64
+	nextSeq  uint64
+}
+
+type spoolFile struct {
+	seq   uint64
+	count int
+	size  int64
+}
+
+func (f spoolFile) name() string {
+	return fmt.Sprintf("%020d-%d.json", f.seq, f.count)
+}
+
+// openSpool loads batches left over from a previous run.
+func openSpool(dir string, maxBytes int64) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read spool dir: %w", err)
+	}
+	s := &spool{dir: dir, maxBytes: maxBytes, nextSeq: 1}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		seqPart, countPart, ok := strings.Cut(strings.TrimSuffix(name, ".json"), "-")
+		if !ok {
+			continue
+		}
+		seq, err1 := strconv.ParseUint(seqPart, 10, 64)
+		count, err2 := strconv.Atoi(countPart)
+		info, err3 := e.Info()
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		s.files = append(s.files, spoolFile{seq: seq, count: count, size: info.Size()})
+		s.size += info.Size()
+		if seq >= s.nextSeq {
+			s.nextSeq = seq + 1
+		}
+	}
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i].seq < s.files[j].seq })
+	return s, nil
+}
+
+// Append writes a batch and returns how many older events were evicted to
+// stay within the size bound.
+func (s *spool) Append(batch []Event) (int, error) {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	evicted := 0
+	for len(s.files) > 0 && s.maxBytes > 0 && s.size+int64(len(data)) > s.maxBytes {
+		oldest := s.files[0]
+		_ = os.Remove(filepath.Join(s.dir, oldest.name()))
+		s.files = s.files[1:]
+		s.size -= oldest.size
+		evicted += oldest.count
+	}
+	if s.maxBytes > 0 && int64(len(data)) > s.maxBytes {
+		return evicted + len(batch), nil
+	}
+
+	f := spoolFile{seq: s.nextSeq, count: len(batch), size: int64(len(data))}
+	path := filepath.Join(s.dir, f.name())
+	if err := fsutil.WriteFileAtomic(path, data, 0o640); err != nil {
+		return evicted, fmt.Errorf("write spool: %w", err)
+	}
+	s.nextSeq++
+	s.files = append(s.files, f)
+	s.size += f.size
+	return evicted, nil
+}
+
+// Peek returns the oldest batch without removing it. A batch that cannot be
+// read or decoded would block replay forever, so it is moved aside with a
+// .bad suffix and reported as a *damagedBatchError.
+func (s *spool) Peek() ([]Event, bool, error) {
+	s.mu.Lock()
+	if len(s.files) == 0 {
+		s.mu.Unlock()
+		return nil, false, nil
+	}
+	path := filepath.Join(s.dir, s.files[0].name())
+	s.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, s.quarantine(path, fmt.Errorf("read spool %s: %w", path, err))
+	}
+	var batch []Event
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, false, s.quarantine(path, fmt.Errorf("decode spool %s: %w", path, err))
+	}
+	return batch, true, nil
+}
+
+// damagedBatchError reports a spooled batch whose Count events are lost.
+type damagedBatchError struct {
+	Count int
+	err   error
+}
+
+func (e *damagedBatchError) Error() string { return e.err.Error() }
+func (e *damagedBatchError) Unwrap() error { return e.err }
+
+// quarantine drops the oldest batch, stored at path, from the spool and keeps
+// the file, if any is left, for inspection.
+func (s *spool) quarantine(path string, cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.files) == 0 || filepath.Join(s.dir, s.files[0].name()) != path {
+		return cause
+	}
+	oldest := s.files[0]
+	if err := os.Rename(path, path+".bad"); err != nil {
+		_ = os.Remove(path)
+	}
+	s.files = s.files[1:]
+	s.size -= oldest.size
+	return &damagedBatchError{Count: oldest.count, err: cause}
+}
+
+// Pop removes the oldest batch.
+func (s *spool) Pop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.files) == 0 {
+		return
+	}
+	oldest := s.files[0]
+	_ = os.Remove(filepath.Join(s.dir, oldest.name()))
+	s.files = s.files[1:]
+	s.size -= oldest.size
+}
+
+// Len reports the number of spooled batches.
+func (s *spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.files)
+}
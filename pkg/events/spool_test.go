@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type recordingTransport struct {
+	mu      sync.Mutex
+	batches [][]Event
+}
+
+func (t *recordingTransport) Send(batch []Event) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.batches = append(t.batches, batch)
+	return nil
+}
+
+func (t *recordingTransport) Close() error   { return nil }
+func (t *recordingTransport) String() string { return "recording" }
+
+func spoolBatches(t *testing.T, dir string, domains ...string) *spool {
+	t.Helper()
+	sp, err := openSpool(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range domains {
+		if _, err := sp.Append([]Event{{Kind: "dns", Domain: d}, {Kind: "dns", Domain: d}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sp
+}
+
+// TestSpoolQuarantinesUnreadableBatch removes a spooled file behind the
+// spool's back; Peek must report the lost batch once and move on.
+func TestSpoolQuarantinesUnreadableBatch(t *testing.T) {
+	dir := t.TempDir()
+	sp := spoolBatches(t, dir, "missing.example", "good.example")
+	if err := os.Remove(filepath.Join(dir, sp.files[0].name())); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := sp.Peek()
+	var damaged *damagedBatchError
+	if !errors.As(err, &damaged) || damaged.Count != 2 {
+		t.Fatalf("peek error = %v, want a damaged batch of 2", err)
+	}
+	batch, ok, err := sp.Peek()
+	if err != nil || !ok || batch[0].Domain != "good.example" {
+		t.Fatalf("peek = %+v, %v, %v", batch, ok, err)
+	}
+}
+
+// TestReplaySkipsDamagedBatches corrupts a spooled file; replay must set it
+// aside, count its events as dropped and deliver the batch behind it.
+func TestReplaySkipsDamagedBatches(t *testing.T) {
+	dir := t.TempDir()
+	sp := spoolBatches(t, dir, "corrupt.example", "good.example")
+	corrupt := filepath.Join(dir, sp.files[0].name())
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := &recordingTransport{}
+	p := NewForwarder(tr, PublisherOptions{SpoolDir: dir})
+	p.replay(context.Background())
+
+	if len(tr.batches) != 1 || tr.batches[0][0].Domain != "good.example" {
+		t.Fatalf("delivered %+v, want only the good batch", tr.batches)
+	}
+	if n := p.spool.Len(); n != 0 {
+		t.Fatalf("%d batches left in the spool", n)
+	}
+	if got := p.Dropped(); got != 2 {
+		t.Fatalf("dropped = %d, want the 2 events of the damaged batch", got)
+	}
+	if _, err := os.Stat(corrupt + ".bad"); err != nil {
+		t.Fatalf("corrupt batch not kept aside: %v", err)
+	}
+
+	// Quarantined files are not picked up again.
+	reopened, err := openSpool(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 0 {
+		t.Fatalf("reopened spool has %d batches", reopened.Len())
+	}
+}
+
+func TestSpoolEvictsOldest(t *testing.T) {
+	dir := t.TempDir()
+	sp := spoolBatches(t, dir, "a.example")
+	sp.maxBytes = sp.size + sp.size/2
+	evicted, err := sp.Append([]Event{{Kind: "dns", Domain: "b.example"}, {Kind: "dns", Domain: "b.example"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evicted != 2 || sp.Len() != 1 {
+		t.Fatalf("evicted %d, %d left", evicted, sp.Len())
+	}
+	batch, ok, err := sp.Peek()
+	if err != nil || !ok || batch[0].Domain != "b.example" {
+		t.Fatalf("peek = %+v, %v, %v", batch, ok, err)
+	}
+}
@@ -1,6 +1,12 @@
 package events
 
-import "strings"
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/config"
+)
 
 // BuildEndpoint converts a listen address (" :8080" or "127.0.0.1:8080")
 // and path into a usable HTTP URL.
@@ -17,3 +23,27 @@ func BuildEndpoint(listen, path string) string {
 	}
 	return strings.TrimSuffix(addr, "/") + path
 }
+
+// Publisher accepts events for asynchronous delivery.
+type Publisher interface {
+	Publish(ev Event)
+}
+
+// NewPublisher creates the publisher for the named collector described by cfg,
+// preferring the web server's Unix socket over HTTP when one is configured.
+func NewPublisher(cfg config.Config, collector string) *Forwarder {
+	opts := PublisherOptions{
+		QueueSize:     cfg.Events.PublisherQueue,
+		BlockTimeout:  time.Duration(cfg.Events.PublisherBlockMS) * time.Millisecond,
+		BatchSize:     cfg.Events.BatchSize,
+		FlushInterval: time.Duration(cfg.Events.FlushMS) * time.Millisecond,
+		SpoolBytes:    int64(cfg.Events.SpoolMB) << 20,
+	}
+	if cfg.Events.SpoolDir != "" {
+		opts.SpoolDir = filepath.Join(cfg.Events.SpoolDir, collector)
+	}
+	if cfg.Events.Socket != "" {
+		return NewForwarder(NewSocketTransport(cfg.Events.Socket, collector), opts)
+	}
+	return NewForwarder(NewHTTPTransport(BuildEndpoint(cfg.Web.Listen, "/api/events/batch"), cfg.Events.Tokens[collector]), opts)
+}
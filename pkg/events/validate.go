@@ -0,0 +1,81 @@
+package events
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// Limits applied to events submitted by collectors. Timestamps may trail
+// the server clock by up to maxEventAge, which covers batches replayed from
+// a spool, but may lead it only by maxClockSkew.
+const (
+	maxDomainLen = 253
+	maxTextLen   = 1024
+	maxPairs     = 10000
+	maxClockSkew = 5 * time.Minute
+	maxEventAge  = 30 * 24 * time.Hour
+)
+
+// ingestKinds lists the kinds collectors may submit and, where restricted,
+// the actions each kind allows. Server-generated kinds such as "control" are
+// deliberately absent.
+var ingestKinds = map[string][]string{
+	"dns":             {"allow", "block", "error"},
+	"ip_pair_summary": nil,
+	KindDropped:       nil,
+	KindDeviceSeen:    nil,
+	KindFlowEnd:       nil,
+}
+
+// Validate reports whether ev is an event a collector may submit at now.
+// A zero timestamp is accepted; the server stamps it on receipt.
+func Validate(ev Event, now time.Time) error {
+	actions, ok := ingestKinds[ev.Kind]
+	if !ok {
+		return fmt.Errorf("unknown event kind %q", ev.Kind)
+	}
+	if !ev.Timestamp.IsZero() {
+		if ev.Timestamp.After(now.Add(maxClockSkew)) {
+			return fmt.Errorf("timestamp %s is more than %s ahead of the server clock", ev.Timestamp.Format(time.RFC3339), maxClockSkew)
+		}
+		if ev.Timestamp.Before(now.Add(-maxEventAge)) {
+			return fmt.Errorf("timestamp %s is older than %s", ev.Timestamp.Format(time.RFC3339), maxEventAge)
+		}
+	}
+	if actions != nil && !contains(actions, ev.Action) {
+		return fmt.Errorf("invalid action %q for kind %q", ev.Action, ev.Kind)
+	}
+	if len(ev.Domain) > maxDomainLen {
+		return fmt.Errorf("domain longer than %d bytes", maxDomainLen)
+	}
+	fields := []string{ev.Rule, ev.Reason, ev.Info, ev.SourceIP, ev.DestinationIP, ev.Transport, ev.Direction}
+	if ev.Flow != nil {
+		fields = append(fields, ev.Flow.TCPFlags, ev.Flow.State)
+	}
+	for _, s := range fields {
+		if len(s) > maxTextLen {
+			return fmt.Errorf("field longer than %d bytes", maxTextLen)
+		}
+	}
+	if ev.Kind == KindDeviceSeen {
+		if ev.Device == nil {
+			return fmt.Errorf("%s event without device", ev.Kind)
+		}
+		if _, err := net.ParseMAC(ev.Device.MAC); err != nil {
+			return fmt.Errorf("invalid device mac %q", ev.Device.MAC)
+		}
+		for _, s := range []string{ev.Device.IP, ev.Device.Hostname, ev.Device.VendorClass, ev.Device.DHCPParams, ev.Device.Source} {
+			if len(s) > maxTextLen {
+				return fmt.Errorf("field longer than %d bytes", maxTextLen)
+			}
+		}
+	}
+	if ev.Kind == KindFlowEnd && ev.Flow == nil {
+		return fmt.Errorf("%s event without flow", ev.Kind)
+	}
+	if len(ev.PairCounts) > maxPairs {
+		return fmt.Errorf("more than %d pair counts", maxPairs)
+	}
+	return nil
+}
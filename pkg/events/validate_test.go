@@ -0,0 +1,39 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		ev   Event
+		err  string
+	}{
+		{"dns", Event{Kind: "dns", Action: "block", Timestamp: now}, ""},
+		{"no timestamp", Event{Kind: "dns", Action: "allow"}, ""},
+		{"spooled", Event{Kind: "dns", Action: "allow", Timestamp: now.Add(-maxEventAge + time.Minute)}, ""},
+		{"slight skew", Event{Kind: "dns", Action: "allow", Timestamp: now.Add(maxClockSkew)}, ""},
+		{"future", Event{Kind: "dns", Action: "allow", Timestamp: now.Add(maxClockSkew + time.Second)}, "ahead of the server clock"},
+		{"too old", Event{Kind: "dns", Action: "allow", Timestamp: now.Add(-maxEventAge - time.Second)}, "older than"},
+		{"control", Event{Kind: "control", Action: "rule-expired"}, "unknown event kind"},
+		{"bad action", Event{Kind: "dns", Action: "rewrite"}, "invalid action"},
+		{"long domain", Event{Kind: "dns", Action: "allow", Domain: strings.Repeat("a", maxDomainLen+1)}, "domain longer"},
+		{"long reason", Event{Kind: "dns", Action: "allow", Reason: strings.Repeat("a", maxTextLen+1)}, "field longer"},
+		{"device without mac", Event{Kind: KindDeviceSeen, Device: &DeviceInfo{}}, "invalid device mac"},
+		{"flow without flow", Event{Kind: KindFlowEnd}, "without flow"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.ev, now)
+			if tc.err == "" && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Fatalf("err = %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
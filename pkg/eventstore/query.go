@@ -0,0 +1,137 @@
+package eventstore
+
+import (
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+// Query selects a page of events. Cursor is the ID of the last event of the
+// previous page; zero starts from the oldest (ascending) or newest
+// (descending) event.
+type Query struct {
+	Filter     events.Filter
+	Cursor     uint64
+	Limit      int
+	Descending bool
+}
+
+// Page is one result page; NextCursor is zero when no more events match.
+type Page struct {
+	Events     []events.Event
+	NextCursor uint64
+}
+
+// Query returns events matching q, skipping segments whose time range or
+// device index cannot contain matches.
+func (s *Store) Query(q Query) (Page, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+	segs := s.views()
+	candidates := make([]*segment, 0, len(segs))
+	for _, seg := range segs {
+		if segmentMayMatch(seg, q) {
+			candidates = append(candidates, seg)
+		}
+	}
+	if q.Descending {
+		return queryDescending(candidates, q)
+	}
+	return queryAscending(candidates, q)
+}
+
+func queryAscending(segs []*segment, q Query) (Page, error) {
+	out := make([]events.Event, 0, q.Limit)
+	more := false
+	for _, seg := range segs {
+		from := seg.First
+		if q.Cursor >= from {
+			from = q.Cursor + 1
+		}
+		offset := seg.offsetFor(from)
+		if !q.Filter.Since.IsZero() {
+			if byTime := seg.offsetForTime(q.Filter.Since); byTime > offset {
+				offset = byTime
+			}
+		}
+		err := readSegmentAt(seg, offset, from, func(ev events.Event) bool {
+			if !q.Filter.Match(ev) {
+				return true
+			}
+			if len(out) == q.Limit {
+				more = true
+				return false
+			}
+			out = append(out, ev)
+			return true
+		})
+		if err != nil {
+			return Page{}, err
+		}
+		if more {
+			break
+		}
+	}
+	return page(out, more), nil
+}
+
+func queryDescending(segs []*segment, q Query) (Page, error) {
+	out := make([]events.Event, 0, q.Limit)
+	more := false
+	for i := len(segs) - 1; i >= 0 && !more; i-- {
+		err := readBackward(segs[i], q.Cursor, q.Filter.Since, func(block []events.Event) bool {
+			for j := len(block) - 1; j >= 0; j-- {
+				if !q.Filter.Match(block[j]) {
+					continue
+				}
+				if len(out) == q.Limit {
+					more = true
+					return false
+				}
+				out = append(out, block[j])
+			}
+			return true
+		})
+		if err != nil {
+			return Page{}, err
+		}
+	}
+	return page(out, more), nil
+}
+
+func segmentMayMatch(seg *segment, q Query) bool {
+	if seg.Count == 0 {
+		return false
+	}
+	if q.Cursor != 0 {
+		if q.Descending && seg.First >= q.Cursor {
+			return false
+		}
+		if !q.Descending && seg.Last <= q.Cursor {
+			return false
+		}
+	}
+	f := q.Filter
+	if !f.Since.IsZero() && seg.MaxTime.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !seg.MinTime.Before(f.Until) {
+		return false
+	}
+	if len(f.Devices) > 0 {
+		for _, d := range f.Devices {
+			if seg.hasDevice(d) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func page(out []events.Event, more bool) Page {
+	p := Page{Events: out}
+	if more && len(out) > 0 {
+		p.NextCursor = out[len(out)-1].ID
+	}
+	return p
+}
@@ -0,0 +1,65 @@
+package eventstore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+// TestQueryDescendingPages pages newest-first through several segments and
+// sparse blocks and checks every matching event comes back exactly once.
+func TestQueryDescendingPages(t *testing.T) {
+	s, err := Open(Options{Dir: t.TempDir(), SegmentBytes: 32 << 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	const total = 1000
+	for i := 0; i < total; i++ {
+		domain := "other.example"
+		if i%3 == 0 {
+			domain = fmt.Sprintf("match%d.example", i)
+		}
+		appendAt(t, s, base.Add(time.Duration(i)*time.Second), domain)
+	}
+	if st := s.Stats(); st.Segments < 3 {
+		t.Fatalf("want several segments, got %+v", st)
+	}
+
+	q := Query{Filter: events.Filter{Domain: "match"}, Limit: 37, Descending: true}
+	var ids []uint64
+	for {
+		page, err := s.Query(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ev := range page.Events {
+			ids = append(ids, ev.ID)
+		}
+		if page.NextCursor == 0 {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	var want []uint64
+	for i := total - 1; i >= 0; i-- {
+		if i%3 == 0 {
+			want = append(want, uint64(i+1))
+		}
+	}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Fatalf("got %d ids %v..., want %d ids %v...", len(ids), ids[:5], len(want), want[:5])
+	}
+
+	since := base.Add((total - 100) * time.Second)
+	page, err := s.Query(Query{Filter: events.Filter{Since: since}, Limit: 500, Descending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Events) != 100 || page.Events[0].ID != total || page.NextCursor != 0 {
+		t.Fatalf("since page: %d events, first %d, next %d", len(page.Events), page.Events[0].ID, page.NextCursor)
+	}
+}
@@ -0,0 +1,192 @@
+package eventstore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+// Last returns up to n most recent events in ascending order.
+func (s *Store) Last(n int) ([]events.Event, error) {
+	if n <= 0 {
+		return []events.Event{}, nil
+	}
+	segs := s.views()
+	var chunks [][]events.Event
+	remaining := n
+	for i := len(segs) - 1; i >= 0 && remaining > 0; i-- {
+		seg := segs[i]
+		if seg.Count == 0 {
+			continue
+		}
+		from := seg.First
+		if seg.Last+1-seg.First > uint64(remaining) {
+			from = seg.Last + 1 - uint64(remaining)
+		}
+		chunk := make([]events.Event, 0, seg.Last+1-from)
+		err := readSegment(seg, from, func(ev events.Event) bool {
+			chunk = append(chunk, ev)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+		remaining -= len(chunk)
+	}
+	out := make([]events.Event, 0, n-remaining)
+	for i := len(chunks) - 1; i >= 0; i-- {
+		out = append(out, chunks[i]...)
+	}
+	return out, nil
+}
+
+// Scan calls fn for every event with ID >= from in ascending order until fn
+// returns false.
+func (s *Store) Scan(from uint64, fn func(events.Event) bool) error {
+	for _, seg := range s.views() {
+		if seg.Count == 0 || seg.Last < from {
+			continue
+		}
+		stopped := false
+		err := readSegment(seg, from, func(ev events.Event) bool {
+			if !fn(ev) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if stopped {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ScanSince calls fn for every event at or after ts in ascending order until
+// fn returns false. Segments ending before ts are skipped without reading.
+func (s *Store) ScanSince(ts time.Time, fn func(events.Event) bool) error {
+	for _, seg := range s.views() {
+		if seg.Count == 0 || seg.MaxTime.Before(ts) {
+			continue
+		}
+		stopped := false
+		err := readSegmentAt(seg, seg.offsetForTime(ts), seg.First, func(ev events.Event) bool {
+			if ev.Timestamp.Before(ts) {
+				return true
+			}
+			if !fn(ev) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if stopped {
+			return nil
+		}
+	}
+	return nil
+}
+
+// LastID returns the ID of the most recently appended event.
+func (s *Store) LastID() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.nextSeq - 1
+}
+
+func (s *Store) views() []*segment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]*segment, len(s.segments))
+	for i, seg := range s.segments {
+		out[i] = seg.view()
+	}
+	return out
+}
+
+// readSegment decodes records with sequence >= from, stopping at the size
+// captured in the view so concurrent appends are never half-read.
+func readSegment(seg *segment, from uint64, fn func(events.Event) bool) error {
+	return readSegmentAt(seg, seg.offsetFor(from), from, fn)
+}
+
+func readSegmentAt(seg *segment, offset int64, from uint64, fn func(events.Event) bool) error {
+	f, err := os.Open(seg.logPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Removed by retention while we were reading.
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	_, err = decodeRecords(f, offset, seg.Size, from, fn)
+	return err
+}
+
+// readBackward decodes seg one sparse block at a time, newest block first,
+// and passes each block's events with IDs below before (any when zero) to fn
+// in ascending order until fn returns false. It stops at blocks holding only
+// events older than since, so a page costs a few blocks rather than the
+// whole segment.
+func readBackward(seg *segment, before uint64, since time.Time, fn func([]events.Event) bool) error {
+	f, err := os.Open(seg.logPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	end, maxTime := seg.Size, seg.MaxTime
+	block := make([]events.Event, 0, sparseEvery)
+	for k := len(seg.Sparse) - 1; k >= 0; k-- {
+		entry := seg.Sparse[k]
+		if !since.IsZero() && maxTime.Before(since) {
+			return nil
+		}
+		if before == 0 || entry.Seq < before {
+			block = block[:0]
+			_, err := decodeRecords(f, entry.Offset, end, 0, func(ev events.Event) bool {
+				if before == 0 || ev.ID < before {
+					block = append(block, ev)
+				}
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			if !fn(block) {
+				return nil
+			}
+		}
+		end, maxTime = entry.Offset, entry.MaxBefore
+	}
+	return nil
+}
+
+// decodeRecords calls fn for each decodable event with sequence >= from
+// between offset and limit.
+func decodeRecords(f *os.File, offset, limit int64, from uint64, fn func(events.Event) bool) (int64, error) {
+	return scanRecords(f, offset, limit, func(seq uint64, _ int64, _ int64, payload []byte) bool {
+		if seq < from {
+			return true
+		}
+		var ev events.Event
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			return true
+		}
+		ev.ID = seq
+		return fn(ev)
+	})
+}
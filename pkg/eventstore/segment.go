@@ -0,0 +1,297 @@
+package eventstore
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/fsutil"
+)
+
+const (
+	segmentExt = ".log"
+	indexExt   = ".idx"
+	// headerLen covers payload length, CRC and sequence number.
+	headerLen = 16
+	// maxRecord rejects corrupt length prefixes before allocating.
+	maxRecord = 4 << 20
+	// sparseEvery controls how many records share one offset index entry.
+	sparseEvery = 64
+	// indexVersion changes whenever the sidecar index format does; older
+	// indexes are rebuilt from the log.
+	indexVersion = 2
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var errCorrupt = errors.New("corrupt record")
+
+// segment describes one append-only log file. Sealed segments persist this
+// metadata in a sidecar index; the active segment is rebuilt on open.
+type segment struct {
+	Version int           `json:"version"`
+	First   uint64        `json:"first"`
+	Last    uint64        `json:"last"`
+	Count   int           `json:"count"`
+	Size    int64         `json:"size"`
+	Created time.Time     `json:"created"`
+	MinTime time.Time     `json:"minTime"`
+	MaxTime time.Time     `json:"maxTime"`
+	Devices []string      `json:"devices"`
+	Sparse  []sparseEntry `json:"sparse"`
+
+	dir     string
+	devices map[string]struct{}
+}
+
+// sparseEntry maps a sequence number to its byte offset. MaxBefore is the
+// latest timestamp of any record before it: collectors replaying a spool
+// append events with old timestamps, so record times alone are not ordered,
+// but this high-water mark is.
+type sparseEntry struct {
+	Seq       uint64    `json:"seq"`
+	Offset    int64     `json:"offset"`
+	MaxBefore time.Time `json:"maxBefore"`
+}
+
+func segmentName(first uint64) string {
+	return fmt.Sprintf("%020d", first)
+}
+
+func (s *segment) logPath() string {
+	return filepath.Join(s.dir, segmentName(s.First)+segmentExt)
+}
+
+func (s *segment) indexPath() string {
+	return filepath.Join(s.dir, segmentName(s.First)+indexExt)
+}
+
+// add updates metadata for a record appended at offset.
+func (s *segment) add(seq uint64, offset, size int64, ev *events.Event) {
+	if s.Count%sparseEvery == 0 {
+		s.Sparse = append(s.Sparse, sparseEntry{Seq: seq, Offset: offset, MaxBefore: s.MaxTime})
+	}
+	s.Count++
+	s.Last = seq
+	s.Size = offset + size
+	if s.MinTime.IsZero() || ev.Timestamp.Before(s.MinTime) {
+		s.MinTime = ev.Timestamp
+	}
+	if ev.Timestamp.After(s.MaxTime) {
+		s.MaxTime = ev.Timestamp
+	}
+	if s.devices == nil {
+		s.devices = make(map[string]struct{})
+	}
+	for _, d := range deviceKeys(ev) {
+		if _, ok := s.devices[d]; !ok {
+			s.devices[d] = struct{}{}
+			s.Devices = append(s.Devices, d)
+		}
+	}
+}
+
+// hasDevice reports whether any record in the segment involves device.
+func (s *segment) hasDevice(device string) bool {
+	_, ok := s.devices[device]
+	return ok
+}
+
+// offsetFor returns the offset of the last sparse entry at or before seq.
+func (s *segment) offsetFor(seq uint64) int64 {
+	i := sort.Search(len(s.Sparse), func(i int) bool { return s.Sparse[i].Seq > seq })
+	if i == 0 {
+		return 0
+	}
+	return s.Sparse[i-1].Offset
+}
+
+// offsetForTime returns an offset from which all records at or after ts are
+// reachable, whatever order they were appended in.
+func (s *segment) offsetForTime(ts time.Time) int64 {
+	i := sort.Search(len(s.Sparse), func(i int) bool { return !s.Sparse[i].MaxBefore.Before(ts) })
+	if i == 0 {
+		return 0
+	}
+	return s.Sparse[i-1].Offset
+}
+
+// view returns a copy safe to read without holding the store lock.
+func (s *segment) view() *segment {
+	cp := *s
+	cp.Sparse = s.Sparse[:len(s.Sparse):len(s.Sparse)]
+	cp.Devices = s.Devices[:len(s.Devices):len(s.Devices)]
+	cp.devices = make(map[string]struct{}, len(s.devices))
+	for d := range s.devices {
+		cp.devices[d] = struct{}{}
+	}
+	return &cp
+}
+
+func (s *segment) writeIndex() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(s.indexPath(), data, 0o640)
+}
+
+func (s *segment) remove() {
+	_ = os.Remove(s.logPath())
+	_ = os.Remove(s.indexPath())
+}
+
+// loadIndex reads a sealed segment's sidecar index, rejecting stale ones.
+func loadIndex(dir string, first uint64, size int64) (*segment, error) {
+	seg := &segment{First: first, dir: dir}
+	data, err := os.ReadFile(seg.indexPath())
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, seg); err != nil {
+		return nil, err
+	}
+	if seg.Version != indexVersion || seg.First != first || seg.Size != size {
+		return nil, fmt.Errorf("stale index for segment %d", first)
+	}
+	seg.dir = dir
+	seg.devices = make(map[string]struct{}, len(seg.Devices))
+	for _, d := range seg.Devices {
+		seg.devices[d] = struct{}{}
+	}
+	return seg, nil
+}
+
+// rebuildSegment scans a log file, truncating any torn or corrupt tail.
+func rebuildSegment(dir string, first uint64, created time.Time) (*segment, error) {
+	seg := &segment{Version: indexVersion, First: first, Last: first - 1, Created: created, dir: dir}
+	f, err := os.OpenFile(seg.logPath(), os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	valid, err := scanRecords(f, 0, -1, func(seq uint64, offset int64, size int64, payload []byte) bool {
+		var ev events.Event
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			return true
+		}
+		seg.add(seq, offset, size, &ev)
+		return true
+	})
+	if err != nil && !errors.Is(err, errCorrupt) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	seg.Size = valid
+	if info, statErr := f.Stat(); statErr == nil && info.Size() > valid {
+		if err := f.Truncate(valid); err != nil {
+			return nil, fmt.Errorf("truncate segment %d: %w", first, err)
+		}
+	}
+	return seg, nil
+}
+
+// scanRecords reads records starting at offset until limit bytes (or EOF when
+// limit < 0). It returns the offset just past the last valid record.
+func scanRecords(f *os.File, offset, limit int64, fn func(seq uint64, offset, size int64, payload []byte) bool) (int64, error) {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+	r := bufio.NewReaderSize(f, 64*1024)
+	var hdr [headerLen]byte
+	var payload []byte
+	for limit < 0 || offset < limit {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return offset, nil
+			}
+			return offset, err
+		}
+		n := binary.LittleEndian.Uint32(hdr[0:4])
+		sum := binary.LittleEndian.Uint32(hdr[4:8])
+		if n == 0 || n > maxRecord {
+			return offset, errCorrupt
+		}
+		if cap(payload) < int(n) {
+			payload = make([]byte, n)
+		}
+		payload = payload[:n]
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return offset, err
+		}
+		crc := crc32.Update(crc32.Checksum(hdr[8:16], crcTable), crcTable, payload)
+		if crc != sum {
+			return offset, errCorrupt
+		}
+		size := int64(headerLen) + int64(n)
+		if !fn(binary.LittleEndian.Uint64(hdr[8:16]), offset, size, payload) {
+			return offset + size, nil
+		}
+		offset += size
+	}
+	return offset, nil
+}
+
+// encodeRecord frames a payload with its length, checksum and sequence number.
+func encodeRecord(seq uint64, payload []byte) []byte {
+	buf := make([]byte, headerLen+len(payload))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint64(buf[8:16], seq)
+	copy(buf[headerLen:], payload)
+	crc := crc32.Update(crc32.Checksum(buf[8:16], crcTable), crcTable, payload)
+	binary.LittleEndian.PutUint32(buf[4:8], crc)
+	return buf
+}
+
+// listSegments returns the first sequence numbers of log files in dir, ascending.
+func listSegments(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var firsts []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		first, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		firsts = append(firsts, first)
+	}
+	sort.Slice(firsts, func(i, j int) bool { return firsts[i] < firsts[j] })
+	return firsts, nil
+}
+
+// deviceKeys lists the addresses an event is indexed under.
+func deviceKeys(ev *events.Event) []string {
+	keys := make([]string, 0, 2+len(ev.PairCounts))
+	if ev.SourceIP != "" {
+		keys = append(keys, ev.SourceIP)
+	}
+	if ev.DestinationIP != "" && ev.DestinationIP != ev.SourceIP {
+		keys = append(keys, ev.DestinationIP)
+	}
+	for _, pc := range ev.PairCounts {
+		if pc.Internal != "" {
+			keys = append(keys, pc.Internal)
+		}
+	}
+	if ev.Device != nil && ev.Device.MAC != "" {
+		keys = append(keys, ev.Device.MAC)
+	}
+	return keys
+}
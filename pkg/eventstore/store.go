@@ -0,0 +1,288 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+// Options controls segment rolling and retention.
+type Options struct {
+	Dir             string
+	SegmentBytes    int64
+	SegmentDuration time.Duration
+	MaxAge          time.Duration
+	MaxBytes        int64
+	SyncInterval    time.Duration
+}
+
+// Store is an embedded append-only event log split into segment files.
+// Records carry a CRC so a torn write after a crash is detected and the
+// damaged tail truncated on the next open.
+type Store struct {
+	mu       sync.RWMutex
+	opts     Options
+	segments []*segment
+	active   *os.File
+	nextSeq  uint64
+	dirty    bool
+	now      func() time.Time
+}
+
+// Open loads or creates the store in opts.Dir.
+func Open(opts Options) (*Store, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("event store dir required")
+	}
+	if opts.SegmentBytes <= 0 {
+		opts.SegmentBytes = 16 << 20
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = time.Second
+	}
+	if err := os.MkdirAll(opts.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create event store dir: %w", err)
+	}
+
+	s := &Store{opts: opts, now: time.Now}
+	firsts, err := listSegments(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("list segments: %w", err)
+	}
+	for i, first := range firsts {
+		path := (&segment{First: first, dir: opts.Dir}).logPath()
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat segment: %w", err)
+		}
+		sealed := i < len(firsts)-1
+		var seg *segment
+		if sealed {
+			seg, err = loadIndex(opts.Dir, first, info.Size())
+		}
+		if seg == nil {
+			seg, err = rebuildSegment(opts.Dir, first, info.ModTime())
+			if err != nil {
+				return nil, fmt.Errorf("rebuild segment %d: %w", first, err)
+			}
+			if sealed {
+				if err := seg.writeIndex(); err != nil {
+					logging.Errorf("write segment index: %v", err)
+				}
+			}
+		}
+		s.segments = append(s.segments, seg)
+	}
+
+	if len(s.segments) == 0 {
+		s.nextSeq = 1
+		if err := s.createSegment(); err != nil {
+			return nil, err
+		}
+	} else {
+		last := s.segments[len(s.segments)-1]
+		s.nextSeq = last.Last + 1
+		f, err := os.OpenFile(last.logPath(), os.O_WRONLY|os.O_APPEND, 0o640)
+		if err != nil {
+			return nil, fmt.Errorf("open active segment: %w", err)
+		}
+		s.active = f
+	}
+	s.enforceRetentionLocked()
+	return s, nil
+}
+
+// Append persists ev and assigns its sequence ID.
+func (s *Store) Append(ev *events.Event) error {
+	return s.AppendBatch([]*events.Event{ev})
+}
+
+// AppendBatch persists evs with consecutive sequence IDs in a single write:
+// either all of them are stored or none is.
+func (s *Store) AppendBatch(evs []*events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		return errors.New("event store closed")
+	}
+	if s.shouldRollLocked() {
+		if err := s.rollLocked(); err != nil {
+			return err
+		}
+	}
+
+	recs := make([][]byte, len(evs))
+	var buf []byte
+	for i, ev := range evs {
+		ev.ID = s.nextSeq + uint64(i)
+		payload, err := json.Marshal(ev)
+		if err != nil {
+			for _, ev := range evs {
+				ev.ID = 0
+			}
+			return fmt.Errorf("encode event: %w", err)
+		}
+		recs[i] = encodeRecord(ev.ID, payload)
+		buf = append(buf, recs[i]...)
+	}
+	seg := s.segments[len(s.segments)-1]
+	offset := seg.Size
+	if _, err := s.active.Write(buf); err != nil {
+		// Drop any partial record so the next append starts on a boundary.
+		_ = s.active.Truncate(offset)
+		for _, ev := range evs {
+			ev.ID = 0
+		}
+		return fmt.Errorf("append event: %w", err)
+	}
+	for i, ev := range evs {
+		seg.add(ev.ID, offset, int64(len(recs[i])), ev)
+		offset += int64(len(recs[i]))
+	}
+	s.nextSeq += uint64(len(evs))
+	s.dirty = true
+	return nil
+}
+
+// Run periodically syncs the active segment, rolls it by age and applies
+// retention until ctx is done.
+func (s *Store) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			if s.active != nil {
+				if s.dirty {
+					if err := s.active.Sync(); err != nil {
+						logging.Errorf("sync event store: %v", err)
+					}
+					s.dirty = false
+				}
+				if s.shouldRollLocked() {
+					if err := s.rollLocked(); err != nil {
+						logging.Errorf("roll event segment: %v", err)
+					}
+				}
+				s.enforceRetentionLocked()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close syncs and closes the active segment.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		return nil
+	}
+	syncErr := s.active.Sync()
+	closeErr := s.active.Close()
+	s.active = nil
+	return errors.Join(syncErr, closeErr)
+}
+
+// Stats summarises the store's on-disk footprint.
+type Stats struct {
+	Segments int       `json:"segments"`
+	Events   int       `json:"events"`
+	Bytes    int64     `json:"bytes"`
+	FirstID  uint64    `json:"firstId"`
+	LastID   uint64    `json:"lastId"`
+	Oldest   time.Time `json:"oldest"`
+}
+
+// Stats reports segment counts and sizes.
+func (s *Store) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st := Stats{Segments: len(s.segments), LastID: s.nextSeq - 1}
+	for _, seg := range s.segments {
+		st.Events += seg.Count
+		st.Bytes += seg.Size
+		if seg.Count > 0 && st.FirstID == 0 {
+			st.FirstID = seg.First
+			st.Oldest = seg.MinTime
+		}
+	}
+	return st
+}
+
+func (s *Store) shouldRollLocked() bool {
+	seg := s.segments[len(s.segments)-1]
+	if seg.Count == 0 {
+		return false
+	}
+	if seg.Size >= s.opts.SegmentBytes {
+		return true
+	}
+	return s.opts.SegmentDuration > 0 && s.now().Sub(seg.Created) >= s.opts.SegmentDuration
+}
+
+// rollLocked seals the active segment and starts a new one.
+func (s *Store) rollLocked() error {
+	seg := s.segments[len(s.segments)-1]
+	if err := s.active.Sync(); err != nil {
+		return fmt.Errorf("sync segment: %w", err)
+	}
+	if err := s.active.Close(); err != nil {
+		return fmt.Errorf("close segment: %w", err)
+	}
+	s.active = nil
+	s.dirty = false
+	if err := seg.writeIndex(); err != nil {
+		logging.Errorf("write segment index: %v", err)
+	}
+	if err := s.createSegment(); err != nil {
+		return err
+	}
+	s.enforceRetentionLocked()
+	return nil
+}
+
+func (s *Store) createSegment() error {
+	seg := &segment{Version: indexVersion, First: s.nextSeq, Last: s.nextSeq - 1, Created: s.now().UTC(), dir: s.opts.Dir}
+	f, err := os.OpenFile(seg.logPath(), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("create segment: %w", err)
+	}
+	s.segments = append(s.segments, seg)
+	s.active = f
+	return nil
+}
+
+// enforceRetentionLocked drops the oldest sealed segments beyond the age and
+// size limits. The active segment is never removed.
+func (s *Store) enforceRetentionLocked() {
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.Size
+	}
+	cutoff := time.Time{}
+	if s.opts.MaxAge > 0 {
+		cutoff = s.now().Add(-s.opts.MaxAge)
+	}
+	for len(s.segments) > 1 {
+		oldest := s.segments[0]
+		expired := !cutoff.IsZero() && oldest.MaxTime.Before(cutoff)
+		oversize := s.opts.MaxBytes > 0 && total > s.opts.MaxBytes
+		if !expired && !oversize {
+			return
+		}
+		oldest.remove()
+		total -= oldest.Size
+		s.segments = s.segments[1:]
+	}
+}
@@ -0,0 +1,150 @@
+package eventstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func appendAt(t *testing.T, s *Store, ts time.Time, domain string) {
+	t.Helper()
+	ev := events.Event{Kind: "dns", Timestamp: ts, Domain: domain}
+	if err := s.Append(&ev); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSinceWithReplayedEvents appends live events, then a spool replay with
+// old timestamps, then live events again. The replayed batch must not hide
+// the newer live events that precede it.
+func TestSinceWithReplayedEvents(t *testing.T) {
+	s := openTestStore(t)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return base.Add(time.Duration(i) * time.Second) }
+	for i := 0; i < 2*sparseEvery; i++ {
+		appendAt(t, s, at(1000+i), "live")
+	}
+	for i := 0; i < 2*sparseEvery; i++ {
+		appendAt(t, s, at(i), "replayed")
+	}
+	for i := 2 * sparseEvery; i < 4*sparseEvery; i++ {
+		appendAt(t, s, at(1000+i), "live")
+	}
+
+	since := at(1000 + sparseEvery + 10)
+	want := 4*sparseEvery - (sparseEvery + 10)
+	var got int
+	err := s.ScanSince(since, func(ev events.Event) bool {
+		if ev.Timestamp.Before(since) {
+			t.Fatalf("event %d at %v is before %v", ev.ID, ev.Timestamp, since)
+		}
+		got++
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("ScanSince found %d events, want %d", got, want)
+	}
+
+	page, err := s.Query(Query{Filter: events.Filter{Since: since}, Limit: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Events) != want {
+		t.Fatalf("Query found %d events, want %d", len(page.Events), want)
+	}
+
+	page, err = s.Query(Query{Filter: events.Filter{Since: at(10), Domain: "replayed"}, Limit: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Events) != 2*sparseEvery-10 {
+		t.Fatalf("Query found %d replayed events, want %d", len(page.Events), 2*sparseEvery-10)
+	}
+}
+
+func TestOffsetForTimeSkipsOlderRecords(t *testing.T) {
+	s := openTestStore(t)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4*sparseEvery; i++ {
+		appendAt(t, s, base.Add(time.Duration(i)*time.Second), "live")
+	}
+	seg := s.views()[0]
+	if off := seg.offsetForTime(base.Add(3 * sparseEvery * time.Second)); off == 0 {
+		t.Fatal("ordered segment is scanned from the start")
+	}
+	if off := seg.offsetForTime(base); off != 0 {
+		t.Fatalf("offset = %d for the first timestamp, want 0", off)
+	}
+}
+
+func TestReopenRebuildsIndex(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(Options{Dir: dir, SegmentBytes: 4096})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 200; i++ {
+		appendAt(t, s, base.Add(time.Duration(i)*time.Second), "live")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(Options{Dir: dir, SegmentBytes: 4096})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if st := s.Stats(); st.Events != 200 || st.Segments < 2 {
+		t.Fatalf("stats after reopen = %+v", st)
+	}
+	var n int
+	if err := s.ScanSince(base.Add(150*time.Second), func(events.Event) bool { n++; return true }); err != nil {
+		t.Fatal(err)
+	}
+	if n != 50 {
+		t.Fatalf("ScanSince after reopen found %d events, want 50", n)
+	}
+}
+
+func TestAppendBatch(t *testing.T) {
+	s := openTestStore(t)
+	appendAt(t, s, time.Now(), "first")
+	batch := []*events.Event{{Kind: "dns", Domain: "a"}, {Kind: "dns", Domain: "b"}, {Kind: "dns", Domain: "c"}}
+	if err := s.AppendBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	for i, ev := range batch {
+		if ev.ID != uint64(i+2) {
+			t.Fatalf("event %d got ID %d, want %d", i, ev.ID, i+2)
+		}
+	}
+	got, err := s.Last(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 || got[1].Domain != "a" || got[3].Domain != "c" {
+		t.Fatalf("stored %+v", got)
+	}
+
+	_ = s.Close()
+	ev := events.Event{Kind: "dns"}
+	if err := s.AppendBatch([]*events.Event{&ev}); err == nil || ev.ID != 0 {
+		t.Fatalf("append to closed store: err %v, ID %d", err, ev.ID)
+	}
+}
@@ -0,0 +1,205 @@
+package pause
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/fsutil"
+)
+
+// ErrInvalidAddr reports a device that is neither a MAC nor an IPv4 address.
+var ErrInvalidAddr = errors.New("device must be a MAC or IPv4 address")
+
+// Entry pauses one device or every device of a profile. Addrs holds the MAC
+// and IPv4 addresses whose traffic is cut off.
+type Entry struct {
+	Device  string     `json:"device,omitempty"`
+	Profile string     `json:"profile,omitempty"`
+	Addrs   []string   `json:"addrs"`
+	By      string     `json:"by,omitempty"`
+	Since   time.Time  `json:"since"`
+	Until   *time.Time `json:"until,omitempty"`
+}
+
+// Key identifies the entry: "device:<addr>" or "profile:<name>".
+func (e Entry) Key() string {
+	if e.Profile != "" {
+		return "profile:" + e.Profile
+	}
+	return "device:" + e.Device
+}
+
+// Expired reports whether the entry resumed automatically at or before now.
+func (e Entry) Expired(now time.Time) bool {
+	return e.Until != nil && !e.Until.After(now)
+}
+
+type stateFile struct {
+	Entries []Entry `json:"entries"`
+}
+
+// State holds the active pauses and persists them to a file the inspector
+// watches.
+type State struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]Entry
+	now     func() time.Time
+}
+
+// Open loads the pause state at path; a missing file means nothing is paused.
+func Open(path string) (*State, error) {
+	entries, err := ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	s := &State{path: path, entries: make(map[string]Entry, len(entries)), now: time.Now}
+	for _, e := range entries {
+		s.entries[e.Key()] = e
+	}
+	return s, nil
+}
+
+// Pause stores e, replacing an entry with the same key, and reports whether
+// it is new.
+func (s *State) Pause(e Entry) (bool, error) {
+	addrs := make([]string, len(e.Addrs))
+	for i, a := range e.Addrs {
+		c, err := Canonical(a)
+		if err != nil {
+			return false, err
+		}
+		addrs[i] = c
+	}
+	e.Addrs = addrs
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.Since.IsZero() {
+		e.Since = s.now().UTC()
+	}
+	_, exists := s.entries[e.Key()]
+	s.entries[e.Key()] = e
+	return !exists, s.save()
+}
+
+// Resume removes the entry with key.
+func (s *State) Resume(key string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return Entry{}, false, nil
+	}
+	delete(s.entries, key)
+	return e, true, s.save()
+}
+
+// Expire removes and returns the entries whose resume time has passed.
+func (s *State) Expire() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var out []Entry
+	for key, e := range s.entries {
+		if e.Expired(now) {
+			out = append(out, e)
+			delete(s.entries, key)
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	sortEntries(out)
+	return out, s.save()
+}
+
+// List returns the active entries sorted by key.
+func (s *State) List() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, e)
+	}
+	sortEntries(out)
+	return out
+}
+
+// Now returns the state's current time.
+func (s *State) Now() time.Time {
+	return s.now()
+}
+
+func (s *State) save() error {
+	f := stateFile{Entries: make([]Entry, 0, len(s.entries))}
+	for _, e := range s.entries {
+		f.Entries = append(f.Entries, e)
+	}
+	sortEntries(f.Entries)
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialize pause state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create pause dir: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write pause state: %w", err)
+	}
+	return nil
+}
+
+// ReadFile returns the entries stored at path.
+func ReadFile(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read pause state: %w", err)
+	}
+	var f stateFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse pause state: %w", err)
+	}
+	return f.Entries, nil
+}
+
+// Targets collects the MAC and IPv4 addresses of entries still in force.
+func Targets(entries []Entry, now time.Time) (map[[6]byte]bool, map[[4]byte]bool) {
+	macs := make(map[[6]byte]bool)
+	ips := make(map[[4]byte]bool)
+	for _, e := range entries {
+		if e.Expired(now) {
+			continue
+		}
+		for _, a := range e.Addrs {
+			if mac, err := net.ParseMAC(a); err == nil && len(mac) == 6 {
+				macs[[6]byte(mac)] = true
+			} else if ip, err := netip.ParseAddr(a); err == nil && ip.Is4() {
+				ips[ip.As4()] = true
+			}
+		}
+	}
+	return macs, ips
+}
+
+// Canonical validates a device address and returns its canonical form.
+func Canonical(a string) (string, error) {
+	if mac, err := net.ParseMAC(a); err == nil && len(mac) == 6 {
+		return mac.String(), nil
+	}
+	if ip, err := netip.ParseAddr(a); err == nil && ip.Is4() {
+		return ip.String(), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidAddr, a)
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key() < entries[j].Key() })
+}
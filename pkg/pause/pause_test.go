@@ -0,0 +1,134 @@
+package pause
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pause", "pause.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	until := now.Add(30 * time.Minute)
+
+	created, err := s.Pause(Entry{Device: "aa:bb:cc:dd:ee:ff", Addrs: []string{"AA-BB-CC-DD-EE-FF", "10.0.0.2"}, Until: &until})
+	if err != nil || !created {
+		t.Fatalf("pause: created %v, err %v", created, err)
+	}
+	if created, err := s.Pause(Entry{Profile: "kid1", Addrs: []string{"11:22:33:44:55:66"}}); err != nil || !created {
+		t.Fatalf("pause profile: created %v, err %v", created, err)
+	}
+	// Pausing the same key again replaces the entry.
+	if created, err := s.Pause(Entry{Profile: "kid1", Addrs: []string{"11:22:33:44:55:67"}, By: "admin"}); err != nil || created {
+		t.Fatalf("pause again: created %v, err %v", created, err)
+	}
+	if _, err := s.Pause(Entry{Device: "x", Addrs: []string{"not an address"}}); !errors.Is(err, ErrInvalidAddr) {
+		t.Fatalf("invalid address: err = %v", err)
+	}
+
+	entries, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Key() != "device:aa:bb:cc:dd:ee:ff" || entries[1].Key() != "profile:kid1" {
+		t.Fatalf("stored entries %+v", entries)
+	}
+	if got := entries[0].Addrs; !reflect.DeepEqual(got, []string{"aa:bb:cc:dd:ee:ff", "10.0.0.2"}) {
+		t.Fatalf("addresses not canonical: %v", got)
+	}
+	if !entries[0].Since.Equal(now) || !entries[0].Until.Equal(until) || entries[1].By != "admin" {
+		t.Fatalf("stored entries %+v", entries)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.List(); !reflect.DeepEqual(got, entries) {
+		t.Fatalf("reopened %+v, want %+v", got, entries)
+	}
+
+	e, ok, err := reopened.Resume("profile:kid1")
+	if err != nil || !ok || e.Profile != "kid1" {
+		t.Fatalf("resume = %+v, %v, %v", e, ok, err)
+	}
+	if _, ok, err := reopened.Resume("profile:kid1"); ok || err != nil {
+		t.Fatalf("resume twice: %v, %v", ok, err)
+	}
+	if entries, err := ReadFile(path); err != nil || len(entries) != 1 {
+		t.Fatalf("after resume: %+v, %v", entries, err)
+	}
+}
+
+func TestStateExpire(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "pause.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	soon, later := now.Add(time.Minute), now.Add(time.Hour)
+	for _, e := range []Entry{
+		{Device: "10.0.0.2", Addrs: []string{"10.0.0.2"}, Until: &soon},
+		{Device: "10.0.0.3", Addrs: []string{"10.0.0.3"}, Until: &later},
+		{Device: "10.0.0.4", Addrs: []string{"10.0.0.4"}},
+	} {
+		if _, err := s.Pause(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if expired, err := s.Expire(); err != nil || len(expired) != 0 {
+		t.Fatalf("expired early: %+v, %v", expired, err)
+	}
+	now = soon
+	expired, err := s.Expire()
+	if err != nil || len(expired) != 1 || expired[0].Device != "10.0.0.2" {
+		t.Fatalf("expired = %+v, %v", expired, err)
+	}
+	if got := s.List(); len(got) != 2 {
+		t.Fatalf("remaining %+v", got)
+	}
+}
+
+func TestTargets(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Second), now.Add(time.Hour)
+	macs, ips := Targets([]Entry{
+		{Addrs: []string{"aa:bb:cc:dd:ee:ff", "10.0.0.2"}, Until: &future},
+		{Addrs: []string{"11:22:33:44:55:66", "10.0.0.3"}, Until: &past},
+		{Addrs: []string{"10.0.0.4", "fe80::1", "garbage"}},
+	}, now)
+	wantMACs := map[[6]byte]bool{{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}: true}
+	wantIPs := map[[4]byte]bool{{10, 0, 0, 2}: true, {10, 0, 0, 4}: true}
+	if !reflect.DeepEqual(macs, wantMACs) || !reflect.DeepEqual(ips, wantIPs) {
+		t.Fatalf("targets = %v, %v", macs, ips)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	for in, want := range map[string]string{
+		"AA:BB:CC:DD:EE:FF": "aa:bb:cc:dd:ee:ff",
+		"aa-bb-cc-dd-ee-ff": "aa:bb:cc:dd:ee:ff",
+		"aabb.ccdd.eeff":    "aa:bb:cc:dd:ee:ff",
+		"10.0.0.2":          "10.0.0.2",
+		"fe80::1":           "",
+		"::ffff:10.0.0.2":   "",
+		"00:00:00:00:fe:80:00:00:00:00:00:00:00:00:00:00:00:00:00:00": "",
+		"kid-laptop": "",
+	} {
+		got, err := Canonical(in)
+		if got != want || (err == nil) != (want != "") {
+			t.Errorf("Canonical(%q) = %q, %v; want %q", in, got, err, want)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidAddr) {
+			t.Errorf("Canonical(%q) error %v is not ErrInvalidAddr", in, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package resolver
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// negativeTTL bounds caching of NXDOMAIN/NODATA answers lacking an SOA.
+const negativeTTL = 60 * time.Second
+
+// Cache is an LRU cache of upstream responses that honours record TTLs.
+type Cache struct {
+	mu      sync.Mutex
+	maxSize int
+	maxTTL  time.Duration
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	key     string
+	msg     *mdns.Msg
+	stored  time.Time
+	expires time.Time
+}
+
+// NewCache creates a cache holding up to size responses, capping TTLs at maxTTL
+// when positive.
+func NewCache(size int, maxTTL time.Duration) *Cache {
+	return &Cache{
+		maxSize: size,
+		maxTTL:  maxTTL,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// SetClock overrides the time source.
+func (c *Cache) SetClock(now func() time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+}
+
+// Get returns a copy of a cached response with TTLs reduced by its age.
+func (c *Cache) Get(q mdns.Question) (*mdns.Msg, bool) {
+	if c == nil || c.maxSize <= 0 {
+		return nil, false
+	}
+	key := cacheKey(q)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	now := c.now()
+	if !now.Before(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	msg := entry.msg.Copy()
+	age := uint32(now.Sub(entry.stored) / time.Second)
+	for _, section := range [][]mdns.RR{msg.Answer, msg.Ns, msg.Extra} {
+		for _, rr := range section {
+			hdr := rr.Header()
+			if hdr.Rrtype == mdns.TypeOPT {
+				continue
+			}
+			if hdr.Ttl > age {
+				hdr.Ttl -= age
+			} else {
+				hdr.Ttl = 0
+			}
+		}
+	}
+	return msg, true
+}
+
+// Put stores a cacheable upstream response.
+func (c *Cache) Put(q mdns.Question, msg *mdns.Msg) {
+	if c == nil || c.maxSize <= 0 || msg == nil || msg.Truncated {
+		return
+	}
+	ttl, ok := cacheTTL(msg)
+	if !ok || ttl <= 0 {
+		return
+	}
+	if c.maxTTL > 0 && ttl > c.maxTTL {
+		ttl = c.maxTTL
+	}
+	key := cacheKey(q)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	entry := &cacheEntry{key: key, msg: msg.Copy(), stored: now, expires: now.Add(ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len reports the number of cached responses.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// cacheTTL derives how long a response may be cached: the minimum answer TTL
+// for positive answers, the SOA minimum for negative ones.
+func cacheTTL(msg *mdns.Msg) (time.Duration, bool) {
+	switch msg.Rcode {
+	case mdns.RcodeSuccess, mdns.RcodeNameError:
+	default:
+		return 0, false
+	}
+	if msg.Rcode == mdns.RcodeSuccess && len(msg.Answer) > 0 {
+		minTTL := ^uint32(0)
+		for _, rr := range msg.Answer {
+			if ttl := rr.Header().Ttl; ttl < minTTL {
+				minTTL = ttl
+			}
+		}
+		return time.Duration(minTTL) * time.Second, true
+	}
+	for _, rr := range msg.Ns {
+		if soa, ok := rr.(*mdns.SOA); ok {
+			ttl := soa.Hdr.Ttl
+			if soa.Minttl < ttl {
+				ttl = soa.Minttl
+			}
+			return time.Duration(ttl) * time.Second, true
+		}
+	}
+	return negativeTTL, true
+}
+
+func cacheKey(q mdns.Question) string {
+	return strings.ToLower(q.Name) + "|" + strconv.Itoa(int(q.Qtype)) + "|" + strconv.Itoa(int(q.Qclass))
+}
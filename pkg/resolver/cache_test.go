@@ -0,0 +1,120 @@
+package resolver
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+func aResponse(name string, ttl uint32) (mdns.Question, *mdns.Msg) {
+	req := new(mdns.Msg)
+	req.SetQuestion(mdns.Fqdn(name), mdns.TypeA)
+	resp := new(mdns.Msg)
+	resp.SetReply(req)
+	resp.Answer = append(resp.Answer, &mdns.A{
+		Hdr: mdns.RR_Header{Name: req.Question[0].Name, Rrtype: mdns.TypeA, Class: mdns.ClassINET, Ttl: ttl},
+		A:   net.ParseIP("192.0.2.1"),
+	})
+	return req.Question[0], resp
+}
+
+func TestCacheTTL(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := NewCache(8, 0)
+	c.SetClock(func() time.Time { return now })
+
+	q, resp := aResponse("example.com", 60)
+	c.Put(q, resp)
+
+	now = now.Add(20 * time.Second)
+	got, ok := c.Get(q)
+	if !ok {
+		t.Fatal("entry missing before expiry")
+	}
+	if ttl := got.Answer[0].Header().Ttl; ttl != 40 {
+		t.Fatalf("ttl = %d, want 40 after 20s", ttl)
+	}
+
+	now = now.Add(40 * time.Second)
+	if _, ok := c.Get(q); ok {
+		t.Fatal("entry served after its ttl")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("len = %d, want expired entry dropped", c.Len())
+	}
+}
+
+func TestCacheMaxTTL(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := NewCache(8, 30*time.Second)
+	c.SetClock(func() time.Time { return now })
+
+	q, resp := aResponse("example.com", 3600)
+	c.Put(q, resp)
+	now = now.Add(31 * time.Second)
+	if _, ok := c.Get(q); ok {
+		t.Fatal("entry outlived maxTTL")
+	}
+}
+
+func TestCacheNegative(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := NewCache(8, 0)
+	c.SetClock(func() time.Time { return now })
+
+	req := new(mdns.Msg)
+	req.SetQuestion("missing.example.", mdns.TypeA)
+	resp := new(mdns.Msg)
+	resp.SetRcode(req, mdns.RcodeNameError)
+	resp.Ns = append(resp.Ns, &mdns.SOA{
+		Hdr:    mdns.RR_Header{Name: "example.", Rrtype: mdns.TypeSOA, Class: mdns.ClassINET, Ttl: 900},
+		Ns:     "ns.example.",
+		Mbox:   "host.example.",
+		Minttl: 10,
+	})
+	c.Put(req.Question[0], resp)
+	now = now.Add(9 * time.Second)
+	if _, ok := c.Get(req.Question[0]); !ok {
+		t.Fatal("negative answer not cached for the SOA minimum")
+	}
+	now = now.Add(time.Second)
+	if _, ok := c.Get(req.Question[0]); ok {
+		t.Fatal("negative answer outlived the SOA minimum")
+	}
+
+	fail := new(mdns.Msg)
+	fail.SetRcode(req, mdns.RcodeServerFailure)
+	q, _ := aResponse("fail.example", 60)
+	c.Put(q, fail)
+	if _, ok := c.Get(q); ok {
+		t.Fatal("SERVFAIL was cached")
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := NewCache(2, 0)
+	qa, a := aResponse("a.example", 60)
+	qb, b := aResponse("b.example", 60)
+	qc, cc := aResponse("c.example", 60)
+
+	c.Put(qa, a)
+	c.Put(qb, b)
+	if _, ok := c.Get(qa); !ok {
+		t.Fatal("a missing")
+	}
+	// b is now least recently used and goes first.
+	c.Put(qc, cc)
+	if _, ok := c.Get(qb); ok {
+		t.Fatal("least recently used entry was kept")
+	}
+	for _, q := range []mdns.Question{qa, qc} {
+		if _, ok := c.Get(q); !ok {
+			t.Fatalf("%s evicted", q.Name)
+		}
+	}
+	if c.Len() != 2 {
+		t.Fatalf("len = %d, want 2", c.Len())
+	}
+}
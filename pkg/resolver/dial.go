@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+)
+
+// bootstrapDialer connects to an upstream by fixed IPs so that resolving the
+// upstream's own hostname never depends on the resolver being configured.
+type bootstrapDialer struct {
+	port   string
+	ips    []string
+	next   atomic.Uint32
+	dialer net.Dialer
+}
+
+func newBootstrapDialer(port string, ips []string) *bootstrapDialer {
+	return &bootstrapDialer{port: port, ips: ips}
+}
+
+// DialContext dials addr directly when no bootstrap IPs are configured,
+// otherwise tries each bootstrap IP starting from a rotating offset.
+func (d *bootstrapDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if len(d.ips) == 0 {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+	port := d.port
+	if _, p, err := net.SplitHostPort(addr); err == nil {
+		port = p
+	}
+	start := int(d.next.Add(1))
+	var errs []error
+	for i := range d.ips {
+		ip := d.ips[(start+i)%len(d.ips)]
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// tlsConfig builds the client TLS settings for an encrypted upstream.
+func tlsConfig(serverName, caFile string, nextProtos []string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         serverName,
+		MinVersion:         tls.VersionTLS12,
+		NextProtos:         nextProtos,
+		ClientSessionCache: tls.NewLRUClientSessionCache(8),
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read upstream ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
@@ -0,0 +1,77 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+const dohMediaType = "application/dns-message"
+
+// dohUpstream speaks DNS-over-HTTPS (RFC 8484) over a keep-alive HTTP/2 client.
+type dohUpstream struct {
+	url    string
+	client *http.Client
+}
+
+func newDoHUpstream(url string, dialer *bootstrapDialer, cfg *tls.Config, timeout time.Duration) *dohUpstream {
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     cfg,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        maxIdleConns,
+		MaxIdleConnsPerHost: maxIdleConns,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: timeout,
+	}
+	return &dohUpstream{
+		url:    url,
+		client: &http.Client{Transport: transport, Timeout: timeout},
+	}
+}
+
+func (u *dohUpstream) Exchange(ctx context.Context, req *mdns.Msg) (*mdns.Msg, error) {
+	// RFC 8484 recommends ID 0 so identical queries are HTTP-cacheable.
+	out := req.Copy()
+	out.Id = 0
+	buf, err := out.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("pack query: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u.url, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", dohMediaType)
+	httpReq.Header.Set("Accept", dohMediaType)
+
+	resp, err := u.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("exchange with %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchange with %s: status %s", u, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, mdns.MaxMsgSize))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", u, err)
+	}
+	var msg mdns.Msg
+	if err := msg.Unpack(body); err != nil {
+		return nil, fmt.Errorf("unpack %s: %w", u, err)
+	}
+	msg.Id = req.Id
+	return &msg, nil
+}
+
+func (u *dohUpstream) String() string {
+	return u.url
+}
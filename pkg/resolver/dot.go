@@ -0,0 +1,113 @@
+package resolver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// maxIdleConns bounds the idle TLS connections kept per DoT upstream.
+const maxIdleConns = 4
+
+// dotUpstream speaks DNS-over-TLS (RFC 7858), reusing idle connections.
+type dotUpstream struct {
+	addr    string
+	dialer  *bootstrapDialer
+	tls     *tls.Config
+	timeout time.Duration
+	idle    chan *mdns.Conn
+}
+
+func newDoTUpstream(addr string, dialer *bootstrapDialer, cfg *tls.Config, timeout time.Duration) *dotUpstream {
+	return &dotUpstream{
+		addr:    addr,
+		dialer:  dialer,
+		tls:     cfg,
+		timeout: timeout,
+		idle:    make(chan *mdns.Conn, maxIdleConns),
+	}
+}
+
+func (u *dotUpstream) Exchange(ctx context.Context, req *mdns.Msg) (*mdns.Msg, error) {
+	conn, reused, err := u.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.roundTrip(ctx, conn, req)
+	if err != nil && reused {
+		// The server may have closed an idle connection; retry once on a fresh one.
+		conn, err = u.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = u.roundTrip(ctx, conn, req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("exchange with %s: %w", u, err)
+	}
+	u.release(conn)
+	return resp, nil
+}
+
+func (u *dotUpstream) roundTrip(ctx context.Context, conn *mdns.Conn, req *mdns.Msg) (*mdns.Msg, error) {
+	deadline := time.Now().Add(u.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+	if err := conn.WriteMsg(req); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp, err := conn.ReadMsg()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.Id != req.Id {
+		conn.Close()
+		return nil, mdns.ErrId
+	}
+	return resp, nil
+}
+
+func (u *dotUpstream) conn(ctx context.Context) (*mdns.Conn, bool, error) {
+	select {
+	case conn := <-u.idle:
+		return conn, true, nil
+	default:
+	}
+	conn, err := u.dial(ctx)
+	return conn, false, err
+}
+
+func (u *dotUpstream) dial(ctx context.Context) (*mdns.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	raw, err := u.dialer.DialContext(ctx, "tcp", u.addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", u, err)
+	}
+	tlsConn := tls.Client(raw, u.tls)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("tls handshake %s: %w", u, err)
+	}
+	return &mdns.Conn{Conn: tlsConn}, nil
+}
+
+func (u *dotUpstream) release(conn *mdns.Conn) {
+	_ = conn.SetDeadline(time.Time{})
+	select {
+	case u.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+func (u *dotUpstream) String() string {
+	return "tls://" + u.addr
+}
@@ -0,0 +1,178 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+// ErrNoUpstream is returned when no upstream answered a query.
+var ErrNoUpstream = errors.New("no upstream available")
+
+// failThreshold is the number of consecutive failures that marks an upstream down.
+const failThreshold = 3
+
+// UpstreamStatus reports the health of one pool member.
+type UpstreamStatus struct {
+	Upstream  string    `json:"upstream"`
+	Healthy   bool      `json:"healthy"`
+	Failures  int       `json:"failures"`
+	LastError string    `json:"lastError,omitempty"`
+	LastCheck time.Time `json:"lastCheck,omitempty"`
+}
+
+// Pool fails over between upstreams in configured order, preferring healthy ones.
+type Pool struct {
+	mu      sync.Mutex
+	members []*member
+}
+
+type member struct {
+	up        Upstream
+	healthy   bool
+	failures  int
+	lastErr   error
+	lastCheck time.Time
+}
+
+// NewPool creates a pool with every upstream initially considered healthy.
+func NewPool(upstreams []Upstream) *Pool {
+	p := &Pool{members: make([]*member, 0, len(upstreams))}
+	for _, up := range upstreams {
+		p.members = append(p.members, &member{up: up, healthy: true})
+	}
+	return p
+}
+
+// Exchange tries healthy upstreams first, then unhealthy ones as a last resort.
+// Each attempt gets an equal share of what is left of ctx's deadline, so a
+// hanging upstream cannot use up the budget of the ones after it.
+func (p *Pool) Exchange(ctx context.Context, req *mdns.Msg) (*mdns.Msg, error) {
+	lastErr := ErrNoUpstream
+	members := p.ordered()
+	for i, m := range members {
+		if ctx.Err() != nil {
+			break
+		}
+		attempt, cancel := attemptContext(ctx, len(members)-i)
+		resp, err := m.up.Exchange(attempt, req)
+		cancel()
+		p.record(m, err)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// attemptContext derives the context of one of remaining attempts.
+func attemptContext(ctx context.Context, remaining int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || remaining <= 1 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/time.Duration(remaining))
+}
+
+func (p *Pool) String() string {
+	return "pool"
+}
+
+// Status returns a snapshot of member health.
+func (p *Pool) Status() []UpstreamStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]UpstreamStatus, 0, len(p.members))
+	for _, m := range p.members {
+		st := UpstreamStatus{Upstream: m.up.String(), Healthy: m.healthy, Failures: m.failures, LastCheck: m.lastCheck}
+		if m.lastErr != nil {
+			st.LastError = m.lastErr.Error()
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// RunHealthChecks probes every upstream each interval until ctx is done.
+func (p *Pool) RunHealthChecks(ctx context.Context, interval, timeout time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.probeAll(ctx, timeout)
+		}
+	}
+}
+
+func (p *Pool) probeAll(ctx context.Context, timeout time.Duration) {
+	p.mu.Lock()
+	members := append([]*member(nil), p.members...)
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, m := range members {
+		wg.Add(1)
+		go func(m *member) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			probe := new(mdns.Msg)
+			probe.SetQuestion(".", mdns.TypeNS)
+			probe.RecursionDesired = true
+			resp, err := m.up.Exchange(probeCtx, probe)
+			if err == nil && resp.Rcode != mdns.RcodeSuccess {
+				err = errors.New(mdns.RcodeToString[resp.Rcode])
+			}
+			p.record(m, err)
+		}(m)
+	}
+	wg.Wait()
+}
+
+func (p *Pool) ordered() []*member {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]*member, 0, len(p.members))
+	for _, m := range p.members {
+		if m.healthy {
+			out = append(out, m)
+		}
+	}
+	for _, m := range p.members {
+		if !m.healthy {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (p *Pool) record(m *member, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m.lastCheck = time.Now().UTC()
+	if err == nil {
+		if !m.healthy {
+			logging.Infof("upstream %s healthy again", m.up)
+		}
+		m.healthy, m.failures, m.lastErr = true, 0, nil
+		return
+	}
+	m.failures++
+	m.lastErr = err
+	if m.healthy && m.failures >= failThreshold {
+		m.healthy = false
+		logging.Errorf("upstream %s marked down: %v", m.up, err)
+	}
+}
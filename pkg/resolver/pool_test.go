@@ -0,0 +1,223 @@
+package resolver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"github.com/kidos/kidosserver/pkg/config"
+)
+
+// startDoHServer runs a local DNS-over-HTTPS stand-in answering with h and
+// counts the TLS connections it accepts.
+func startDoHServer(t *testing.T, h mdns.HandlerFunc, conns *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil || r.Header.Get("Content-Type") != dohMediaType {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		req := new(mdns.Msg)
+		if err := req.Unpack(body); err != nil {
+			http.Error(w, "bad message", http.StatusBadRequest)
+			return
+		}
+		rw := &recorder{}
+		h(rw, req)
+		out, err := rw.msg.Pack()
+		if err != nil {
+			http.Error(w, "pack", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", dohMediaType)
+		_, _ = w.Write(out)
+	}))
+	srv.EnableHTTP2 = true
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew && conns != nil {
+			conns.Add(1)
+		}
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// startDoTServer runs a local DNS-over-TLS stand-in with the certificate of
+// cert and counts the connections it accepts.
+func startDoTServer(t *testing.T, cert tls.Certificate, h mdns.HandlerFunc, conns *atomic.Int32) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	srv := &mdns.Server{
+		Listener:          &countingListener{Listener: ln, n: conns},
+		Net:               "tcp-tls",
+		Handler:           h,
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go srv.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { _ = srv.Shutdown() })
+	return ln.Addr().String()
+}
+
+type countingListener struct {
+	net.Listener
+	n *atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err == nil && l.n != nil {
+		l.n.Add(1)
+	}
+	return c, err
+}
+
+// writeCA stores the test server's certificate for use as CAFile.
+func writeCA(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func exchangeA(t *testing.T, up Upstream, name string) *mdns.Msg {
+	t.Helper()
+	req := new(mdns.Msg)
+	req.SetQuestion(mdns.Fqdn(name), mdns.TypeA)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	resp, err := up.Exchange(ctx, req)
+	if err != nil {
+		t.Fatalf("exchange via %s: %v", up, err)
+	}
+	if resp.Id != req.Id || len(resp.Answer) != 1 {
+		t.Fatalf("unexpected answer via %s: %v", up, resp)
+	}
+	return resp
+}
+
+func TestDoHUpstream(t *testing.T) {
+	var conns atomic.Int32
+	srv := startDoHServer(t, answerA("203.0.113.1", 60, nil), &conns)
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	// The host name only resolves through the bootstrap address.
+	up, err := NewUpstream(config.UpstreamConfig{
+		Address:    "https://dns.invalid:" + port + "/dns-query",
+		Bootstrap:  []string{"127.0.0.1"},
+		ServerName: "example.com",
+		CAFile:     writeCA(t, srv),
+	}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exchangeA(t, up, "a.example")
+	exchangeA(t, up, "b.example")
+	if n := conns.Load(); n != 1 {
+		t.Fatalf("DoH opened %d connections, want 1 reused", n)
+	}
+}
+
+func TestDoHUpstreamUntrusted(t *testing.T) {
+	srv := startDoHServer(t, answerA("203.0.113.1", 60, nil), nil)
+	up, err := NewUpstream(config.UpstreamConfig{Address: srv.URL + "/dns-query"}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := new(mdns.Msg)
+	req.SetQuestion("a.example.", mdns.TypeA)
+	if _, err := up.Exchange(context.Background(), req); err == nil {
+		t.Fatal("exchange succeeded against an untrusted certificate")
+	}
+}
+
+func TestDoTUpstream(t *testing.T) {
+	doh := startDoHServer(t, answerA("203.0.113.1", 60, nil), nil)
+	var conns atomic.Int32
+	addr := startDoTServer(t, doh.TLS.Certificates[0], answerA("203.0.113.2", 60, nil), &conns)
+
+	up, err := NewUpstream(config.UpstreamConfig{Address: "tls://" + addr, CAFile: writeCA(t, doh)}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := exchangeA(t, up, "a.example")
+	if a := resp.Answer[0].(*mdns.A); !a.A.Equal(net.ParseIP("203.0.113.2")) {
+		t.Fatalf("answer = %v", a)
+	}
+	exchangeA(t, up, "b.example")
+	if n := conns.Load(); n != 1 {
+		t.Fatalf("DoT opened %d connections, want 1 reused", n)
+	}
+}
+
+// hangingUpstream never answers before ctx is done.
+type hangingUpstream struct {
+	calls atomic.Int32
+}
+
+func (u *hangingUpstream) Exchange(ctx context.Context, _ *mdns.Msg) (*mdns.Msg, error) {
+	u.calls.Add(1)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (u *hangingUpstream) String() string { return "hanging" }
+
+func TestPoolFailsOverWithinDeadline(t *testing.T) {
+	addr := startPlainServer(t, answerA("203.0.113.3", 60, nil))
+	hang := &hangingUpstream{}
+	pool := NewPool([]Upstream{hang, NewPlainUpstream(addr, time.Second)})
+
+	req := new(mdns.Msg)
+	req.SetQuestion("a.example.", mdns.TypeA)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := pool.Exchange(ctx, req)
+	if err != nil {
+		t.Fatalf("pool did not fail over: %v", err)
+	}
+	if len(resp.Answer) != 1 || hang.calls.Load() != 1 {
+		t.Fatalf("answer = %v, primary calls = %d", resp, hang.calls.Load())
+	}
+	st := pool.Status()
+	if st[0].Failures != 1 || st[1].Failures != 0 {
+		t.Fatalf("status = %+v", st)
+	}
+}
+
+func TestPoolPrefersHealthy(t *testing.T) {
+	var calls atomic.Int32
+	addr := startPlainServer(t, answerA("203.0.113.4", 60, &calls))
+	pool := NewPool([]Upstream{failingUpstream{}, NewPlainUpstream(addr, time.Second)})
+
+	for i := 0; i < failThreshold; i++ {
+		exchangeA(t, pool, "a.example")
+	}
+	if st := pool.Status(); st[0].Healthy || !st[1].Healthy {
+		t.Fatalf("status = %+v, want the failing upstream marked down", st)
+	}
+	// Healthy members come first, so the down one is no longer tried first.
+	if members := pool.ordered(); members[0].up.String() != NewPlainUpstream(addr, 0).String() {
+		t.Fatalf("first member = %s", members[0].up)
+	}
+}
@@ -0,0 +1,181 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/logging"
+	"github.com/kidos/kidosserver/pkg/rules"
+)
+
+// sinkholeTTL keeps clients from caching a sinkhole answer long after the
+// block is lifted.
+const sinkholeTTL = 10
+
+// Handler answers client queries: blocked names get NXDOMAIN, or the sinkhole
+// address when one is set, the rest are served from cache or forwarded
+// upstream.
+type Handler struct {
+	rules      *rules.RuleEngine
+	cache      *Cache
+	upstream   Upstream
+	publisher  events.Publisher
+	timeout    time.Duration
+	now        func() time.Time
+	sinkhole   net.IP
+	sinkholeV6 net.IP
+}
+
+// NewHandler wires a handler; publisher may be nil.
+func NewHandler(engine *rules.RuleEngine, cache *Cache, upstream Upstream, publisher events.Publisher, timeout time.Duration) *Handler {
+	return &Handler{
+		rules:     engine,
+		cache:     cache,
+		upstream:  upstream,
+		publisher: publisher,
+		timeout:   timeout,
+		now:       time.Now,
+	}
+}
+
+// SetSinkhole makes blocked names resolve to v4 and v6, which serve the block
+// page. Queries for other types, or for AAAA without v6, get an empty answer.
+func (h *Handler) SetSinkhole(v4, v6 net.IP) {
+	h.sinkhole, h.sinkholeV6 = v4.To4(), v6
+}
+
+// ServeDNS implements mdns.Handler.
+func (h *Handler) ServeDNS(w mdns.ResponseWriter, req *mdns.Msg) {
+	if len(req.Question) != 1 || req.Opcode != mdns.OpcodeQuery {
+		resp := new(mdns.Msg)
+		resp.SetRcode(req, mdns.RcodeFormatError)
+		_ = w.WriteMsg(resp)
+		return
+	}
+
+	q := req.Question[0]
+	domain := normalize(q.Name)
+	ev := h.newEvent(w, domain)
+
+	if rule, blocked := h.rules.Match(domain, ev.SourceIP); blocked {
+		h.reply(w, req, h.blocked(req))
+		ev.Action = "block"
+		ev.Reason = "domain blocked"
+		ev.Rule = rule
+		h.publish(ev)
+		return
+	}
+
+	if cached, ok := h.cache.Get(q); ok {
+		cached.Id = req.Id
+		h.reply(w, req, cached)
+		ev.Action = "allow"
+		ev.Reason = "cached"
+		h.publish(ev)
+		return
+	}
+
+	resp, err := h.forward(req)
+	if err != nil {
+		logging.Errorf("resolve %s: %v", domain, err)
+		fail := new(mdns.Msg)
+		fail.SetRcode(req, mdns.RcodeServerFailure)
+		fail.RecursionAvailable = true
+		h.reply(w, req, fail)
+		ev.Action = "error"
+		ev.Reason = "upstream failure"
+		h.publish(ev)
+		return
+	}
+	h.cache.Put(q, resp)
+	resp.Id = req.Id
+	h.reply(w, req, resp)
+	ev.Action = "allow"
+	ev.Reason = "passed"
+	h.publish(ev)
+}
+
+// blocked builds the answer to a blocked query.
+func (h *Handler) blocked(req *mdns.Msg) *mdns.Msg {
+	resp := new(mdns.Msg)
+	resp.RecursionAvailable = true
+	if h.sinkhole == nil {
+		resp.SetRcode(req, mdns.RcodeNameError)
+		return resp
+	}
+	resp.SetReply(req)
+	q := req.Question[0]
+	hdr := mdns.RR_Header{Name: q.Name, Rrtype: q.Qtype, Class: mdns.ClassINET, Ttl: sinkholeTTL}
+	switch {
+	case q.Qtype == mdns.TypeA:
+		resp.Answer = append(resp.Answer, &mdns.A{Hdr: hdr, A: h.sinkhole})
+	case q.Qtype == mdns.TypeAAAA && h.sinkholeV6 != nil:
+		resp.Answer = append(resp.Answer, &mdns.AAAA{Hdr: hdr, AAAA: h.sinkholeV6})
+	}
+	return resp
+}
+
+// forward sends the query upstream within the handler timeout.
+func (h *Handler) forward(req *mdns.Msg) (*mdns.Msg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	out := req.Copy()
+	out.RecursionDesired = true
+	return h.upstream.Exchange(ctx, out)
+}
+
+// reply trims UDP answers to the client's advertised buffer size.
+func (h *Handler) reply(w mdns.ResponseWriter, req, resp *mdns.Msg) {
+	if _, ok := w.RemoteAddr().(*net.UDPAddr); ok {
+		size := mdns.MinMsgSize
+		if opt := req.IsEdns0(); opt != nil {
+			size = int(opt.UDPSize())
+		}
+		resp.Truncate(size)
+	}
+	if err := w.WriteMsg(resp); err != nil {
+		logging.Errorf("write dns response: %v", err)
+	}
+}
+
+func (h *Handler) newEvent(w mdns.ResponseWriter, domain string) events.Event {
+	ev := events.Event{
+		Kind:      "dns",
+		Timestamp: h.now().UTC(),
+		Direction: "query",
+		Domain:    domain,
+	}
+	ev.Transport = "udp"
+	if _, ok := w.RemoteAddr().(*net.TCPAddr); ok {
+		ev.Transport = "tcp"
+	}
+	ev.SourceIP, ev.SourcePort = addrParts(w.RemoteAddr())
+	ev.DestinationIP, ev.DestinationPort = addrParts(w.LocalAddr())
+	return ev
+}
+
+func (h *Handler) publish(ev events.Event) {
+	if h.publisher != nil {
+		h.publisher.Publish(ev)
+	}
+}
+
+func addrParts(addr net.Addr) (string, uint16) {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP.String(), uint16(a.Port)
+	case *net.TCPAddr:
+		return a.IP.String(), uint16(a.Port)
+	}
+	return "", 0
+}
+
+func normalize(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, "."))
+}
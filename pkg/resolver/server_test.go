@@ -0,0 +1,183 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"github.com/kidos/kidosserver/pkg/events"
+	"github.com/kidos/kidosserver/pkg/rules"
+)
+
+// startPlainServer runs a local DNS server over UDP as a stand-in upstream
+// and returns its address.
+func startPlainServer(t *testing.T, h mdns.HandlerFunc) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	srv := &mdns.Server{PacketConn: pc, Handler: h, NotifyStartedFunc: func() { close(started) }}
+	go srv.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { _ = srv.Shutdown() })
+	return pc.LocalAddr().String()
+}
+
+// answerA replies to every query with one A record.
+func answerA(ip string, ttl uint32, calls *atomic.Int32) mdns.HandlerFunc {
+	return func(w mdns.ResponseWriter, req *mdns.Msg) {
+		if calls != nil {
+			calls.Add(1)
+		}
+		resp := new(mdns.Msg)
+		resp.SetReply(req)
+		resp.Answer = append(resp.Answer, &mdns.A{
+			Hdr: mdns.RR_Header{Name: req.Question[0].Name, Rrtype: mdns.TypeA, Class: mdns.ClassINET, Ttl: ttl},
+			A:   net.ParseIP(ip),
+		})
+		_ = w.WriteMsg(resp)
+	}
+}
+
+// recorder is a ResponseWriter that keeps the written message.
+type recorder struct {
+	msg *mdns.Msg
+}
+
+func (r *recorder) LocalAddr() net.Addr {
+	return &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 53}
+}
+
+func (r *recorder) RemoteAddr() net.Addr {
+	return &net.UDPAddr{IP: net.IPv4(192, 168, 1, 20), Port: 40000}
+}
+
+func (r *recorder) WriteMsg(m *mdns.Msg) error {
+	r.msg = m
+	return nil
+}
+
+func (r *recorder) Write([]byte) (int, error) { return 0, errors.New("not supported") }
+func (r *recorder) Close() error              { return nil }
+func (r *recorder) TsigStatus() error         { return nil }
+func (r *recorder) TsigTimersOnly(bool)       {}
+func (r *recorder) Hijack()                   {}
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (l *eventLog) Publish(ev events.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, ev)
+}
+
+func (l *eventLog) last(t *testing.T) events.Event {
+	t.Helper()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.events) == 0 {
+		t.Fatal("no event published")
+	}
+	return l.events[len(l.events)-1]
+}
+
+// failingUpstream always errors.
+type failingUpstream struct{}
+
+func (failingUpstream) Exchange(context.Context, *mdns.Msg) (*mdns.Msg, error) {
+	return nil, errors.New("unreachable")
+}
+
+func (failingUpstream) String() string { return "failing" }
+
+func query(h *Handler, name string, qtype uint16) *mdns.Msg {
+	req := new(mdns.Msg)
+	req.SetQuestion(mdns.Fqdn(name), qtype)
+	w := &recorder{}
+	h.ServeDNS(w, req)
+	return w.msg
+}
+
+func TestServeDNSBlocked(t *testing.T) {
+	log := &eventLog{}
+	h := NewHandler(rules.New([]string{"blocked.example"}), NewCache(16, 0), failingUpstream{}, log, time.Second)
+
+	resp := query(h, "blocked.example", mdns.TypeA)
+	if resp == nil || resp.Rcode != mdns.RcodeNameError {
+		t.Fatalf("blocked query: got %v, want NXDOMAIN", resp)
+	}
+	if ev := log.last(t); ev.Action != "block" || ev.Rule == "" {
+		t.Fatalf("event = %+v, want a block with its rule", ev)
+	}
+
+	h.SetSinkhole(net.ParseIP("192.168.1.1"), nil)
+	resp = query(h, "blocked.example", mdns.TypeA)
+	if resp.Rcode != mdns.RcodeSuccess || len(resp.Answer) != 1 {
+		t.Fatalf("sinkholed query: got %v", resp)
+	}
+	if a := resp.Answer[0].(*mdns.A); !a.A.Equal(net.ParseIP("192.168.1.1")) || a.Hdr.Ttl != sinkholeTTL {
+		t.Fatalf("sinkhole answer = %v", a)
+	}
+	if resp = query(h, "blocked.example", mdns.TypeAAAA); resp.Rcode != mdns.RcodeSuccess || len(resp.Answer) != 0 {
+		t.Fatalf("AAAA without v6 sinkhole: got %v, want empty NOERROR", resp)
+	}
+}
+
+func TestServeDNSForwardAndCache(t *testing.T) {
+	var calls atomic.Int32
+	addr := startPlainServer(t, answerA("203.0.113.7", 300, &calls))
+	log := &eventLog{}
+	h := NewHandler(rules.New(nil), NewCache(16, 0), NewPlainUpstream(addr, time.Second), log, time.Second)
+
+	resp := query(h, "example.com", mdns.TypeA)
+	if resp == nil || resp.Rcode != mdns.RcodeSuccess || len(resp.Answer) != 1 {
+		t.Fatalf("forwarded query: got %v", resp)
+	}
+	if ev := log.last(t); ev.Action != "allow" || ev.Reason != "passed" {
+		t.Fatalf("event = %+v, want a passed allow", ev)
+	}
+
+	resp = query(h, "EXAMPLE.com", mdns.TypeA)
+	if resp == nil || len(resp.Answer) != 1 {
+		t.Fatalf("cached query: got %v", resp)
+	}
+	if ev := log.last(t); ev.Reason != "cached" {
+		t.Fatalf("event = %+v, want a cache hit", ev)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("upstream saw %d queries, want 1", n)
+	}
+}
+
+func TestServeDNSUpstreamFailure(t *testing.T) {
+	log := &eventLog{}
+	h := NewHandler(rules.New(nil), NewCache(16, 0), failingUpstream{}, log, time.Second)
+
+	resp := query(h, "example.com", mdns.TypeA)
+	if resp == nil || resp.Rcode != mdns.RcodeServerFailure {
+		t.Fatalf("got %v, want SERVFAIL", resp)
+	}
+	if ev := log.last(t); ev.Action != "error" {
+		t.Fatalf("event = %+v, want an error", ev)
+	}
+}
+
+func TestServeDNSFormatError(t *testing.T) {
+	h := NewHandler(rules.New(nil), NewCache(16, 0), failingUpstream{}, nil, time.Second)
+	w := &recorder{}
+	h.ServeDNS(w, new(mdns.Msg))
+	if w.msg == nil || w.msg.Rcode != mdns.RcodeFormatError {
+		t.Fatalf("got %v, want FORMERR", w.msg)
+	}
+}
@@ -0,0 +1,101 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"github.com/kidos/kidosserver/pkg/config"
+)
+
+// Upstream forwards a query to a recursive resolver.
+type Upstream interface {
+	Exchange(ctx context.Context, req *mdns.Msg) (*mdns.Msg, error)
+	String() string
+}
+
+// NewUpstream builds an upstream from its address scheme: plain "1.1.1.1" or
+// "udp://host:port", DNS-over-TLS "tls://host[:853]", or DNS-over-HTTPS
+// "https://host/dns-query".
+func NewUpstream(cfg config.UpstreamConfig, timeout time.Duration) (Upstream, error) {
+	addr := cfg.Address
+	if !strings.Contains(addr, "://") {
+		addr = "udp://" + addr
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parse upstream %q: %w", cfg.Address, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("upstream %q has no host", cfg.Address)
+	}
+	serverName := cfg.ServerName
+	if serverName == "" {
+		serverName = u.Hostname()
+	}
+
+	switch u.Scheme {
+	case "udp", "dns":
+		return NewPlainUpstream(u.Host, timeout), nil
+	case "tls":
+		tlsCfg, err := tlsConfig(serverName, cfg.CAFile, []string{"dot"})
+		if err != nil {
+			return nil, err
+		}
+		dialer := newBootstrapDialer("853", cfg.Bootstrap)
+		return newDoTUpstream(withDefaultPort(u.Host, "853"), dialer, tlsCfg, timeout), nil
+	case "https":
+		tlsCfg, err := tlsConfig(serverName, cfg.CAFile, nil)
+		if err != nil {
+			return nil, err
+		}
+		dialer := newBootstrapDialer("443", cfg.Bootstrap)
+		return newDoHUpstream(u.String(), dialer, tlsCfg, timeout), nil
+	default:
+		return nil, fmt.Errorf("upstream %q: unsupported scheme %q", cfg.Address, u.Scheme)
+	}
+}
+
+// plainUpstream speaks classic DNS over UDP, retrying over TCP on truncation.
+type plainUpstream struct {
+	addr string
+	udp  *mdns.Client
+	tcp  *mdns.Client
+}
+
+// NewPlainUpstream creates a UDP/TCP upstream; the port defaults to 53.
+func NewPlainUpstream(addr string, timeout time.Duration) Upstream {
+	return &plainUpstream{
+		addr: withDefaultPort(addr, "53"),
+		udp:  &mdns.Client{Net: "udp", Timeout: timeout, UDPSize: mdns.DefaultMsgSize},
+		tcp:  &mdns.Client{Net: "tcp", Timeout: timeout},
+	}
+}
+
+func (u *plainUpstream) Exchange(ctx context.Context, req *mdns.Msg) (*mdns.Msg, error) {
+	resp, _, err := u.udp.ExchangeContext(ctx, req, u.addr)
+	if err == nil && resp.Truncated {
+		resp, _, err = u.tcp.ExchangeContext(ctx, req, u.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("exchange with %s: %w", u.addr, err)
+	}
+	return resp, nil
+}
+
+func (u *plainUpstream) String() string {
+	return "udp://" + u.addr
+}
+
+// withDefaultPort appends port when addr has none.
+func withDefaultPort(addr, port string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	return net.JoinHostPort(strings.Trim(addr, "[]"), port)
+}
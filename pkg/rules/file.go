@@ -0,0 +1,129 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/fsutil"
+	"github.com/kidos/kidosserver/pkg/logging"
+)
+
+// ruleFile is the on-disk form of the rule set.
+type ruleFile struct {
+	Version uint64 `json:"version"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Load reads the rule file at path, falling back to the seed domains (the
+// legacy dns.blocklist setting) when it does not exist yet.
+func Load(path string, seed []string) (*RuleEngine, error) {
+	eng := New(seed)
+	if err := eng.LoadFromFile(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return eng, err
+	}
+	return eng, nil
+}
+
+// LoadFromFile replaces the rule set with the one stored at path. A plain
+// JSON array of domains is accepted as well.
+func (r *RuleEngine) LoadFromFile(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("read rules: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read rules: %w", err)
+	}
+	var f ruleFile
+	if len(data) > 0 && data[0] == '[' {
+		var domains []string
+		if err := json.Unmarshal(data, &domains); err != nil {
+			return fmt.Errorf("parse rules: %w", err)
+		}
+		for _, d := range domains {
+			f.Rules = append(f.Rules, Rule{Domain: d})
+		}
+	} else if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("parse rules: %w", err)
+	}
+
+	m := make(map[RuleRef]*Rule, len(f.Rules))
+	for i := range f.Rules {
+		rule := f.Rules[i]
+		ref := rule.Ref().normalized()
+		rule.Domain, rule.Action, rule.Device = ref.Domain, ref.Action, ref.Device
+		m[ref] = &rule
+	}
+	r.load(m, f.Version)
+	r.saveMu.Lock()
+	r.fileMod, r.fileSize = fi.ModTime(), fi.Size()
+	r.saveMu.Unlock()
+	return nil
+}
+
+// SaveToFile atomically writes the rule set and its version to path.
+func (r *RuleEngine) SaveToFile(path string) error {
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+	r.mu.RLock()
+	f := ruleFile{Version: r.version, Rules: r.sorted()}
+	r.mu.RUnlock()
+	return writeRuleFile(path, f)
+}
+
+// Persist makes every later mutation write the rule set to path before it
+// takes effect, so the file and the engine never disagree.
+func (r *RuleEngine) Persist(path string) {
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+	r.path = path
+}
+
+func writeRuleFile(path string, f ruleFile) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialize rules: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create rules dir: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path, data, 0o644); err != nil {
+		return fmt.Errorf("write rules: %w", err)
+	}
+	return nil
+}
+
+// Watch reloads path whenever its modification time or size changes, until
+// ctx is cancelled. Collectors use it to pick up edits made by the web server.
+func (r *RuleEngine) Watch(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		r.saveMu.Lock()
+		unchanged := fi.ModTime().Equal(r.fileMod) && fi.Size() == r.fileSize
+		r.saveMu.Unlock()
+		if unchanged {
+			continue
+		}
+		if err := r.LoadFromFile(path); err != nil {
+			logging.Errorf("reload rules: %v", err)
+			continue
+		}
+		logging.Infof("reloaded %d rules (version %d) from %s", len(r.List()), r.Version(), path)
+	}
+}
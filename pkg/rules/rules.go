@@ -2,86 +2,577 @@ package rules
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"maps"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
-// RuleEngine manages DNS blocklist rules.
+// Rule actions. Allow rules are exceptions that override a block on the
+// same domain while they last.
+const (
+	ActionBlock = "block"
+	ActionAllow = "allow"
+)
+
+// Errors returned by rule mutations.
+var (
+	ErrVersionMismatch = errors.New("rule set version mismatch")
+	ErrInvalidDomain   = errors.New("invalid domain")
+	ErrInvalidAction   = errors.New("invalid rule action")
+)
+
+// Rule is one blocklist entry or exception with its bookkeeping metadata.
+// A rule with a Device only applies to queries from that device, and one
+// with Subdomains also covers every name below Domain.
+type Rule struct {
+	Domain     string     `json:"domain"`
+	Action     string     `json:"action"`
+	Device     string     `json:"device,omitempty"`
+	Subdomains bool       `json:"subdomains,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	CreatedBy  string     `json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Ref returns the key identifying the rule.
+func (r Rule) Ref() RuleRef {
+	return RuleRef{Domain: r.Domain, Action: r.Action, Device: r.Device}
+}
+
+// Expired reports whether the rule has an expiry at or before now.
+func (r Rule) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !r.ExpiresAt.After(now)
+}
+
+// Remaining returns how long a temporary rule stays in force.
+func (r Rule) Remaining(now time.Time) time.Duration {
+	if r.ExpiresAt == nil {
+		return 0
+	}
+	return max(r.ExpiresAt.Sub(now), 0)
+}
+
+// RuleRef identifies a rule; a domain may carry one block and one allow rule
+// for all devices and for each single device.
+type RuleRef struct {
+	Domain string `json:"domain"`
+	Action string `json:"action,omitempty"`
+	Device string `json:"device,omitempty"`
+}
+
+// ParseRef is the inverse of RuleRef.String.
+func ParseRef(s string) RuleRef {
+	var ref RuleRef
+	if rest, ok := strings.CutPrefix(s, ActionAllow+":"); ok {
+		ref.Action, s = ActionAllow, rest
+	}
+	ref.Domain, ref.Device, _ = strings.Cut(s, "@")
+	return ref.normalized()
+}
+
+// UnmarshalJSON accepts an object or a bare domain naming a block rule.
+func (k *RuleRef) UnmarshalJSON(data []byte) error {
+	var domain string
+	if err := json.Unmarshal(data, &domain); err == nil {
+		*k = RuleRef{Domain: domain}
+		return nil
+	}
+	type plain RuleRef
+	return json.Unmarshal(data, (*plain)(k))
+}
+
+func (k RuleRef) String() string {
+	s := k.Domain
+	if k.Device != "" {
+		s += "@" + k.Device
+	}
+	if k.Action == ActionAllow {
+		s = ActionAllow + ":" + s
+	}
+	return s
+}
+
+// normalized canonicalizes the domain and defaults the action to block.
+func (k RuleRef) normalized() RuleRef {
+	k.Domain = normalize(strings.TrimSpace(k.Domain))
+	k.Device = strings.TrimSpace(k.Device)
+	if k.Action == "" {
+		k.Action = ActionBlock
+	}
+	return k
+}
+
+// Change summarizes what a mutation did to the rule set.
+type Change struct {
+	Version uint64    `json:"version"`
+	Added   []RuleRef `json:"added,omitempty"`
+	Updated []RuleRef `json:"updated,omitempty"`
+	Removed []RuleRef `json:"removed,omitempty"`
+	Missing []RuleRef `json:"missing,omitempty"`
+}
+
+// Empty reports whether the mutation left the rule set untouched.
+func (c Change) Empty() bool {
+	return len(c.Added)+len(c.Updated)+len(c.Removed) == 0
+}
+
+// RuleEngine manages DNS blocklist rules and their exceptions.
 type RuleEngine struct {
-	mu        sync.RWMutex
-	blocklist map[string]struct{}
+	mu      sync.RWMutex
+	rules   map[RuleRef]*Rule
+	version uint64
+	hits    map[string]*RuleHits
+	now     func() time.Time
+
+	// saveMu serializes file writes and guards the stat of the last load.
+	// Mutations take it before mu.
+	saveMu   sync.Mutex
+	fileMod  time.Time
+	fileSize int64
+	path     string
+}
+
+// RuleHits counts how often a rule fired, overall and per device.
+type RuleHits struct {
+	Rule    string       `json:"rule"`
+	Hits    uint64       `json:"hits"`
+	LastHit time.Time    `json:"lastHit,omitempty"`
+	Devices []DeviceHits `json:"devices,omitempty"`
+
+	devices map[string]*DeviceHits
+}
+
+// DeviceHits counts rule hits attributed to one device.
+type DeviceHits struct {
+	Device  string    `json:"device"`
+	Hits    uint64    `json:"hits"`
+	LastHit time.Time `json:"lastHit"`
 }
 
 // New creates an engine from domain list.
 func New(domains []string) *RuleEngine {
-	eng := &RuleEngine{blocklist: make(map[string]struct{}, len(domains))}
+	eng := &RuleEngine{
+		rules:   make(map[RuleRef]*Rule, len(domains)),
+		version: 1,
+		hits:    make(map[string]*RuleHits),
+		now:     time.Now,
+	}
 	for _, d := range domains {
-		eng.blocklist[normalize(d)] = struct{}{}
+		rule := &Rule{Domain: normalize(d), Action: ActionBlock}
+		eng.rules[rule.Ref()] = rule
 	}
 	return eng
 }
 
-// ShouldBlock reports whether domain must be blocked.
-func (r *RuleEngine) ShouldBlock(domain string) bool {
+// Now returns the engine's current time.
+func (r *RuleEngine) Now() time.Time {
 	r.mu.RLock()
-	_, ok := r.blocklist[normalize(domain)]
-	r.mu.RUnlock()
+	defer r.mu.RUnlock()
+	return r.now()
+}
+
+// Match returns the name of the rule that blocks domain for device, if any.
+// Expired rules stop applying before they are retired. An active allow rule
+// that covers the query overrides every block; otherwise the most specific
+// block wins, preferring rules scoped to the device.
+func (r *RuleEngine) Match(domain, device string) (string, bool) {
+	d := normalize(domain)
+	if d == "" {
+		return "", false
+	}
+	scopes := []string{""}
+	if device != "" {
+		scopes = []string{device, ""}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	now := r.now()
+	var block *Rule
+	for name := d; ; {
+		for _, dev := range scopes {
+			if rule := r.applicable(RuleRef{Domain: name, Action: ActionAllow, Device: dev}, name == d, now); rule != nil {
+				return "", false
+			}
+			if block == nil {
+				block = r.applicable(RuleRef{Domain: name, Action: ActionBlock, Device: dev}, name == d, now)
+			}
+		}
+		i := strings.IndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+	if block == nil {
+		return "", false
+	}
+	return block.Ref().String(), true
+}
+
+// applicable returns the rule at ref if it is in force and covers the
+// queried name, which is ref.Domain itself when exact is set.
+func (r *RuleEngine) applicable(ref RuleRef, exact bool, now time.Time) *Rule {
+	rule, ok := r.rules[ref]
+	if !ok || rule.Expired(now) || !exact && !rule.Subdomains {
+		return nil
+	}
+	return rule
+}
+
+// RecordHit attributes one firing of rule, named as Match returns it, to
+// device at the given time.
+func (r *RuleEngine) RecordHit(rule, device string, at time.Time) {
+	ref := ParseRef(rule)
+	rule = ref.String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rules[ref]; !ok || ref.Action != ActionBlock {
+		return
+	}
+	h := r.hits[rule]
+	if h == nil {
+		h = &RuleHits{Rule: rule, devices: make(map[string]*DeviceHits)}
+		r.hits[rule] = h
+	}
+	h.Hits++
+	if at.After(h.LastHit) {
+		h.LastHit = at
+	}
+	if device == "" {
+		return
+	}
+	dh := h.devices[device]
+	if dh == nil {
+		dh = &DeviceHits{Device: device}
+		h.devices[device] = dh
+	}
+	dh.Hits++
+	if at.After(dh.LastHit) {
+		dh.LastHit = at
+	}
+}
+
+// Hits returns hit counters for every current block rule, including rules
+// that never fired.
+func (r *RuleEngine) Hits() []RuleHits {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]RuleHits, 0, len(r.rules))
+	for ref := range r.rules {
+		if ref.Action == ActionBlock {
+			out = append(out, r.hitsFor(ref.String()))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Hits == out[j].Hits {
+			return out[i].Rule < out[j].Rule
+		}
+		return out[i].Hits > out[j].Hits
+	})
+	return out
+}
+
+// RuleHitsFor returns the hit counters of one block rule.
+func (r *RuleEngine) RuleHitsFor(ref RuleRef) RuleHits {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.hitsFor(ref.normalized().String())
+}
+
+func (r *RuleEngine) hitsFor(name string) RuleHits {
+	entry := RuleHits{Rule: name}
+	if h := r.hits[name]; h != nil {
+		entry.Hits = h.Hits
+		entry.LastHit = h.LastHit
+		entry.Devices = make([]DeviceHits, 0, len(h.devices))
+		for _, dh := range h.devices {
+			entry.Devices = append(entry.Devices, *dh)
+		}
+		sort.Slice(entry.Devices, func(i, j int) bool {
+			return entry.Devices[i].Hits > entry.Devices[j].Hits
+		})
+	}
+	return entry
+}
+
+// ShouldBlock reports whether domain must be blocked for device.
+func (r *RuleEngine) ShouldBlock(domain, device string) bool {
+	_, ok := r.Match(domain, device)
 	return ok
 }
 
+// Version returns the rule set version, bumped by every change.
+func (r *RuleEngine) Version() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.version
+}
+
+// Get returns the rule identified by ref.
+func (r *RuleEngine) Get(ref RuleRef) (Rule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[ref.normalized()]
+	if !ok {
+		return Rule{}, false
+	}
+	return *rule, true
+}
+
+// Rules returns every rule sorted by domain, blocks before exceptions.
+func (r *RuleEngine) Rules() []Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sorted()
+}
+
+func (r *RuleEngine) sorted() []Rule {
+	return sortedRules(r.rules)
+}
+
+func sortedRules(m map[RuleRef]*Rule) []Rule {
+	out := make([]Rule, 0, len(m))
+	for _, rule := range m {
+		out = append(out, *rule)
+	}
+	sort.Slice(out, func(i, j int) bool { return refLess(out[i].Ref(), out[j].Ref()) })
+	return out
+}
+
+// Apply adds or updates the given rules and removes the listed ones in a
+// single version step. A non-zero expect must equal the current version.
+// When the engine persists to a file, a change that cannot be written is not
+// applied. New rules are stamped with the current time; updates keep the original
+// creator and creation time.
+func (r *RuleEngine) Apply(expect uint64, add []Rule, remove []RuleRef) (Change, error) {
+	for i := range add {
+		ref := add[i].Ref().normalized()
+		if ref.Action != ActionBlock && ref.Action != ActionAllow {
+			return Change{}, fmt.Errorf("%w: %q", ErrInvalidAction, ref.Action)
+		}
+		d, err := ValidateDomain(ref.Domain)
+		if err != nil {
+			return Change{}, err
+		}
+		add[i].Domain, add[i].Action, add[i].Device = d, ref.Action, ref.Device
+	}
+
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if expect != 0 && expect != r.version {
+		return Change{Version: r.version}, ErrVersionMismatch
+	}
+	next := maps.Clone(r.rules)
+	var ch Change
+	for _, ref := range remove {
+		ref = ref.normalized()
+		if _, ok := next[ref]; !ok {
+			ch.Missing = append(ch.Missing, ref)
+			continue
+		}
+		delete(next, ref)
+		ch.Removed = append(ch.Removed, ref)
+	}
+	now := r.now().UTC()
+	for _, rule := range add {
+		rule := rule
+		ref := rule.Ref()
+		if prev, ok := next[ref]; ok {
+			rule.CreatedBy, rule.CreatedAt = prev.CreatedBy, prev.CreatedAt
+			ch.Updated = append(ch.Updated, ref)
+		} else {
+			rule.CreatedAt = now
+			ch.Added = append(ch.Added, ref)
+		}
+		next[ref] = &rule
+	}
+	if !ch.Empty() {
+		if err := r.commitLocked(next, ch.Removed); err != nil {
+			return Change{Version: r.version}, err
+		}
+	}
+	ch.Version = r.version
+	return ch, nil
+}
+
+// Replace swaps the block rules for all devices for domains, keeping the
+// metadata of domains that stay. Allow and device rules are left alone. A
+// non-zero expect must equal the current version, which only moves when the
+// set actually changes.
+func (r *RuleEngine) Replace(expect uint64, domains []string, by string) (Change, error) {
+	keep := make(map[RuleRef]bool, len(domains))
+	for _, d := range domains {
+		d, err := ValidateDomain(d)
+		if err != nil {
+			return Change{}, err
+		}
+		keep[RuleRef{Domain: d, Action: ActionBlock}] = true
+	}
+
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if expect != 0 && expect != r.version {
+		return Change{Version: r.version}, ErrVersionMismatch
+	}
+	next := maps.Clone(r.rules)
+	var ch Change
+	for ref := range next {
+		if ref.Action == ActionBlock && ref.Device == "" && !keep[ref] {
+			delete(next, ref)
+			ch.Removed = append(ch.Removed, ref)
+		}
+	}
+	now := r.now().UTC()
+	for ref := range keep {
+		if _, ok := next[ref]; ok {
+			continue
+		}
+		next[ref] = &Rule{Domain: ref.Domain, Action: ActionBlock, CreatedBy: by, CreatedAt: now}
+		ch.Added = append(ch.Added, ref)
+	}
+	if !ch.Empty() {
+		if err := r.commitLocked(next, ch.Removed); err != nil {
+			return Change{Version: r.version}, err
+		}
+	}
+	ch.Version = r.version
+	sortRefs(ch.Added)
+	sortRefs(ch.Removed)
+	return ch, nil
+}
+
+// Expire retires every rule whose expiry has passed and returns them with
+// the resulting version. If the change cannot be persisted the rules stay
+// in place and the next call retries.
+func (r *RuleEngine) Expire() ([]Rule, uint64, error) {
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	var retired []Rule
+	var refs []RuleRef
+	for ref, rule := range r.rules {
+		if rule.Expired(now) {
+			retired = append(retired, *rule)
+			refs = append(refs, ref)
+		}
+	}
+	if len(retired) == 0 {
+		return nil, r.version, nil
+	}
+	next := maps.Clone(r.rules)
+	for _, ref := range refs {
+		delete(next, ref)
+	}
+	if err := r.commitLocked(next, refs); err != nil {
+		return nil, r.version, err
+	}
+	sort.Slice(retired, func(i, j int) bool { return refLess(retired[i].Ref(), retired[j].Ref()) })
+	return retired, r.version, nil
+}
+
+// commitLocked installs next as the rule set under a new version, dropping
+// the hit counters of removed rules. When the engine persists to a file,
+// next is written first and a failed write leaves the current set in place.
+// Callers hold saveMu and mu.
+func (r *RuleEngine) commitLocked(next map[RuleRef]*Rule, removed []RuleRef) error {
+	if r.path != "" {
+		if err := writeRuleFile(r.path, ruleFile{Version: r.version + 1, Rules: sortedRules(next)}); err != nil {
+			return err
+		}
+	}
+	for _, ref := range removed {
+		delete(r.hits, ref.String())
+	}
+	r.rules = next
+	r.version++
+	return nil
+}
+
 // Set replaces the current blocklist.
 func (r *RuleEngine) Set(domains []string) {
-	m := make(map[string]struct{}, len(domains))
+	m := make(map[RuleRef]*Rule, len(domains))
 	for _, d := range domains {
-		m[normalize(d)] = struct{}{}
+		rule := &Rule{Domain: normalize(d), Action: ActionBlock}
+		m[rule.Ref()] = rule
 	}
+	r.load(m, 0)
+}
+
+// load installs a complete rule set, keeping hit counters of surviving
+// rules. A zero version just bumps the current one.
+func (r *RuleEngine) load(m map[RuleRef]*Rule, version uint64) {
 	r.mu.Lock()
-	r.blocklist = m
-	r.mu.Unlock()
+	defer r.mu.Unlock()
+	r.rules = m
+	for name := range r.hits {
+		if _, ok := m[ParseRef(name)]; !ok {
+			delete(r.hits, name)
+		}
+	}
+	if version == 0 {
+		version = r.version + 1
+	}
+	r.version = version
 }
 
-// List returns current domains.
+// List returns the domains blocked for all devices.
 func (r *RuleEngine) List() []string {
 	r.mu.RLock()
-	domains := make([]string, 0, len(r.blocklist))
-	for d := range r.blocklist {
-		domains = append(domains, d)
+	domains := make([]string, 0, len(r.rules))
+	for ref := range r.rules {
+		if ref.Action == ActionBlock && ref.Device == "" {
+			domains = append(domains, ref.Domain)
+		}
 	}
 	r.mu.RUnlock()
+	sort.Strings(domains)
 	return domains
 }
 
-// LoadFromFile loads blocklist from JSON file.
-func (r *RuleEngine) LoadFromFile(path string) error {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return fmt.Errorf("read blocklist: %w", err)
+// ValidateDomain normalizes d and checks that it is a plausible DNS name.
+func ValidateDomain(d string) (string, error) {
+	d = normalize(strings.TrimSpace(d))
+	if d == "" || len(d) > 253 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDomain, d)
 	}
-	var domains []string
-	if err := json.Unmarshal(data, &domains); err != nil {
-		return fmt.Errorf("parse blocklist: %w", err)
+	for _, label := range strings.Split(d, ".") {
+		if label == "" || len(label) > 63 {
+			return "", fmt.Errorf("%w: %q", ErrInvalidDomain, d)
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return "", fmt.Errorf("%w: %q", ErrInvalidDomain, d)
+			}
+		}
 	}
-	r.Set(domains)
-	return nil
+	return d, nil
 }
 
-// SaveToFile writes blocklist to JSON file.
-func (r *RuleEngine) SaveToFile(path string) error {
-	r.mu.RLock()
-	domains := make([]string, 0, len(r.blocklist))
-	for d := range r.blocklist {
-		domains = append(domains, d)
+// refLess orders refs by domain, then device, with the block rule first.
+func refLess(a, b RuleRef) bool {
+	if a.Domain != b.Domain {
+		return a.Domain < b.Domain
 	}
-	r.mu.RUnlock()
-	data, err := json.MarshalIndent(domains, "", "  ")
-	if err != nil {
-		return fmt.Errorf("serialize blocklist: %w", err)
+	if a.Device != b.Device {
+		return a.Device < b.Device
 	}
-	if err := os.WriteFile(path, data, 0o644); err != nil {
-		return fmt.Errorf("write blocklist: %w", err)
-	}
-	return nil
+	return a.Action > b.Action
+}
+
+func sortRefs(refs []RuleRef) {
+	sort.Slice(refs, func(i, j int) bool { return refLess(refs[i], refs[j]) })
 }
 
 func normalize(domain string) string {
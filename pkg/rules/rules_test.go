@@ -0,0 +1,287 @@
+package rules
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mustApply(t *testing.T, eng *RuleEngine, expect uint64, add []Rule, remove []RuleRef) Change {
+	t.Helper()
+	ch, err := eng.Apply(expect, add, remove)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch
+}
+
+func TestMatchPrecedence(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	eng := New(nil)
+	eng.now = func() time.Time { return now }
+	mustApply(t, eng, 0, []Rule{
+		{Domain: "ads.example", Action: ActionBlock, Subdomains: true},
+		{Domain: "exact.example", Action: ActionBlock},
+		{Domain: "exact.example", Action: ActionBlock, Device: "10.0.0.2"},
+		{Domain: "ok.ads.example", Action: ActionAllow},
+		{Domain: "games.example", Action: ActionBlock, Device: "10.0.0.2", Subdomains: true},
+		{Domain: "play.games.example", Action: ActionBlock},
+		{Domain: "video.example", Action: ActionBlock},
+		{Domain: "video.example", Action: ActionAllow, Device: "10.0.0.3"},
+		{Domain: "old.example", Action: ActionBlock, ExpiresAt: &past},
+		{Domain: "news.example", Action: ActionBlock},
+		{Domain: "news.example", Action: ActionAllow, ExpiresAt: &past},
+	}, nil)
+
+	for _, tc := range []struct {
+		domain, device, want string
+	}{
+		{"ads.example", "", "ads.example"},
+		{"x.y.ads.example.", "10.0.0.9", "ads.example"},
+		{"ADS.Example", "", "ads.example"},
+		{"exact.example", "", "exact.example"},
+		{"sub.exact.example", "", ""},
+		// An allow rule overrides the block above it.
+		{"ok.ads.example", "", ""},
+		// On the same name the device rule beats the global one; otherwise
+		// the most specific name wins.
+		{"exact.example", "10.0.0.2", "exact.example@10.0.0.2"},
+		{"exact.example", "10.0.0.3", "exact.example"},
+		{"www.games.example", "10.0.0.2", "games.example@10.0.0.2"},
+		{"play.games.example", "10.0.0.2", "play.games.example"},
+		{"games.example", "", ""},
+		{"video.example", "10.0.0.3", ""},
+		{"video.example", "10.0.0.4", "video.example"},
+		// Expired rules stop applying before they are retired.
+		{"old.example", "", ""},
+		{"news.example", "", "news.example"},
+		{"", "", ""},
+	} {
+		got, ok := eng.Match(tc.domain, tc.device)
+		if got != tc.want || ok != (tc.want != "") {
+			t.Errorf("Match(%q, %q) = %q, %v; want %q", tc.domain, tc.device, got, ok, tc.want)
+		}
+	}
+}
+
+func TestApplyVersions(t *testing.T) {
+	eng := New([]string{"a.example"})
+	if v := eng.Version(); v != 1 {
+		t.Fatalf("initial version %d", v)
+	}
+	ch := mustApply(t, eng, 1, []Rule{{Domain: "B.example."}}, nil)
+	if ch.Version != 2 || !reflect.DeepEqual(ch.Added, []RuleRef{{Domain: "b.example", Action: ActionBlock}}) {
+		t.Fatalf("change = %+v", ch)
+	}
+	if _, err := eng.Apply(1, []Rule{{Domain: "c.example"}}, nil); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("stale version: err = %v", err)
+	}
+	if _, ok := eng.Get(RuleRef{Domain: "c.example"}); ok {
+		t.Fatal("rejected change was applied")
+	}
+
+	// Updates keep the creator; removing a missing rule changes nothing.
+	ch = mustApply(t, eng, 0, []Rule{{Domain: "b.example", Action: ActionBlock, Note: "n", CreatedBy: "other"}}, nil)
+	if ch.Version != 3 || len(ch.Updated) != 1 {
+		t.Fatalf("update change = %+v", ch)
+	}
+	ch = mustApply(t, eng, 0, nil, []RuleRef{{Domain: "missing.example"}})
+	if !ch.Empty() || ch.Version != 3 || len(ch.Missing) != 1 {
+		t.Fatalf("no-op change = %+v", ch)
+	}
+
+	for _, bad := range []Rule{{Domain: "bad domain"}, {Domain: "x.example", Action: "deny"}} {
+		if _, err := eng.Apply(0, []Rule{bad}, nil); err == nil {
+			t.Errorf("Apply(%+v) succeeded", bad)
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	eng := New([]string{"a.example", "b.example"})
+	mustApply(t, eng, 0, []Rule{
+		{Domain: "a.example", Action: ActionAllow},
+		{Domain: "d.example", Action: ActionBlock, Device: "10.0.0.2"},
+	}, nil)
+	v := eng.Version()
+
+	ch, err := eng.Replace(v, []string{"b.example", "c.example"}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch.Version != v+1 || len(ch.Added) != 1 || len(ch.Removed) != 1 || ch.Removed[0].Domain != "a.example" {
+		t.Fatalf("change = %+v", ch)
+	}
+	if got := eng.List(); !reflect.DeepEqual(got, []string{"b.example", "c.example"}) {
+		t.Fatalf("list = %v", got)
+	}
+	// Allow and device rules are left alone.
+	if len(eng.Rules()) != 4 {
+		t.Fatalf("rules = %+v", eng.Rules())
+	}
+	if c, _ := eng.Get(RuleRef{Domain: "c.example"}); c.CreatedBy != "admin" {
+		t.Fatalf("new rule = %+v", c)
+	}
+
+	// The same list again is not a change and keeps the version.
+	ch, err = eng.Replace(v+1, []string{"c.example", "b.example"}, "admin")
+	if err != nil || !ch.Empty() || ch.Version != v+1 {
+		t.Fatalf("unchanged replace = %+v, %v", ch, err)
+	}
+	if _, err := eng.Replace(v, nil, "admin"); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("stale version: err = %v", err)
+	}
+}
+
+func TestRefRoundTrip(t *testing.T) {
+	for _, ref := range []RuleRef{
+		{Domain: "a.example", Action: ActionBlock},
+		{Domain: "a.example", Action: ActionAllow},
+		{Domain: "a.example", Action: ActionBlock, Device: "10.0.0.2"},
+		{Domain: "a.example", Action: ActionAllow, Device: "aa:bb:cc:dd:ee:ff"},
+	} {
+		if got := ParseRef(ref.String()); got != ref {
+			t.Errorf("ParseRef(%q) = %+v, want %+v", ref.String(), got, ref)
+		}
+	}
+	if got := ParseRef("Ads.Example."); got != (RuleRef{Domain: "ads.example", Action: ActionBlock}) {
+		t.Errorf("ParseRef normalizes to %+v", got)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules", "rules.json")
+	expires := time.Date(2026, 3, 2, 13, 0, 0, 0, time.UTC)
+	eng := New([]string{"a.example"})
+	mustApply(t, eng, 0, []Rule{
+		{Domain: "b.example", Action: ActionAllow, Device: "10.0.0.2", Subdomains: true, Note: "homework", CreatedBy: "admin"},
+		{Domain: "c.example", Action: ActionBlock, ExpiresAt: &expires},
+	}, nil)
+	if err := eng.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path, []string{"seed.example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Version() != eng.Version() {
+		t.Fatalf("version %d, want %d", loaded.Version(), eng.Version())
+	}
+	if got, want := loaded.Rules(), eng.Rules(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("loaded %+v\nwant %+v", got, want)
+	}
+
+	// Without a file the seed domains are used.
+	seeded, err := Load(filepath.Join(t.TempDir(), "missing.json"), []string{"seed.example"})
+	if err != nil || !reflect.DeepEqual(seeded.List(), []string{"seed.example"}) {
+		t.Fatalf("seeded = %v, %v", seeded.List(), err)
+	}
+}
+
+func TestLoadLegacyArray(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`["Ads.Example", "tracker.example."]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	eng, err := Load(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := eng.List(); !reflect.DeepEqual(got, []string{"ads.example", "tracker.example"}) {
+		t.Fatalf("list = %v", got)
+	}
+	if !eng.ShouldBlock("ads.example", "") {
+		t.Fatal("legacy rule does not match")
+	}
+}
+
+func TestPersistRollsBackFailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.json")
+	eng := New(nil)
+	eng.Persist(path)
+	ch := mustApply(t, eng, 0, []Rule{{Domain: "a.example"}}, nil)
+	if loaded, err := Load(path, nil); err != nil || loaded.Version() != ch.Version || !loaded.ShouldBlock("a.example", "") {
+		t.Fatalf("persisted engine: %v", err)
+	}
+
+	// A directory in place of the file makes every write fail.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocked"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.Apply(0, []Rule{{Domain: "b.example"}}, []RuleRef{{Domain: "a.example"}}); err == nil {
+		t.Fatal("Apply succeeded without saving")
+	}
+	if _, err := eng.Replace(0, nil, "admin"); err == nil {
+		t.Fatal("Replace succeeded without saving")
+	}
+	if eng.Version() != ch.Version || !reflect.DeepEqual(eng.List(), []string{"a.example"}) {
+		t.Fatalf("failed writes changed the engine: version %d, list %v", eng.Version(), eng.List())
+	}
+}
+
+func TestTemporaryRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	until := now.Add(30 * time.Minute)
+	eng := New([]string{"games.example"})
+	eng.now = func() time.Time { return now }
+	eng.Persist(path)
+	mustApply(t, eng, 0, []Rule{
+		{Domain: "games.example", Action: ActionAllow, Device: "10.0.0.2", ExpiresAt: &until},
+		{Domain: "video.example", Action: ActionBlock, ExpiresAt: &until},
+	}, nil)
+
+	// The expiry survives a save/load round trip.
+	loaded, err := Load(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.now = eng.now
+	rule, ok := loaded.Get(RuleRef{Domain: "video.example"})
+	if !ok || rule.ExpiresAt == nil || !rule.ExpiresAt.Equal(until) || rule.Remaining(now) != 30*time.Minute {
+		t.Fatalf("loaded rule = %+v", rule)
+	}
+
+	for _, e := range []*RuleEngine{eng, loaded} {
+		now = until.Add(-time.Second)
+		if !e.ShouldBlock("video.example", "") || e.ShouldBlock("games.example", "10.0.0.2") {
+			t.Fatal("temporary rules not in force before they expire")
+		}
+		if retired, _, err := e.Expire(); err != nil || len(retired) != 0 {
+			t.Fatalf("retired early: %+v, %v", retired, err)
+		}
+		// At ExpiresAt the rules stop applying even before they are retired.
+		now = until
+		if e.ShouldBlock("video.example", "") || !e.ShouldBlock("games.example", "10.0.0.2") {
+			t.Fatal("temporary rules still in force at their expiry")
+		}
+	}
+
+	v := eng.Version()
+	retired, version, err := eng.Expire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retired) != 2 || retired[0].Ref() != (RuleRef{Domain: "games.example", Action: ActionAllow, Device: "10.0.0.2"}) || version != v+1 {
+		t.Fatalf("retired %+v at version %d", retired, version)
+	}
+	if retired, _, _ := eng.Expire(); len(retired) != 0 {
+		t.Fatalf("retired twice: %+v", retired)
+	}
+	reloaded, err := Load(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Rules(); len(got) != 1 || got[0].Domain != "games.example" || reloaded.Version() != version {
+		t.Fatalf("file after expiry holds %+v", got)
+	}
+}
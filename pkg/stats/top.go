@@ -0,0 +1,185 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+// TopCounter aggregates DNS query counts per device, action and domain in
+// fixed time buckets so top-N queries can be answered for any time range.
+type TopCounter struct {
+	mu        sync.Mutex
+	bucket    time.Duration
+	retention time.Duration
+	buckets   map[int64]map[topKey]*DomainCount
+	now       func() time.Time
+}
+
+type topKey struct {
+	device string
+	action string
+	domain string
+}
+
+// DomainCount is the number of queries for a domain within a range.
+type DomainCount struct {
+	Domain   string    `json:"domain"`
+	Count    uint64    `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// DeviceTop lists the most blocked and allowed domains of one device.
+type DeviceTop struct {
+	Device  string        `json:"device"`
+	Blocked []DomainCount `json:"blocked"`
+	Allowed []DomainCount `json:"allowed"`
+}
+
+// TopQuery selects the devices and time range to report on.
+type TopQuery struct {
+	Device string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// NewTopCounter creates a counter with the given bucket width and retention.
+func NewTopCounter(bucket, retention time.Duration) *TopCounter {
+	return &TopCounter{
+		bucket:    bucket,
+		retention: retention,
+		buckets:   make(map[int64]map[topKey]*DomainCount),
+		now:       time.Now,
+	}
+}
+
+// Record counts a DNS query event; other events are ignored.
+func (t *TopCounter) Record(ev events.Event) {
+	if ev.Kind != "dns" || ev.Domain == "" || ev.SourceIP == "" {
+		return
+	}
+	if ev.Direction != "" && ev.Direction != "query" {
+		return
+	}
+	if ev.Action != "block" && ev.Action != "allow" {
+		return
+	}
+	ts := ev.Timestamp
+	if ts.IsZero() {
+		ts = t.now()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	idx := ts.UnixNano() / int64(t.bucket)
+	counts := t.buckets[idx]
+	if counts == nil {
+		counts = make(map[topKey]*DomainCount)
+		t.buckets[idx] = counts
+		t.pruneLocked()
+	}
+	key := topKey{device: ev.SourceIP, action: ev.Action, domain: ev.Domain}
+	dc := counts[key]
+	if dc == nil {
+		dc = &DomainCount{Domain: ev.Domain}
+		counts[key] = dc
+	}
+	dc.Count++
+	if ts.After(dc.LastSeen) {
+		dc.LastSeen = ts
+	}
+}
+
+// Top returns per-device top blocked and allowed domains for buckets
+// overlapping the query range.
+func (t *TopCounter) Top(q TopQuery) []DeviceTop {
+	if q.Limit <= 0 {
+		q.Limit = 10
+	}
+	until := q.Until
+	if until.IsZero() {
+		until = t.now()
+	}
+	first := q.Since.UnixNano() / int64(t.bucket)
+	last := until.UnixNano() / int64(t.bucket)
+
+	type agg struct {
+		blocked map[string]*DomainCount
+		allowed map[string]*DomainCount
+	}
+	perDevice := make(map[string]*agg)
+
+	t.mu.Lock()
+	for idx, counts := range t.buckets {
+		if idx < first || idx > last {
+			continue
+		}
+		for key, dc := range counts {
+			if q.Device != "" && key.device != q.Device {
+				continue
+			}
+			a := perDevice[key.device]
+			if a == nil {
+				a = &agg{blocked: map[string]*DomainCount{}, allowed: map[string]*DomainCount{}}
+				perDevice[key.device] = a
+			}
+			target := a.allowed
+			if key.action == "block" {
+				target = a.blocked
+			}
+			sum := target[key.domain]
+			if sum == nil {
+				sum = &DomainCount{Domain: key.domain}
+				target[key.domain] = sum
+			}
+			sum.Count += dc.Count
+			if dc.LastSeen.After(sum.LastSeen) {
+				sum.LastSeen = dc.LastSeen
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	out := make([]DeviceTop, 0, len(perDevice))
+	for device, a := range perDevice {
+		out = append(out, DeviceTop{
+			Device:  device,
+			Blocked: topN(a.blocked, q.Limit),
+			Allowed: topN(a.allowed, q.Limit),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Device < out[j].Device })
+	return out
+}
+
+func (t *TopCounter) pruneLocked() {
+	if t.retention <= 0 {
+		return
+	}
+	oldest := t.now().Add(-t.retention).UnixNano() / int64(t.bucket)
+	for idx := range t.buckets {
+		if idx < oldest {
+			delete(t.buckets, idx)
+		}
+	}
+}
+
+func topN(m map[string]*DomainCount, limit int) []DomainCount {
+	out := make([]DomainCount, 0, len(m))
+	for _, dc := range m {
+		out = append(out, *dc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count == out[j].Count {
+			return out[i].Domain < out[j].Domain
+		}
+		return out[i].Count > out[j].Count
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/events"
+)
+
+func dnsEvent(at time.Time, device, action, domain string) events.Event {
+	return events.Event{Kind: "dns", Timestamp: at, SourceIP: device, Action: action, Domain: domain}
+}
+
+func counts(dcs []DomainCount) map[string]uint64 {
+	out := make(map[string]uint64, len(dcs))
+	for _, dc := range dcs {
+		out[dc.Domain] = dc.Count
+	}
+	return out
+}
+
+func TestTopAggregatesAcrossBuckets(t *testing.T) {
+	base := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	c := NewTopCounter(time.Minute, 0)
+	c.now = func() time.Time { return base.Add(time.Hour) }
+	for _, ev := range []events.Event{
+		dnsEvent(base, "10.0.0.2", "block", "ads.example"),
+		dnsEvent(base.Add(10*time.Second), "10.0.0.2", "block", "ads.example"),
+		dnsEvent(base.Add(2*time.Minute), "10.0.0.2", "block", "ads.example"),
+		dnsEvent(base.Add(3*time.Minute), "10.0.0.2", "block", "games.example"),
+		dnsEvent(base.Add(3*time.Minute), "10.0.0.2", "allow", "school.example"),
+		dnsEvent(base.Add(4*time.Minute), "10.0.0.3", "block", "ads.example"),
+	} {
+		c.Record(ev)
+	}
+	// Events the counter does not track.
+	c.Record(dnsEvent(base, "10.0.0.2", "error", "broken.example"))
+	c.Record(dnsEvent(base, "", "block", "ads.example"))
+	c.Record(events.Event{Kind: "dns", Timestamp: base, SourceIP: "10.0.0.2", Action: "block", Domain: "ads.example", Direction: "response"})
+	c.Record(events.Event{Kind: "control", Timestamp: base, SourceIP: "10.0.0.2", Action: "block", Domain: "ads.example"})
+
+	top := c.Top(TopQuery{})
+	if len(top) != 2 || top[0].Device != "10.0.0.2" || top[1].Device != "10.0.0.3" {
+		t.Fatalf("devices %+v", top)
+	}
+	kid := top[0]
+	if !reflect.DeepEqual(counts(kid.Blocked), map[string]uint64{"ads.example": 3, "games.example": 1}) {
+		t.Fatalf("blocked %+v", kid.Blocked)
+	}
+	if kid.Blocked[0].Domain != "ads.example" || !kid.Blocked[0].LastSeen.Equal(base.Add(2*time.Minute)) {
+		t.Fatalf("top blocked %+v", kid.Blocked[0])
+	}
+	if !reflect.DeepEqual(counts(kid.Allowed), map[string]uint64{"school.example": 1}) {
+		t.Fatalf("allowed %+v", kid.Allowed)
+	}
+
+	// A range covers the buckets it overlaps.
+	ranged := c.Top(TopQuery{Device: "10.0.0.2", Since: base.Add(90 * time.Second), Until: base.Add(150 * time.Second)})
+	if len(ranged) != 1 || !reflect.DeepEqual(counts(ranged[0].Blocked), map[string]uint64{"ads.example": 1}) || len(ranged[0].Allowed) != 0 {
+		t.Fatalf("ranged %+v", ranged)
+	}
+	if got := c.Top(TopQuery{Device: "10.0.0.9"}); len(got) != 0 {
+		t.Fatalf("unknown device %+v", got)
+	}
+}
+
+func TestTopLimitAndOrder(t *testing.T) {
+	base := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	c := NewTopCounter(time.Minute, 0)
+	c.now = func() time.Time { return base.Add(time.Minute) }
+	for domain, n := range map[string]int{"a.example": 1, "b.example": 3, "c.example": 3, "d.example": 2} {
+		for i := 0; i < n; i++ {
+			c.Record(dnsEvent(base, "10.0.0.2", "block", domain))
+		}
+	}
+	top := c.Top(TopQuery{Limit: 3})
+	var got []string
+	for _, dc := range top[0].Blocked {
+		got = append(got, dc.Domain)
+	}
+	// Ties are broken by name.
+	if want := []string{"b.example", "c.example", "d.example"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("top 3 = %v, want %v", got, want)
+	}
+	if n := len(c.Top(TopQuery{})[0].Blocked); n != 4 {
+		t.Fatalf("default limit returned %d domains", n)
+	}
+}
+
+func TestTopRetention(t *testing.T) {
+	base := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	now := base
+	c := NewTopCounter(time.Minute, 10*time.Minute)
+	c.now = func() time.Time { return now }
+	c.Record(dnsEvent(base, "10.0.0.2", "block", "old.example"))
+
+	// Opening a new bucket past the retention drops the old one.
+	now = base.Add(20 * time.Minute)
+	c.Record(dnsEvent(now, "10.0.0.2", "block", "new.example"))
+	top := c.Top(TopQuery{Since: base})
+	if len(top) != 1 || !reflect.DeepEqual(counts(top[0].Blocked), map[string]uint64{"new.example": 1}) {
+		t.Fatalf("after retention %+v", top)
+	}
+}
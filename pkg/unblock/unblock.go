@@ -0,0 +1,246 @@
+package unblock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kidos/kidosserver/pkg/fsutil"
+)
+
+// Request states.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusDenied   = "denied"
+)
+
+const (
+	// MaxPendingPerDevice caps how many open requests one device may have.
+	MaxPendingPerDevice = 10
+	// MaxReasonLen bounds the free-text reason a child may enter.
+	MaxReasonLen = 500
+	// keepDecided is how long decided requests stay in the queue.
+	keepDecided = 30 * 24 * time.Hour
+)
+
+// Errors returned by the queue.
+var (
+	ErrNotFound   = errors.New("request not found")
+	ErrDecided    = errors.New("request already decided")
+	ErrClaimed    = errors.New("request is being decided")
+	ErrTooMany    = errors.New("too many pending requests")
+	ErrReasonSize = errors.New("reason is too long")
+)
+
+// Request is a child's plea to unblock Domain on Device.
+type Request struct {
+	ID        string     `json:"id"`
+	Domain    string     `json:"domain"`
+	Device    string     `json:"device"`
+	Rule      string     `json:"rule,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	Status    string     `json:"status"`
+	Created   time.Time  `json:"created"`
+	DecidedBy string     `json:"decidedBy,omitempty"`
+	DecidedAt *time.Time `json:"decidedAt,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Queue keeps unblock requests in a JSON file.
+type Queue struct {
+	mu   sync.Mutex
+	path string
+	reqs map[string]*Request
+	now  func() time.Time
+
+	// claimed holds the pending requests a decision is in progress for.
+	claimed map[string]bool
+}
+
+// Open loads the queue stored at path; a missing file is an empty queue.
+func Open(path string) (*Queue, error) {
+	q := &Queue{path: path, reqs: make(map[string]*Request), now: time.Now}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read unblock requests: %w", err)
+	}
+	var list []*Request
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parse unblock requests: %w", err)
+	}
+	for _, r := range list {
+		q.reqs[r.ID] = r
+	}
+	return q, nil
+}
+
+// Submit queues a request. A pending request for the same domain and device
+// is updated instead, and created reports which happened.
+func (q *Queue) Submit(domain, device, rule, reason string) (req Request, created bool, err error) {
+	if len(reason) > MaxReasonLen {
+		return Request{}, false, ErrReasonSize
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pending := 0
+	for _, r := range q.reqs {
+		if r.Status != StatusPending || r.Device != device {
+			continue
+		}
+		if r.Domain == domain {
+			r.Reason, r.Rule = reason, rule
+			return *r, false, q.save()
+		}
+		pending++
+	}
+	if pending >= MaxPendingPerDevice {
+		return Request{}, false, ErrTooMany
+	}
+	id, err := newID()
+	if err != nil {
+		return Request{}, false, err
+	}
+	r := &Request{
+		ID:      id,
+		Domain:  domain,
+		Device:  device,
+		Rule:    rule,
+		Reason:  reason,
+		Status:  StatusPending,
+		Created: q.now().UTC(),
+	}
+	q.reqs[id] = r
+	return *r, true, q.save()
+}
+
+// Claim reserves a pending request for one decider, who then either decides
+// it or releases the claim. A request being decided cannot be claimed again.
+func (q *Queue) Claim(id string) (Request, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	r, ok := q.reqs[id]
+	if !ok {
+		return Request{}, ErrNotFound
+	}
+	if r.Status != StatusPending {
+		return *r, ErrDecided
+	}
+	if q.claimed[id] {
+		return *r, ErrClaimed
+	}
+	if q.claimed == nil {
+		q.claimed = make(map[string]bool)
+	}
+	q.claimed[id] = true
+	return *r, nil
+}
+
+// Release gives up a claim without deciding the request.
+func (q *Queue) Release(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.claimed, id)
+}
+
+// Decide approves or denies a pending request, ending any claim on it.
+func (q *Queue) Decide(id, status, by, note string, expires *time.Time) (Request, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	r, ok := q.reqs[id]
+	if !ok {
+		return Request{}, ErrNotFound
+	}
+	if r.Status != StatusPending {
+		return *r, ErrDecided
+	}
+	delete(q.claimed, id)
+	now := q.now().UTC()
+	r.Status, r.DecidedBy, r.DecidedAt, r.Note, r.ExpiresAt = status, by, &now, note, expires
+	return *r, q.save()
+}
+
+// Get returns the request with id.
+func (q *Queue) Get(id string) (Request, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	r, ok := q.reqs[id]
+	if !ok {
+		return Request{}, false
+	}
+	return *r, true
+}
+
+// Latest returns the newest request for domain from device.
+func (q *Queue) Latest(domain, device string) (Request, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var latest *Request
+	for _, r := range q.reqs {
+		if r.Domain == domain && r.Device == device && (latest == nil || r.Created.After(latest.Created)) {
+			latest = r
+		}
+	}
+	if latest == nil {
+		return Request{}, false
+	}
+	return *latest, true
+}
+
+// List returns the requests with status, or all when empty, newest first.
+func (q *Queue) List(status string) []Request {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]Request, 0, len(q.reqs))
+	for _, r := range q.reqs {
+		if status == "" || r.Status == status {
+			out = append(out, *r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.After(out[j].Created) })
+	return out
+}
+
+// save prunes old decided requests and writes the queue atomically.
+func (q *Queue) save() error {
+	cutoff := q.now().Add(-keepDecided)
+	list := make([]*Request, 0, len(q.reqs))
+	for id, r := range q.reqs {
+		if r.DecidedAt != nil && r.DecidedAt.Before(cutoff) {
+			delete(q.reqs, id)
+			continue
+		}
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Created.Before(list[j].Created) })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialize unblock requests: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0o755); err != nil {
+		return fmt.Errorf("create unblock dir: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(q.path, data, 0o644); err != nil {
+		return fmt.Errorf("write unblock requests: %w", err)
+	}
+	return nil
+}
+
+func newID() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,113 @@
+package unblock
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openQueue(t *testing.T) (*Queue, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "unblock.json")
+	q, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q, path
+}
+
+func TestSubmitDedupesPending(t *testing.T) {
+	q, path := openQueue(t)
+	first, created, err := q.Submit("games.example", "10.0.0.2", "games.example", "homework")
+	if err != nil || !created {
+		t.Fatalf("first submit: created %v, err %v", created, err)
+	}
+	again, created, err := q.Submit("games.example", "10.0.0.2", "games.example", "please")
+	if err != nil || created || again.ID != first.ID || again.Reason != "please" {
+		t.Fatalf("repeat submit = %+v, created %v, err %v", again, created, err)
+	}
+	// Another device asking for the same domain is a separate request.
+	if _, created, err := q.Submit("games.example", "10.0.0.3", "games.example", ""); err != nil || !created {
+		t.Fatalf("other device: created %v, err %v", created, err)
+	}
+	// Once decided, asking again opens a new request.
+	if _, err := q.Decide(first.ID, StatusDenied, "admin", "no", nil); err != nil {
+		t.Fatal(err)
+	}
+	next, created, err := q.Submit("games.example", "10.0.0.2", "games.example", "really")
+	if err != nil || !created || next.ID == first.ID {
+		t.Fatalf("submit after decision = %+v, created %v, err %v", next, created, err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.List(StatusPending); len(got) != 2 {
+		t.Fatalf("reopened queue has %d pending requests, want 2", len(got))
+	}
+	if latest, ok := reopened.Latest("games.example", "10.0.0.2"); !ok || latest.ID != next.ID {
+		t.Fatalf("latest = %+v", latest)
+	}
+}
+
+func TestSubmitLimits(t *testing.T) {
+	q, _ := openQueue(t)
+	for i := 0; i < MaxPendingPerDevice; i++ {
+		if _, _, err := q.Submit(fmt.Sprintf("site%d.example", i), "10.0.0.2", "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := q.Submit("more.example", "10.0.0.2", "", ""); !errors.Is(err, ErrTooMany) {
+		t.Fatalf("submit over the limit: err = %v", err)
+	}
+	// Updating an open request and other devices are not limited.
+	if _, _, err := q.Submit("site0.example", "10.0.0.2", "", "again"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := q.Submit("more.example", "10.0.0.3", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := q.Submit("x.example", "10.0.0.3", "", strings.Repeat("x", MaxReasonLen+1)); !errors.Is(err, ErrReasonSize) {
+		t.Fatalf("long reason: err = %v", err)
+	}
+}
+
+func TestClaimAndDecide(t *testing.T) {
+	q, _ := openQueue(t)
+	req, _, err := q.Submit("games.example", "10.0.0.2", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Claim("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("claim missing: err = %v", err)
+	}
+	if _, err := q.Claim(req.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Claim(req.ID); !errors.Is(err, ErrClaimed) {
+		t.Fatalf("second claim: err = %v", err)
+	}
+	q.Release(req.ID)
+	if _, err := q.Claim(req.ID); err != nil {
+		t.Fatalf("claim after release: %v", err)
+	}
+
+	until := time.Now().Add(time.Hour).UTC()
+	got, err := q.Decide(req.ID, StatusApproved, "admin", "ok", &until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusApproved || got.DecidedBy != "admin" || got.DecidedAt == nil || !got.ExpiresAt.Equal(until) {
+		t.Fatalf("decided = %+v", got)
+	}
+	if _, err := q.Claim(req.ID); !errors.Is(err, ErrDecided) {
+		t.Fatalf("claim decided: err = %v", err)
+	}
+	if _, err := q.Decide(req.ID, StatusDenied, "admin", "", nil); !errors.Is(err, ErrDecided) {
+		t.Fatalf("decide twice: err = %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+#!/usr/bin/env bash
+# Regenerates pkg/devices/oui.txt, the vendor table embedded in the binaries,
+# from the full IEEE MA-L registry. Only the "(hex)" lines are kept.
+set -euo pipefail
+
+ROOT_DIR=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+OUI_URL=${OUI_URL:-https://standards-oui.ieee.org/oui/oui.txt}
+OUT=${1:-${ROOT_DIR}/pkg/devices/oui.txt}
+MIN_ENTRIES=${MIN_ENTRIES:-20000}
+
+tmp=$(mktemp)
+trap 'rm -f "${tmp}" "${OUT}.new"' EXIT
+
+curl -fsSL --retry 3 -A "kidos-gen-oui" "${OUI_URL}" -o "${tmp}"
+
+{
+	echo "# IEEE MA-L registry (${OUI_URL}), fetched $(date -u +%Y-%m-%d)."
+	echo "# Generated by scripts/gen-oui.sh; do not edit."
+	tr -d '\r' <"${tmp}" |
+		grep -E '^[0-9A-Fa-f]{2}-[0-9A-Fa-f]{2}-[0-9A-Fa-f]{2}[[:space:]]+\(hex\)' |
+		sed -E 's/^([0-9A-Fa-f-]{8})[[:space:]]+\(hex\)[[:space:]]*/\1   (hex)\t\t/' |
+		LC_ALL=C sort -u
+} >"${OUT}.new"
+
+count=$(grep -c '(hex)' "${OUT}.new" || true)
+if [ "${count}" -lt "${MIN_ENTRIES}" ]; then
+	echo "gen-oui: only ${count} entries from ${OUI_URL}, keeping ${OUT}" >&2
+	exit 1
+fi
+mv "${OUT}.new" "${OUT}"
+echo "gen-oui: wrote ${count} entries to ${OUT}"
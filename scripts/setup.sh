@@ -98,6 +98,7 @@ MONITOR_IFINDEX=$(ip netns exec "${NS_NAME}" cat /sys/class/net/${MONITOR_NS_IF}
 MONITOR_IF_HEX=$(printf "%02x %02x %02x %02x" $((MONITOR_IFINDEX & 0xff)) $(((MONITOR_IFINDEX >> 8) & 0xff)) $(((MONITOR_IFINDEX >> 16) & 0xff)) $(((MONITOR_IFINDEX >> 24) & 0xff)))
 
 WEB_LISTEN="${MGMT_HOST_ADDR}:8080"
+WEB_TLS_LISTEN="${MGMT_HOST_ADDR}:8443"
 
 python3 - <<PYCONF
 import json
@@ -111,6 +112,10 @@ except FileNotFoundError:
 cfg.setdefault("interfaces", {})["physical"] = "${DNS_IF}"
 cfg.setdefault("dns", {}).setdefault("blocklist", [])
 cfg.setdefault("web", {})["listen"] = "${WEB_LISTEN}"
+cfg["web"].setdefault("tls", {}).setdefault("listen", "${WEB_TLS_LISTEN}")
+servers = cfg.setdefault("pause", {}).setdefault("serverAddrs", [])
+if "${MGMT_HOST_ADDR}" not in servers:
+    servers.append("${MGMT_HOST_ADDR}")
 
 with open(path, "w", encoding="utf-8") as f:
     json.dump(cfg, f, indent=2)
@@ -131,6 +136,7 @@ cd "${ROOT_DIR}"
 go build -o "${ROOT_DIR}/bin/dns-inspector" "${ROOT_DIR}/cmd/dns-inspector"
 go build -o "${ROOT_DIR}/bin/monitor" "${ROOT_DIR}/cmd/monitor"
 go build -o "${ROOT_DIR}/bin/web" "${ROOT_DIR}/cmd/web"
+go build -o "${ROOT_DIR}/bin/resolver" "${ROOT_DIR}/cmd/resolver"
 
 ip netns exec "${NS_NAME}" ip link set dev "${DNS_IF}" xdp off 2>/dev/null || true
 ip netns exec "${NS_NAME}" ip link set dev "${DNS_IF}" xdp obj "${ROOT_DIR}/bpf/xdp_dns_redirect.bpf.o" sec xdp
@@ -165,7 +171,8 @@ case "${WEB_LISTEN}" in
   :*) UI_URL="http://${MGMT_HOST_ADDR}${WEB_LISTEN}" ;;
   *) UI_URL="http://${WEB_LISTEN}" ;;
 esac
-echo "[kidos] services started. UI at ${UI_URL}"
+echo "[kidos] services started. UI at ${UI_URL} (redirects to https://${WEB_TLS_LISTEN} when web.tls is enabled)"
+echo "[kidos] install ${UI_URL}/ca.crt on parent devices to trust the HTTPS certificate"
 
 CHROMIUM="/usr/bin/chromium-browser"
 APP_USER=${SUDO_USER:-$USER}
@@ -25,6 +25,7 @@ fi
 pkill -f "${ROOT_DIR}/bin/dns-inspector" 2>/dev/null || true
 pkill -f "${ROOT_DIR}/bin/monitor" 2>/dev/null || true
 pkill -f "${ROOT_DIR}/bin/web" 2>/dev/null || true
+pkill -f "${ROOT_DIR}/bin/resolver" 2>/dev/null || true
 pkill -f chromium-browser 2>/dev/null || true
 sleep 1
 pkill -9 -f "${ROOT_DIR}/bin/monitor" 2>/dev/null || true
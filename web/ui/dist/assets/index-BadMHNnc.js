@@ -1,40 +0,0 @@
-(function(){const t=document.createElement("link").relList;if(t&&t.supports&&t.supports("modulepreload"))return;for(const l of document.querySelectorAll('link[rel="modulepreload"]'))r(l);new MutationObserver(l=>{for(const u of l)if(u.type==="childList")for(const o of u.addedNodes)o.tagName==="LINK"&&o.rel==="modulepreload"&&r(o)}).observe(document,{childList:!0,subtree:!0});function n(l){const u={};return l.integrity&&(u.integrity=l.integrity),l.referrerPolicy&&(u.referrerPolicy=l.referrerPolicy),l.crossOrigin==="use-credentials"?u.credentials="include":l.crossOrigin==="anonymous"?u.credentials="omit":u.credentials="same-origin",u}function r(l){if(l.ep)return;l.ep=!0;const u=n(l);fetch(l.href,u)}})();function sc(e){return e&&e.__esModule&&Object.prototype.hasOwnProperty.call(e,"default")?e.default:e}var Xi={exports:{}},tl={},Gi={exports:{}},D={};/**
- * @license React
- * react.production.min.js
- *
- * Copyright (c) Facebook, Inc. and its affiliates.
- *
- * This source code is licensed under the MIT license found in the
- * LICENSE file in the root directory of this source tree.
- */var Xn=Symbol.for("react.element"),ac=Symbol.for("react.portal"),cc=Symbol.for("react.fragment"),fc=Symbol.for("react.strict_mode"),dc=Symbol.for("react.profiler"),pc=Symbol.for("react.provider"),mc=Symbol.for("react.context"),hc=Symbol.for("react.forward_ref"),vc=Symbol.for("react.suspense"),yc=Symbol.for("react.memo"),gc=Symbol.for("react.lazy"),$o=Symbol.iterator;function wc(e){return e===null||typeof e!="object"?null:(e=$o&&e[$o]||e["@@iterator"],typeof e=="function"?e:null)}var Zi={isMounted:function(){return!1},enqueueForceUpdate:function(){},enqueueReplaceState:function(){},enqueueSetState:function(){}},Ji=Object.assign,qi={};function ln(e,t,n){this.props=e,this.context=t,this.refs=qi,this.updater=n||Zi}ln.prototype.isReactComponent={};ln.prototype.setState=function(e,t){if(typeof e!="object"&&typeof e!="function"&&e!=null)throw Error("setState(...): takes an object of state variables to update or a function which returns an object of state variables.");this.updater.enqueueSetState(this,e,t,"setState")};ln.prototype.forceUpdate=function(e){this.updater.enqueueForceUpdate(this,e,"forceUpdate")};function bi(){}bi.prototype=ln.prototype;function Vu(e,t,n){this.props=e,this.context=t,this.refs=qi,this.updater=n||Zi}var Hu=Vu.prototype=new bi;Hu.constructor=Vu;Ji(Hu,ln.prototype);Hu.isPureReactComponent=!0;var Uo=Array.isArray,es=Object.prototype.hasOwnProperty,Wu={current:null},ts={key:!0,ref:!0,__self:!0,__source:!0};function ns(e,t,n){var r,l={},u=null,o=null;if(t!=null)for(r in t.ref!==void 0&&(o=t.ref),t.key!==void 0&&(u=""+t.key),t)es.call(t,r)&&!ts.hasOwnProperty(r)&&(l[r]=t[r]);var i=arguments.length-2;if(i===1)l.children=n;else if(1<i){for(var s=Array(i),f=0;f<i;f++)s[f]=arguments[f+2];l.children=s}if(e&&e.defaultProps)for(r in i=e.defaultProps,i)l[r]===void 0&&(l[r]=i[r]);return{$$typeof:Xn,type:e,key:u,ref:o,props:l,_owner:Wu.current}}function Sc(e,t){return{$$typeof:Xn,type:e.type,key:t,ref:e.ref,props:e.props,_owner:e._owner}}function Qu(e){return typeof e=="object"&&e!==null&&e.$$typeof===Xn}function kc(e){var t={"=":"=0",":":"=2"};return"$"+e.replace(/[=:]/g,function(n){return t[n]})}var Ao=/\/+/g;function kl(e,t){return typeof e=="object"&&e!==null&&e.key!=null?kc(""+e.key):t.toString(36)}function gr(e,t,n,r,l){var u=typeof e;(u==="undefined"||u==="boolean")&&(e=null);var o=!1;if(e===null)o=!0;else switch(u){case"string":case"number":o=!0;break;case"object":switch(e.$$typeof){case Xn:case ac:o=!0}}if(o)return o=e,l=l(o),e=r===""?"."+kl(o,0):r,Uo(l)?(n="",e!=null&&(n=e.replace(Ao,"$&/")+"/"),gr(l,t,n,"",function(f){return f})):l!=null&&(Qu(l)&&(l=Sc(l,n+(!l.key||o&&o.key===l.key?"":(""+l.key).replace(Ao,"$&/")+"/")+e)),t.push(l)),1;if(o=0,r=r===""?".":r+":",Uo(e))for(var i=0;i<e.length;i++){u=e[i];var s=r+kl(u,i);o+=gr(u,t,n,s,l)}else if(s=wc(e),typeof s=="function")for(e=s.call(e),i=0;!(u=e.next()).done;)u=u.value,s=r+kl(u,i++),o+=gr(u,t,n,s,l);else if(u==="object")throw t=String(e),Error("Objects are not valid as a React child (found: "+(t==="[object Object]"?"object with keys {"+Object.keys(e).join(", ")+"}":t)+"). If you meant to render a collection of children, use an array instead.");return o}function tr(e,t,n){if(e==null)return e;var r=[],l=0;return gr(e,r,"","",function(u){return t.call(n,u,l++)}),r}function Ec(e){if(e._status===-1){var t=e._result;t=t(),t.then(function(n){(e._status===0||e._status===-1)&&(e._status=1,e._result=n)},function(n){(e._status===0||e._status===-1)&&(e._status=2,e._result=n)}),e._status===-1&&(e._status=0,e._result=t)}if(e._status===1)return e._result.default;throw e._result}var ie={current:null},wr={transition:null},xc={ReactCurrentDispatcher:ie,ReactCurrentBatchConfig:wr,ReactCurrentOwner:Wu};D.Children={map:tr,forEach:function(e,t,n){tr(e,function(){t.apply(this,arguments)},n)},count:function(e){var t=0;return tr(e,function(){t++}),t},toArray:function(e){return tr(e,function(t){return t})||[]},only:function(e){if(!Qu(e))throw Error("React.Children.only expected to receive a single React element child.");return e}};D.Component=ln;D.Fragment=cc;D.Profiler=dc;D.PureComponent=Vu;D.StrictMode=fc;D.Suspense=vc;D.__SECRET_INTERNALS_DO_NOT_USE_OR_YOU_WILL_BE_FIRED=xc;D.cloneElement=function(e,t,n){if(e==null)throw Error("React.cloneElement(...): The argument must be a React element, but you passed "+e+".");var r=Ji({},e.props),l=e.key,u=e.ref,o=e._owner;if(t!=null){if(t.ref!==void 0&&(u=t.ref,o=Wu.current),t.key!==void 0&&(l=""+t.key),e.type&&e.type.defaultProps)var i=e.type.defaultProps;for(s in t)es.call(t,s)&&!ts.hasOwnProperty(s)&&(r[s]=t[s]===void 0&&i!==void 0?i[s]:t[s])}var s=arguments.length-2;if(s===1)r.children=n;else if(1<s){i=Array(s);for(var f=0;f<s;f++)i[f]=arguments[f+2];r.children=i}return{$$typeof:Xn,type:e.type,key:l,ref:u,props:r,_owner:o}};D.createContext=function(e){return e={$$typeof:mc,_currentValue:e,_currentValue2:e,_threadCount:0,Provider:null,Consumer:null,_defaultValue:null,_globalName:null},e.Provider={$$typeof:pc,_context:e},e.Consumer=e};D.createElement=ns;D.createFactory=function(e){var t=ns.bind(null,e);return t.type=e,t};D.createRef=function(){return{current:null}};D.forwardRef=function(e){return{$$typeof:hc,render:e}};D.isValidElement=Qu;D.lazy=function(e){return{$$typeof:gc,_payload:{_status:-1,_result:e},_init:Ec}};D.memo=function(e,t){return{$$typeof:yc,type:e,compare:t===void 0?null:t}};D.startTransition=function(e){var t=wr.transition;wr.transition={};try{e()}finally{wr.transition=t}};D.unstable_act=function(){throw Error("act(...) is not supported in production builds of React.")};D.useCallback=function(e,t){return ie.current.useCallback(e,t)};D.useContext=function(e){return ie.current.useContext(e)};D.useDebugValue=function(){};D.useDeferredValue=function(e){return ie.current.useDeferredValue(e)};D.useEffect=function(e,t){return ie.current.useEffect(e,t)};D.useId=function(){return ie.current.useId()};D.useImperativeHandle=function(e,t,n){return ie.current.useImperativeHandle(e,t,n)};D.useInsertionEffect=function(e,t){return ie.current.useInsertionEffect(e,t)};D.useLayoutEffect=function(e,t){return ie.current.useLayoutEffect(e,t)};D.useMemo=function(e,t){return ie.current.useMemo(e,t)};D.useReducer=function(e,t,n){return ie.current.useReducer(e,t,n)};D.useRef=function(e){return ie.current.useRef(e)};D.useState=function(e){return ie.current.useState(e)};D.useSyncExternalStore=function(e,t,n){return ie.current.useSyncExternalStore(e,t,n)};D.useTransition=function(){return ie.current.useTransition()};D.version="18.2.0";Gi.exports=D;var me=Gi.exports;const Cc=sc(me);/**
- * @license React
- * react-jsx-runtime.production.min.js
- *
- * Copyright (c) Facebook, Inc. and its affiliates.
- *
- * This source code is licensed under the MIT license found in the
- * LICENSE file in the root directory of this source tree.
- */var _c=me,Nc=Symbol.for("react.element"),Pc=Symbol.for("react.fragment"),zc=Object.prototype.hasOwnProperty,Tc=_c.__SECRET_INTERNALS_DO_NOT_USE_OR_YOU_WILL_BE_FIRED.ReactCurrentOwner,Lc={key:!0,ref:!0,__self:!0,__source:!0};function rs(e,t,n){var r,l={},u=null,o=null;n!==void 0&&(u=""+n),t.key!==void 0&&(u=""+t.key),t.ref!==void 0&&(o=t.ref);for(r in t)zc.call(t,r)&&!Lc.hasOwnProperty(r)&&(l[r]=t[r]);if(e&&e.defaultProps)for(r in t=e.defaultProps,t)l[r]===void 0&&(l[r]=t[r]);return{$$typeof:Nc,type:e,key:u,ref:o,props:l,_owner:Tc.current}}tl.Fragment=Pc;tl.jsx=rs;tl.jsxs=rs;Xi.exports=tl;var z=Xi.exports,Gl={},ls={exports:{}},we={},us={exports:{}},os={};/**
- * @license React
- * scheduler.production.min.js
- *
- * Copyright (c) Facebook, Inc. and its affiliates.
- *
- * This source code is licensed under the MIT license found in the
- * LICENSE file in the root directory of this source tree.
- */(function(e){function t(x,T){var L=x.length;x.push(T);e:for(;0<L;){var W=L-1>>>1,G=x[W];if(0<l(G,T))x[W]=T,x[L]=G,L=W;else break e}}function n(x){return x.length===0?null:x[0]}function r(x){if(x.length===0)return null;var T=x[0],L=x.pop();if(L!==T){x[0]=L;e:for(var W=0,G=x.length,bn=G>>>1;W<bn;){var vt=2*(W+1)-1,Sl=x[vt],yt=vt+1,er=x[yt];if(0>l(Sl,L))yt<G&&0>l(er,Sl)?(x[W]=er,x[yt]=L,W=yt):(x[W]=Sl,x[vt]=L,W=vt);else if(yt<G&&0>l(er,L))x[W]=er,x[yt]=L,W=yt;else break e}}return T}function l(x,T){var L=x.sortIndex-T.sortIndex;return L!==0?L:x.id-T.id}if(typeof performance=="object"&&typeof performance.now=="function"){var u=performance;e.unstable_now=function(){return u.now()}}else{var o=Date,i=o.now();e.unstable_now=function(){return o.now()-i}}var s=[],f=[],m=1,h=null,p=3,g=!1,w=!1,S=!1,P=typeof setTimeout=="function"?setTimeout:null,c=typeof clearTimeout=="function"?clearTimeout:null,a=typeof setImmediate<"u"?setImmediate:null;typeof navigator<"u"&&navigator.scheduling!==void 0&&navigator.scheduling.isInputPending!==void 0&&navigator.scheduling.isInputPending.bind(navigator.scheduling);function d(x){for(var T=n(f);T!==null;){if(T.callback===null)r(f);else if(T.startTime<=x)r(f),T.sortIndex=T.expirationTime,t(s,T);else break;T=n(f)}}function v(x){if(S=!1,d(x),!w)if(n(s)!==null)w=!0,gl(E);else{var T=n(f);T!==null&&wl(v,T.startTime-x)}}function E(x,T){w=!1,S&&(S=!1,c(N),N=-1),g=!0;var L=p;try{for(d(T),h=n(s);h!==null&&(!(h.expirationTime>T)||x&&!Pe());){var W=h.callback;if(typeof W=="function"){h.callback=null,p=h.priorityLevel;var G=W(h.expirationTime<=T);T=e.unstable_now(),typeof G=="function"?h.callback=G:h===n(s)&&r(s),d(T)}else r(s);h=n(s)}if(h!==null)var bn=!0;else{var vt=n(f);vt!==null&&wl(v,vt.startTime-T),bn=!1}return bn}finally{h=null,p=L,g=!1}}var C=!1,_=null,N=-1,H=5,j=-1;function Pe(){return!(e.unstable_now()-j<H)}function sn(){if(_!==null){var x=e.unstable_now();j=x;var T=!0;try{T=_(!0,x)}finally{T?an():(C=!1,_=null)}}else C=!1}var an;if(typeof a=="function")an=function(){a(sn)};else if(typeof MessageChannel<"u"){var Fo=new MessageChannel,ic=Fo.port2;Fo.port1.onmessage=sn,an=function(){ic.postMessage(null)}}else an=function(){P(sn,0)};function gl(x){_=x,C||(C=!0,an())}function wl(x,T){N=P(function(){x(e.unstable_now())},T)}e.unstable_IdlePriority=5,e.unstable_ImmediatePriority=1,e.unstable_LowPriority=4,e.unstable_NormalPriority=3,e.unstable_Profiling=null,e.unstable_UserBlockingPriority=2,e.unstable_cancelCallback=function(x){x.callback=null},e.unstable_continueExecution=function(){w||g||(w=!0,gl(E))},e.unstable_forceFrameRate=function(x){0>x||125<x?console.error("forceFrameRate takes a positive int between 0 and 125, forcing frame rates higher than 125 fps is not supported"):H=0<x?Math.floor(1e3/x):5},e.unstable_getCurrentPriorityLevel=function(){return p},e.unstable_getFirstCallbackNode=function(){return n(s)},e.unstable_next=function(x){switch(p){case 1:case 2:case 3:var T=3;break;default:T=p}var L=p;p=T;try{return x()}finally{p=L}},e.unstable_pauseExecution=function(){},e.unstable_requestPaint=function(){},e.unstable_runWithPriority=function(x,T){switch(x){case 1:case 2:case 3:case 4:case 5:break;default:x=3}var L=p;p=x;try{return T()}finally{p=L}},e.unstable_scheduleCallback=function(x,T,L){var W=e.unstable_now();switch(typeof L=="object"&&L!==null?(L=L.delay,L=typeof L=="number"&&0<L?W+L:W):L=W,x){case 1:var G=-1;break;case 2:G=250;break;case 5:G=1073741823;break;case 4:G=1e4;break;default:G=5e3}return G=L+G,x={id:m++,callback:T,priorityLevel:x,startTime:L,expirationTime:G,sortIndex:-1},L>W?(x.sortIndex=L,t(f,x),n(s)===null&&x===n(f)&&(S?(c(N),N=-1):S=!0,wl(v,L-W))):(x.sortIndex=G,t(s,x),w||g||(w=!0,gl(E))),x},e.unstable_shouldYield=Pe,e.unstable_wrapCallback=function(x){var T=p;return function(){var L=p;p=T;try{return x.apply(this,arguments)}finally{p=L}}}})(os);us.exports=os;var Dc=us.exports;/**
- * @license React
- * react-dom.production.min.js
- *
- * Copyright (c) Facebook, Inc. and its affiliates.
- *
- * This source code is licensed under the MIT license found in the
- * LICENSE file in the root directory of this source tree.
- */var is=me,ge=Dc;function y(e){for(var t="https://reactjs.org/docs/error-decoder.html?invariant="+e,n=1;n<arguments.length;n++)t+="&args[]="+encodeURIComponent(arguments[n]);return"Minified React error #"+e+"; visit "+t+" for the full message or use the non-minified dev environment for full errors and additional helpful warnings."}var ss=new Set,Dn={};function Lt(e,t){Jt(e,t),Jt(e+"Capture",t)}function Jt(e,t){for(Dn[e]=t,e=0;e<t.length;e++)ss.add(t[e])}var Qe=!(typeof window>"u"||typeof window.document>"u"||typeof window.document.createElement>"u"),Zl=Object.prototype.hasOwnProperty,jc=/^[:A-Z_a-z\u00C0-\u00D6\u00D8-\u00F6\u00F8-\u02FF\u0370-\u037D\u037F-\u1FFF\u200C-\u200D\u2070-\u218F\u2C00-\u2FEF\u3001-\uD7FF\uF900-\uFDCF\uFDF0-\uFFFD][:A-Z_a-z\u00C0-\u00D6\u00D8-\u00F6\u00F8-\u02FF\u0370-\u037D\u037F-\u1FFF\u200C-\u200D\u2070-\u218F\u2C00-\u2FEF\u3001-\uD7FF\uF900-\uFDCF\uFDF0-\uFFFD\-.0-9\u00B7\u0300-\u036F\u203F-\u2040]*$/,Bo={},Vo={};function Rc(e){return Zl.call(Vo,e)?!0:Zl.call(Bo,e)?!1:jc.test(e)?Vo[e]=!0:(Bo[e]=!0,!1)}function Oc(e,t,n,r){if(n!==null&&n.type===0)return!1;switch(typeof t){case"function":case"symbol":return!0;case"boolean":return r?!1:n!==null?!n.acceptsBooleans:(e=e.toLowerCase().slice(0,5),e!=="data-"&&e!=="aria-");default:return!1}}function Mc(e,t,n,r){if(t===null||typeof t>"u"||Oc(e,t,n,r))return!0;if(r)return!1;if(n!==null)switch(n.type){case 3:return!t;case 4:return t===!1;case 5:return isNaN(t);case 6:return isNaN(t)||1>t}return!1}function se(e,t,n,r,l,u,o){this.acceptsBooleans=t===2||t===3||t===4,this.attributeName=r,this.attributeNamespace=l,this.mustUseProperty=n,this.propertyName=e,this.type=t,this.sanitizeURL=u,this.removeEmptyString=o}var ee={};"children dangerouslySetInnerHTML defaultValue defaultChecked innerHTML suppressContentEditableWarning suppressHydrationWarning style".split(" ").forEach(function(e){ee[e]=new se(e,0,!1,e,null,!1,!1)});[["acceptCharset","accept-charset"],["className","class"],["htmlFor","for"],["httpEquiv","http-equiv"]].forEach(function(e){var t=e[0];ee[t]=new se(t,1,!1,e[1],null,!1,!1)});["contentEditable","draggable","spellCheck","value"].forEach(function(e){ee[e]=new se(e,2,!1,e.toLowerCase(),null,!1,!1)});["autoReverse","externalResourcesRequired","focusable","preserveAlpha"].forEach(function(e){ee[e]=new se(e,2,!1,e,null,!1,!1)});"allowFullScreen async autoFocus autoPlay controls default defer disabled disablePictureInPicture disableRemotePlayback formNoValidate hidden loop noModule noValidate open playsInline readOnly required reversed scoped seamless itemScope".split(" ").forEach(function(e){ee[e]=new se(e,3,!1,e.toLowerCase(),null,!1,!1)});["checked","multiple","muted","selected"].forEach(function(e){ee[e]=new se(e,3,!0,e,null,!1,!1)});["capture","download"].forEach(function(e){ee[e]=new se(e,4,!1,e,null,!1,!1)});["cols","rows","size","span"].forEach(function(e){ee[e]=new se(e,6,!1,e,null,!1,!1)});["rowSpan","start"].forEach(function(e){ee[e]=new se(e,5,!1,e.toLowerCase(),null,!1,!1)});var Ku=/[\-:]([a-z])/g;function Yu(e){return e[1].toUpperCase()}"accent-height alignment-baseline arabic-form baseline-shift cap-height clip-path clip-rule color-interpolation color-interpolation-filters color-profile color-rendering dominant-baseline enable-background fill-opacity fill-rule flood-color flood-opacity font-family font-size font-size-adjust font-stretch font-style font-variant font-weight glyph-name glyph-orientation-horizontal glyph-orientation-vertical horiz-adv-x horiz-origin-x image-rendering letter-spacing lighting-color marker-end marker-mid marker-start overline-position overline-thickness paint-order panose-1 pointer-events rendering-intent shape-rendering stop-color stop-opacity strikethrough-position strikethrough-thickness stroke-dasharray stroke-dashoffset stroke-linecap stroke-linejoin stroke-miterlimit stroke-opacity stroke-width text-anchor text-decoration text-rendering underline-position underline-thickness unicode-bidi unicode-range units-per-em v-alphabetic v-hanging v-ideographic v-mathematical vector-effect vert-adv-y vert-origin-x vert-origin-y word-spacing writing-mode xmlns:xlink x-height".split(" ").forEach(function(e){var t=e.replace(Ku,Yu);ee[t]=new se(t,1,!1,e,null,!1,!1)});"xlink:actuate xlink:arcrole xlink:role xlink:show xlink:title xlink:type".split(" ").forEach(function(e){var t=e.replace(Ku,Yu);ee[t]=new se(t,1,!1,e,"http://www.w3.org/1999/xlink",!1,!1)});["xml:base","xml:lang","xml:space"].forEach(function(e){var t=e.replace(Ku,Yu);ee[t]=new se(t,1,!1,e,"http://www.w3.org/XML/1998/namespace",!1,!1)});["tabIndex","crossOrigin"].forEach(function(e){ee[e]=new se(e,1,!1,e.toLowerCase(),null,!1,!1)});ee.xlinkHref=new se("xlinkHref",1,!1,"xlink:href","http://www.w3.org/1999/xlink",!0,!1);["src","href","action","formAction"].forEach(function(e){ee[e]=new se(e,1,!1,e.toLowerCase(),null,!0,!0)});function Xu(e,t,n,r){var l=ee.hasOwnProperty(t)?ee[t]:null;(l!==null?l.type!==0:r||!(2<t.length)||t[0]!=="o"&&t[0]!=="O"||t[1]!=="n"&&t[1]!=="N")&&(Mc(t,n,l,r)&&(n=null),r||l===null?Rc(t)&&(n===null?e.removeAttribute(t):e.setAttribute(t,""+n)):l.mustUseProperty?e[l.propertyName]=n===null?l.type===3?!1:"":n:(t=l.attributeName,r=l.attributeNamespace,n===null?e.removeAttribute(t):(l=l.type,n=l===3||l===4&&n===!0?"":""+n,r?e.setAttributeNS(r,t,n):e.setAttribute(t,n))))}var Ge=is.__SECRET_INTERNALS_DO_NOT_USE_OR_YOU_WILL_BE_FIRED,nr=Symbol.for("react.element"),Rt=Symbol.for("react.portal"),Ot=Symbol.for("react.fragment"),Gu=Symbol.for("react.strict_mode"),Jl=Symbol.for("react.profiler"),as=Symbol.for("react.provider"),cs=Symbol.for("react.context"),Zu=Symbol.for("react.forward_ref"),ql=Symbol.for("react.suspense"),bl=Symbol.for("react.suspense_list"),Ju=Symbol.for("react.memo"),Je=Symbol.for("react.lazy"),fs=Symbol.for("react.offscreen"),Ho=Symbol.iterator;function cn(e){return e===null||typeof e!="object"?null:(e=Ho&&e[Ho]||e["@@iterator"],typeof e=="function"?e:null)}var B=Object.assign,El;function gn(e){if(El===void 0)try{throw Error()}catch(n){var t=n.stack.trim().match(/\n( *(at )?)/);El=t&&t[1]||""}return`
-`+El+e}var xl=!1;function Cl(e,t){if(!e||xl)return"";xl=!0;var n=Error.prepareStackTrace;Error.prepareStackTrace=void 0;try{if(t)if(t=function(){throw Error()},Object.defineProperty(t.prototype,"props",{set:function(){throw Error()}}),typeof Reflect=="object"&&Reflect.construct){try{Reflect.construct(t,[])}catch(f){var r=f}Reflect.construct(e,[],t)}else{try{t.call()}catch(f){r=f}e.call(t.prototype)}else{try{throw Error()}catch(f){r=f}e()}}catch(f){if(f&&r&&typeof f.stack=="string"){for(var l=f.stack.split(`
-`),u=r.stack.split(`
-`),o=l.length-1,i=u.length-1;1<=o&&0<=i&&l[o]!==u[i];)i--;for(;1<=o&&0<=i;o--,i--)if(l[o]!==u[i]){if(o!==1||i!==1)do if(o--,i--,0>i||l[o]!==u[i]){var s=`
-`+l[o].replace(" at new "," at ");return e.displayName&&s.includes("<anonymous>")&&(s=s.replace("<anonymous>",e.displayName)),s}while(1<=o&&0<=i);break}}}finally{xl=!1,Error.prepareStackTrace=n}return(e=e?e.displayName||e.name:"")?gn(e):""}function Ic(e){switch(e.tag){case 5:return gn(e.type);case 16:return gn("Lazy");case 13:return gn("Suspense");case 19:return gn("SuspenseList");case 0:case 2:case 15:return e=Cl(e.type,!1),e;case 11:return e=Cl(e.type.render,!1),e;case 1:return e=Cl(e.type,!0),e;default:return""}}function eu(e){if(e==null)return null;if(typeof e=="function")return e.displayName||e.name||null;if(typeof e=="string")return e;switch(e){case Ot:return"Fragment";case Rt:return"Portal";case Jl:return"Profiler";case Gu:return"StrictMode";case ql:return"Suspense";case bl:return"SuspenseList"}if(typeof e=="object")switch(e.$$typeof){case cs:return(e.displayName||"Context")+".Consumer";case as:return(e._context.displayName||"Context")+".Provider";case Zu:var t=e.render;return e=e.displayName,e||(e=t.displayName||t.name||"",e=e!==""?"ForwardRef("+e+")":"ForwardRef"),e;case Ju:return t=e.displayName||null,t!==null?t:eu(e.type)||"Memo";case Je:t=e._payload,e=e._init;try{return eu(e(t))}catch{}}return null}function Fc(e){var t=e.type;switch(e.tag){case 24:return"Cache";case 9:return(t.displayName||"Context")+".Consumer";case 10:return(t._context.displayName||"Context")+".Provider";case 18:return"DehydratedFragment";case 11:return e=t.render,e=e.displayName||e.name||"",t.displayName||(e!==""?"ForwardRef("+e+")":"ForwardRef");case 7:return"Fragment";case 5:return t;case 4:return"Portal";case 3:return"Root";case 6:return"Text";case 16:return eu(t);case 8:return t===Gu?"StrictMode":"Mode";case 22:return"Offscreen";case 12:return"Profiler";case 21:return"Scope";case 13:return"Suspense";case 19:return"SuspenseList";case 25:return"TracingMarker";case 1:case 0:case 17:case 2:case 14:case 15:if(typeof t=="function")return t.displayName||t.name||null;if(typeof t=="string")return t}return null}function ft(e){switch(typeof e){case"boolean":case"number":case"string":case"undefined":return e;case"object":return e;default:return""}}function ds(e){var t=e.type;return(e=e.nodeName)&&e.toLowerCase()==="input"&&(t==="checkbox"||t==="radio")}function $c(e){var t=ds(e)?"checked":"value",n=Object.getOwnPropertyDescriptor(e.constructor.prototype,t),r=""+e[t];if(!e.hasOwnProperty(t)&&typeof n<"u"&&typeof n.get=="function"&&typeof n.set=="function"){var l=n.get,u=n.set;return Object.defineProperty(e,t,{configurable:!0,get:function(){return l.call(this)},set:function(o){r=""+o,u.call(this,o)}}),Object.defineProperty(e,t,{enumerable:n.enumerable}),{getValue:function(){return r},setValue:function(o){r=""+o},stopTracking:function(){e._valueTracker=null,delete e[t]}}}}function rr(e){e._valueTracker||(e._valueTracker=$c(e))}function ps(e){if(!e)return!1;var t=e._valueTracker;if(!t)return!0;var n=t.getValue(),r="";return e&&(r=ds(e)?e.checked?"true":"false":e.value),e=r,e!==n?(t.setValue(e),!0):!1}function Dr(e){if(e=e||(typeof document<"u"?document:void 0),typeof e>"u")return null;try{return e.activeElement||e.body}catch{return e.body}}function tu(e,t){var n=t.checked;return B({},t,{defaultChecked:void 0,defaultValue:void 0,value:void 0,checked:n??e._wrapperState.initialChecked})}function Wo(e,t){var n=t.defaultValue==null?"":t.defaultValue,r=t.checked!=null?t.checked:t.defaultChecked;n=ft(t.value!=null?t.value:n),e._wrapperState={initialChecked:r,initialValue:n,controlled:t.type==="checkbox"||t.type==="radio"?t.checked!=null:t.value!=null}}function ms(e,t){t=t.checked,t!=null&&Xu(e,"checked",t,!1)}function nu(e,t){ms(e,t);var n=ft(t.value),r=t.type;if(n!=null)r==="number"?(n===0&&e.value===""||e.value!=n)&&(e.value=""+n):e.value!==""+n&&(e.value=""+n);else if(r==="submit"||r==="reset"){e.removeAttribute("value");return}t.hasOwnProperty("value")?ru(e,t.type,n):t.hasOwnProperty("defaultValue")&&ru(e,t.type,ft(t.defaultValue)),t.checked==null&&t.defaultChecked!=null&&(e.defaultChecked=!!t.defaultChecked)}function Qo(e,t,n){if(t.hasOwnProperty("value")||t.hasOwnProperty("defaultValue")){var r=t.type;if(!(r!=="submit"&&r!=="reset"||t.value!==void 0&&t.value!==null))return;t=""+e._wrapperState.initialValue,n||t===e.value||(e.value=t),e.defaultValue=t}n=e.name,n!==""&&(e.name=""),e.defaultChecked=!!e._wrapperState.initialChecked,n!==""&&(e.name=n)}function ru(e,t,n){(t!=="number"||Dr(e.ownerDocument)!==e)&&(n==null?e.defaultValue=""+e._wrapperState.initialValue:e.defaultValue!==""+n&&(e.defaultValue=""+n))}var wn=Array.isArray;function Qt(e,t,n,r){if(e=e.options,t){t={};for(var l=0;l<n.length;l++)t["$"+n[l]]=!0;for(n=0;n<e.length;n++)l=t.hasOwnProperty("$"+e[n].value),e[n].selected!==l&&(e[n].selected=l),l&&r&&(e[n].defaultSelected=!0)}else{for(n=""+ft(n),t=null,l=0;l<e.length;l++){if(e[l].value===n){e[l].selected=!0,r&&(e[l].defaultSelected=!0);return}t!==null||e[l].disabled||(t=e[l])}t!==null&&(t.selected=!0)}}function lu(e,t){if(t.dangerouslySetInnerHTML!=null)throw Error(y(91));return B({},t,{value:void 0,defaultValue:void 0,children:""+e._wrapperState.initialValue})}function Ko(e,t){var n=t.value;if(n==null){if(n=t.children,t=t.defaultValue,n!=null){if(t!=null)throw Error(y(92));if(wn(n)){if(1<n.length)throw Error(y(93));n=n[0]}t=n}t==null&&(t=""),n=t}e._wrapperState={initialValue:ft(n)}}function hs(e,t){var n=ft(t.value),r=ft(t.defaultValue);n!=null&&(n=""+n,n!==e.value&&(e.value=n),t.defaultValue==null&&e.defaultValue!==n&&(e.defaultValue=n)),r!=null&&(e.defaultValue=""+r)}function Yo(e){var t=e.textContent;t===e._wrapperState.initialValue&&t!==""&&t!==null&&(e.value=t)}function vs(e){switch(e){case"svg":return"http://www.w3.org/2000/svg";case"math":return"http://www.w3.org/1998/Math/MathML";default:return"http://www.w3.org/1999/xhtml"}}function uu(e,t){return e==null||e==="http://www.w3.org/1999/xhtml"?vs(t):e==="http://www.w3.org/2000/svg"&&t==="foreignObject"?"http://www.w3.org/1999/xhtml":e}var lr,ys=function(e){return typeof MSApp<"u"&&MSApp.execUnsafeLocalFunction?function(t,n,r,l){MSApp.execUnsafeLocalFunction(function(){return e(t,n,r,l)})}:e}(function(e,t){if(e.namespaceURI!=="http://www.w3.org/2000/svg"||"innerHTML"in e)e.innerHTML=t;else{for(lr=lr||document.createElement("div"),lr.innerHTML="<svg>"+t.valueOf().toString()+"</svg>",t=lr.firstChild;e.firstChild;)e.removeChild(e.firstChild);for(;t.firstChild;)e.appendChild(t.firstChild)}});function jn(e,t){if(t){var n=e.firstChild;if(n&&n===e.lastChild&&n.nodeType===3){n.nodeValue=t;return}}e.textContent=t}var En={animationIterationCount:!0,aspectRatio:!0,borderImageOutset:!0,borderImageSlice:!0,borderImageWidth:!0,boxFlex:!0,boxFlexGroup:!0,boxOrdinalGroup:!0,columnCount:!0,columns:!0,flex:!0,flexGrow:!0,flexPositive:!0,flexShrink:!0,flexNegative:!0,flexOrder:!0,gridArea:!0,gridRow:!0,gridRowEnd:!0,gridRowSpan:!0,gridRowStart:!0,gridColumn:!0,gridColumnEnd:!0,gridColumnSpan:!0,gridColumnStart:!0,fontWeight:!0,lineClamp:!0,lineHeight:!0,opacity:!0,order:!0,orphans:!0,tabSize:!0,widows:!0,zIndex:!0,zoom:!0,fillOpacity:!0,floodOpacity:!0,stopOpacity:!0,strokeDasharray:!0,strokeDashoffset:!0,strokeMiterlimit:!0,strokeOpacity:!0,strokeWidth:!0},Uc=["Webkit","ms","Moz","O"];Object.keys(En).forEach(function(e){Uc.forEach(function(t){t=t+e.charAt(0).toUpperCase()+e.substring(1),En[t]=En[e]})});function gs(e,t,n){return t==null||typeof t=="boolean"||t===""?"":n||typeof t!="number"||t===0||En.hasOwnProperty(e)&&En[e]?(""+t).trim():t+"px"}function ws(e,t){e=e.style;for(var n in t)if(t.hasOwnProperty(n)){var r=n.indexOf("--")===0,l=gs(n,t[n],r);n==="float"&&(n="cssFloat"),r?e.setProperty(n,l):e[n]=l}}var Ac=B({menuitem:!0},{area:!0,base:!0,br:!0,col:!0,embed:!0,hr:!0,img:!0,input:!0,keygen:!0,link:!0,meta:!0,param:!0,source:!0,track:!0,wbr:!0});function ou(e,t){if(t){if(Ac[e]&&(t.children!=null||t.dangerouslySetInnerHTML!=null))throw Error(y(137,e));if(t.dangerouslySetInnerHTML!=null){if(t.children!=null)throw Error(y(60));if(typeof t.dangerouslySetInnerHTML!="object"||!("__html"in t.dangerouslySetInnerHTML))throw Error(y(61))}if(t.style!=null&&typeof t.style!="object")throw Error(y(62))}}function iu(e,t){if(e.indexOf("-")===-1)return typeof t.is=="string";switch(e){case"annotation-xml":case"color-profile":case"font-face":case"font-face-src":case"font-face-uri":case"font-face-format":case"font-face-name":case"missing-glyph":return!1;default:return!0}}var su=null;function qu(e){return e=e.target||e.srcElement||window,e.correspondingUseElement&&(e=e.correspondingUseElement),e.nodeType===3?e.parentNode:e}var au=null,Kt=null,Yt=null;function Xo(e){if(e=Jn(e)){if(typeof au!="function")throw Error(y(280));var t=e.stateNode;t&&(t=ol(t),au(e.stateNode,e.type,t))}}function Ss(e){Kt?Yt?Yt.push(e):Yt=[e]:Kt=e}function ks(){if(Kt){var e=Kt,t=Yt;if(Yt=Kt=null,Xo(e),t)for(e=0;e<t.length;e++)Xo(t[e])}}function Es(e,t){return e(t)}function xs(){}var _l=!1;function Cs(e,t,n){if(_l)return e(t,n);_l=!0;try{return Es(e,t,n)}finally{_l=!1,(Kt!==null||Yt!==null)&&(xs(),ks())}}function Rn(e,t){var n=e.stateNode;if(n===null)return null;var r=ol(n);if(r===null)return null;n=r[t];e:switch(t){case"onClick":case"onClickCapture":case"onDoubleClick":case"onDoubleClickCapture":case"onMouseDown":case"onMouseDownCapture":case"onMouseMove":case"onMouseMoveCapture":case"onMouseUp":case"onMouseUpCapture":case"onMouseEnter":(r=!r.disabled)||(e=e.type,r=!(e==="button"||e==="input"||e==="select"||e==="textarea")),e=!r;break e;default:e=!1}if(e)return null;if(n&&typeof n!="function")throw Error(y(231,t,typeof n));return n}var cu=!1;if(Qe)try{var fn={};Object.defineProperty(fn,"passive",{get:function(){cu=!0}}),window.addEventListener("test",fn,fn),window.removeEventListener("test",fn,fn)}catch{cu=!1}function Bc(e,t,n,r,l,u,o,i,s){var f=Array.prototype.slice.call(arguments,3);try{t.apply(n,f)}catch(m){this.onError(m)}}var xn=!1,jr=null,Rr=!1,fu=null,Vc={onError:function(e){xn=!0,jr=e}};function Hc(e,t,n,r,l,u,o,i,s){xn=!1,jr=null,Bc.apply(Vc,arguments)}function Wc(e,t,n,r,l,u,o,i,s){if(Hc.apply(this,arguments),xn){if(xn){var f=jr;xn=!1,jr=null}else throw Error(y(198));Rr||(Rr=!0,fu=f)}}function Dt(e){var t=e,n=e;if(e.alternate)for(;t.return;)t=t.return;else{e=t;do t=e,t.flags&4098&&(n=t.return),e=t.return;while(e)}return t.tag===3?n:null}function _s(e){if(e.tag===13){var t=e.memoizedState;if(t===null&&(e=e.alternate,e!==null&&(t=e.memoizedState)),t!==null)return t.dehydrated}return null}function Go(e){if(Dt(e)!==e)throw Error(y(188))}function Qc(e){var t=e.alternate;if(!t){if(t=Dt(e),t===null)throw Error(y(188));return t!==e?null:e}for(var n=e,r=t;;){var l=n.return;if(l===null)break;var u=l.alternate;if(u===null){if(r=l.return,r!==null){n=r;continue}break}if(l.child===u.child){for(u=l.child;u;){if(u===n)return Go(l),e;if(u===r)return Go(l),t;u=u.sibling}throw Error(y(188))}if(n.return!==r.return)n=l,r=u;else{for(var o=!1,i=l.child;i;){if(i===n){o=!0,n=l,r=u;break}if(i===r){o=!0,r=l,n=u;break}i=i.sibling}if(!o){for(i=u.child;i;){if(i===n){o=!0,n=u,r=l;break}if(i===r){o=!0,r=u,n=l;break}i=i.sibling}if(!o)throw Error(y(189))}}if(n.alternate!==r)throw Error(y(190))}if(n.tag!==3)throw Error(y(188));return n.stateNode.current===n?e:t}function Ns(e){return e=Qc(e),e!==null?Ps(e):null}function Ps(e){if(e.tag===5||e.tag===6)return e;for(e=e.child;e!==null;){var t=Ps(e);if(t!==null)return t;e=e.sibling}return null}var zs=ge.unstable_scheduleCallback,Zo=ge.unstable_cancelCallback,Kc=ge.unstable_shouldYield,Yc=ge.unstable_requestPaint,Q=ge.unstable_now,Xc=ge.unstable_getCurrentPriorityLevel,bu=ge.unstable_ImmediatePriority,Ts=ge.unstable_UserBlockingPriority,Or=ge.unstable_NormalPriority,Gc=ge.unstable_LowPriority,Ls=ge.unstable_IdlePriority,nl=null,$e=null;function Zc(e){if($e&&typeof $e.onCommitFiberRoot=="function")try{$e.onCommitFiberRoot(nl,e,void 0,(e.current.flags&128)===128)}catch{}}var je=Math.clz32?Math.clz32:bc,Jc=Math.log,qc=Math.LN2;function bc(e){return e>>>=0,e===0?32:31-(Jc(e)/qc|0)|0}var ur=64,or=4194304;function Sn(e){switch(e&-e){case 1:return 1;case 2:return 2;case 4:return 4;case 8:return 8;case 16:return 16;case 32:return 32;case 64:case 128:case 256:case 512:case 1024:case 2048:case 4096:case 8192:case 16384:case 32768:case 65536:case 131072:case 262144:case 524288:case 1048576:case 2097152:return e&4194240;case 4194304:case 8388608:case 16777216:case 33554432:case 67108864:return e&130023424;case 134217728:return 134217728;case 268435456:return 268435456;case 536870912:return 536870912;case 1073741824:return 1073741824;default:return e}}function Mr(e,t){var n=e.pendingLanes;if(n===0)return 0;var r=0,l=e.suspendedLanes,u=e.pingedLanes,o=n&268435455;if(o!==0){var i=o&~l;i!==0?r=Sn(i):(u&=o,u!==0&&(r=Sn(u)))}else o=n&~l,o!==0?r=Sn(o):u!==0&&(r=Sn(u));if(r===0)return 0;if(t!==0&&t!==r&&!(t&l)&&(l=r&-r,u=t&-t,l>=u||l===16&&(u&4194240)!==0))return t;if(r&4&&(r|=n&16),t=e.entangledLanes,t!==0)for(e=e.entanglements,t&=r;0<t;)n=31-je(t),l=1<<n,r|=e[n],t&=~l;return r}function ef(e,t){switch(e){case 1:case 2:case 4:return t+250;case 8:case 16:case 32:case 64:case 128:case 256:case 512:case 1024:case 2048:case 4096:case 8192:case 16384:case 32768:case 65536:case 131072:case 262144:case 524288:case 1048576:case 2097152:return t+5e3;case 4194304:case 8388608:case 16777216:case 33554432:case 67108864:return-1;case 134217728:case 268435456:case 536870912:case 1073741824:return-1;default:return-1}}function tf(e,t){for(var n=e.suspendedLanes,r=e.pingedLanes,l=e.expirationTimes,u=e.pendingLanes;0<u;){var o=31-je(u),i=1<<o,s=l[o];s===-1?(!(i&n)||i&r)&&(l[o]=ef(i,t)):s<=t&&(e.expiredLanes|=i),u&=~i}}function du(e){return e=e.pendingLanes&-1073741825,e!==0?e:e&1073741824?1073741824:0}function Ds(){var e=ur;return ur<<=1,!(ur&4194240)&&(ur=64),e}function Nl(e){for(var t=[],n=0;31>n;n++)t.push(e);return t}function Gn(e,t,n){e.pendingLanes|=t,t!==536870912&&(e.suspendedLanes=0,e.pingedLanes=0),e=e.eventTimes,t=31-je(t),e[t]=n}function nf(e,t){var n=e.pendingLanes&~t;e.pendingLanes=t,e.suspendedLanes=0,e.pingedLanes=0,e.expiredLanes&=t,e.mutableReadLanes&=t,e.entangledLanes&=t,t=e.entanglements;var r=e.eventTimes;for(e=e.expirationTimes;0<n;){var l=31-je(n),u=1<<l;t[l]=0,r[l]=-1,e[l]=-1,n&=~u}}function eo(e,t){var n=e.entangledLanes|=t;for(e=e.entanglements;n;){var r=31-je(n),l=1<<r;l&t|e[r]&t&&(e[r]|=t),n&=~l}}var O=0;function js(e){return e&=-e,1<e?4<e?e&268435455?16:536870912:4:1}var Rs,to,Os,Ms,Is,pu=!1,ir=[],rt=null,lt=null,ut=null,On=new Map,Mn=new Map,be=[],rf="mousedown mouseup touchcancel touchend touchstart auxclick dblclick pointercancel pointerdown pointerup dragend dragstart drop compositionend compositionstart keydown keypress keyup input textInput copy cut paste click change contextmenu reset submit".split(" ");function Jo(e,t){switch(e){case"focusin":case"focusout":rt=null;break;case"dragenter":case"dragleave":lt=null;break;case"mouseover":case"mouseout":ut=null;break;case"pointerover":case"pointerout":On.delete(t.pointerId);break;case"gotpointercapture":case"lostpointercapture":Mn.delete(t.pointerId)}}function dn(e,t,n,r,l,u){return e===null||e.nativeEvent!==u?(e={blockedOn:t,domEventName:n,eventSystemFlags:r,nativeEvent:u,targetContainers:[l]},t!==null&&(t=Jn(t),t!==null&&to(t)),e):(e.eventSystemFlags|=r,t=e.targetContainers,l!==null&&t.indexOf(l)===-1&&t.push(l),e)}function lf(e,t,n,r,l){switch(t){case"focusin":return rt=dn(rt,e,t,n,r,l),!0;case"dragenter":return lt=dn(lt,e,t,n,r,l),!0;case"mouseover":return ut=dn(ut,e,t,n,r,l),!0;case"pointerover":var u=l.pointerId;return On.set(u,dn(On.get(u)||null,e,t,n,r,l)),!0;case"gotpointercapture":return u=l.pointerId,Mn.set(u,dn(Mn.get(u)||null,e,t,n,r,l)),!0}return!1}function Fs(e){var t=St(e.target);if(t!==null){var n=Dt(t);if(n!==null){if(t=n.tag,t===13){if(t=_s(n),t!==null){e.blockedOn=t,Is(e.priority,function(){Os(n)});return}}else if(t===3&&n.stateNode.current.memoizedState.isDehydrated){e.blockedOn=n.tag===3?n.stateNode.containerInfo:null;return}}}e.blockedOn=null}function Sr(e){if(e.blockedOn!==null)return!1;for(var t=e.targetContainers;0<t.length;){var n=mu(e.domEventName,e.eventSystemFlags,t[0],e.nativeEvent);if(n===null){n=e.nativeEvent;var r=new n.constructor(n.type,n);su=r,n.target.dispatchEvent(r),su=null}else return t=Jn(n),t!==null&&to(t),e.blockedOn=n,!1;t.shift()}return!0}function qo(e,t,n){Sr(e)&&n.delete(t)}function uf(){pu=!1,rt!==null&&Sr(rt)&&(rt=null),lt!==null&&Sr(lt)&&(lt=null),ut!==null&&Sr(ut)&&(ut=null),On.forEach(qo),Mn.forEach(qo)}function pn(e,t){e.blockedOn===t&&(e.blockedOn=null,pu||(pu=!0,ge.unstable_scheduleCallback(ge.unstable_NormalPriority,uf)))}function In(e){function t(l){return pn(l,e)}if(0<ir.length){pn(ir[0],e);for(var n=1;n<ir.length;n++){var r=ir[n];r.blockedOn===e&&(r.blockedOn=null)}}for(rt!==null&&pn(rt,e),lt!==null&&pn(lt,e),ut!==null&&pn(ut,e),On.forEach(t),Mn.forEach(t),n=0;n<be.length;n++)r=be[n],r.blockedOn===e&&(r.blockedOn=null);for(;0<be.length&&(n=be[0],n.blockedOn===null);)Fs(n),n.blockedOn===null&&be.shift()}var Xt=Ge.ReactCurrentBatchConfig,Ir=!0;function of(e,t,n,r){var l=O,u=Xt.transition;Xt.transition=null;try{O=1,no(e,t,n,r)}finally{O=l,Xt.transition=u}}function sf(e,t,n,r){var l=O,u=Xt.transition;Xt.transition=null;try{O=4,no(e,t,n,r)}finally{O=l,Xt.transition=u}}function no(e,t,n,r){if(Ir){var l=mu(e,t,n,r);if(l===null)Il(e,t,r,Fr,n),Jo(e,r);else if(lf(l,e,t,n,r))r.stopPropagation();else if(Jo(e,r),t&4&&-1<rf.indexOf(e)){for(;l!==null;){var u=Jn(l);if(u!==null&&Rs(u),u=mu(e,t,n,r),u===null&&Il(e,t,r,Fr,n),u===l)break;l=u}l!==null&&r.stopPropagation()}else Il(e,t,r,null,n)}}var Fr=null;function mu(e,t,n,r){if(Fr=null,e=qu(r),e=St(e),e!==null)if(t=Dt(e),t===null)e=null;else if(n=t.tag,n===13){if(e=_s(t),e!==null)return e;e=null}else if(n===3){if(t.stateNode.current.memoizedState.isDehydrated)return t.tag===3?t.stateNode.containerInfo:null;e=null}else t!==e&&(e=null);return Fr=e,null}function $s(e){switch(e){case"cancel":case"click":case"close":case"contextmenu":case"copy":case"cut":case"auxclick":case"dblclick":case"dragend":case"dragstart":case"drop":case"focusin":case"focusout":case"input":case"invalid":case"keydown":case"keypress":case"keyup":case"mousedown":case"mouseup":case"paste":case"pause":case"play":case"pointercancel":case"pointerdown":case"pointerup":case"ratechange":case"reset":case"resize":case"seeked":case"submit":case"touchcancel":case"touchend":case"touchstart":case"volumechange":case"change":case"selectionchange":case"textInput":case"compositionstart":case"compositionend":case"compositionupdate":case"beforeblur":case"afterblur":case"beforeinput":case"blur":case"fullscreenchange":case"focus":case"hashchange":case"popstate":case"select":case"selectstart":return 1;case"drag":case"dragenter":case"dragexit":case"dragleave":case"dragover":case"mousemove":case"mouseout":case"mouseover":case"pointermove":case"pointerout":case"pointerover":case"scroll":case"toggle":case"touchmove":case"wheel":case"mouseenter":case"mouseleave":case"pointerenter":case"pointerleave":return 4;case"message":switch(Xc()){case bu:return 1;case Ts:return 4;case Or:case Gc:return 16;case Ls:return 536870912;default:return 16}default:return 16}}var tt=null,ro=null,kr=null;function Us(){if(kr)return kr;var e,t=ro,n=t.length,r,l="value"in tt?tt.value:tt.textContent,u=l.length;for(e=0;e<n&&t[e]===l[e];e++);var o=n-e;for(r=1;r<=o&&t[n-r]===l[u-r];r++);return kr=l.slice(e,1<r?1-r:void 0)}function Er(e){var t=e.keyCode;return"charCode"in e?(e=e.charCode,e===0&&t===13&&(e=13)):e=t,e===10&&(e=13),32<=e||e===13?e:0}function sr(){return!0}function bo(){return!1}function Se(e){function t(n,r,l,u,o){this._reactName=n,this._targetInst=l,this.type=r,this.nativeEvent=u,this.target=o,this.currentTarget=null;for(var i in e)e.hasOwnProperty(i)&&(n=e[i],this[i]=n?n(u):u[i]);return this.isDefaultPrevented=(u.defaultPrevented!=null?u.defaultPrevented:u.returnValue===!1)?sr:bo,this.isPropagationStopped=bo,this}return B(t.prototype,{preventDefault:function(){this.defaultPrevented=!0;var n=this.nativeEvent;n&&(n.preventDefault?n.preventDefault():typeof n.returnValue!="unknown"&&(n.returnValue=!1),this.isDefaultPrevented=sr)},stopPropagation:function(){var n=this.nativeEvent;n&&(n.stopPropagation?n.stopPropagation():typeof n.cancelBubble!="unknown"&&(n.cancelBubble=!0),this.isPropagationStopped=sr)},persist:function(){},isPersistent:sr}),t}var un={eventPhase:0,bubbles:0,cancelable:0,timeStamp:function(e){return e.timeStamp||Date.now()},defaultPrevented:0,isTrusted:0},lo=Se(un),Zn=B({},un,{view:0,detail:0}),af=Se(Zn),Pl,zl,mn,rl=B({},Zn,{screenX:0,screenY:0,clientX:0,clientY:0,pageX:0,pageY:0,ctrlKey:0,shiftKey:0,altKey:0,metaKey:0,getModifierState:uo,button:0,buttons:0,relatedTarget:function(e){return e.relatedTarget===void 0?e.fromElement===e.srcElement?e.toElement:e.fromElement:e.relatedTarget},movementX:function(e){return"movementX"in e?e.movementX:(e!==mn&&(mn&&e.type==="mousemove"?(Pl=e.screenX-mn.screenX,zl=e.screenY-mn.screenY):zl=Pl=0,mn=e),Pl)},movementY:function(e){return"movementY"in e?e.movementY:zl}}),ei=Se(rl),cf=B({},rl,{dataTransfer:0}),ff=Se(cf),df=B({},Zn,{relatedTarget:0}),Tl=Se(df),pf=B({},un,{animationName:0,elapsedTime:0,pseudoElement:0}),mf=Se(pf),hf=B({},un,{clipboardData:function(e){return"clipboardData"in e?e.clipboardData:window.clipboardData}}),vf=Se(hf),yf=B({},un,{data:0}),ti=Se(yf),gf={Esc:"Escape",Spacebar:" ",Left:"ArrowLeft",Up:"ArrowUp",Right:"ArrowRight",Down:"ArrowDown",Del:"Delete",Win:"OS",Menu:"ContextMenu",Apps:"ContextMenu",Scroll:"ScrollLock",MozPrintableKey:"Unidentified"},wf={8:"Backspace",9:"Tab",12:"Clear",13:"Enter",16:"Shift",17:"Control",18:"Alt",19:"Pause",20:"CapsLock",27:"Escape",32:" ",33:"PageUp",34:"PageDown",35:"End",36:"Home",37:"ArrowLeft",38:"ArrowUp",39:"ArrowRight",40:"ArrowDown",45:"Insert",46:"Delete",112:"F1",113:"F2",114:"F3",115:"F4",116:"F5",117:"F6",118:"F7",119:"F8",120:"F9",121:"F10",122:"F11",123:"F12",144:"NumLock",145:"ScrollLock",224:"Meta"},Sf={Alt:"altKey",Control:"ctrlKey",Meta:"metaKey",Shift:"shiftKey"};function kf(e){var t=this.nativeEvent;return t.getModifierState?t.getModifierState(e):(e=Sf[e])?!!t[e]:!1}function uo(){return kf}var Ef=B({},Zn,{key:function(e){if(e.key){var t=gf[e.key]||e.key;if(t!=="Unidentified")return t}return e.type==="keypress"?(e=Er(e),e===13?"Enter":String.fromCharCode(e)):e.type==="keydown"||e.type==="keyup"?wf[e.keyCode]||"Unidentified":""},code:0,location:0,ctrlKey:0,shiftKey:0,altKey:0,metaKey:0,repeat:0,locale:0,getModifierState:uo,charCode:function(e){return e.type==="keypress"?Er(e):0},keyCode:function(e){return e.type==="keydown"||e.type==="keyup"?e.keyCode:0},which:function(e){return e.type==="keypress"?Er(e):e.type==="keydown"||e.type==="keyup"?e.keyCode:0}}),xf=Se(Ef),Cf=B({},rl,{pointerId:0,width:0,height:0,pressure:0,tangentialPressure:0,tiltX:0,tiltY:0,twist:0,pointerType:0,isPrimary:0}),ni=Se(Cf),_f=B({},Zn,{touches:0,targetTouches:0,changedTouches:0,altKey:0,metaKey:0,ctrlKey:0,shiftKey:0,getModifierState:uo}),Nf=Se(_f),Pf=B({},un,{propertyName:0,elapsedTime:0,pseudoElement:0}),zf=Se(Pf),Tf=B({},rl,{deltaX:function(e){return"deltaX"in e?e.deltaX:"wheelDeltaX"in e?-e.wheelDeltaX:0},deltaY:function(e){return"deltaY"in e?e.deltaY:"wheelDeltaY"in e?-e.wheelDeltaY:"wheelDelta"in e?-e.wheelDelta:0},deltaZ:0,deltaMode:0}),Lf=Se(Tf),Df=[9,13,27,32],oo=Qe&&"CompositionEvent"in window,Cn=null;Qe&&"documentMode"in document&&(Cn=document.documentMode);var jf=Qe&&"TextEvent"in window&&!Cn,As=Qe&&(!oo||Cn&&8<Cn&&11>=Cn),ri=" ",li=!1;function Bs(e,t){switch(e){case"keyup":return Df.indexOf(t.keyCode)!==-1;case"keydown":return t.keyCode!==229;case"keypress":case"mousedown":case"focusout":return!0;default:return!1}}function Vs(e){return e=e.detail,typeof e=="object"&&"data"in e?e.data:null}var Mt=!1;function Rf(e,t){switch(e){case"compositionend":return Vs(t);case"keypress":return t.which!==32?null:(li=!0,ri);case"textInput":return e=t.data,e===ri&&li?null:e;default:return null}}function Of(e,t){if(Mt)return e==="compositionend"||!oo&&Bs(e,t)?(e=Us(),kr=ro=tt=null,Mt=!1,e):null;switch(e){case"paste":return null;case"keypress":if(!(t.ctrlKey||t.altKey||t.metaKey)||t.ctrlKey&&t.altKey){if(t.char&&1<t.char.length)return t.char;if(t.which)return String.fromCharCode(t.which)}return null;case"compositionend":return As&&t.locale!=="ko"?null:t.data;default:return null}}var Mf={color:!0,date:!0,datetime:!0,"datetime-local":!0,email:!0,month:!0,number:!0,password:!0,range:!0,search:!0,tel:!0,text:!0,time:!0,url:!0,week:!0};function ui(e){var t=e&&e.nodeName&&e.nodeName.toLowerCase();return t==="input"?!!Mf[e.type]:t==="textarea"}function Hs(e,t,n,r){Ss(r),t=$r(t,"onChange"),0<t.length&&(n=new lo("onChange","change",null,n,r),e.push({event:n,listeners:t}))}var _n=null,Fn=null;function If(e){ea(e,0)}function ll(e){var t=$t(e);if(ps(t))return e}function Ff(e,t){if(e==="change")return t}var Ws=!1;if(Qe){var Ll;if(Qe){var Dl="oninput"in document;if(!Dl){var oi=document.createElement("div");oi.setAttribute("oninput","return;"),Dl=typeof oi.oninput=="function"}Ll=Dl}else Ll=!1;Ws=Ll&&(!document.documentMode||9<document.documentMode)}function ii(){_n&&(_n.detachEvent("onpropertychange",Qs),Fn=_n=null)}function Qs(e){if(e.propertyName==="value"&&ll(Fn)){var t=[];Hs(t,Fn,e,qu(e)),Cs(If,t)}}function $f(e,t,n){e==="focusin"?(ii(),_n=t,Fn=n,_n.attachEvent("onpropertychange",Qs)):e==="focusout"&&ii()}function Uf(e){if(e==="selectionchange"||e==="keyup"||e==="keydown")return ll(Fn)}function Af(e,t){if(e==="click")return ll(t)}function Bf(e,t){if(e==="input"||e==="change")return ll(t)}function Vf(e,t){return e===t&&(e!==0||1/e===1/t)||e!==e&&t!==t}var Oe=typeof Object.is=="function"?Object.is:Vf;function $n(e,t){if(Oe(e,t))return!0;if(typeof e!="object"||e===null||typeof t!="object"||t===null)return!1;var n=Object.keys(e),r=Object.keys(t);if(n.length!==r.length)return!1;for(r=0;r<n.length;r++){var l=n[r];if(!Zl.call(t,l)||!Oe(e[l],t[l]))return!1}return!0}function si(e){for(;e&&e.firstChild;)e=e.firstChild;return e}function ai(e,t){var n=si(e);e=0;for(var r;n;){if(n.nodeType===3){if(r=e+n.textContent.length,e<=t&&r>=t)return{node:n,offset:t-e};e=r}e:{for(;n;){if(n.nextSibling){n=n.nextSibling;break e}n=n.parentNode}n=void 0}n=si(n)}}function Ks(e,t){return e&&t?e===t?!0:e&&e.nodeType===3?!1:t&&t.nodeType===3?Ks(e,t.parentNode):"contains"in e?e.contains(t):e.compareDocumentPosition?!!(e.compareDocumentPosition(t)&16):!1:!1}function Ys(){for(var e=window,t=Dr();t instanceof e.HTMLIFrameElement;){try{var n=typeof t.contentWindow.location.href=="string"}catch{n=!1}if(n)e=t.contentWindow;else break;t=Dr(e.document)}return t}function io(e){var t=e&&e.nodeName&&e.nodeName.toLowerCase();return t&&(t==="input"&&(e.type==="text"||e.type==="search"||e.type==="tel"||e.type==="url"||e.type==="password")||t==="textarea"||e.contentEditable==="true")}function Hf(e){var t=Ys(),n=e.focusedElem,r=e.selectionRange;if(t!==n&&n&&n.ownerDocument&&Ks(n.ownerDocument.documentElement,n)){if(r!==null&&io(n)){if(t=r.start,e=r.end,e===void 0&&(e=t),"selectionStart"in n)n.selectionStart=t,n.selectionEnd=Math.min(e,n.value.length);else if(e=(t=n.ownerDocument||document)&&t.defaultView||window,e.getSelection){e=e.getSelection();var l=n.textContent.length,u=Math.min(r.start,l);r=r.end===void 0?u:Math.min(r.end,l),!e.extend&&u>r&&(l=r,r=u,u=l),l=ai(n,u);var o=ai(n,r);l&&o&&(e.rangeCount!==1||e.anchorNode!==l.node||e.anchorOffset!==l.offset||e.focusNode!==o.node||e.focusOffset!==o.offset)&&(t=t.createRange(),t.setStart(l.node,l.offset),e.removeAllRanges(),u>r?(e.addRange(t),e.extend(o.node,o.offset)):(t.setEnd(o.node,o.offset),e.addRange(t)))}}for(t=[],e=n;e=e.parentNode;)e.nodeType===1&&t.push({element:e,left:e.scrollLeft,top:e.scrollTop});for(typeof n.focus=="function"&&n.focus(),n=0;n<t.length;n++)e=t[n],e.element.scrollLeft=e.left,e.element.scrollTop=e.top}}var Wf=Qe&&"documentMode"in document&&11>=document.documentMode,It=null,hu=null,Nn=null,vu=!1;function ci(e,t,n){var r=n.window===n?n.document:n.nodeType===9?n:n.ownerDocument;vu||It==null||It!==Dr(r)||(r=It,"selectionStart"in r&&io(r)?r={start:r.selectionStart,end:r.selectionEnd}:(r=(r.ownerDocument&&r.ownerDocument.defaultView||window).getSelection(),r={anchorNode:r.anchorNode,anchorOffset:r.anchorOffset,focusNode:r.focusNode,focusOffset:r.focusOffset}),Nn&&$n(Nn,r)||(Nn=r,r=$r(hu,"onSelect"),0<r.length&&(t=new lo("onSelect","select",null,t,n),e.push({event:t,listeners:r}),t.target=It)))}function ar(e,t){var n={};return n[e.toLowerCase()]=t.toLowerCase(),n["Webkit"+e]="webkit"+t,n["Moz"+e]="moz"+t,n}var Ft={animationend:ar("Animation","AnimationEnd"),animationiteration:ar("Animation","AnimationIteration"),animationstart:ar("Animation","AnimationStart"),transitionend:ar("Transition","TransitionEnd")},jl={},Xs={};Qe&&(Xs=document.createElement("div").style,"AnimationEvent"in window||(delete Ft.animationend.animation,delete Ft.animationiteration.animation,delete Ft.animationstart.animation),"TransitionEvent"in window||delete Ft.transitionend.transition);function ul(e){if(jl[e])return jl[e];if(!Ft[e])return e;var t=Ft[e],n;for(n in t)if(t.hasOwnProperty(n)&&n in Xs)return jl[e]=t[n];return e}var Gs=ul("animationend"),Zs=ul("animationiteration"),Js=ul("animationstart"),qs=ul("transitionend"),bs=new Map,fi="abort auxClick cancel canPlay canPlayThrough click close contextMenu copy cut drag dragEnd dragEnter dragExit dragLeave dragOver dragStart drop durationChange emptied encrypted ended error gotPointerCapture input invalid keyDown keyPress keyUp load loadedData loadedMetadata loadStart lostPointerCapture mouseDown mouseMove mouseOut mouseOver mouseUp paste pause play playing pointerCancel pointerDown pointerMove pointerOut pointerOver pointerUp progress rateChange reset resize seeked seeking stalled submit suspend timeUpdate touchCancel touchEnd touchStart volumeChange scroll toggle touchMove waiting wheel".split(" ");function pt(e,t){bs.set(e,t),Lt(t,[e])}for(var Rl=0;Rl<fi.length;Rl++){var Ol=fi[Rl],Qf=Ol.toLowerCase(),Kf=Ol[0].toUpperCase()+Ol.slice(1);pt(Qf,"on"+Kf)}pt(Gs,"onAnimationEnd");pt(Zs,"onAnimationIteration");pt(Js,"onAnimationStart");pt("dblclick","onDoubleClick");pt("focusin","onFocus");pt("focusout","onBlur");pt(qs,"onTransitionEnd");Jt("onMouseEnter",["mouseout","mouseover"]);Jt("onMouseLeave",["mouseout","mouseover"]);Jt("onPointerEnter",["pointerout","pointerover"]);Jt("onPointerLeave",["pointerout","pointerover"]);Lt("onChange","change click focusin focusout input keydown keyup selectionchange".split(" "));Lt("onSelect","focusout contextmenu dragend focusin keydown keyup mousedown mouseup selectionchange".split(" "));Lt("onBeforeInput",["compositionend","keypress","textInput","paste"]);Lt("onCompositionEnd","compositionend focusout keydown keypress keyup mousedown".split(" "));Lt("onCompositionStart","compositionstart focusout keydown keypress keyup mousedown".split(" "));Lt("onCompositionUpdate","compositionupdate focusout keydown keypress keyup mousedown".split(" "));var kn="abort canplay canplaythrough durationchange emptied encrypted ended error loadeddata loadedmetadata loadstart pause play playing progress ratechange resize seeked seeking stalled suspend timeupdate volumechange waiting".split(" "),Yf=new Set("cancel close invalid load scroll toggle".split(" ").concat(kn));function di(e,t,n){var r=e.type||"unknown-event";e.currentTarget=n,Wc(r,t,void 0,e),e.currentTarget=null}function ea(e,t){t=(t&4)!==0;for(var n=0;n<e.length;n++){var r=e[n],l=r.event;r=r.listeners;e:{var u=void 0;if(t)for(var o=r.length-1;0<=o;o--){var i=r[o],s=i.instance,f=i.currentTarget;if(i=i.listener,s!==u&&l.isPropagationStopped())break e;di(l,i,f),u=s}else for(o=0;o<r.length;o++){if(i=r[o],s=i.instance,f=i.currentTarget,i=i.listener,s!==u&&l.isPropagationStopped())break e;di(l,i,f),u=s}}}if(Rr)throw e=fu,Rr=!1,fu=null,e}function I(e,t){var n=t[ku];n===void 0&&(n=t[ku]=new Set);var r=e+"__bubble";n.has(r)||(ta(t,e,2,!1),n.add(r))}function Ml(e,t,n){var r=0;t&&(r|=4),ta(n,e,r,t)}var cr="_reactListening"+Math.random().toString(36).slice(2);function Un(e){if(!e[cr]){e[cr]=!0,ss.forEach(function(n){n!=="selectionchange"&&(Yf.has(n)||Ml(n,!1,e),Ml(n,!0,e))});var t=e.nodeType===9?e:e.ownerDocument;t===null||t[cr]||(t[cr]=!0,Ml("selectionchange",!1,t))}}function ta(e,t,n,r){switch($s(t)){case 1:var l=of;break;case 4:l=sf;break;default:l=no}n=l.bind(null,t,n,e),l=void 0,!cu||t!=="touchstart"&&t!=="touchmove"&&t!=="wheel"||(l=!0),r?l!==void 0?e.addEventListener(t,n,{capture:!0,passive:l}):e.addEventListener(t,n,!0):l!==void 0?e.addEventListener(t,n,{passive:l}):e.addEventListener(t,n,!1)}function Il(e,t,n,r,l){var u=r;if(!(t&1)&&!(t&2)&&r!==null)e:for(;;){if(r===null)return;var o=r.tag;if(o===3||o===4){var i=r.stateNode.containerInfo;if(i===l||i.nodeType===8&&i.parentNode===l)break;if(o===4)for(o=r.return;o!==null;){var s=o.tag;if((s===3||s===4)&&(s=o.stateNode.containerInfo,s===l||s.nodeType===8&&s.parentNode===l))return;o=o.return}for(;i!==null;){if(o=St(i),o===null)return;if(s=o.tag,s===5||s===6){r=u=o;continue e}i=i.parentNode}}r=r.return}Cs(function(){var f=u,m=qu(n),h=[];e:{var p=bs.get(e);if(p!==void 0){var g=lo,w=e;switch(e){case"keypress":if(Er(n)===0)break e;case"keydown":case"keyup":g=xf;break;case"focusin":w="focus",g=Tl;break;case"focusout":w="blur",g=Tl;break;case"beforeblur":case"afterblur":g=Tl;break;case"click":if(n.button===2)break e;case"auxclick":case"dblclick":case"mousedown":case"mousemove":case"mouseup":case"mouseout":case"mouseover":case"contextmenu":g=ei;break;case"drag":case"dragend":case"dragenter":case"dragexit":case"dragleave":case"dragover":case"dragstart":case"drop":g=ff;break;case"touchcancel":case"touchend":case"touchmove":case"touchstart":g=Nf;break;case Gs:case Zs:case Js:g=mf;break;case qs:g=zf;break;case"scroll":g=af;break;case"wheel":g=Lf;break;case"copy":case"cut":case"paste":g=vf;break;case"gotpointercapture":case"lostpointercapture":case"pointercancel":case"pointerdown":case"pointermove":case"pointerout":case"pointerover":case"pointerup":g=ni}var S=(t&4)!==0,P=!S&&e==="scroll",c=S?p!==null?p+"Capture":null:p;S=[];for(var a=f,d;a!==null;){d=a;var v=d.stateNode;if(d.tag===5&&v!==null&&(d=v,c!==null&&(v=Rn(a,c),v!=null&&S.push(An(a,v,d)))),P)break;a=a.return}0<S.length&&(p=new g(p,w,null,n,m),h.push({event:p,listeners:S}))}}if(!(t&7)){e:{if(p=e==="mouseover"||e==="pointerover",g=e==="mouseout"||e==="pointerout",p&&n!==su&&(w=n.relatedTarget||n.fromElement)&&(St(w)||w[Ke]))break e;if((g||p)&&(p=m.window===m?m:(p=m.ownerDocument)?p.defaultView||p.parentWindow:window,g?(w=n.relatedTarget||n.toElement,g=f,w=w?St(w):null,w!==null&&(P=Dt(w),w!==P||w.tag!==5&&w.tag!==6)&&(w=null)):(g=null,w=f),g!==w)){if(S=ei,v="onMouseLeave",c="onMouseEnter",a="mouse",(e==="pointerout"||e==="pointerover")&&(S=ni,v="onPointerLeave",c="onPointerEnter",a="pointer"),P=g==null?p:$t(g),d=w==null?p:$t(w),p=new S(v,a+"leave",g,n,m),p.target=P,p.relatedTarget=d,v=null,St(m)===f&&(S=new S(c,a+"enter",w,n,m),S.target=d,S.relatedTarget=P,v=S),P=v,g&&w)t:{for(S=g,c=w,a=0,d=S;d;d=jt(d))a++;for(d=0,v=c;v;v=jt(v))d++;for(;0<a-d;)S=jt(S),a--;for(;0<d-a;)c=jt(c),d--;for(;a--;){if(S===c||c!==null&&S===c.alternate)break t;S=jt(S),c=jt(c)}S=null}else S=null;g!==null&&pi(h,p,g,S,!1),w!==null&&P!==null&&pi(h,P,w,S,!0)}}e:{if(p=f?$t(f):window,g=p.nodeName&&p.nodeName.toLowerCase(),g==="select"||g==="input"&&p.type==="file")var E=Ff;else if(ui(p))if(Ws)E=Bf;else{E=Uf;var C=$f}else(g=p.nodeName)&&g.toLowerCase()==="input"&&(p.type==="checkbox"||p.type==="radio")&&(E=Af);if(E&&(E=E(e,f))){Hs(h,E,n,m);break e}C&&C(e,p,f),e==="focusout"&&(C=p._wrapperState)&&C.controlled&&p.type==="number"&&ru(p,"number",p.value)}switch(C=f?$t(f):window,e){case"focusin":(ui(C)||C.contentEditable==="true")&&(It=C,hu=f,Nn=null);break;case"focusout":Nn=hu=It=null;break;case"mousedown":vu=!0;break;case"contextmenu":case"mouseup":case"dragend":vu=!1,ci(h,n,m);break;case"selectionchange":if(Wf)break;case"keydown":case"keyup":ci(h,n,m)}var _;if(oo)e:{switch(e){case"compositionstart":var N="onCompositionStart";break e;case"compositionend":N="onCompositionEnd";break e;case"compositionupdate":N="onCompositionUpdate";break e}N=void 0}else Mt?Bs(e,n)&&(N="onCompositionEnd"):e==="keydown"&&n.keyCode===229&&(N="onCompositionStart");N&&(As&&n.locale!=="ko"&&(Mt||N!=="onCompositionStart"?N==="onCompositionEnd"&&Mt&&(_=Us()):(tt=m,ro="value"in tt?tt.value:tt.textContent,Mt=!0)),C=$r(f,N),0<C.length&&(N=new ti(N,e,null,n,m),h.push({event:N,listeners:C}),_?N.data=_:(_=Vs(n),_!==null&&(N.data=_)))),(_=jf?Rf(e,n):Of(e,n))&&(f=$r(f,"onBeforeInput"),0<f.length&&(m=new ti("onBeforeInput","beforeinput",null,n,m),h.push({event:m,listeners:f}),m.data=_))}ea(h,t)})}function An(e,t,n){return{instance:e,listener:t,currentTarget:n}}function $r(e,t){for(var n=t+"Capture",r=[];e!==null;){var l=e,u=l.stateNode;l.tag===5&&u!==null&&(l=u,u=Rn(e,n),u!=null&&r.unshift(An(e,u,l)),u=Rn(e,t),u!=null&&r.push(An(e,u,l))),e=e.return}return r}function jt(e){if(e===null)return null;do e=e.return;while(e&&e.tag!==5);return e||null}function pi(e,t,n,r,l){for(var u=t._reactName,o=[];n!==null&&n!==r;){var i=n,s=i.alternate,f=i.stateNode;if(s!==null&&s===r)break;i.tag===5&&f!==null&&(i=f,l?(s=Rn(n,u),s!=null&&o.unshift(An(n,s,i))):l||(s=Rn(n,u),s!=null&&o.push(An(n,s,i)))),n=n.return}o.length!==0&&e.push({event:t,listeners:o})}var Xf=/\r\n?/g,Gf=/\u0000|\uFFFD/g;function mi(e){return(typeof e=="string"?e:""+e).replace(Xf,`
-`).replace(Gf,"")}function fr(e,t,n){if(t=mi(t),mi(e)!==t&&n)throw Error(y(425))}function Ur(){}var yu=null,gu=null;function wu(e,t){return e==="textarea"||e==="noscript"||typeof t.children=="string"||typeof t.children=="number"||typeof t.dangerouslySetInnerHTML=="object"&&t.dangerouslySetInnerHTML!==null&&t.dangerouslySetInnerHTML.__html!=null}var Su=typeof setTimeout=="function"?setTimeout:void 0,Zf=typeof clearTimeout=="function"?clearTimeout:void 0,hi=typeof Promise=="function"?Promise:void 0,Jf=typeof queueMicrotask=="function"?queueMicrotask:typeof hi<"u"?function(e){return hi.resolve(null).then(e).catch(qf)}:Su;function qf(e){setTimeout(function(){throw e})}function Fl(e,t){var n=t,r=0;do{var l=n.nextSibling;if(e.removeChild(n),l&&l.nodeType===8)if(n=l.data,n==="/$"){if(r===0){e.removeChild(l),In(t);return}r--}else n!=="$"&&n!=="$?"&&n!=="$!"||r++;n=l}while(n);In(t)}function ot(e){for(;e!=null;e=e.nextSibling){var t=e.nodeType;if(t===1||t===3)break;if(t===8){if(t=e.data,t==="$"||t==="$!"||t==="$?")break;if(t==="/$")return null}}return e}function vi(e){e=e.previousSibling;for(var t=0;e;){if(e.nodeType===8){var n=e.data;if(n==="$"||n==="$!"||n==="$?"){if(t===0)return e;t--}else n==="/$"&&t++}e=e.previousSibling}return null}var on=Math.random().toString(36).slice(2),Fe="__reactFiber$"+on,Bn="__reactProps$"+on,Ke="__reactContainer$"+on,ku="__reactEvents$"+on,bf="__reactListeners$"+on,ed="__reactHandles$"+on;function St(e){var t=e[Fe];if(t)return t;for(var n=e.parentNode;n;){if(t=n[Ke]||n[Fe]){if(n=t.alternate,t.child!==null||n!==null&&n.child!==null)for(e=vi(e);e!==null;){if(n=e[Fe])return n;e=vi(e)}return t}e=n,n=e.parentNode}return null}function Jn(e){return e=e[Fe]||e[Ke],!e||e.tag!==5&&e.tag!==6&&e.tag!==13&&e.tag!==3?null:e}function $t(e){if(e.tag===5||e.tag===6)return e.stateNode;throw Error(y(33))}function ol(e){return e[Bn]||null}var Eu=[],Ut=-1;function mt(e){return{current:e}}function F(e){0>Ut||(e.current=Eu[Ut],Eu[Ut]=null,Ut--)}function M(e,t){Ut++,Eu[Ut]=e.current,e.current=t}var dt={},le=mt(dt),fe=mt(!1),_t=dt;function qt(e,t){var n=e.type.contextTypes;if(!n)return dt;var r=e.stateNode;if(r&&r.__reactInternalMemoizedUnmaskedChildContext===t)return r.__reactInternalMemoizedMaskedChildContext;var l={},u;for(u in n)l[u]=t[u];return r&&(e=e.stateNode,e.__reactInternalMemoizedUnmaskedChildContext=t,e.__reactInternalMemoizedMaskedChildContext=l),l}function de(e){return e=e.childContextTypes,e!=null}function Ar(){F(fe),F(le)}function yi(e,t,n){if(le.current!==dt)throw Error(y(168));M(le,t),M(fe,n)}function na(e,t,n){var r=e.stateNode;if(t=t.childContextTypes,typeof r.getChildContext!="function")return n;r=r.getChildContext();for(var l in r)if(!(l in t))throw Error(y(108,Fc(e)||"Unknown",l));return B({},n,r)}function Br(e){return e=(e=e.stateNode)&&e.__reactInternalMemoizedMergedChildContext||dt,_t=le.current,M(le,e),M(fe,fe.current),!0}function gi(e,t,n){var r=e.stateNode;if(!r)throw Error(y(169));n?(e=na(e,t,_t),r.__reactInternalMemoizedMergedChildContext=e,F(fe),F(le),M(le,e)):F(fe),M(fe,n)}var Be=null,il=!1,$l=!1;function ra(e){Be===null?Be=[e]:Be.push(e)}function td(e){il=!0,ra(e)}function ht(){if(!$l&&Be!==null){$l=!0;var e=0,t=O;try{var n=Be;for(O=1;e<n.length;e++){var r=n[e];do r=r(!0);while(r!==null)}Be=null,il=!1}catch(l){throw Be!==null&&(Be=Be.slice(e+1)),zs(bu,ht),l}finally{O=t,$l=!1}}return null}var At=[],Bt=0,Vr=null,Hr=0,ke=[],Ee=0,Nt=null,Ve=1,He="";function gt(e,t){At[Bt++]=Hr,At[Bt++]=Vr,Vr=e,Hr=t}function la(e,t,n){ke[Ee++]=Ve,ke[Ee++]=He,ke[Ee++]=Nt,Nt=e;var r=Ve;e=He;var l=32-je(r)-1;r&=~(1<<l),n+=1;var u=32-je(t)+l;if(30<u){var o=l-l%5;u=(r&(1<<o)-1).toString(32),r>>=o,l-=o,Ve=1<<32-je(t)+l|n<<l|r,He=u+e}else Ve=1<<u|n<<l|r,He=e}function so(e){e.return!==null&&(gt(e,1),la(e,1,0))}function ao(e){for(;e===Vr;)Vr=At[--Bt],At[Bt]=null,Hr=At[--Bt],At[Bt]=null;for(;e===Nt;)Nt=ke[--Ee],ke[Ee]=null,He=ke[--Ee],ke[Ee]=null,Ve=ke[--Ee],ke[Ee]=null}var ye=null,ve=null,$=!1,De=null;function ua(e,t){var n=xe(5,null,null,0);n.elementType="DELETED",n.stateNode=t,n.return=e,t=e.deletions,t===null?(e.deletions=[n],e.flags|=16):t.push(n)}function wi(e,t){switch(e.tag){case 5:var n=e.type;return t=t.nodeType!==1||n.toLowerCase()!==t.nodeName.toLowerCase()?null:t,t!==null?(e.stateNode=t,ye=e,ve=ot(t.firstChild),!0):!1;case 6:return t=e.pendingProps===""||t.nodeType!==3?null:t,t!==null?(e.stateNode=t,ye=e,ve=null,!0):!1;case 13:return t=t.nodeType!==8?null:t,t!==null?(n=Nt!==null?{id:Ve,overflow:He}:null,e.memoizedState={dehydrated:t,treeContext:n,retryLane:1073741824},n=xe(18,null,null,0),n.stateNode=t,n.return=e,e.child=n,ye=e,ve=null,!0):!1;default:return!1}}function xu(e){return(e.mode&1)!==0&&(e.flags&128)===0}function Cu(e){if($){var t=ve;if(t){var n=t;if(!wi(e,t)){if(xu(e))throw Error(y(418));t=ot(n.nextSibling);var r=ye;t&&wi(e,t)?ua(r,n):(e.flags=e.flags&-4097|2,$=!1,ye=e)}}else{if(xu(e))throw Error(y(418));e.flags=e.flags&-4097|2,$=!1,ye=e}}}function Si(e){for(e=e.return;e!==null&&e.tag!==5&&e.tag!==3&&e.tag!==13;)e=e.return;ye=e}function dr(e){if(e!==ye)return!1;if(!$)return Si(e),$=!0,!1;var t;if((t=e.tag!==3)&&!(t=e.tag!==5)&&(t=e.type,t=t!=="head"&&t!=="body"&&!wu(e.type,e.memoizedProps)),t&&(t=ve)){if(xu(e))throw oa(),Error(y(418));for(;t;)ua(e,t),t=ot(t.nextSibling)}if(Si(e),e.tag===13){if(e=e.memoizedState,e=e!==null?e.dehydrated:null,!e)throw Error(y(317));e:{for(e=e.nextSibling,t=0;e;){if(e.nodeType===8){var n=e.data;if(n==="/$"){if(t===0){ve=ot(e.nextSibling);break e}t--}else n!=="$"&&n!=="$!"&&n!=="$?"||t++}e=e.nextSibling}ve=null}}else ve=ye?ot(e.stateNode.nextSibling):null;return!0}function oa(){for(var e=ve;e;)e=ot(e.nextSibling)}function bt(){ve=ye=null,$=!1}function co(e){De===null?De=[e]:De.push(e)}var nd=Ge.ReactCurrentBatchConfig;function Te(e,t){if(e&&e.defaultProps){t=B({},t),e=e.defaultProps;for(var n in e)t[n]===void 0&&(t[n]=e[n]);return t}return t}var Wr=mt(null),Qr=null,Vt=null,fo=null;function po(){fo=Vt=Qr=null}function mo(e){var t=Wr.current;F(Wr),e._currentValue=t}function _u(e,t,n){for(;e!==null;){var r=e.alternate;if((e.childLanes&t)!==t?(e.childLanes|=t,r!==null&&(r.childLanes|=t)):r!==null&&(r.childLanes&t)!==t&&(r.childLanes|=t),e===n)break;e=e.return}}function Gt(e,t){Qr=e,fo=Vt=null,e=e.dependencies,e!==null&&e.firstContext!==null&&(e.lanes&t&&(ce=!0),e.firstContext=null)}function _e(e){var t=e._currentValue;if(fo!==e)if(e={context:e,memoizedValue:t,next:null},Vt===null){if(Qr===null)throw Error(y(308));Vt=e,Qr.dependencies={lanes:0,firstContext:e}}else Vt=Vt.next=e;return t}var kt=null;function ho(e){kt===null?kt=[e]:kt.push(e)}function ia(e,t,n,r){var l=t.interleaved;return l===null?(n.next=n,ho(t)):(n.next=l.next,l.next=n),t.interleaved=n,Ye(e,r)}function Ye(e,t){e.lanes|=t;var n=e.alternate;for(n!==null&&(n.lanes|=t),n=e,e=e.return;e!==null;)e.childLanes|=t,n=e.alternate,n!==null&&(n.childLanes|=t),n=e,e=e.return;return n.tag===3?n.stateNode:null}var qe=!1;function vo(e){e.updateQueue={baseState:e.memoizedState,firstBaseUpdate:null,lastBaseUpdate:null,shared:{pending:null,interleaved:null,lanes:0},effects:null}}function sa(e,t){e=e.updateQueue,t.updateQueue===e&&(t.updateQueue={baseState:e.baseState,firstBaseUpdate:e.firstBaseUpdate,lastBaseUpdate:e.lastBaseUpdate,shared:e.shared,effects:e.effects})}function We(e,t){return{eventTime:e,lane:t,tag:0,payload:null,callback:null,next:null}}function it(e,t,n){var r=e.updateQueue;if(r===null)return null;if(r=r.shared,R&2){var l=r.pending;return l===null?t.next=t:(t.next=l.next,l.next=t),r.pending=t,Ye(e,n)}return l=r.interleaved,l===null?(t.next=t,ho(r)):(t.next=l.next,l.next=t),r.interleaved=t,Ye(e,n)}function xr(e,t,n){if(t=t.updateQueue,t!==null&&(t=t.shared,(n&4194240)!==0)){var r=t.lanes;r&=e.pendingLanes,n|=r,t.lanes=n,eo(e,n)}}function ki(e,t){var n=e.updateQueue,r=e.alternate;if(r!==null&&(r=r.updateQueue,n===r)){var l=null,u=null;if(n=n.firstBaseUpdate,n!==null){do{var o={eventTime:n.eventTime,lane:n.lane,tag:n.tag,payload:n.payload,callback:n.callback,next:null};u===null?l=u=o:u=u.next=o,n=n.next}while(n!==null);u===null?l=u=t:u=u.next=t}else l=u=t;n={baseState:r.baseState,firstBaseUpdate:l,lastBaseUpdate:u,shared:r.shared,effects:r.effects},e.updateQueue=n;return}e=n.lastBaseUpdate,e===null?n.firstBaseUpdate=t:e.next=t,n.lastBaseUpdate=t}function Kr(e,t,n,r){var l=e.updateQueue;qe=!1;var u=l.firstBaseUpdate,o=l.lastBaseUpdate,i=l.shared.pending;if(i!==null){l.shared.pending=null;var s=i,f=s.next;s.next=null,o===null?u=f:o.next=f,o=s;var m=e.alternate;m!==null&&(m=m.updateQueue,i=m.lastBaseUpdate,i!==o&&(i===null?m.firstBaseUpdate=f:i.next=f,m.lastBaseUpdate=s))}if(u!==null){var h=l.baseState;o=0,m=f=s=null,i=u;do{var p=i.lane,g=i.eventTime;if((r&p)===p){m!==null&&(m=m.next={eventTime:g,lane:0,tag:i.tag,payload:i.payload,callback:i.callback,next:null});e:{var w=e,S=i;switch(p=t,g=n,S.tag){case 1:if(w=S.payload,typeof w=="function"){h=w.call(g,h,p);break e}h=w;break e;case 3:w.flags=w.flags&-65537|128;case 0:if(w=S.payload,p=typeof w=="function"?w.call(g,h,p):w,p==null)break e;h=B({},h,p);break e;case 2:qe=!0}}i.callback!==null&&i.lane!==0&&(e.flags|=64,p=l.effects,p===null?l.effects=[i]:p.push(i))}else g={eventTime:g,lane:p,tag:i.tag,payload:i.payload,callback:i.callback,next:null},m===null?(f=m=g,s=h):m=m.next=g,o|=p;if(i=i.next,i===null){if(i=l.shared.pending,i===null)break;p=i,i=p.next,p.next=null,l.lastBaseUpdate=p,l.shared.pending=null}}while(!0);if(m===null&&(s=h),l.baseState=s,l.firstBaseUpdate=f,l.lastBaseUpdate=m,t=l.shared.interleaved,t!==null){l=t;do o|=l.lane,l=l.next;while(l!==t)}else u===null&&(l.shared.lanes=0);zt|=o,e.lanes=o,e.memoizedState=h}}function Ei(e,t,n){if(e=t.effects,t.effects=null,e!==null)for(t=0;t<e.length;t++){var r=e[t],l=r.callback;if(l!==null){if(r.callback=null,r=n,typeof l!="function")throw Error(y(191,l));l.call(r)}}}var aa=new is.Component().refs;function Nu(e,t,n,r){t=e.memoizedState,n=n(r,t),n=n==null?t:B({},t,n),e.memoizedState=n,e.lanes===0&&(e.updateQueue.baseState=n)}var sl={isMounted:function(e){return(e=e._reactInternals)?Dt(e)===e:!1},enqueueSetState:function(e,t,n){e=e._reactInternals;var r=oe(),l=at(e),u=We(r,l);u.payload=t,n!=null&&(u.callback=n),t=it(e,u,l),t!==null&&(Re(t,e,l,r),xr(t,e,l))},enqueueReplaceState:function(e,t,n){e=e._reactInternals;var r=oe(),l=at(e),u=We(r,l);u.tag=1,u.payload=t,n!=null&&(u.callback=n),t=it(e,u,l),t!==null&&(Re(t,e,l,r),xr(t,e,l))},enqueueForceUpdate:function(e,t){e=e._reactInternals;var n=oe(),r=at(e),l=We(n,r);l.tag=2,t!=null&&(l.callback=t),t=it(e,l,r),t!==null&&(Re(t,e,r,n),xr(t,e,r))}};function xi(e,t,n,r,l,u,o){return e=e.stateNode,typeof e.shouldComponentUpdate=="function"?e.shouldComponentUpdate(r,u,o):t.prototype&&t.prototype.isPureReactComponent?!$n(n,r)||!$n(l,u):!0}function ca(e,t,n){var r=!1,l=dt,u=t.contextType;return typeof u=="object"&&u!==null?u=_e(u):(l=de(t)?_t:le.current,r=t.contextTypes,u=(r=r!=null)?qt(e,l):dt),t=new t(n,u),e.memoizedState=t.state!==null&&t.state!==void 0?t.state:null,t.updater=sl,e.stateNode=t,t._reactInternals=e,r&&(e=e.stateNode,e.__reactInternalMemoizedUnmaskedChildContext=l,e.__reactInternalMemoizedMaskedChildContext=u),t}function Ci(e,t,n,r){e=t.state,typeof t.componentWillReceiveProps=="function"&&t.componentWillReceiveProps(n,r),typeof t.UNSAFE_componentWillReceiveProps=="function"&&t.UNSAFE_componentWillReceiveProps(n,r),t.state!==e&&sl.enqueueReplaceState(t,t.state,null)}function Pu(e,t,n,r){var l=e.stateNode;l.props=n,l.state=e.memoizedState,l.refs=aa,vo(e);var u=t.contextType;typeof u=="object"&&u!==null?l.context=_e(u):(u=de(t)?_t:le.current,l.context=qt(e,u)),l.state=e.memoizedState,u=t.getDerivedStateFromProps,typeof u=="function"&&(Nu(e,t,u,n),l.state=e.memoizedState),typeof t.getDerivedStateFromProps=="function"||typeof l.getSnapshotBeforeUpdate=="function"||typeof l.UNSAFE_componentWillMount!="function"&&typeof l.componentWillMount!="function"||(t=l.state,typeof l.componentWillMount=="function"&&l.componentWillMount(),typeof l.UNSAFE_componentWillMount=="function"&&l.UNSAFE_componentWillMount(),t!==l.state&&sl.enqueueReplaceState(l,l.state,null),Kr(e,n,l,r),l.state=e.memoizedState),typeof l.componentDidMount=="function"&&(e.flags|=4194308)}function hn(e,t,n){if(e=n.ref,e!==null&&typeof e!="function"&&typeof e!="object"){if(n._owner){if(n=n._owner,n){if(n.tag!==1)throw Error(y(309));var r=n.stateNode}if(!r)throw Error(y(147,e));var l=r,u=""+e;return t!==null&&t.ref!==null&&typeof t.ref=="function"&&t.ref._stringRef===u?t.ref:(t=function(o){var i=l.refs;i===aa&&(i=l.refs={}),o===null?delete i[u]:i[u]=o},t._stringRef=u,t)}if(typeof e!="string")throw Error(y(284));if(!n._owner)throw Error(y(290,e))}return e}function pr(e,t){throw e=Object.prototype.toString.call(t),Error(y(31,e==="[object Object]"?"object with keys {"+Object.keys(t).join(", ")+"}":e))}function _i(e){var t=e._init;return t(e._payload)}function fa(e){function t(c,a){if(e){var d=c.deletions;d===null?(c.deletions=[a],c.flags|=16):d.push(a)}}function n(c,a){if(!e)return null;for(;a!==null;)t(c,a),a=a.sibling;return null}function r(c,a){for(c=new Map;a!==null;)a.key!==null?c.set(a.key,a):c.set(a.index,a),a=a.sibling;return c}function l(c,a){return c=ct(c,a),c.index=0,c.sibling=null,c}function u(c,a,d){return c.index=d,e?(d=c.alternate,d!==null?(d=d.index,d<a?(c.flags|=2,a):d):(c.flags|=2,a)):(c.flags|=1048576,a)}function o(c){return e&&c.alternate===null&&(c.flags|=2),c}function i(c,a,d,v){return a===null||a.tag!==6?(a=Ql(d,c.mode,v),a.return=c,a):(a=l(a,d),a.return=c,a)}function s(c,a,d,v){var E=d.type;return E===Ot?m(c,a,d.props.children,v,d.key):a!==null&&(a.elementType===E||typeof E=="object"&&E!==null&&E.$$typeof===Je&&_i(E)===a.type)?(v=l(a,d.props),v.ref=hn(c,a,d),v.return=c,v):(v=Tr(d.type,d.key,d.props,null,c.mode,v),v.ref=hn(c,a,d),v.return=c,v)}function f(c,a,d,v){return a===null||a.tag!==4||a.stateNode.containerInfo!==d.containerInfo||a.stateNode.implementation!==d.implementation?(a=Kl(d,c.mode,v),a.return=c,a):(a=l(a,d.children||[]),a.return=c,a)}function m(c,a,d,v,E){return a===null||a.tag!==7?(a=Ct(d,c.mode,v,E),a.return=c,a):(a=l(a,d),a.return=c,a)}function h(c,a,d){if(typeof a=="string"&&a!==""||typeof a=="number")return a=Ql(""+a,c.mode,d),a.return=c,a;if(typeof a=="object"&&a!==null){switch(a.$$typeof){case nr:return d=Tr(a.type,a.key,a.props,null,c.mode,d),d.ref=hn(c,null,a),d.return=c,d;case Rt:return a=Kl(a,c.mode,d),a.return=c,a;case Je:var v=a._init;return h(c,v(a._payload),d)}if(wn(a)||cn(a))return a=Ct(a,c.mode,d,null),a.return=c,a;pr(c,a)}return null}function p(c,a,d,v){var E=a!==null?a.key:null;if(typeof d=="string"&&d!==""||typeof d=="number")return E!==null?null:i(c,a,""+d,v);if(typeof d=="object"&&d!==null){switch(d.$$typeof){case nr:return d.key===E?s(c,a,d,v):null;case Rt:return d.key===E?f(c,a,d,v):null;case Je:return E=d._init,p(c,a,E(d._payload),v)}if(wn(d)||cn(d))return E!==null?null:m(c,a,d,v,null);pr(c,d)}return null}function g(c,a,d,v,E){if(typeof v=="string"&&v!==""||typeof v=="number")return c=c.get(d)||null,i(a,c,""+v,E);if(typeof v=="object"&&v!==null){switch(v.$$typeof){case nr:return c=c.get(v.key===null?d:v.key)||null,s(a,c,v,E);case Rt:return c=c.get(v.key===null?d:v.key)||null,f(a,c,v,E);case Je:var C=v._init;return g(c,a,d,C(v._payload),E)}if(wn(v)||cn(v))return c=c.get(d)||null,m(a,c,v,E,null);pr(a,v)}return null}function w(c,a,d,v){for(var E=null,C=null,_=a,N=a=0,H=null;_!==null&&N<d.length;N++){_.index>N?(H=_,_=null):H=_.sibling;var j=p(c,_,d[N],v);if(j===null){_===null&&(_=H);break}e&&_&&j.alternate===null&&t(c,_),a=u(j,a,N),C===null?E=j:C.sibling=j,C=j,_=H}if(N===d.length)return n(c,_),$&&gt(c,N),E;if(_===null){for(;N<d.length;N++)_=h(c,d[N],v),_!==null&&(a=u(_,a,N),C===null?E=_:C.sibling=_,C=_);return $&&gt(c,N),E}for(_=r(c,_);N<d.length;N++)H=g(_,c,N,d[N],v),H!==null&&(e&&H.alternate!==null&&_.delete(H.key===null?N:H.key),a=u(H,a,N),C===null?E=H:C.sibling=H,C=H);return e&&_.forEach(function(Pe){return t(c,Pe)}),$&&gt(c,N),E}function S(c,a,d,v){var E=cn(d);if(typeof E!="function")throw Error(y(150));if(d=E.call(d),d==null)throw Error(y(151));for(var C=E=null,_=a,N=a=0,H=null,j=d.next();_!==null&&!j.done;N++,j=d.next()){_.index>N?(H=_,_=null):H=_.sibling;var Pe=p(c,_,j.value,v);if(Pe===null){_===null&&(_=H);break}e&&_&&Pe.alternate===null&&t(c,_),a=u(Pe,a,N),C===null?E=Pe:C.sibling=Pe,C=Pe,_=H}if(j.done)return n(c,_),$&&gt(c,N),E;if(_===null){for(;!j.done;N++,j=d.next())j=h(c,j.value,v),j!==null&&(a=u(j,a,N),C===null?E=j:C.sibling=j,C=j);return $&&gt(c,N),E}for(_=r(c,_);!j.done;N++,j=d.next())j=g(_,c,N,j.value,v),j!==null&&(e&&j.alternate!==null&&_.delete(j.key===null?N:j.key),a=u(j,a,N),C===null?E=j:C.sibling=j,C=j);return e&&_.forEach(function(sn){return t(c,sn)}),$&&gt(c,N),E}function P(c,a,d,v){if(typeof d=="object"&&d!==null&&d.type===Ot&&d.key===null&&(d=d.props.children),typeof d=="object"&&d!==null){switch(d.$$typeof){case nr:e:{for(var E=d.key,C=a;C!==null;){if(C.key===E){if(E=d.type,E===Ot){if(C.tag===7){n(c,C.sibling),a=l(C,d.props.children),a.return=c,c=a;break e}}else if(C.elementType===E||typeof E=="object"&&E!==null&&E.$$typeof===Je&&_i(E)===C.type){n(c,C.sibling),a=l(C,d.props),a.ref=hn(c,C,d),a.return=c,c=a;break e}n(c,C);break}else t(c,C);C=C.sibling}d.type===Ot?(a=Ct(d.props.children,c.mode,v,d.key),a.return=c,c=a):(v=Tr(d.type,d.key,d.props,null,c.mode,v),v.ref=hn(c,a,d),v.return=c,c=v)}return o(c);case Rt:e:{for(C=d.key;a!==null;){if(a.key===C)if(a.tag===4&&a.stateNode.containerInfo===d.containerInfo&&a.stateNode.implementation===d.implementation){n(c,a.sibling),a=l(a,d.children||[]),a.return=c,c=a;break e}else{n(c,a);break}else t(c,a);a=a.sibling}a=Kl(d,c.mode,v),a.return=c,c=a}return o(c);case Je:return C=d._init,P(c,a,C(d._payload),v)}if(wn(d))return w(c,a,d,v);if(cn(d))return S(c,a,d,v);pr(c,d)}return typeof d=="string"&&d!==""||typeof d=="number"?(d=""+d,a!==null&&a.tag===6?(n(c,a.sibling),a=l(a,d),a.return=c,c=a):(n(c,a),a=Ql(d,c.mode,v),a.return=c,c=a),o(c)):n(c,a)}return P}var en=fa(!0),da=fa(!1),qn={},Ue=mt(qn),Vn=mt(qn),Hn=mt(qn);function Et(e){if(e===qn)throw Error(y(174));return e}function yo(e,t){switch(M(Hn,t),M(Vn,e),M(Ue,qn),e=t.nodeType,e){case 9:case 11:t=(t=t.documentElement)?t.namespaceURI:uu(null,"");break;default:e=e===8?t.parentNode:t,t=e.namespaceURI||null,e=e.tagName,t=uu(t,e)}F(Ue),M(Ue,t)}function tn(){F(Ue),F(Vn),F(Hn)}function pa(e){Et(Hn.current);var t=Et(Ue.current),n=uu(t,e.type);t!==n&&(M(Vn,e),M(Ue,n))}function go(e){Vn.current===e&&(F(Ue),F(Vn))}var U=mt(0);function Yr(e){for(var t=e;t!==null;){if(t.tag===13){var n=t.memoizedState;if(n!==null&&(n=n.dehydrated,n===null||n.data==="$?"||n.data==="$!"))return t}else if(t.tag===19&&t.memoizedProps.revealOrder!==void 0){if(t.flags&128)return t}else if(t.child!==null){t.child.return=t,t=t.child;continue}if(t===e)break;for(;t.sibling===null;){if(t.return===null||t.return===e)return null;t=t.return}t.sibling.return=t.return,t=t.sibling}return null}var Ul=[];function wo(){for(var e=0;e<Ul.length;e++)Ul[e]._workInProgressVersionPrimary=null;Ul.length=0}var Cr=Ge.ReactCurrentDispatcher,Al=Ge.ReactCurrentBatchConfig,Pt=0,A=null,Y=null,Z=null,Xr=!1,Pn=!1,Wn=0,rd=0;function te(){throw Error(y(321))}function So(e,t){if(t===null)return!1;for(var n=0;n<t.length&&n<e.length;n++)if(!Oe(e[n],t[n]))return!1;return!0}function ko(e,t,n,r,l,u){if(Pt=u,A=t,t.memoizedState=null,t.updateQueue=null,t.lanes=0,Cr.current=e===null||e.memoizedState===null?id:sd,e=n(r,l),Pn){u=0;do{if(Pn=!1,Wn=0,25<=u)throw Error(y(301));u+=1,Z=Y=null,t.updateQueue=null,Cr.current=ad,e=n(r,l)}while(Pn)}if(Cr.current=Gr,t=Y!==null&&Y.next!==null,Pt=0,Z=Y=A=null,Xr=!1,t)throw Error(y(300));return e}function Eo(){var e=Wn!==0;return Wn=0,e}function Ie(){var e={memoizedState:null,baseState:null,baseQueue:null,queue:null,next:null};return Z===null?A.memoizedState=Z=e:Z=Z.next=e,Z}function Ne(){if(Y===null){var e=A.alternate;e=e!==null?e.memoizedState:null}else e=Y.next;var t=Z===null?A.memoizedState:Z.next;if(t!==null)Z=t,Y=e;else{if(e===null)throw Error(y(310));Y=e,e={memoizedState:Y.memoizedState,baseState:Y.baseState,baseQueue:Y.baseQueue,queue:Y.queue,next:null},Z===null?A.memoizedState=Z=e:Z=Z.next=e}return Z}function Qn(e,t){return typeof t=="function"?t(e):t}function Bl(e){var t=Ne(),n=t.queue;if(n===null)throw Error(y(311));n.lastRenderedReducer=e;var r=Y,l=r.baseQueue,u=n.pending;if(u!==null){if(l!==null){var o=l.next;l.next=u.next,u.next=o}r.baseQueue=l=u,n.pending=null}if(l!==null){u=l.next,r=r.baseState;var i=o=null,s=null,f=u;do{var m=f.lane;if((Pt&m)===m)s!==null&&(s=s.next={lane:0,action:f.action,hasEagerState:f.hasEagerState,eagerState:f.eagerState,next:null}),r=f.hasEagerState?f.eagerState:e(r,f.action);else{var h={lane:m,action:f.action,hasEagerState:f.hasEagerState,eagerState:f.eagerState,next:null};s===null?(i=s=h,o=r):s=s.next=h,A.lanes|=m,zt|=m}f=f.next}while(f!==null&&f!==u);s===null?o=r:s.next=i,Oe(r,t.memoizedState)||(ce=!0),t.memoizedState=r,t.baseState=o,t.baseQueue=s,n.lastRenderedState=r}if(e=n.interleaved,e!==null){l=e;do u=l.lane,A.lanes|=u,zt|=u,l=l.next;while(l!==e)}else l===null&&(n.lanes=0);return[t.memoizedState,n.dispatch]}function Vl(e){var t=Ne(),n=t.queue;if(n===null)throw Error(y(311));n.lastRenderedReducer=e;var r=n.dispatch,l=n.pending,u=t.memoizedState;if(l!==null){n.pending=null;var o=l=l.next;do u=e(u,o.action),o=o.next;while(o!==l);Oe(u,t.memoizedState)||(ce=!0),t.memoizedState=u,t.baseQueue===null&&(t.baseState=u),n.lastRenderedState=u}return[u,r]}function ma(){}function ha(e,t){var n=A,r=Ne(),l=t(),u=!Oe(r.memoizedState,l);if(u&&(r.memoizedState=l,ce=!0),r=r.queue,xo(ga.bind(null,n,r,e),[e]),r.getSnapshot!==t||u||Z!==null&&Z.memoizedState.tag&1){if(n.flags|=2048,Kn(9,ya.bind(null,n,r,l,t),void 0,null),J===null)throw Error(y(349));Pt&30||va(n,t,l)}return l}function va(e,t,n){e.flags|=16384,e={getSnapshot:t,value:n},t=A.updateQueue,t===null?(t={lastEffect:null,stores:null},A.updateQueue=t,t.stores=[e]):(n=t.stores,n===null?t.stores=[e]:n.push(e))}function ya(e,t,n,r){t.value=n,t.getSnapshot=r,wa(t)&&Sa(e)}function ga(e,t,n){return n(function(){wa(t)&&Sa(e)})}function wa(e){var t=e.getSnapshot;e=e.value;try{var n=t();return!Oe(e,n)}catch{return!0}}function Sa(e){var t=Ye(e,1);t!==null&&Re(t,e,1,-1)}function Ni(e){var t=Ie();return typeof e=="function"&&(e=e()),t.memoizedState=t.baseState=e,e={pending:null,interleaved:null,lanes:0,dispatch:null,lastRenderedReducer:Qn,lastRenderedState:e},t.queue=e,e=e.dispatch=od.bind(null,A,e),[t.memoizedState,e]}function Kn(e,t,n,r){return e={tag:e,create:t,destroy:n,deps:r,next:null},t=A.updateQueue,t===null?(t={lastEffect:null,stores:null},A.updateQueue=t,t.lastEffect=e.next=e):(n=t.lastEffect,n===null?t.lastEffect=e.next=e:(r=n.next,n.next=e,e.next=r,t.lastEffect=e)),e}function ka(){return Ne().memoizedState}function _r(e,t,n,r){var l=Ie();A.flags|=e,l.memoizedState=Kn(1|t,n,void 0,r===void 0?null:r)}function al(e,t,n,r){var l=Ne();r=r===void 0?null:r;var u=void 0;if(Y!==null){var o=Y.memoizedState;if(u=o.destroy,r!==null&&So(r,o.deps)){l.memoizedState=Kn(t,n,u,r);return}}A.flags|=e,l.memoizedState=Kn(1|t,n,u,r)}function Pi(e,t){return _r(8390656,8,e,t)}function xo(e,t){return al(2048,8,e,t)}function Ea(e,t){return al(4,2,e,t)}function xa(e,t){return al(4,4,e,t)}function Ca(e,t){if(typeof t=="function")return e=e(),t(e),function(){t(null)};if(t!=null)return e=e(),t.current=e,function(){t.current=null}}function _a(e,t,n){return n=n!=null?n.concat([e]):null,al(4,4,Ca.bind(null,t,e),n)}function Co(){}function Na(e,t){var n=Ne();t=t===void 0?null:t;var r=n.memoizedState;return r!==null&&t!==null&&So(t,r[1])?r[0]:(n.memoizedState=[e,t],e)}function Pa(e,t){var n=Ne();t=t===void 0?null:t;var r=n.memoizedState;return r!==null&&t!==null&&So(t,r[1])?r[0]:(e=e(),n.memoizedState=[e,t],e)}function za(e,t,n){return Pt&21?(Oe(n,t)||(n=Ds(),A.lanes|=n,zt|=n,e.baseState=!0),t):(e.baseState&&(e.baseState=!1,ce=!0),e.memoizedState=n)}function ld(e,t){var n=O;O=n!==0&&4>n?n:4,e(!0);var r=Al.transition;Al.transition={};try{e(!1),t()}finally{O=n,Al.transition=r}}function Ta(){return Ne().memoizedState}function ud(e,t,n){var r=at(e);if(n={lane:r,action:n,hasEagerState:!1,eagerState:null,next:null},La(e))Da(t,n);else if(n=ia(e,t,n,r),n!==null){var l=oe();Re(n,e,r,l),ja(n,t,r)}}function od(e,t,n){var r=at(e),l={lane:r,action:n,hasEagerState:!1,eagerState:null,next:null};if(La(e))Da(t,l);else{var u=e.alternate;if(e.lanes===0&&(u===null||u.lanes===0)&&(u=t.lastRenderedReducer,u!==null))try{var o=t.lastRenderedState,i=u(o,n);if(l.hasEagerState=!0,l.eagerState=i,Oe(i,o)){var s=t.interleaved;s===null?(l.next=l,ho(t)):(l.next=s.next,s.next=l),t.interleaved=l;return}}catch{}finally{}n=ia(e,t,l,r),n!==null&&(l=oe(),Re(n,e,r,l),ja(n,t,r))}}function La(e){var t=e.alternate;return e===A||t!==null&&t===A}function Da(e,t){Pn=Xr=!0;var n=e.pending;n===null?t.next=t:(t.next=n.next,n.next=t),e.pending=t}function ja(e,t,n){if(n&4194240){var r=t.lanes;r&=e.pendingLanes,n|=r,t.lanes=n,eo(e,n)}}var Gr={readContext:_e,useCallback:te,useContext:te,useEffect:te,useImperativeHandle:te,useInsertionEffect:te,useLayoutEffect:te,useMemo:te,useReducer:te,useRef:te,useState:te,useDebugValue:te,useDeferredValue:te,useTransition:te,useMutableSource:te,useSyncExternalStore:te,useId:te,unstable_isNewReconciler:!1},id={readContext:_e,useCallback:function(e,t){return Ie().memoizedState=[e,t===void 0?null:t],e},useContext:_e,useEffect:Pi,useImperativeHandle:function(e,t,n){return n=n!=null?n.concat([e]):null,_r(4194308,4,Ca.bind(null,t,e),n)},useLayoutEffect:function(e,t){return _r(4194308,4,e,t)},useInsertionEffect:function(e,t){return _r(4,2,e,t)},useMemo:function(e,t){var n=Ie();return t=t===void 0?null:t,e=e(),n.memoizedState=[e,t],e},useReducer:function(e,t,n){var r=Ie();return t=n!==void 0?n(t):t,r.memoizedState=r.baseState=t,e={pending:null,interleaved:null,lanes:0,dispatch:null,lastRenderedReducer:e,lastRenderedState:t},r.queue=e,e=e.dispatch=ud.bind(null,A,e),[r.memoizedState,e]},useRef:function(e){var t=Ie();return e={current:e},t.memoizedState=e},useState:Ni,useDebugValue:Co,useDeferredValue:function(e){return Ie().memoizedState=e},useTransition:function(){var e=Ni(!1),t=e[0];return e=ld.bind(null,e[1]),Ie().memoizedState=e,[t,e]},useMutableSource:function(){},useSyncExternalStore:function(e,t,n){var r=A,l=Ie();if($){if(n===void 0)throw Error(y(407));n=n()}else{if(n=t(),J===null)throw Error(y(349));Pt&30||va(r,t,n)}l.memoizedState=n;var u={value:n,getSnapshot:t};return l.queue=u,Pi(ga.bind(null,r,u,e),[e]),r.flags|=2048,Kn(9,ya.bind(null,r,u,n,t),void 0,null),n},useId:function(){var e=Ie(),t=J.identifierPrefix;if($){var n=He,r=Ve;n=(r&~(1<<32-je(r)-1)).toString(32)+n,t=":"+t+"R"+n,n=Wn++,0<n&&(t+="H"+n.toString(32)),t+=":"}else n=rd++,t=":"+t+"r"+n.toString(32)+":";return e.memoizedState=t},unstable_isNewReconciler:!1},sd={readContext:_e,useCallback:Na,useContext:_e,useEffect:xo,useImperativeHandle:_a,useInsertionEffect:Ea,useLayoutEffect:xa,useMemo:Pa,useReducer:Bl,useRef:ka,useState:function(){return Bl(Qn)},useDebugValue:Co,useDeferredValue:function(e){var t=Ne();return za(t,Y.memoizedState,e)},useTransition:function(){var e=Bl(Qn)[0],t=Ne().memoizedState;return[e,t]},useMutableSource:ma,useSyncExternalStore:ha,useId:Ta,unstable_isNewReconciler:!1},ad={readContext:_e,useCallback:Na,useContext:_e,useEffect:xo,useImperativeHandle:_a,useInsertionEffect:Ea,useLayoutEffect:xa,useMemo:Pa,useReducer:Vl,useRef:ka,useState:function(){return Vl(Qn)},useDebugValue:Co,useDeferredValue:function(e){var t=Ne();return Y===null?t.memoizedState=e:za(t,Y.memoizedState,e)},useTransition:function(){var e=Vl(Qn)[0],t=Ne().memoizedState;return[e,t]},useMutableSource:ma,useSyncExternalStore:ha,useId:Ta,unstable_isNewReconciler:!1};function nn(e,t){try{var n="",r=t;do n+=Ic(r),r=r.return;while(r);var l=n}catch(u){l=`
-Error generating stack: `+u.message+`
-`+u.stack}return{value:e,source:t,stack:l,digest:null}}function Hl(e,t,n){return{value:e,source:null,stack:n??null,digest:t??null}}function zu(e,t){try{console.error(t.value)}catch(n){setTimeout(function(){throw n})}}var cd=typeof WeakMap=="function"?WeakMap:Map;function Ra(e,t,n){n=We(-1,n),n.tag=3,n.payload={element:null};var r=t.value;return n.callback=function(){Jr||(Jr=!0,$u=r),zu(e,t)},n}function Oa(e,t,n){n=We(-1,n),n.tag=3;var r=e.type.getDerivedStateFromError;if(typeof r=="function"){var l=t.value;n.payload=function(){return r(l)},n.callback=function(){zu(e,t)}}var u=e.stateNode;return u!==null&&typeof u.componentDidCatch=="function"&&(n.callback=function(){zu(e,t),typeof r!="function"&&(st===null?st=new Set([this]):st.add(this));var o=t.stack;this.componentDidCatch(t.value,{componentStack:o!==null?o:""})}),n}function zi(e,t,n){var r=e.pingCache;if(r===null){r=e.pingCache=new cd;var l=new Set;r.set(t,l)}else l=r.get(t),l===void 0&&(l=new Set,r.set(t,l));l.has(n)||(l.add(n),e=Cd.bind(null,e,t,n),t.then(e,e))}function Ti(e){do{var t;if((t=e.tag===13)&&(t=e.memoizedState,t=t!==null?t.dehydrated!==null:!0),t)return e;e=e.return}while(e!==null);return null}function Li(e,t,n,r,l){return e.mode&1?(e.flags|=65536,e.lanes=l,e):(e===t?e.flags|=65536:(e.flags|=128,n.flags|=131072,n.flags&=-52805,n.tag===1&&(n.alternate===null?n.tag=17:(t=We(-1,1),t.tag=2,it(n,t,1))),n.lanes|=1),e)}var fd=Ge.ReactCurrentOwner,ce=!1;function ue(e,t,n,r){t.child=e===null?da(t,null,n,r):en(t,e.child,n,r)}function Di(e,t,n,r,l){n=n.render;var u=t.ref;return Gt(t,l),r=ko(e,t,n,r,u,l),n=Eo(),e!==null&&!ce?(t.updateQueue=e.updateQueue,t.flags&=-2053,e.lanes&=~l,Xe(e,t,l)):($&&n&&so(t),t.flags|=1,ue(e,t,r,l),t.child)}function ji(e,t,n,r,l){if(e===null){var u=n.type;return typeof u=="function"&&!jo(u)&&u.defaultProps===void 0&&n.compare===null&&n.defaultProps===void 0?(t.tag=15,t.type=u,Ma(e,t,u,r,l)):(e=Tr(n.type,null,r,t,t.mode,l),e.ref=t.ref,e.return=t,t.child=e)}if(u=e.child,!(e.lanes&l)){var o=u.memoizedProps;if(n=n.compare,n=n!==null?n:$n,n(o,r)&&e.ref===t.ref)return Xe(e,t,l)}return t.flags|=1,e=ct(u,r),e.ref=t.ref,e.return=t,t.child=e}function Ma(e,t,n,r,l){if(e!==null){var u=e.memoizedProps;if($n(u,r)&&e.ref===t.ref)if(ce=!1,t.pendingProps=r=u,(e.lanes&l)!==0)e.flags&131072&&(ce=!0);else return t.lanes=e.lanes,Xe(e,t,l)}return Tu(e,t,n,r,l)}function Ia(e,t,n){var r=t.pendingProps,l=r.children,u=e!==null?e.memoizedState:null;if(r.mode==="hidden")if(!(t.mode&1))t.memoizedState={baseLanes:0,cachePool:null,transitions:null},M(Wt,he),he|=n;else{if(!(n&1073741824))return e=u!==null?u.baseLanes|n:n,t.lanes=t.childLanes=1073741824,t.memoizedState={baseLanes:e,cachePool:null,transitions:null},t.updateQueue=null,M(Wt,he),he|=e,null;t.memoizedState={baseLanes:0,cachePool:null,transitions:null},r=u!==null?u.baseLanes:n,M(Wt,he),he|=r}else u!==null?(r=u.baseLanes|n,t.memoizedState=null):r=n,M(Wt,he),he|=r;return ue(e,t,l,n),t.child}function Fa(e,t){var n=t.ref;(e===null&&n!==null||e!==null&&e.ref!==n)&&(t.flags|=512,t.flags|=2097152)}function Tu(e,t,n,r,l){var u=de(n)?_t:le.current;return u=qt(t,u),Gt(t,l),n=ko(e,t,n,r,u,l),r=Eo(),e!==null&&!ce?(t.updateQueue=e.updateQueue,t.flags&=-2053,e.lanes&=~l,Xe(e,t,l)):($&&r&&so(t),t.flags|=1,ue(e,t,n,l),t.child)}function Ri(e,t,n,r,l){if(de(n)){var u=!0;Br(t)}else u=!1;if(Gt(t,l),t.stateNode===null)Nr(e,t),ca(t,n,r),Pu(t,n,r,l),r=!0;else if(e===null){var o=t.stateNode,i=t.memoizedProps;o.props=i;var s=o.context,f=n.contextType;typeof f=="object"&&f!==null?f=_e(f):(f=de(n)?_t:le.current,f=qt(t,f));var m=n.getDerivedStateFromProps,h=typeof m=="function"||typeof o.getSnapshotBeforeUpdate=="function";h||typeof o.UNSAFE_componentWillReceiveProps!="function"&&typeof o.componentWillReceiveProps!="function"||(i!==r||s!==f)&&Ci(t,o,r,f),qe=!1;var p=t.memoizedState;o.state=p,Kr(t,r,o,l),s=t.memoizedState,i!==r||p!==s||fe.current||qe?(typeof m=="function"&&(Nu(t,n,m,r),s=t.memoizedState),(i=qe||xi(t,n,i,r,p,s,f))?(h||typeof o.UNSAFE_componentWillMount!="function"&&typeof o.componentWillMount!="function"||(typeof o.componentWillMount=="function"&&o.componentWillMount(),typeof o.UNSAFE_componentWillMount=="function"&&o.UNSAFE_componentWillMount()),typeof o.componentDidMount=="function"&&(t.flags|=4194308)):(typeof o.componentDidMount=="function"&&(t.flags|=4194308),t.memoizedProps=r,t.memoizedState=s),o.props=r,o.state=s,o.context=f,r=i):(typeof o.componentDidMount=="function"&&(t.flags|=4194308),r=!1)}else{o=t.stateNode,sa(e,t),i=t.memoizedProps,f=t.type===t.elementType?i:Te(t.type,i),o.props=f,h=t.pendingProps,p=o.context,s=n.contextType,typeof s=="object"&&s!==null?s=_e(s):(s=de(n)?_t:le.current,s=qt(t,s));var g=n.getDerivedStateFromProps;(m=typeof g=="function"||typeof o.getSnapshotBeforeUpdate=="function")||typeof o.UNSAFE_componentWillReceiveProps!="function"&&typeof o.componentWillReceiveProps!="function"||(i!==h||p!==s)&&Ci(t,o,r,s),qe=!1,p=t.memoizedState,o.state=p,Kr(t,r,o,l);var w=t.memoizedState;i!==h||p!==w||fe.current||qe?(typeof g=="function"&&(Nu(t,n,g,r),w=t.memoizedState),(f=qe||xi(t,n,f,r,p,w,s)||!1)?(m||typeof o.UNSAFE_componentWillUpdate!="function"&&typeof o.componentWillUpdate!="function"||(typeof o.componentWillUpdate=="function"&&o.componentWillUpdate(r,w,s),typeof o.UNSAFE_componentWillUpdate=="function"&&o.UNSAFE_componentWillUpdate(r,w,s)),typeof o.componentDidUpdate=="function"&&(t.flags|=4),typeof o.getSnapshotBeforeUpdate=="function"&&(t.flags|=1024)):(typeof o.componentDidUpdate!="function"||i===e.memoizedProps&&p===e.memoizedState||(t.flags|=4),typeof o.getSnapshotBeforeUpdate!="function"||i===e.memoizedProps&&p===e.memoizedState||(t.flags|=1024),t.memoizedProps=r,t.memoizedState=w),o.props=r,o.state=w,o.context=s,r=f):(typeof o.componentDidUpdate!="function"||i===e.memoizedProps&&p===e.memoizedState||(t.flags|=4),typeof o.getSnapshotBeforeUpdate!="function"||i===e.memoizedProps&&p===e.memoizedState||(t.flags|=1024),r=!1)}return Lu(e,t,n,r,u,l)}function Lu(e,t,n,r,l,u){Fa(e,t);var o=(t.flags&128)!==0;if(!r&&!o)return l&&gi(t,n,!1),Xe(e,t,u);r=t.stateNode,fd.current=t;var i=o&&typeof n.getDerivedStateFromError!="function"?null:r.render();return t.flags|=1,e!==null&&o?(t.child=en(t,e.child,null,u),t.child=en(t,null,i,u)):ue(e,t,i,u),t.memoizedState=r.state,l&&gi(t,n,!0),t.child}function $a(e){var t=e.stateNode;t.pendingContext?yi(e,t.pendingContext,t.pendingContext!==t.context):t.context&&yi(e,t.context,!1),yo(e,t.containerInfo)}function Oi(e,t,n,r,l){return bt(),co(l),t.flags|=256,ue(e,t,n,r),t.child}var Du={dehydrated:null,treeContext:null,retryLane:0};function ju(e){return{baseLanes:e,cachePool:null,transitions:null}}function Ua(e,t,n){var r=t.pendingProps,l=U.current,u=!1,o=(t.flags&128)!==0,i;if((i=o)||(i=e!==null&&e.memoizedState===null?!1:(l&2)!==0),i?(u=!0,t.flags&=-129):(e===null||e.memoizedState!==null)&&(l|=1),M(U,l&1),e===null)return Cu(t),e=t.memoizedState,e!==null&&(e=e.dehydrated,e!==null)?(t.mode&1?e.data==="$!"?t.lanes=8:t.lanes=1073741824:t.lanes=1,null):(o=r.children,e=r.fallback,u?(r=t.mode,u=t.child,o={mode:"hidden",children:o},!(r&1)&&u!==null?(u.childLanes=0,u.pendingProps=o):u=dl(o,r,0,null),e=Ct(e,r,n,null),u.return=t,e.return=t,u.sibling=e,t.child=u,t.child.memoizedState=ju(n),t.memoizedState=Du,e):_o(t,o));if(l=e.memoizedState,l!==null&&(i=l.dehydrated,i!==null))return dd(e,t,o,r,i,l,n);if(u){u=r.fallback,o=t.mode,l=e.child,i=l.sibling;var s={mode:"hidden",children:r.children};return!(o&1)&&t.child!==l?(r=t.child,r.childLanes=0,r.pendingProps=s,t.deletions=null):(r=ct(l,s),r.subtreeFlags=l.subtreeFlags&14680064),i!==null?u=ct(i,u):(u=Ct(u,o,n,null),u.flags|=2),u.return=t,r.return=t,r.sibling=u,t.child=r,r=u,u=t.child,o=e.child.memoizedState,o=o===null?ju(n):{baseLanes:o.baseLanes|n,cachePool:null,transitions:o.transitions},u.memoizedState=o,u.childLanes=e.childLanes&~n,t.memoizedState=Du,r}return u=e.child,e=u.sibling,r=ct(u,{mode:"visible",children:r.children}),!(t.mode&1)&&(r.lanes=n),r.return=t,r.sibling=null,e!==null&&(n=t.deletions,n===null?(t.deletions=[e],t.flags|=16):n.push(e)),t.child=r,t.memoizedState=null,r}function _o(e,t){return t=dl({mode:"visible",children:t},e.mode,0,null),t.return=e,e.child=t}function mr(e,t,n,r){return r!==null&&co(r),en(t,e.child,null,n),e=_o(t,t.pendingProps.children),e.flags|=2,t.memoizedState=null,e}function dd(e,t,n,r,l,u,o){if(n)return t.flags&256?(t.flags&=-257,r=Hl(Error(y(422))),mr(e,t,o,r)):t.memoizedState!==null?(t.child=e.child,t.flags|=128,null):(u=r.fallback,l=t.mode,r=dl({mode:"visible",children:r.children},l,0,null),u=Ct(u,l,o,null),u.flags|=2,r.return=t,u.return=t,r.sibling=u,t.child=r,t.mode&1&&en(t,e.child,null,o),t.child.memoizedState=ju(o),t.memoizedState=Du,u);if(!(t.mode&1))return mr(e,t,o,null);if(l.data==="$!"){if(r=l.nextSibling&&l.nextSibling.dataset,r)var i=r.dgst;return r=i,u=Error(y(419)),r=Hl(u,r,void 0),mr(e,t,o,r)}if(i=(o&e.childLanes)!==0,ce||i){if(r=J,r!==null){switch(o&-o){case 4:l=2;break;case 16:l=8;break;case 64:case 128:case 256:case 512:case 1024:case 2048:case 4096:case 8192:case 16384:case 32768:case 65536:case 131072:case 262144:case 524288:case 1048576:case 2097152:case 4194304:case 8388608:case 16777216:case 33554432:case 67108864:l=32;break;case 536870912:l=268435456;break;default:l=0}l=l&(r.suspendedLanes|o)?0:l,l!==0&&l!==u.retryLane&&(u.retryLane=l,Ye(e,l),Re(r,e,l,-1))}return Do(),r=Hl(Error(y(421))),mr(e,t,o,r)}return l.data==="$?"?(t.flags|=128,t.child=e.child,t=_d.bind(null,e),l._reactRetry=t,null):(e=u.treeContext,ve=ot(l.nextSibling),ye=t,$=!0,De=null,e!==null&&(ke[Ee++]=Ve,ke[Ee++]=He,ke[Ee++]=Nt,Ve=e.id,He=e.overflow,Nt=t),t=_o(t,r.children),t.flags|=4096,t)}function Mi(e,t,n){e.lanes|=t;var r=e.alternate;r!==null&&(r.lanes|=t),_u(e.return,t,n)}function Wl(e,t,n,r,l){var u=e.memoizedState;u===null?e.memoizedState={isBackwards:t,rendering:null,renderingStartTime:0,last:r,tail:n,tailMode:l}:(u.isBackwards=t,u.rendering=null,u.renderingStartTime=0,u.last=r,u.tail=n,u.tailMode=l)}function Aa(e,t,n){var r=t.pendingProps,l=r.revealOrder,u=r.tail;if(ue(e,t,r.children,n),r=U.current,r&2)r=r&1|2,t.flags|=128;else{if(e!==null&&e.flags&128)e:for(e=t.child;e!==null;){if(e.tag===13)e.memoizedState!==null&&Mi(e,n,t);else if(e.tag===19)Mi(e,n,t);else if(e.child!==null){e.child.return=e,e=e.child;continue}if(e===t)break e;for(;e.sibling===null;){if(e.return===null||e.return===t)break e;e=e.return}e.sibling.return=e.return,e=e.sibling}r&=1}if(M(U,r),!(t.mode&1))t.memoizedState=null;else switch(l){case"forwards":for(n=t.child,l=null;n!==null;)e=n.alternate,e!==null&&Yr(e)===null&&(l=n),n=n.sibling;n=l,n===null?(l=t.child,t.child=null):(l=n.sibling,n.sibling=null),Wl(t,!1,l,n,u);break;case"backwards":for(n=null,l=t.child,t.child=null;l!==null;){if(e=l.alternate,e!==null&&Yr(e)===null){t.child=l;break}e=l.sibling,l.sibling=n,n=l,l=e}Wl(t,!0,n,null,u);break;case"together":Wl(t,!1,null,null,void 0);break;default:t.memoizedState=null}return t.child}function Nr(e,t){!(t.mode&1)&&e!==null&&(e.alternate=null,t.alternate=null,t.flags|=2)}function Xe(e,t,n){if(e!==null&&(t.dependencies=e.dependencies),zt|=t.lanes,!(n&t.childLanes))return null;if(e!==null&&t.child!==e.child)throw Error(y(153));if(t.child!==null){for(e=t.child,n=ct(e,e.pendingProps),t.child=n,n.return=t;e.sibling!==null;)e=e.sibling,n=n.sibling=ct(e,e.pendingProps),n.return=t;n.sibling=null}return t.child}function pd(e,t,n){switch(t.tag){case 3:$a(t),bt();break;case 5:pa(t);break;case 1:de(t.type)&&Br(t);break;case 4:yo(t,t.stateNode.containerInfo);break;case 10:var r=t.type._context,l=t.memoizedProps.value;M(Wr,r._currentValue),r._currentValue=l;break;case 13:if(r=t.memoizedState,r!==null)return r.dehydrated!==null?(M(U,U.current&1),t.flags|=128,null):n&t.child.childLanes?Ua(e,t,n):(M(U,U.current&1),e=Xe(e,t,n),e!==null?e.sibling:null);M(U,U.current&1);break;case 19:if(r=(n&t.childLanes)!==0,e.flags&128){if(r)return Aa(e,t,n);t.flags|=128}if(l=t.memoizedState,l!==null&&(l.rendering=null,l.tail=null,l.lastEffect=null),M(U,U.current),r)break;return null;case 22:case 23:return t.lanes=0,Ia(e,t,n)}return Xe(e,t,n)}var Ba,Ru,Va,Ha;Ba=function(e,t){for(var n=t.child;n!==null;){if(n.tag===5||n.tag===6)e.appendChild(n.stateNode);else if(n.tag!==4&&n.child!==null){n.child.return=n,n=n.child;continue}if(n===t)break;for(;n.sibling===null;){if(n.return===null||n.return===t)return;n=n.return}n.sibling.return=n.return,n=n.sibling}};Ru=function(){};Va=function(e,t,n,r){var l=e.memoizedProps;if(l!==r){e=t.stateNode,Et(Ue.current);var u=null;switch(n){case"input":l=tu(e,l),r=tu(e,r),u=[];break;case"select":l=B({},l,{value:void 0}),r=B({},r,{value:void 0}),u=[];break;case"textarea":l=lu(e,l),r=lu(e,r),u=[];break;default:typeof l.onClick!="function"&&typeof r.onClick=="function"&&(e.onclick=Ur)}ou(n,r);var o;n=null;for(f in l)if(!r.hasOwnProperty(f)&&l.hasOwnProperty(f)&&l[f]!=null)if(f==="style"){var i=l[f];for(o in i)i.hasOwnProperty(o)&&(n||(n={}),n[o]="")}else f!=="dangerouslySetInnerHTML"&&f!=="children"&&f!=="suppressContentEditableWarning"&&f!=="suppressHydrationWarning"&&f!=="autoFocus"&&(Dn.hasOwnProperty(f)?u||(u=[]):(u=u||[]).push(f,null));for(f in r){var s=r[f];if(i=l!=null?l[f]:void 0,r.hasOwnProperty(f)&&s!==i&&(s!=null||i!=null))if(f==="style")if(i){for(o in i)!i.hasOwnProperty(o)||s&&s.hasOwnProperty(o)||(n||(n={}),n[o]="");for(o in s)s.hasOwnProperty(o)&&i[o]!==s[o]&&(n||(n={}),n[o]=s[o])}else n||(u||(u=[]),u.push(f,n)),n=s;else f==="dangerouslySetInnerHTML"?(s=s?s.__html:void 0,i=i?i.__html:void 0,s!=null&&i!==s&&(u=u||[]).push(f,s)):f==="children"?typeof s!="string"&&typeof s!="number"||(u=u||[]).push(f,""+s):f!=="suppressContentEditableWarning"&&f!=="suppressHydrationWarning"&&(Dn.hasOwnProperty(f)?(s!=null&&f==="onScroll"&&I("scroll",e),u||i===s||(u=[])):(u=u||[]).push(f,s))}n&&(u=u||[]).push("style",n);var f=u;(t.updateQueue=f)&&(t.flags|=4)}};Ha=function(e,t,n,r){n!==r&&(t.flags|=4)};function vn(e,t){if(!$)switch(e.tailMode){case"hidden":t=e.tail;for(var n=null;t!==null;)t.alternate!==null&&(n=t),t=t.sibling;n===null?e.tail=null:n.sibling=null;break;case"collapsed":n=e.tail;for(var r=null;n!==null;)n.alternate!==null&&(r=n),n=n.sibling;r===null?t||e.tail===null?e.tail=null:e.tail.sibling=null:r.sibling=null}}function ne(e){var t=e.alternate!==null&&e.alternate.child===e.child,n=0,r=0;if(t)for(var l=e.child;l!==null;)n|=l.lanes|l.childLanes,r|=l.subtreeFlags&14680064,r|=l.flags&14680064,l.return=e,l=l.sibling;else for(l=e.child;l!==null;)n|=l.lanes|l.childLanes,r|=l.subtreeFlags,r|=l.flags,l.return=e,l=l.sibling;return e.subtreeFlags|=r,e.childLanes=n,t}function md(e,t,n){var r=t.pendingProps;switch(ao(t),t.tag){case 2:case 16:case 15:case 0:case 11:case 7:case 8:case 12:case 9:case 14:return ne(t),null;case 1:return de(t.type)&&Ar(),ne(t),null;case 3:return r=t.stateNode,tn(),F(fe),F(le),wo(),r.pendingContext&&(r.context=r.pendingContext,r.pendingContext=null),(e===null||e.child===null)&&(dr(t)?t.flags|=4:e===null||e.memoizedState.isDehydrated&&!(t.flags&256)||(t.flags|=1024,De!==null&&(Bu(De),De=null))),Ru(e,t),ne(t),null;case 5:go(t);var l=Et(Hn.current);if(n=t.type,e!==null&&t.stateNode!=null)Va(e,t,n,r,l),e.ref!==t.ref&&(t.flags|=512,t.flags|=2097152);else{if(!r){if(t.stateNode===null)throw Error(y(166));return ne(t),null}if(e=Et(Ue.current),dr(t)){r=t.stateNode,n=t.type;var u=t.memoizedProps;switch(r[Fe]=t,r[Bn]=u,e=(t.mode&1)!==0,n){case"dialog":I("cancel",r),I("close",r);break;case"iframe":case"object":case"embed":I("load",r);break;case"video":case"audio":for(l=0;l<kn.length;l++)I(kn[l],r);break;case"source":I("error",r);break;case"img":case"image":case"link":I("error",r),I("load",r);break;case"details":I("toggle",r);break;case"input":Wo(r,u),I("invalid",r);break;case"select":r._wrapperState={wasMultiple:!!u.multiple},I("invalid",r);break;case"textarea":Ko(r,u),I("invalid",r)}ou(n,u),l=null;for(var o in u)if(u.hasOwnProperty(o)){var i=u[o];o==="children"?typeof i=="string"?r.textContent!==i&&(u.suppressHydrationWarning!==!0&&fr(r.textContent,i,e),l=["children",i]):typeof i=="number"&&r.textContent!==""+i&&(u.suppressHydrationWarning!==!0&&fr(r.textContent,i,e),l=["children",""+i]):Dn.hasOwnProperty(o)&&i!=null&&o==="onScroll"&&I("scroll",r)}switch(n){case"input":rr(r),Qo(r,u,!0);break;case"textarea":rr(r),Yo(r);break;case"select":case"option":break;default:typeof u.onClick=="function"&&(r.onclick=Ur)}r=l,t.updateQueue=r,r!==null&&(t.flags|=4)}else{o=l.nodeType===9?l:l.ownerDocument,e==="http://www.w3.org/1999/xhtml"&&(e=vs(n)),e==="http://www.w3.org/1999/xhtml"?n==="script"?(e=o.createElement("div"),e.innerHTML="<script><\/script>",e=e.removeChild(e.firstChild)):typeof r.is=="string"?e=o.createElement(n,{is:r.is}):(e=o.createElement(n),n==="select"&&(o=e,r.multiple?o.multiple=!0:r.size&&(o.size=r.size))):e=o.createElementNS(e,n),e[Fe]=t,e[Bn]=r,Ba(e,t,!1,!1),t.stateNode=e;e:{switch(o=iu(n,r),n){case"dialog":I("cancel",e),I("close",e),l=r;break;case"iframe":case"object":case"embed":I("load",e),l=r;break;case"video":case"audio":for(l=0;l<kn.length;l++)I(kn[l],e);l=r;break;case"source":I("error",e),l=r;break;case"img":case"image":case"link":I("error",e),I("load",e),l=r;break;case"details":I("toggle",e),l=r;break;case"input":Wo(e,r),l=tu(e,r),I("invalid",e);break;case"option":l=r;break;case"select":e._wrapperState={wasMultiple:!!r.multiple},l=B({},r,{value:void 0}),I("invalid",e);break;case"textarea":Ko(e,r),l=lu(e,r),I("invalid",e);break;default:l=r}ou(n,l),i=l;for(u in i)if(i.hasOwnProperty(u)){var s=i[u];u==="style"?ws(e,s):u==="dangerouslySetInnerHTML"?(s=s?s.__html:void 0,s!=null&&ys(e,s)):u==="children"?typeof s=="string"?(n!=="textarea"||s!=="")&&jn(e,s):typeof s=="number"&&jn(e,""+s):u!=="suppressContentEditableWarning"&&u!=="suppressHydrationWarning"&&u!=="autoFocus"&&(Dn.hasOwnProperty(u)?s!=null&&u==="onScroll"&&I("scroll",e):s!=null&&Xu(e,u,s,o))}switch(n){case"input":rr(e),Qo(e,r,!1);break;case"textarea":rr(e),Yo(e);break;case"option":r.value!=null&&e.setAttribute("value",""+ft(r.value));break;case"select":e.multiple=!!r.multiple,u=r.value,u!=null?Qt(e,!!r.multiple,u,!1):r.defaultValue!=null&&Qt(e,!!r.multiple,r.defaultValue,!0);break;default:typeof l.onClick=="function"&&(e.onclick=Ur)}switch(n){case"button":case"input":case"select":case"textarea":r=!!r.autoFocus;break e;case"img":r=!0;break e;default:r=!1}}r&&(t.flags|=4)}t.ref!==null&&(t.flags|=512,t.flags|=2097152)}return ne(t),null;case 6:if(e&&t.stateNode!=null)Ha(e,t,e.memoizedProps,r);else{if(typeof r!="string"&&t.stateNode===null)throw Error(y(166));if(n=Et(Hn.current),Et(Ue.current),dr(t)){if(r=t.stateNode,n=t.memoizedProps,r[Fe]=t,(u=r.nodeValue!==n)&&(e=ye,e!==null))switch(e.tag){case 3:fr(r.nodeValue,n,(e.mode&1)!==0);break;case 5:e.memoizedProps.suppressHydrationWarning!==!0&&fr(r.nodeValue,n,(e.mode&1)!==0)}u&&(t.flags|=4)}else r=(n.nodeType===9?n:n.ownerDocument).createTextNode(r),r[Fe]=t,t.stateNode=r}return ne(t),null;case 13:if(F(U),r=t.memoizedState,e===null||e.memoizedState!==null&&e.memoizedState.dehydrated!==null){if($&&ve!==null&&t.mode&1&&!(t.flags&128))oa(),bt(),t.flags|=98560,u=!1;else if(u=dr(t),r!==null&&r.dehydrated!==null){if(e===null){if(!u)throw Error(y(318));if(u=t.memoizedState,u=u!==null?u.dehydrated:null,!u)throw Error(y(317));u[Fe]=t}else bt(),!(t.flags&128)&&(t.memoizedState=null),t.flags|=4;ne(t),u=!1}else De!==null&&(Bu(De),De=null),u=!0;if(!u)return t.flags&65536?t:null}return t.flags&128?(t.lanes=n,t):(r=r!==null,r!==(e!==null&&e.memoizedState!==null)&&r&&(t.child.flags|=8192,t.mode&1&&(e===null||U.current&1?X===0&&(X=3):Do())),t.updateQueue!==null&&(t.flags|=4),ne(t),null);case 4:return tn(),Ru(e,t),e===null&&Un(t.stateNode.containerInfo),ne(t),null;case 10:return mo(t.type._context),ne(t),null;case 17:return de(t.type)&&Ar(),ne(t),null;case 19:if(F(U),u=t.memoizedState,u===null)return ne(t),null;if(r=(t.flags&128)!==0,o=u.rendering,o===null)if(r)vn(u,!1);else{if(X!==0||e!==null&&e.flags&128)for(e=t.child;e!==null;){if(o=Yr(e),o!==null){for(t.flags|=128,vn(u,!1),r=o.updateQueue,r!==null&&(t.updateQueue=r,t.flags|=4),t.subtreeFlags=0,r=n,n=t.child;n!==null;)u=n,e=r,u.flags&=14680066,o=u.alternate,o===null?(u.childLanes=0,u.lanes=e,u.child=null,u.subtreeFlags=0,u.memoizedProps=null,u.memoizedState=null,u.updateQueue=null,u.dependencies=null,u.stateNode=null):(u.childLanes=o.childLanes,u.lanes=o.lanes,u.child=o.child,u.subtreeFlags=0,u.deletions=null,u.memoizedProps=o.memoizedProps,u.memoizedState=o.memoizedState,u.updateQueue=o.updateQueue,u.type=o.type,e=o.dependencies,u.dependencies=e===null?null:{lanes:e.lanes,firstContext:e.firstContext}),n=n.sibling;return M(U,U.current&1|2),t.child}e=e.sibling}u.tail!==null&&Q()>rn&&(t.flags|=128,r=!0,vn(u,!1),t.lanes=4194304)}else{if(!r)if(e=Yr(o),e!==null){if(t.flags|=128,r=!0,n=e.updateQueue,n!==null&&(t.updateQueue=n,t.flags|=4),vn(u,!0),u.tail===null&&u.tailMode==="hidden"&&!o.alternate&&!$)return ne(t),null}else 2*Q()-u.renderingStartTime>rn&&n!==1073741824&&(t.flags|=128,r=!0,vn(u,!1),t.lanes=4194304);u.isBackwards?(o.sibling=t.child,t.child=o):(n=u.last,n!==null?n.sibling=o:t.child=o,u.last=o)}return u.tail!==null?(t=u.tail,u.rendering=t,u.tail=t.sibling,u.renderingStartTime=Q(),t.sibling=null,n=U.current,M(U,r?n&1|2:n&1),t):(ne(t),null);case 22:case 23:return Lo(),r=t.memoizedState!==null,e!==null&&e.memoizedState!==null!==r&&(t.flags|=8192),r&&t.mode&1?he&1073741824&&(ne(t),t.subtreeFlags&6&&(t.flags|=8192)):ne(t),null;case 24:return null;case 25:return null}throw Error(y(156,t.tag))}function hd(e,t){switch(ao(t),t.tag){case 1:return de(t.type)&&Ar(),e=t.flags,e&65536?(t.flags=e&-65537|128,t):null;case 3:return tn(),F(fe),F(le),wo(),e=t.flags,e&65536&&!(e&128)?(t.flags=e&-65537|128,t):null;case 5:return go(t),null;case 13:if(F(U),e=t.memoizedState,e!==null&&e.dehydrated!==null){if(t.alternate===null)throw Error(y(340));bt()}return e=t.flags,e&65536?(t.flags=e&-65537|128,t):null;case 19:return F(U),null;case 4:return tn(),null;case 10:return mo(t.type._context),null;case 22:case 23:return Lo(),null;case 24:return null;default:return null}}var hr=!1,re=!1,vd=typeof WeakSet=="function"?WeakSet:Set,k=null;function Ht(e,t){var n=e.ref;if(n!==null)if(typeof n=="function")try{n(null)}catch(r){V(e,t,r)}else n.current=null}function Ou(e,t,n){try{n()}catch(r){V(e,t,r)}}var Ii=!1;function yd(e,t){if(yu=Ir,e=Ys(),io(e)){if("selectionStart"in e)var n={start:e.selectionStart,end:e.selectionEnd};else e:{n=(n=e.ownerDocument)&&n.defaultView||window;var r=n.getSelection&&n.getSelection();if(r&&r.rangeCount!==0){n=r.anchorNode;var l=r.anchorOffset,u=r.focusNode;r=r.focusOffset;try{n.nodeType,u.nodeType}catch{n=null;break e}var o=0,i=-1,s=-1,f=0,m=0,h=e,p=null;t:for(;;){for(var g;h!==n||l!==0&&h.nodeType!==3||(i=o+l),h!==u||r!==0&&h.nodeType!==3||(s=o+r),h.nodeType===3&&(o+=h.nodeValue.length),(g=h.firstChild)!==null;)p=h,h=g;for(;;){if(h===e)break t;if(p===n&&++f===l&&(i=o),p===u&&++m===r&&(s=o),(g=h.nextSibling)!==null)break;h=p,p=h.parentNode}h=g}n=i===-1||s===-1?null:{start:i,end:s}}else n=null}n=n||{start:0,end:0}}else n=null;for(gu={focusedElem:e,selectionRange:n},Ir=!1,k=t;k!==null;)if(t=k,e=t.child,(t.subtreeFlags&1028)!==0&&e!==null)e.return=t,k=e;else for(;k!==null;){t=k;try{var w=t.alternate;if(t.flags&1024)switch(t.tag){case 0:case 11:case 15:break;case 1:if(w!==null){var S=w.memoizedProps,P=w.memoizedState,c=t.stateNode,a=c.getSnapshotBeforeUpdate(t.elementType===t.type?S:Te(t.type,S),P);c.__reactInternalSnapshotBeforeUpdate=a}break;case 3:var d=t.stateNode.containerInfo;d.nodeType===1?d.textContent="":d.nodeType===9&&d.documentElement&&d.removeChild(d.documentElement);break;case 5:case 6:case 4:case 17:break;default:throw Error(y(163))}}catch(v){V(t,t.return,v)}if(e=t.sibling,e!==null){e.return=t.return,k=e;break}k=t.return}return w=Ii,Ii=!1,w}function zn(e,t,n){var r=t.updateQueue;if(r=r!==null?r.lastEffect:null,r!==null){var l=r=r.next;do{if((l.tag&e)===e){var u=l.destroy;l.destroy=void 0,u!==void 0&&Ou(t,n,u)}l=l.next}while(l!==r)}}function cl(e,t){if(t=t.updateQueue,t=t!==null?t.lastEffect:null,t!==null){var n=t=t.next;do{if((n.tag&e)===e){var r=n.create;n.destroy=r()}n=n.next}while(n!==t)}}function Mu(e){var t=e.ref;if(t!==null){var n=e.stateNode;switch(e.tag){case 5:e=n;break;default:e=n}typeof t=="function"?t(e):t.current=e}}function Wa(e){var t=e.alternate;t!==null&&(e.alternate=null,Wa(t)),e.child=null,e.deletions=null,e.sibling=null,e.tag===5&&(t=e.stateNode,t!==null&&(delete t[Fe],delete t[Bn],delete t[ku],delete t[bf],delete t[ed])),e.stateNode=null,e.return=null,e.dependencies=null,e.memoizedProps=null,e.memoizedState=null,e.pendingProps=null,e.stateNode=null,e.updateQueue=null}function Qa(e){return e.tag===5||e.tag===3||e.tag===4}function Fi(e){e:for(;;){for(;e.sibling===null;){if(e.return===null||Qa(e.return))return null;e=e.return}for(e.sibling.return=e.return,e=e.sibling;e.tag!==5&&e.tag!==6&&e.tag!==18;){if(e.flags&2||e.child===null||e.tag===4)continue e;e.child.return=e,e=e.child}if(!(e.flags&2))return e.stateNode}}function Iu(e,t,n){var r=e.tag;if(r===5||r===6)e=e.stateNode,t?n.nodeType===8?n.parentNode.insertBefore(e,t):n.insertBefore(e,t):(n.nodeType===8?(t=n.parentNode,t.insertBefore(e,n)):(t=n,t.appendChild(e)),n=n._reactRootContainer,n!=null||t.onclick!==null||(t.onclick=Ur));else if(r!==4&&(e=e.child,e!==null))for(Iu(e,t,n),e=e.sibling;e!==null;)Iu(e,t,n),e=e.sibling}function Fu(e,t,n){var r=e.tag;if(r===5||r===6)e=e.stateNode,t?n.insertBefore(e,t):n.appendChild(e);else if(r!==4&&(e=e.child,e!==null))for(Fu(e,t,n),e=e.sibling;e!==null;)Fu(e,t,n),e=e.sibling}var q=null,Le=!1;function Ze(e,t,n){for(n=n.child;n!==null;)Ka(e,t,n),n=n.sibling}function Ka(e,t,n){if($e&&typeof $e.onCommitFiberUnmount=="function")try{$e.onCommitFiberUnmount(nl,n)}catch{}switch(n.tag){case 5:re||Ht(n,t);case 6:var r=q,l=Le;q=null,Ze(e,t,n),q=r,Le=l,q!==null&&(Le?(e=q,n=n.stateNode,e.nodeType===8?e.parentNode.removeChild(n):e.removeChild(n)):q.removeChild(n.stateNode));break;case 18:q!==null&&(Le?(e=q,n=n.stateNode,e.nodeType===8?Fl(e.parentNode,n):e.nodeType===1&&Fl(e,n),In(e)):Fl(q,n.stateNode));break;case 4:r=q,l=Le,q=n.stateNode.containerInfo,Le=!0,Ze(e,t,n),q=r,Le=l;break;case 0:case 11:case 14:case 15:if(!re&&(r=n.updateQueue,r!==null&&(r=r.lastEffect,r!==null))){l=r=r.next;do{var u=l,o=u.destroy;u=u.tag,o!==void 0&&(u&2||u&4)&&Ou(n,t,o),l=l.next}while(l!==r)}Ze(e,t,n);break;case 1:if(!re&&(Ht(n,t),r=n.stateNode,typeof r.componentWillUnmount=="function"))try{r.props=n.memoizedProps,r.state=n.memoizedState,r.componentWillUnmount()}catch(i){V(n,t,i)}Ze(e,t,n);break;case 21:Ze(e,t,n);break;case 22:n.mode&1?(re=(r=re)||n.memoizedState!==null,Ze(e,t,n),re=r):Ze(e,t,n);break;default:Ze(e,t,n)}}function $i(e){var t=e.updateQueue;if(t!==null){e.updateQueue=null;var n=e.stateNode;n===null&&(n=e.stateNode=new vd),t.forEach(function(r){var l=Nd.bind(null,e,r);n.has(r)||(n.add(r),r.then(l,l))})}}function ze(e,t){var n=t.deletions;if(n!==null)for(var r=0;r<n.length;r++){var l=n[r];try{var u=e,o=t,i=o;e:for(;i!==null;){switch(i.tag){case 5:q=i.stateNode,Le=!1;break e;case 3:q=i.stateNode.containerInfo,Le=!0;break e;case 4:q=i.stateNode.containerInfo,Le=!0;break e}i=i.return}if(q===null)throw Error(y(160));Ka(u,o,l),q=null,Le=!1;var s=l.alternate;s!==null&&(s.return=null),l.return=null}catch(f){V(l,t,f)}}if(t.subtreeFlags&12854)for(t=t.child;t!==null;)Ya(t,e),t=t.sibling}function Ya(e,t){var n=e.alternate,r=e.flags;switch(e.tag){case 0:case 11:case 14:case 15:if(ze(t,e),Me(e),r&4){try{zn(3,e,e.return),cl(3,e)}catch(S){V(e,e.return,S)}try{zn(5,e,e.return)}catch(S){V(e,e.return,S)}}break;case 1:ze(t,e),Me(e),r&512&&n!==null&&Ht(n,n.return);break;case 5:if(ze(t,e),Me(e),r&512&&n!==null&&Ht(n,n.return),e.flags&32){var l=e.stateNode;try{jn(l,"")}catch(S){V(e,e.return,S)}}if(r&4&&(l=e.stateNode,l!=null)){var u=e.memoizedProps,o=n!==null?n.memoizedProps:u,i=e.type,s=e.updateQueue;if(e.updateQueue=null,s!==null)try{i==="input"&&u.type==="radio"&&u.name!=null&&ms(l,u),iu(i,o);var f=iu(i,u);for(o=0;o<s.length;o+=2){var m=s[o],h=s[o+1];m==="style"?ws(l,h):m==="dangerouslySetInnerHTML"?ys(l,h):m==="children"?jn(l,h):Xu(l,m,h,f)}switch(i){case"input":nu(l,u);break;case"textarea":hs(l,u);break;case"select":var p=l._wrapperState.wasMultiple;l._wrapperState.wasMultiple=!!u.multiple;var g=u.value;g!=null?Qt(l,!!u.multiple,g,!1):p!==!!u.multiple&&(u.defaultValue!=null?Qt(l,!!u.multiple,u.defaultValue,!0):Qt(l,!!u.multiple,u.multiple?[]:"",!1))}l[Bn]=u}catch(S){V(e,e.return,S)}}break;case 6:if(ze(t,e),Me(e),r&4){if(e.stateNode===null)throw Error(y(162));l=e.stateNode,u=e.memoizedProps;try{l.nodeValue=u}catch(S){V(e,e.return,S)}}break;case 3:if(ze(t,e),Me(e),r&4&&n!==null&&n.memoizedState.isDehydrated)try{In(t.containerInfo)}catch(S){V(e,e.return,S)}break;case 4:ze(t,e),Me(e);break;case 13:ze(t,e),Me(e),l=e.child,l.flags&8192&&(u=l.memoizedState!==null,l.stateNode.isHidden=u,!u||l.alternate!==null&&l.alternate.memoizedState!==null||(zo=Q())),r&4&&$i(e);break;case 22:if(m=n!==null&&n.memoizedState!==null,e.mode&1?(re=(f=re)||m,ze(t,e),re=f):ze(t,e),Me(e),r&8192){if(f=e.memoizedState!==null,(e.stateNode.isHidden=f)&&!m&&e.mode&1)for(k=e,m=e.child;m!==null;){for(h=k=m;k!==null;){switch(p=k,g=p.child,p.tag){case 0:case 11:case 14:case 15:zn(4,p,p.return);break;case 1:Ht(p,p.return);var w=p.stateNode;if(typeof w.componentWillUnmount=="function"){r=p,n=p.return;try{t=r,w.props=t.memoizedProps,w.state=t.memoizedState,w.componentWillUnmount()}catch(S){V(r,n,S)}}break;case 5:Ht(p,p.return);break;case 22:if(p.memoizedState!==null){Ai(h);continue}}g!==null?(g.return=p,k=g):Ai(h)}m=m.sibling}e:for(m=null,h=e;;){if(h.tag===5){if(m===null){m=h;try{l=h.stateNode,f?(u=l.style,typeof u.setProperty=="function"?u.setProperty("display","none","important"):u.display="none"):(i=h.stateNode,s=h.memoizedProps.style,o=s!=null&&s.hasOwnProperty("display")?s.display:null,i.style.display=gs("display",o))}catch(S){V(e,e.return,S)}}}else if(h.tag===6){if(m===null)try{h.stateNode.nodeValue=f?"":h.memoizedProps}catch(S){V(e,e.return,S)}}else if((h.tag!==22&&h.tag!==23||h.memoizedState===null||h===e)&&h.child!==null){h.child.return=h,h=h.child;continue}if(h===e)break e;for(;h.sibling===null;){if(h.return===null||h.return===e)break e;m===h&&(m=null),h=h.return}m===h&&(m=null),h.sibling.return=h.return,h=h.sibling}}break;case 19:ze(t,e),Me(e),r&4&&$i(e);break;case 21:break;default:ze(t,e),Me(e)}}function Me(e){var t=e.flags;if(t&2){try{e:{for(var n=e.return;n!==null;){if(Qa(n)){var r=n;break e}n=n.return}throw Error(y(160))}switch(r.tag){case 5:var l=r.stateNode;r.flags&32&&(jn(l,""),r.flags&=-33);var u=Fi(e);Fu(e,u,l);break;case 3:case 4:var o=r.stateNode.containerInfo,i=Fi(e);Iu(e,i,o);break;default:throw Error(y(161))}}catch(s){V(e,e.return,s)}e.flags&=-3}t&4096&&(e.flags&=-4097)}function gd(e,t,n){k=e,Xa(e)}function Xa(e,t,n){for(var r=(e.mode&1)!==0;k!==null;){var l=k,u=l.child;if(l.tag===22&&r){var o=l.memoizedState!==null||hr;if(!o){var i=l.alternate,s=i!==null&&i.memoizedState!==null||re;i=hr;var f=re;if(hr=o,(re=s)&&!f)for(k=l;k!==null;)o=k,s=o.child,o.tag===22&&o.memoizedState!==null?Bi(l):s!==null?(s.return=o,k=s):Bi(l);for(;u!==null;)k=u,Xa(u),u=u.sibling;k=l,hr=i,re=f}Ui(e)}else l.subtreeFlags&8772&&u!==null?(u.return=l,k=u):Ui(e)}}function Ui(e){for(;k!==null;){var t=k;if(t.flags&8772){var n=t.alternate;try{if(t.flags&8772)switch(t.tag){case 0:case 11:case 15:re||cl(5,t);break;case 1:var r=t.stateNode;if(t.flags&4&&!re)if(n===null)r.componentDidMount();else{var l=t.elementType===t.type?n.memoizedProps:Te(t.type,n.memoizedProps);r.componentDidUpdate(l,n.memoizedState,r.__reactInternalSnapshotBeforeUpdate)}var u=t.updateQueue;u!==null&&Ei(t,u,r);break;case 3:var o=t.updateQueue;if(o!==null){if(n=null,t.child!==null)switch(t.child.tag){case 5:n=t.child.stateNode;break;case 1:n=t.child.stateNode}Ei(t,o,n)}break;case 5:var i=t.stateNode;if(n===null&&t.flags&4){n=i;var s=t.memoizedProps;switch(t.type){case"button":case"input":case"select":case"textarea":s.autoFocus&&n.focus();break;case"img":s.src&&(n.src=s.src)}}break;case 6:break;case 4:break;case 12:break;case 13:if(t.memoizedState===null){var f=t.alternate;if(f!==null){var m=f.memoizedState;if(m!==null){var h=m.dehydrated;h!==null&&In(h)}}}break;case 19:case 17:case 21:case 22:case 23:case 25:break;default:throw Error(y(163))}re||t.flags&512&&Mu(t)}catch(p){V(t,t.return,p)}}if(t===e){k=null;break}if(n=t.sibling,n!==null){n.return=t.return,k=n;break}k=t.return}}function Ai(e){for(;k!==null;){var t=k;if(t===e){k=null;break}var n=t.sibling;if(n!==null){n.return=t.return,k=n;break}k=t.return}}function Bi(e){for(;k!==null;){var t=k;try{switch(t.tag){case 0:case 11:case 15:var n=t.return;try{cl(4,t)}catch(s){V(t,n,s)}break;case 1:var r=t.stateNode;if(typeof r.componentDidMount=="function"){var l=t.return;try{r.componentDidMount()}catch(s){V(t,l,s)}}var u=t.return;try{Mu(t)}catch(s){V(t,u,s)}break;case 5:var o=t.return;try{Mu(t)}catch(s){V(t,o,s)}}}catch(s){V(t,t.return,s)}if(t===e){k=null;break}var i=t.sibling;if(i!==null){i.return=t.return,k=i;break}k=t.return}}var wd=Math.ceil,Zr=Ge.ReactCurrentDispatcher,No=Ge.ReactCurrentOwner,Ce=Ge.ReactCurrentBatchConfig,R=0,J=null,K=null,b=0,he=0,Wt=mt(0),X=0,Yn=null,zt=0,fl=0,Po=0,Tn=null,ae=null,zo=0,rn=1/0,Ae=null,Jr=!1,$u=null,st=null,vr=!1,nt=null,qr=0,Ln=0,Uu=null,Pr=-1,zr=0;function oe(){return R&6?Q():Pr!==-1?Pr:Pr=Q()}function at(e){return e.mode&1?R&2&&b!==0?b&-b:nd.transition!==null?(zr===0&&(zr=Ds()),zr):(e=O,e!==0||(e=window.event,e=e===void 0?16:$s(e.type)),e):1}function Re(e,t,n,r){if(50<Ln)throw Ln=0,Uu=null,Error(y(185));Gn(e,n,r),(!(R&2)||e!==J)&&(e===J&&(!(R&2)&&(fl|=n),X===4&&et(e,b)),pe(e,r),n===1&&R===0&&!(t.mode&1)&&(rn=Q()+500,il&&ht()))}function pe(e,t){var n=e.callbackNode;tf(e,t);var r=Mr(e,e===J?b:0);if(r===0)n!==null&&Zo(n),e.callbackNode=null,e.callbackPriority=0;else if(t=r&-r,e.callbackPriority!==t){if(n!=null&&Zo(n),t===1)e.tag===0?td(Vi.bind(null,e)):ra(Vi.bind(null,e)),Jf(function(){!(R&6)&&ht()}),n=null;else{switch(js(r)){case 1:n=bu;break;case 4:n=Ts;break;case 16:n=Or;break;case 536870912:n=Ls;break;default:n=Or}n=nc(n,Ga.bind(null,e))}e.callbackPriority=t,e.callbackNode=n}}function Ga(e,t){if(Pr=-1,zr=0,R&6)throw Error(y(327));var n=e.callbackNode;if(Zt()&&e.callbackNode!==n)return null;var r=Mr(e,e===J?b:0);if(r===0)return null;if(r&30||r&e.expiredLanes||t)t=br(e,r);else{t=r;var l=R;R|=2;var u=Ja();(J!==e||b!==t)&&(Ae=null,rn=Q()+500,xt(e,t));do try{Ed();break}catch(i){Za(e,i)}while(!0);po(),Zr.current=u,R=l,K!==null?t=0:(J=null,b=0,t=X)}if(t!==0){if(t===2&&(l=du(e),l!==0&&(r=l,t=Au(e,l))),t===1)throw n=Yn,xt(e,0),et(e,r),pe(e,Q()),n;if(t===6)et(e,r);else{if(l=e.current.alternate,!(r&30)&&!Sd(l)&&(t=br(e,r),t===2&&(u=du(e),u!==0&&(r=u,t=Au(e,u))),t===1))throw n=Yn,xt(e,0),et(e,r),pe(e,Q()),n;switch(e.finishedWork=l,e.finishedLanes=r,t){case 0:case 1:throw Error(y(345));case 2:wt(e,ae,Ae);break;case 3:if(et(e,r),(r&130023424)===r&&(t=zo+500-Q(),10<t)){if(Mr(e,0)!==0)break;if(l=e.suspendedLanes,(l&r)!==r){oe(),e.pingedLanes|=e.suspendedLanes&l;break}e.timeoutHandle=Su(wt.bind(null,e,ae,Ae),t);break}wt(e,ae,Ae);break;case 4:if(et(e,r),(r&4194240)===r)break;for(t=e.eventTimes,l=-1;0<r;){var o=31-je(r);u=1<<o,o=t[o],o>l&&(l=o),r&=~u}if(r=l,r=Q()-r,r=(120>r?120:480>r?480:1080>r?1080:1920>r?1920:3e3>r?3e3:4320>r?4320:1960*wd(r/1960))-r,10<r){e.timeoutHandle=Su(wt.bind(null,e,ae,Ae),r);break}wt(e,ae,Ae);break;case 5:wt(e,ae,Ae);break;default:throw Error(y(329))}}}return pe(e,Q()),e.callbackNode===n?Ga.bind(null,e):null}function Au(e,t){var n=Tn;return e.current.memoizedState.isDehydrated&&(xt(e,t).flags|=256),e=br(e,t),e!==2&&(t=ae,ae=n,t!==null&&Bu(t)),e}function Bu(e){ae===null?ae=e:ae.push.apply(ae,e)}function Sd(e){for(var t=e;;){if(t.flags&16384){var n=t.updateQueue;if(n!==null&&(n=n.stores,n!==null))for(var r=0;r<n.length;r++){var l=n[r],u=l.getSnapshot;l=l.value;try{if(!Oe(u(),l))return!1}catch{return!1}}}if(n=t.child,t.subtreeFlags&16384&&n!==null)n.return=t,t=n;else{if(t===e)break;for(;t.sibling===null;){if(t.return===null||t.return===e)return!0;t=t.return}t.sibling.return=t.return,t=t.sibling}}return!0}function et(e,t){for(t&=~Po,t&=~fl,e.suspendedLanes|=t,e.pingedLanes&=~t,e=e.expirationTimes;0<t;){var n=31-je(t),r=1<<n;e[n]=-1,t&=~r}}function Vi(e){if(R&6)throw Error(y(327));Zt();var t=Mr(e,0);if(!(t&1))return pe(e,Q()),null;var n=br(e,t);if(e.tag!==0&&n===2){var r=du(e);r!==0&&(t=r,n=Au(e,r))}if(n===1)throw n=Yn,xt(e,0),et(e,t),pe(e,Q()),n;if(n===6)throw Error(y(345));return e.finishedWork=e.current.alternate,e.finishedLanes=t,wt(e,ae,Ae),pe(e,Q()),null}function To(e,t){var n=R;R|=1;try{return e(t)}finally{R=n,R===0&&(rn=Q()+500,il&&ht())}}function Tt(e){nt!==null&&nt.tag===0&&!(R&6)&&Zt();var t=R;R|=1;var n=Ce.transition,r=O;try{if(Ce.transition=null,O=1,e)return e()}finally{O=r,Ce.transition=n,R=t,!(R&6)&&ht()}}function Lo(){he=Wt.current,F(Wt)}function xt(e,t){e.finishedWork=null,e.finishedLanes=0;var n=e.timeoutHandle;if(n!==-1&&(e.timeoutHandle=-1,Zf(n)),K!==null)for(n=K.return;n!==null;){var r=n;switch(ao(r),r.tag){case 1:r=r.type.childContextTypes,r!=null&&Ar();break;case 3:tn(),F(fe),F(le),wo();break;case 5:go(r);break;case 4:tn();break;case 13:F(U);break;case 19:F(U);break;case 10:mo(r.type._context);break;case 22:case 23:Lo()}n=n.return}if(J=e,K=e=ct(e.current,null),b=he=t,X=0,Yn=null,Po=fl=zt=0,ae=Tn=null,kt!==null){for(t=0;t<kt.length;t++)if(n=kt[t],r=n.interleaved,r!==null){n.interleaved=null;var l=r.next,u=n.pending;if(u!==null){var o=u.next;u.next=l,r.next=o}n.pending=r}kt=null}return e}function Za(e,t){do{var n=K;try{if(po(),Cr.current=Gr,Xr){for(var r=A.memoizedState;r!==null;){var l=r.queue;l!==null&&(l.pending=null),r=r.next}Xr=!1}if(Pt=0,Z=Y=A=null,Pn=!1,Wn=0,No.current=null,n===null||n.return===null){X=1,Yn=t,K=null;break}e:{var u=e,o=n.return,i=n,s=t;if(t=b,i.flags|=32768,s!==null&&typeof s=="object"&&typeof s.then=="function"){var f=s,m=i,h=m.tag;if(!(m.mode&1)&&(h===0||h===11||h===15)){var p=m.alternate;p?(m.updateQueue=p.updateQueue,m.memoizedState=p.memoizedState,m.lanes=p.lanes):(m.updateQueue=null,m.memoizedState=null)}var g=Ti(o);if(g!==null){g.flags&=-257,Li(g,o,i,u,t),g.mode&1&&zi(u,f,t),t=g,s=f;var w=t.updateQueue;if(w===null){var S=new Set;S.add(s),t.updateQueue=S}else w.add(s);break e}else{if(!(t&1)){zi(u,f,t),Do();break e}s=Error(y(426))}}else if($&&i.mode&1){var P=Ti(o);if(P!==null){!(P.flags&65536)&&(P.flags|=256),Li(P,o,i,u,t),co(nn(s,i));break e}}u=s=nn(s,i),X!==4&&(X=2),Tn===null?Tn=[u]:Tn.push(u),u=o;do{switch(u.tag){case 3:u.flags|=65536,t&=-t,u.lanes|=t;var c=Ra(u,s,t);ki(u,c);break e;case 1:i=s;var a=u.type,d=u.stateNode;if(!(u.flags&128)&&(typeof a.getDerivedStateFromError=="function"||d!==null&&typeof d.componentDidCatch=="function"&&(st===null||!st.has(d)))){u.flags|=65536,t&=-t,u.lanes|=t;var v=Oa(u,i,t);ki(u,v);break e}}u=u.return}while(u!==null)}ba(n)}catch(E){t=E,K===n&&n!==null&&(K=n=n.return);continue}break}while(!0)}function Ja(){var e=Zr.current;return Zr.current=Gr,e===null?Gr:e}function Do(){(X===0||X===3||X===2)&&(X=4),J===null||!(zt&268435455)&&!(fl&268435455)||et(J,b)}function br(e,t){var n=R;R|=2;var r=Ja();(J!==e||b!==t)&&(Ae=null,xt(e,t));do try{kd();break}catch(l){Za(e,l)}while(!0);if(po(),R=n,Zr.current=r,K!==null)throw Error(y(261));return J=null,b=0,X}function kd(){for(;K!==null;)qa(K)}function Ed(){for(;K!==null&&!Kc();)qa(K)}function qa(e){var t=tc(e.alternate,e,he);e.memoizedProps=e.pendingProps,t===null?ba(e):K=t,No.current=null}function ba(e){var t=e;do{var n=t.alternate;if(e=t.return,t.flags&32768){if(n=hd(n,t),n!==null){n.flags&=32767,K=n;return}if(e!==null)e.flags|=32768,e.subtreeFlags=0,e.deletions=null;else{X=6,K=null;return}}else if(n=md(n,t,he),n!==null){K=n;return}if(t=t.sibling,t!==null){K=t;return}K=t=e}while(t!==null);X===0&&(X=5)}function wt(e,t,n){var r=O,l=Ce.transition;try{Ce.transition=null,O=1,xd(e,t,n,r)}finally{Ce.transition=l,O=r}return null}function xd(e,t,n,r){do Zt();while(nt!==null);if(R&6)throw Error(y(327));n=e.finishedWork;var l=e.finishedLanes;if(n===null)return null;if(e.finishedWork=null,e.finishedLanes=0,n===e.current)throw Error(y(177));e.callbackNode=null,e.callbackPriority=0;var u=n.lanes|n.childLanes;if(nf(e,u),e===J&&(K=J=null,b=0),!(n.subtreeFlags&2064)&&!(n.flags&2064)||vr||(vr=!0,nc(Or,function(){return Zt(),null})),u=(n.flags&15990)!==0,n.subtreeFlags&15990||u){u=Ce.transition,Ce.transition=null;var o=O;O=1;var i=R;R|=4,No.current=null,yd(e,n),Ya(n,e),Hf(gu),Ir=!!yu,gu=yu=null,e.current=n,gd(n),Yc(),R=i,O=o,Ce.transition=u}else e.current=n;if(vr&&(vr=!1,nt=e,qr=l),u=e.pendingLanes,u===0&&(st=null),Zc(n.stateNode),pe(e,Q()),t!==null)for(r=e.onRecoverableError,n=0;n<t.length;n++)l=t[n],r(l.value,{componentStack:l.stack,digest:l.digest});if(Jr)throw Jr=!1,e=$u,$u=null,e;return qr&1&&e.tag!==0&&Zt(),u=e.pendingLanes,u&1?e===Uu?Ln++:(Ln=0,Uu=e):Ln=0,ht(),null}function Zt(){if(nt!==null){var e=js(qr),t=Ce.transition,n=O;try{if(Ce.transition=null,O=16>e?16:e,nt===null)var r=!1;else{if(e=nt,nt=null,qr=0,R&6)throw Error(y(331));var l=R;for(R|=4,k=e.current;k!==null;){var u=k,o=u.child;if(k.flags&16){var i=u.deletions;if(i!==null){for(var s=0;s<i.length;s++){var f=i[s];for(k=f;k!==null;){var m=k;switch(m.tag){case 0:case 11:case 15:zn(8,m,u)}var h=m.child;if(h!==null)h.return=m,k=h;else for(;k!==null;){m=k;var p=m.sibling,g=m.return;if(Wa(m),m===f){k=null;break}if(p!==null){p.return=g,k=p;break}k=g}}}var w=u.alternate;if(w!==null){var S=w.child;if(S!==null){w.child=null;do{var P=S.sibling;S.sibling=null,S=P}while(S!==null)}}k=u}}if(u.subtreeFlags&2064&&o!==null)o.return=u,k=o;else e:for(;k!==null;){if(u=k,u.flags&2048)switch(u.tag){case 0:case 11:case 15:zn(9,u,u.return)}var c=u.sibling;if(c!==null){c.return=u.return,k=c;break e}k=u.return}}var a=e.current;for(k=a;k!==null;){o=k;var d=o.child;if(o.subtreeFlags&2064&&d!==null)d.return=o,k=d;else e:for(o=a;k!==null;){if(i=k,i.flags&2048)try{switch(i.tag){case 0:case 11:case 15:cl(9,i)}}catch(E){V(i,i.return,E)}if(i===o){k=null;break e}var v=i.sibling;if(v!==null){v.return=i.return,k=v;break e}k=i.return}}if(R=l,ht(),$e&&typeof $e.onPostCommitFiberRoot=="function")try{$e.onPostCommitFiberRoot(nl,e)}catch{}r=!0}return r}finally{O=n,Ce.transition=t}}return!1}function Hi(e,t,n){t=nn(n,t),t=Ra(e,t,1),e=it(e,t,1),t=oe(),e!==null&&(Gn(e,1,t),pe(e,t))}function V(e,t,n){if(e.tag===3)Hi(e,e,n);else for(;t!==null;){if(t.tag===3){Hi(t,e,n);break}else if(t.tag===1){var r=t.stateNode;if(typeof t.type.getDerivedStateFromError=="function"||typeof r.componentDidCatch=="function"&&(st===null||!st.has(r))){e=nn(n,e),e=Oa(t,e,1),t=it(t,e,1),e=oe(),t!==null&&(Gn(t,1,e),pe(t,e));break}}t=t.return}}function Cd(e,t,n){var r=e.pingCache;r!==null&&r.delete(t),t=oe(),e.pingedLanes|=e.suspendedLanes&n,J===e&&(b&n)===n&&(X===4||X===3&&(b&130023424)===b&&500>Q()-zo?xt(e,0):Po|=n),pe(e,t)}function ec(e,t){t===0&&(e.mode&1?(t=or,or<<=1,!(or&130023424)&&(or=4194304)):t=1);var n=oe();e=Ye(e,t),e!==null&&(Gn(e,t,n),pe(e,n))}function _d(e){var t=e.memoizedState,n=0;t!==null&&(n=t.retryLane),ec(e,n)}function Nd(e,t){var n=0;switch(e.tag){case 13:var r=e.stateNode,l=e.memoizedState;l!==null&&(n=l.retryLane);break;case 19:r=e.stateNode;break;default:throw Error(y(314))}r!==null&&r.delete(t),ec(e,n)}var tc;tc=function(e,t,n){if(e!==null)if(e.memoizedProps!==t.pendingProps||fe.current)ce=!0;else{if(!(e.lanes&n)&&!(t.flags&128))return ce=!1,pd(e,t,n);ce=!!(e.flags&131072)}else ce=!1,$&&t.flags&1048576&&la(t,Hr,t.index);switch(t.lanes=0,t.tag){case 2:var r=t.type;Nr(e,t),e=t.pendingProps;var l=qt(t,le.current);Gt(t,n),l=ko(null,t,r,e,l,n);var u=Eo();return t.flags|=1,typeof l=="object"&&l!==null&&typeof l.render=="function"&&l.$$typeof===void 0?(t.tag=1,t.memoizedState=null,t.updateQueue=null,de(r)?(u=!0,Br(t)):u=!1,t.memoizedState=l.state!==null&&l.state!==void 0?l.state:null,vo(t),l.updater=sl,t.stateNode=l,l._reactInternals=t,Pu(t,r,e,n),t=Lu(null,t,r,!0,u,n)):(t.tag=0,$&&u&&so(t),ue(null,t,l,n),t=t.child),t;case 16:r=t.elementType;e:{switch(Nr(e,t),e=t.pendingProps,l=r._init,r=l(r._payload),t.type=r,l=t.tag=zd(r),e=Te(r,e),l){case 0:t=Tu(null,t,r,e,n);break e;case 1:t=Ri(null,t,r,e,n);break e;case 11:t=Di(null,t,r,e,n);break e;case 14:t=ji(null,t,r,Te(r.type,e),n);break e}throw Error(y(306,r,""))}return t;case 0:return r=t.type,l=t.pendingProps,l=t.elementType===r?l:Te(r,l),Tu(e,t,r,l,n);case 1:return r=t.type,l=t.pendingProps,l=t.elementType===r?l:Te(r,l),Ri(e,t,r,l,n);case 3:e:{if($a(t),e===null)throw Error(y(387));r=t.pendingProps,u=t.memoizedState,l=u.element,sa(e,t),Kr(t,r,null,n);var o=t.memoizedState;if(r=o.element,u.isDehydrated)if(u={element:r,isDehydrated:!1,cache:o.cache,pendingSuspenseBoundaries:o.pendingSuspenseBoundaries,transitions:o.transitions},t.updateQueue.baseState=u,t.memoizedState=u,t.flags&256){l=nn(Error(y(423)),t),t=Oi(e,t,r,n,l);break e}else if(r!==l){l=nn(Error(y(424)),t),t=Oi(e,t,r,n,l);break e}else for(ve=ot(t.stateNode.containerInfo.firstChild),ye=t,$=!0,De=null,n=da(t,null,r,n),t.child=n;n;)n.flags=n.flags&-3|4096,n=n.sibling;else{if(bt(),r===l){t=Xe(e,t,n);break e}ue(e,t,r,n)}t=t.child}return t;case 5:return pa(t),e===null&&Cu(t),r=t.type,l=t.pendingProps,u=e!==null?e.memoizedProps:null,o=l.children,wu(r,l)?o=null:u!==null&&wu(r,u)&&(t.flags|=32),Fa(e,t),ue(e,t,o,n),t.child;case 6:return e===null&&Cu(t),null;case 13:return Ua(e,t,n);case 4:return yo(t,t.stateNode.containerInfo),r=t.pendingProps,e===null?t.child=en(t,null,r,n):ue(e,t,r,n),t.child;case 11:return r=t.type,l=t.pendingProps,l=t.elementType===r?l:Te(r,l),Di(e,t,r,l,n);case 7:return ue(e,t,t.pendingProps,n),t.child;case 8:return ue(e,t,t.pendingProps.children,n),t.child;case 12:return ue(e,t,t.pendingProps.children,n),t.child;case 10:e:{if(r=t.type._context,l=t.pendingProps,u=t.memoizedProps,o=l.value,M(Wr,r._currentValue),r._currentValue=o,u!==null)if(Oe(u.value,o)){if(u.children===l.children&&!fe.current){t=Xe(e,t,n);break e}}else for(u=t.child,u!==null&&(u.return=t);u!==null;){var i=u.dependencies;if(i!==null){o=u.child;for(var s=i.firstContext;s!==null;){if(s.context===r){if(u.tag===1){s=We(-1,n&-n),s.tag=2;var f=u.updateQueue;if(f!==null){f=f.shared;var m=f.pending;m===null?s.next=s:(s.next=m.next,m.next=s),f.pending=s}}u.lanes|=n,s=u.alternate,s!==null&&(s.lanes|=n),_u(u.return,n,t),i.lanes|=n;break}s=s.next}}else if(u.tag===10)o=u.type===t.type?null:u.child;else if(u.tag===18){if(o=u.return,o===null)throw Error(y(341));o.lanes|=n,i=o.alternate,i!==null&&(i.lanes|=n),_u(o,n,t),o=u.sibling}else o=u.child;if(o!==null)o.return=u;else for(o=u;o!==null;){if(o===t){o=null;break}if(u=o.sibling,u!==null){u.return=o.return,o=u;break}o=o.return}u=o}ue(e,t,l.children,n),t=t.child}return t;case 9:return l=t.type,r=t.pendingProps.children,Gt(t,n),l=_e(l),r=r(l),t.flags|=1,ue(e,t,r,n),t.child;case 14:return r=t.type,l=Te(r,t.pendingProps),l=Te(r.type,l),ji(e,t,r,l,n);case 15:return Ma(e,t,t.type,t.pendingProps,n);case 17:return r=t.type,l=t.pendingProps,l=t.elementType===r?l:Te(r,l),Nr(e,t),t.tag=1,de(r)?(e=!0,Br(t)):e=!1,Gt(t,n),ca(t,r,l),Pu(t,r,l,n),Lu(null,t,r,!0,e,n);case 19:return Aa(e,t,n);case 22:return Ia(e,t,n)}throw Error(y(156,t.tag))};function nc(e,t){return zs(e,t)}function Pd(e,t,n,r){this.tag=e,this.key=n,this.sibling=this.child=this.return=this.stateNode=this.type=this.elementType=null,this.index=0,this.ref=null,this.pendingProps=t,this.dependencies=this.memoizedState=this.updateQueue=this.memoizedProps=null,this.mode=r,this.subtreeFlags=this.flags=0,this.deletions=null,this.childLanes=this.lanes=0,this.alternate=null}function xe(e,t,n,r){return new Pd(e,t,n,r)}function jo(e){return e=e.prototype,!(!e||!e.isReactComponent)}function zd(e){if(typeof e=="function")return jo(e)?1:0;if(e!=null){if(e=e.$$typeof,e===Zu)return 11;if(e===Ju)return 14}return 2}function ct(e,t){var n=e.alternate;return n===null?(n=xe(e.tag,t,e.key,e.mode),n.elementType=e.elementType,n.type=e.type,n.stateNode=e.stateNode,n.alternate=e,e.alternate=n):(n.pendingProps=t,n.type=e.type,n.flags=0,n.subtreeFlags=0,n.deletions=null),n.flags=e.flags&14680064,n.childLanes=e.childLanes,n.lanes=e.lanes,n.child=e.child,n.memoizedProps=e.memoizedProps,n.memoizedState=e.memoizedState,n.updateQueue=e.updateQueue,t=e.dependencies,n.dependencies=t===null?null:{lanes:t.lanes,firstContext:t.firstContext},n.sibling=e.sibling,n.index=e.index,n.ref=e.ref,n}function Tr(e,t,n,r,l,u){var o=2;if(r=e,typeof e=="function")jo(e)&&(o=1);else if(typeof e=="string")o=5;else e:switch(e){case Ot:return Ct(n.children,l,u,t);case Gu:o=8,l|=8;break;case Jl:return e=xe(12,n,t,l|2),e.elementType=Jl,e.lanes=u,e;case ql:return e=xe(13,n,t,l),e.elementType=ql,e.lanes=u,e;case bl:return e=xe(19,n,t,l),e.elementType=bl,e.lanes=u,e;case fs:return dl(n,l,u,t);default:if(typeof e=="object"&&e!==null)switch(e.$$typeof){case as:o=10;break e;case cs:o=9;break e;case Zu:o=11;break e;case Ju:o=14;break e;case Je:o=16,r=null;break e}throw Error(y(130,e==null?e:typeof e,""))}return t=xe(o,n,t,l),t.elementType=e,t.type=r,t.lanes=u,t}function Ct(e,t,n,r){return e=xe(7,e,r,t),e.lanes=n,e}function dl(e,t,n,r){return e=xe(22,e,r,t),e.elementType=fs,e.lanes=n,e.stateNode={isHidden:!1},e}function Ql(e,t,n){return e=xe(6,e,null,t),e.lanes=n,e}function Kl(e,t,n){return t=xe(4,e.children!==null?e.children:[],e.key,t),t.lanes=n,t.stateNode={containerInfo:e.containerInfo,pendingChildren:null,implementation:e.implementation},t}function Td(e,t,n,r,l){this.tag=t,this.containerInfo=e,this.finishedWork=this.pingCache=this.current=this.pendingChildren=null,this.timeoutHandle=-1,this.callbackNode=this.pendingContext=this.context=null,this.callbackPriority=0,this.eventTimes=Nl(0),this.expirationTimes=Nl(-1),this.entangledLanes=this.finishedLanes=this.mutableReadLanes=this.expiredLanes=this.pingedLanes=this.suspendedLanes=this.pendingLanes=0,this.entanglements=Nl(0),this.identifierPrefix=r,this.onRecoverableError=l,this.mutableSourceEagerHydrationData=null}function Ro(e,t,n,r,l,u,o,i,s){return e=new Td(e,t,n,i,s),t===1?(t=1,u===!0&&(t|=8)):t=0,u=xe(3,null,null,t),e.current=u,u.stateNode=e,u.memoizedState={element:r,isDehydrated:n,cache:null,transitions:null,pendingSuspenseBoundaries:null},vo(u),e}function Ld(e,t,n){var r=3<arguments.length&&arguments[3]!==void 0?arguments[3]:null;return{$$typeof:Rt,key:r==null?null:""+r,children:e,containerInfo:t,implementation:n}}function rc(e){if(!e)return dt;e=e._reactInternals;e:{if(Dt(e)!==e||e.tag!==1)throw Error(y(170));var t=e;do{switch(t.tag){case 3:t=t.stateNode.context;break e;case 1:if(de(t.type)){t=t.stateNode.__reactInternalMemoizedMergedChildContext;break e}}t=t.return}while(t!==null);throw Error(y(171))}if(e.tag===1){var n=e.type;if(de(n))return na(e,n,t)}return t}function lc(e,t,n,r,l,u,o,i,s){return e=Ro(n,r,!0,e,l,u,o,i,s),e.context=rc(null),n=e.current,r=oe(),l=at(n),u=We(r,l),u.callback=t??null,it(n,u,l),e.current.lanes=l,Gn(e,l,r),pe(e,r),e}function pl(e,t,n,r){var l=t.current,u=oe(),o=at(l);return n=rc(n),t.context===null?t.context=n:t.pendingContext=n,t=We(u,o),t.payload={element:e},r=r===void 0?null:r,r!==null&&(t.callback=r),e=it(l,t,o),e!==null&&(Re(e,l,o,u),xr(e,l,o)),o}function el(e){if(e=e.current,!e.child)return null;switch(e.child.tag){case 5:return e.child.stateNode;default:return e.child.stateNode}}function Wi(e,t){if(e=e.memoizedState,e!==null&&e.dehydrated!==null){var n=e.retryLane;e.retryLane=n!==0&&n<t?n:t}}function Oo(e,t){Wi(e,t),(e=e.alternate)&&Wi(e,t)}function Dd(){return null}var uc=typeof reportError=="function"?reportError:function(e){console.error(e)};function Mo(e){this._internalRoot=e}ml.prototype.render=Mo.prototype.render=function(e){var t=this._internalRoot;if(t===null)throw Error(y(409));pl(e,t,null,null)};ml.prototype.unmount=Mo.prototype.unmount=function(){var e=this._internalRoot;if(e!==null){this._internalRoot=null;var t=e.containerInfo;Tt(function(){pl(null,e,null,null)}),t[Ke]=null}};function ml(e){this._internalRoot=e}ml.prototype.unstable_scheduleHydration=function(e){if(e){var t=Ms();e={blockedOn:null,target:e,priority:t};for(var n=0;n<be.length&&t!==0&&t<be[n].priority;n++);be.splice(n,0,e),n===0&&Fs(e)}};function Io(e){return!(!e||e.nodeType!==1&&e.nodeType!==9&&e.nodeType!==11)}function hl(e){return!(!e||e.nodeType!==1&&e.nodeType!==9&&e.nodeType!==11&&(e.nodeType!==8||e.nodeValue!==" react-mount-point-unstable "))}function Qi(){}function jd(e,t,n,r,l){if(l){if(typeof r=="function"){var u=r;r=function(){var f=el(o);u.call(f)}}var o=lc(t,r,e,0,null,!1,!1,"",Qi);return e._reactRootContainer=o,e[Ke]=o.current,Un(e.nodeType===8?e.parentNode:e),Tt(),o}for(;l=e.lastChild;)e.removeChild(l);if(typeof r=="function"){var i=r;r=function(){var f=el(s);i.call(f)}}var s=Ro(e,0,!1,null,null,!1,!1,"",Qi);return e._reactRootContainer=s,e[Ke]=s.current,Un(e.nodeType===8?e.parentNode:e),Tt(function(){pl(t,s,n,r)}),s}function vl(e,t,n,r,l){var u=n._reactRootContainer;if(u){var o=u;if(typeof l=="function"){var i=l;l=function(){var s=el(o);i.call(s)}}pl(t,o,e,l)}else o=jd(n,t,e,l,r);return el(o)}Rs=function(e){switch(e.tag){case 3:var t=e.stateNode;if(t.current.memoizedState.isDehydrated){var n=Sn(t.pendingLanes);n!==0&&(eo(t,n|1),pe(t,Q()),!(R&6)&&(rn=Q()+500,ht()))}break;case 13:Tt(function(){var r=Ye(e,1);if(r!==null){var l=oe();Re(r,e,1,l)}}),Oo(e,1)}};to=function(e){if(e.tag===13){var t=Ye(e,134217728);if(t!==null){var n=oe();Re(t,e,134217728,n)}Oo(e,134217728)}};Os=function(e){if(e.tag===13){var t=at(e),n=Ye(e,t);if(n!==null){var r=oe();Re(n,e,t,r)}Oo(e,t)}};Ms=function(){return O};Is=function(e,t){var n=O;try{return O=e,t()}finally{O=n}};au=function(e,t,n){switch(t){case"input":if(nu(e,n),t=n.name,n.type==="radio"&&t!=null){for(n=e;n.parentNode;)n=n.parentNode;for(n=n.querySelectorAll("input[name="+JSON.stringify(""+t)+'][type="radio"]'),t=0;t<n.length;t++){var r=n[t];if(r!==e&&r.form===e.form){var l=ol(r);if(!l)throw Error(y(90));ps(r),nu(r,l)}}}break;case"textarea":hs(e,n);break;case"select":t=n.value,t!=null&&Qt(e,!!n.multiple,t,!1)}};Es=To;xs=Tt;var Rd={usingClientEntryPoint:!1,Events:[Jn,$t,ol,Ss,ks,To]},yn={findFiberByHostInstance:St,bundleType:0,version:"18.2.0",rendererPackageName:"react-dom"},Od={bundleType:yn.bundleType,version:yn.version,rendererPackageName:yn.rendererPackageName,rendererConfig:yn.rendererConfig,overrideHookState:null,overrideHookStateDeletePath:null,overrideHookStateRenamePath:null,overrideProps:null,overridePropsDeletePath:null,overridePropsRenamePath:null,setErrorHandler:null,setSuspenseHandler:null,scheduleUpdate:null,currentDispatcherRef:Ge.ReactCurrentDispatcher,findHostInstanceByFiber:function(e){return e=Ns(e),e===null?null:e.stateNode},findFiberByHostInstance:yn.findFiberByHostInstance||Dd,findHostInstancesForRefresh:null,scheduleRefresh:null,scheduleRoot:null,setRefreshHandler:null,getCurrentFiber:null,reconcilerVersion:"18.2.0-next-9e3b772b8-20220608"};if(typeof __REACT_DEVTOOLS_GLOBAL_HOOK__<"u"){var yr=__REACT_DEVTOOLS_GLOBAL_HOOK__;if(!yr.isDisabled&&yr.supportsFiber)try{nl=yr.inject(Od),$e=yr}catch{}}we.__SECRET_INTERNALS_DO_NOT_USE_OR_YOU_WILL_BE_FIRED=Rd;we.createPortal=function(e,t){var n=2<arguments.length&&arguments[2]!==void 0?arguments[2]:null;if(!Io(t))throw Error(y(200));return Ld(e,t,null,n)};we.createRoot=function(e,t){if(!Io(e))throw Error(y(299));var n=!1,r="",l=uc;return t!=null&&(t.unstable_strictMode===!0&&(n=!0),t.identifierPrefix!==void 0&&(r=t.identifierPrefix),t.onRecoverableError!==void 0&&(l=t.onRecoverableError)),t=Ro(e,1,!1,null,null,n,!1,r,l),e[Ke]=t.current,Un(e.nodeType===8?e.parentNode:e),new Mo(t)};we.findDOMNode=function(e){if(e==null)return null;if(e.nodeType===1)return e;var t=e._reactInternals;if(t===void 0)throw typeof e.render=="function"?Error(y(188)):(e=Object.keys(e).join(","),Error(y(268,e)));return e=Ns(t),e=e===null?null:e.stateNode,e};we.flushSync=function(e){return Tt(e)};we.hydrate=function(e,t,n){if(!hl(t))throw Error(y(200));return vl(null,e,t,!0,n)};we.hydrateRoot=function(e,t,n){if(!Io(e))throw Error(y(405));var r=n!=null&&n.hydratedSources||null,l=!1,u="",o=uc;if(n!=null&&(n.unstable_strictMode===!0&&(l=!0),n.identifierPrefix!==void 0&&(u=n.identifierPrefix),n.onRecoverableError!==void 0&&(o=n.onRecoverableError)),t=lc(t,null,e,1,n??null,l,!1,u,o),e[Ke]=t.current,Un(e),r)for(e=0;e<r.length;e++)n=r[e],l=n._getVersion,l=l(n._source),t.mutableSourceEagerHydrationData==null?t.mutableSourceEagerHydrationData=[n,l]:t.mutableSourceEagerHydrationData.push(n,l);return new ml(t)};we.render=function(e,t,n){if(!hl(t))throw Error(y(200));return vl(null,e,t,!1,n)};we.unmountComponentAtNode=function(e){if(!hl(e))throw Error(y(40));return e._reactRootContainer?(Tt(function(){vl(null,null,e,!1,function(){e._reactRootContainer=null,e[Ke]=null})}),!0):!1};we.unstable_batchedUpdates=To;we.unstable_renderSubtreeIntoContainer=function(e,t,n,r){if(!hl(n))throw Error(y(200));if(e==null||e._reactInternals===void 0)throw Error(y(38));return vl(e,t,n,!1,r)};we.version="18.2.0-next-9e3b772b8-20220608";function oc(){if(!(typeof __REACT_DEVTOOLS_GLOBAL_HOOK__>"u"||typeof __REACT_DEVTOOLS_GLOBAL_HOOK__.checkDCE!="function"))try{__REACT_DEVTOOLS_GLOBAL_HOOK__.checkDCE(oc)}catch(e){console.error(e)}}oc(),ls.exports=we;var Md=ls.exports,Ki=Md;Gl.createRoot=Ki.createRoot,Gl.hydrateRoot=Ki.hydrateRoot;const yl="/api";async function Id(){const e=await fetch(`${yl}/events`);if(!e.ok)throw new Error("failed to load events");const t=await e.json();if(!Array.isArray(t.events))return[];const r=t.events.filter(l=>l.kind==="ip_pair_summary").at(-1);return(r==null?void 0:r.pairCounts)??[]}async function Fd(e=200){const t=await fetch(`${yl}/events`);if(!t.ok)throw new Error("failed to load events");const n=await t.json();return Array.isArray(n.events)?n.events.filter(l=>l.kind==="dns").slice(-e).reverse():[]}function $d(e){const n=`${window.location.protocol==="https:"?"wss":"ws"}://${window.location.host}/ws/dns`,r=new WebSocket(n);return r.onopen=()=>{var l;return(l=e.onOpen)==null?void 0:l.call(e)},r.onclose=()=>{var l;return(l=e.onClose)==null?void 0:l.call(e)},r.onmessage=l=>{var u,o,i,s;try{const f=JSON.parse(l.data);if((f==null?void 0:f.kind)==="snapshot"&&Array.isArray(f.events)){const m=f,p=m.events.filter(w=>w.kind==="ip_pair_summary").at(-1);p!=null&&p.pairCounts&&((u=e.onPairSummary)==null||u.call(e,p.pairCounts));const g=m.events.filter(w=>w.kind==="dns");g.length>0&&((o=e.onDnsBatch)==null||o.call(e,g));return}if(f.kind==="ip_pair_summary"){const m=f;m.pairCounts&&((i=e.onPairSummary)==null||i.call(e,m.pairCounts));return}if(f.kind==="dns"){(s=e.onDnsEvent)==null||s.call(e,f);return}}catch(f){console.error("invalid websocket payload",f)}},()=>r.close()}async function Ud(){const e=await fetch(`${yl}/rules`);if(!e.ok)throw new Error("failed to load blocklist");const t=await e.json();return Array.isArray(t.domains)?t.domains:[]}async function Ad(e){if(!(await fetch(`${yl}/rules`,{method:"POST",headers:{"Content-Type":"application/json"},body:JSON.stringify({domains:e})})).ok)throw new Error("failed to update blocklist")}const Yl=200;function Bd(){const[e,t]=me.useState([]),[n,r]=me.useState([]),[l,u]=me.useState([]),[o,i]=me.useState(null),[s,f]=me.useState(!1),[m,h]=me.useState("traffic");me.useEffect(()=>{Id().then(c=>t(Yi(c))).catch(c=>console.error("failed to load pair counts",c)),Fd(Yl).then(c=>r(Xl(c))).catch(c=>console.error("failed to load DNS events",c)),Ud().then(c=>u(c.map(Lr))).catch(c=>console.error("failed to load blocklist",c));const P=$d({onOpen:()=>f(!0),onClose:()=>f(!1),onPairSummary:c=>t(Yi(c)),onDnsBatch:c=>r(Xl(c).slice(0,Yl)),onDnsEvent:c=>r(a=>Xl([c,...a]).slice(0,Yl))});return()=>P()},[]);const p=me.useMemo(()=>e.map(Vd),[e]),g=me.useMemo(()=>n.map(Hd),[n]),w=me.useMemo(()=>new Set(l),[l]),S=async P=>{if(!P)return;const c=Lr(P);if(!(w.has(c)||o===c)){i(c);try{const a=Array.from(new Set([...l,c]));await Ad(a),u(a)}catch(a){console.error("failed to block domain",a)}finally{i(null)}}};return z.jsxs("main",{className:"app",children:[z.jsxs("header",{className:"app__header",children:[z.jsxs("div",{children:[z.jsx("h1",{children:"Network Intelligence"}),z.jsxs("div",{className:"tabs",children:[z.jsx("button",{className:`tab ${m==="traffic"?"tab--active":""}`,onClick:()=>h("traffic"),children:"Traffic"}),z.jsx("button",{className:`tab ${m==="dns"?"tab--active":""}`,onClick:()=>h("dns"),children:"DNS Activity"})]})]}),z.jsx("span",{className:`status ${s?"status--up":"status--down"}`,children:s?"live":"offline"})]}),z.jsx("section",{className:"app__body",children:m==="traffic"?z.jsxs("table",{className:"packet-table",children:[z.jsx("thead",{children:z.jsxs("tr",{children:[z.jsx("th",{children:"Type"}),z.jsx("th",{children:"Internal"}),z.jsx("th",{children:"External"}),z.jsx("th",{children:"Packets (in/out)"})]})}),z.jsx("tbody",{children:p.length===0?z.jsx("tr",{children:z.jsx("td",{colSpan:4,className:"packet-table__empty",children:"Waiting for traffic..."})}):p.map(P=>z.jsxs("tr",{children:[z.jsx("td",{children:P.type}),z.jsx("td",{children:P.internal}),z.jsx("td",{children:P.external}),z.jsx("td",{children:P.packets})]},P.id))})]},"traffic"):z.jsxs("table",{className:"packet-table",children:[z.jsx("thead",{children:z.jsxs("tr",{children:[z.jsx("th",{children:"Time"}),z.jsx("th",{children:"Domain"}),z.jsx("th",{children:"Direction"}),z.jsx("th",{children:"Flow"}),z.jsx("th",{children:"Action"})]})}),z.jsx("tbody",{children:g.length===0?z.jsx("tr",{children:z.jsx("td",{colSpan:5,className:"packet-table__empty",children:"Waiting for DNS events..."})}):g.map(P=>{const c=Lr(P.rawDomain??""),a=c!==""&&w.has(c),d=o===c,v=P.rawDomain!==""&&P.rawDomain!==null&&P.rawDomain!==void 0;return z.jsxs("tr",{children:[z.jsx("td",{children:P.time}),z.jsxs("td",{children:[z.jsx("span",{children:P.domain}),v&&z.jsx("button",{className:`block-button ${a?"block-button--blocked":""}`,onClick:()=>S(P.rawDomain),disabled:a||d,children:a?"Blocked":d?"Blocking...":"Block"})]}),z.jsx("td",{children:P.direction}),z.jsx("td",{children:P.flow}),z.jsx("td",{children:P.action})]},P.id)})})]},"dns")})]})}function Vd(e){const t=`${e.incoming}/${e.outgoing}`;return{id:`${e.category}|${e.internal}->${e.external}`,type:Wd(e.category),internal:e.internal||"-",external:Qd(e.external,e.externalDomain),packets:t}}function Hd(e){const t=(e.domain??"").trim(),n=Lr(t),r=t||n||"-";return{id:`${e.timestamp}-${r}`,time:Yd(e.timestamp),domain:r,rawDomain:n,direction:Kd(e.direction??""),flow:`${e.sourceIp??"-"} → ${e.destinationIp??"-"} (${e.transport??""})`,action:e.reason?`${e.action??""} (${e.reason})`:e.action??"allow"}}function Yi(e){return[...e].sort((t,n)=>{const r=t.incoming+t.outgoing,l=n.incoming+n.outgoing;return r===l?`${t.internal}->${t.external}`.localeCompare(`${n.internal}->${n.external}`):l-r})}function Xl(e){return[...e].sort((t,n)=>Date.parse(n.timestamp)-Date.parse(t.timestamp))}function Wd(e){switch(e){case"internal":return"Internal";case"external":return"External";default:return e||"-"}}function Qd(e,t){return e?t?`${e} (${t})`:e:"-"}function Kd(e){return e?e.charAt(0).toUpperCase()+e.slice(1):"-"}function Yd(e){const t=new Date(e);return Number.isNaN(t.getTime())?e:t.toLocaleTimeString([],{hour12:!1})}function Lr(e){return e.trim().toLowerCase()}Gl.createRoot(document.getElementById("root")).render(z.jsx(Cc.StrictMode,{children:z.jsx(Bd,{})}));
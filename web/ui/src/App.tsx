@@ -2,9 +2,11 @@ import React, { useEffect, useMemo, useState } from "react";
 import {
   fetchDnsEvents,
   fetchPairCounts,
+  mergePairCounts,
   fetchBlocklist,
   updateBlocklist,
   PairCount,
+  Traffic,
   DnsEvent,
   subscribeToEvents,
 } from "./api";
@@ -18,7 +20,9 @@ type PairRow = {
   type: string;
   internal: string;
   external: string;
-  packets: string;
+  last10s: string;
+  last1m: string;
+  last1h: string;
 };
 
 type DnsRow = {
@@ -55,7 +59,7 @@ function App() {
     const unsubscribe = subscribeToEvents({
       onOpen: () => setConnected(true),
       onClose: () => setConnected(false),
-      onPairSummary: (counts) => setPairCounts(sortCounts(counts)),
+      onPairSummary: (summary) => setPairCounts((prev) => sortCounts(mergePairCounts(prev, summary))),
       onDnsBatch: (events) => setDnsEvents(sortDns(events).slice(0, MAX_DNS_EVENTS)),
       onDnsEvent: (event) =>
         setDnsEvents((prev) => sortDns([event, ...prev]).slice(0, MAX_DNS_EVENTS)),
@@ -121,13 +125,15 @@ function App() {
                 <th>Type</th>
                 <th>Internal</th>
                 <th>External</th>
-                <th>Packets (in/out)</th>
+                <th>Last 10 s (in/out)</th>
+                <th>Last minute</th>
+                <th>Last hour</th>
               </tr>
             </thead>
             <tbody>
               {trafficRows.length === 0 ? (
                 <tr>
-                  <td colSpan={4} className="packet-table__empty">
+                  <td colSpan={6} className="packet-table__empty">
                     Waiting for traffic...
                   </td>
                 </tr>
@@ -137,7 +143,9 @@ function App() {
                     <td>{row.type}</td>
                     <td>{row.internal}</td>
                     <td>{row.external}</td>
-                    <td>{row.packets}</td>
+                    <td>{row.last10s}</td>
+                    <td>{row.last1m}</td>
+                    <td>{row.last1h}</td>
                   </tr>
                 ))
               )}
@@ -199,16 +207,21 @@ function App() {
 }
 
 function toPairRow(item: PairCount): PairRow {
-  const total = `${item.incoming}/${item.outgoing}`;
   return {
     id: `${item.category}|${item.internal}->${item.external}`,
     type: labelCategory(item.category),
     internal: item.internal || "-",
     external: externalLabel(item.external, item.externalDomain),
-    packets: total,
+    last10s: formatTraffic(item.last10s),
+    last1m: formatTraffic(item.last1m),
+    last1h: formatTraffic(item.last1h),
   };
 }
 
+function formatTraffic(t?: Traffic): string {
+  return t ? `${t.incoming}/${t.outgoing}` : "-";
+}
+
 function toDnsRow(event: DnsEvent): DnsRow {
   const raw = (event.domain ?? "").trim();
   const normalized = normalizeDomain(raw);
@@ -226,8 +239,8 @@ function toDnsRow(event: DnsEvent): DnsRow {
 
 function sortCounts(counts: PairCount[]): PairCount[] {
   return [...counts].sort((a, b) => {
-    const totalA = a.incoming + a.outgoing;
-    const totalB = b.incoming + b.outgoing;
+    const totalA = (a.last1m?.incoming ?? 0) + (a.last1m?.outgoing ?? 0);
+    const totalB = (b.last1m?.incoming ?? 0) + (b.last1m?.outgoing ?? 0);
     if (totalA === totalB) {
       return `${a.internal}->${a.external}`.localeCompare(`${b.internal}->${b.external}`);
     }
@@ -1,3 +1,8 @@
+export type Traffic = {
+  incoming: number;
+  outgoing: number;
+};
+
 export type PairCount = {
   category: string;
   internal: string;
@@ -5,6 +10,11 @@ export type PairCount = {
   externalDomain?: string;
   incoming: number;
   outgoing: number;
+  last10s?: Traffic;
+  last1m?: Traffic;
+  last1h?: Traffic;
+  // seenAt is when a summary last listed the pair, in epoch milliseconds.
+  seenAt?: number;
 };
 
 export type DnsEvent = {
@@ -31,9 +41,62 @@ export type SnapshotPayload = {
 };
 
 const apiBase = "/api";
+// Idle pairs are listed once a minute; one missing for longer has aged out.
+const pairStaleMs = 150_000;
+const csrfCookie = "kidos_csrf";
+const safeMethods = new Set(["GET", "HEAD", "OPTIONS"]);
+
+function readCookie(name: string): string | undefined {
+  for (const part of document.cookie.split(";")) {
+    const [key, ...rest] = part.trim().split("=");
+    if (key === name) {
+      return decodeURIComponent(rest.join("="));
+    }
+  }
+  return undefined;
+}
+
+// apiFetch sends the session's CSRF token on mutating requests and returns
+// to the login page once the session has expired.
+async function apiFetch(path: string, init: RequestInit = {}): Promise<Response> {
+  const method = (init.method ?? "GET").toUpperCase();
+  const headers = new Headers(init.headers);
+  if (!safeMethods.has(method)) {
+    const token = readCookie(csrfCookie);
+    if (token) {
+      headers.set("X-CSRF-Token", token);
+    }
+  }
+  const res = await fetch(`${apiBase}${path}`, { ...init, headers, credentials: "same-origin" });
+  if (res.status === 401) {
+    window.location.assign("/login.html");
+    throw new Error("session expired");
+  }
+  return res;
+}
+
+function pairKey(pair: PairCount): string {
+  return `${pair.internal}->${pair.external}`;
+}
+
+// mergePairCounts folds a summary into the current view. Summaries only list
+// the pairs with new packets and, once a minute, the idle ones.
+export function mergePairCounts(current: PairCount[], summary: SummaryEvent): PairCount[] {
+  const at = Date.parse(summary.timestamp);
+  const merged = new Map<string, PairCount>();
+  for (const pair of current) {
+    if (pair.seenAt === undefined || at - pair.seenAt < pairStaleMs) {
+      merged.set(pairKey(pair), pair);
+    }
+  }
+  for (const pair of summary.pairCounts ?? []) {
+    merged.set(pairKey(pair), { ...pair, seenAt: at });
+  }
+  return [...merged.values()];
+}
 
 export async function fetchPairCounts(): Promise<PairCount[]> {
-  const res = await fetch(`${apiBase}/events`);
+  const res = await apiFetch("/events");
   if (!res.ok) {
     throw new Error("failed to load events");
   }
@@ -42,12 +105,11 @@ export async function fetchPairCounts(): Promise<PairCount[]> {
     return [];
   }
   const summaries = (body.events as SummaryEvent[]).filter((event) => event.kind === "ip_pair_summary");
-  const latest = summaries.at(-1);
-  return latest?.pairCounts ?? [];
+  return summaries.reduce<PairCount[]>(mergePairCounts, []);
 }
 
 export async function fetchDnsEvents(limit = 200): Promise<DnsEvent[]> {
-  const res = await fetch(`${apiBase}/events`);
+  const res = await apiFetch("/events");
   if (!res.ok) {
     throw new Error("failed to load events");
   }
@@ -62,7 +124,7 @@ export async function fetchDnsEvents(limit = 200): Promise<DnsEvent[]> {
 type EventCallbacks = {
   onOpen?: () => void;
   onClose?: () => void;
-  onPairSummary?: (counts: PairCount[]) => void;
+  onPairSummary?: (summary: SummaryEvent) => void;
   onDnsBatch?: (events: DnsEvent[]) => void;
   onDnsEvent?: (event: DnsEvent) => void;
 };
@@ -80,10 +142,7 @@ export function subscribeToEvents(callbacks: EventCallbacks): () => void {
       if (payload?.kind === "snapshot" && Array.isArray((payload as SnapshotPayload).events)) {
         const snapshot = payload as SnapshotPayload;
         const summaries = snapshot.events.filter((event) => event.kind === "ip_pair_summary") as SummaryEvent[];
-        const latest = summaries.at(-1);
-        if (latest?.pairCounts) {
-          callbacks.onPairSummary?.(latest.pairCounts);
-        }
+        summaries.forEach((summary) => callbacks.onPairSummary?.(summary));
         const dnsEvents = snapshot.events.filter((event) => event.kind === "dns") as DnsEvent[];
         if (dnsEvents.length > 0) {
           callbacks.onDnsBatch?.(dnsEvents);
@@ -92,10 +151,7 @@ export function subscribeToEvents(callbacks: EventCallbacks): () => void {
       }
 
       if ((payload as SummaryEvent).kind === "ip_pair_summary") {
-        const summary = payload as SummaryEvent;
-        if (summary.pairCounts) {
-          callbacks.onPairSummary?.(summary.pairCounts);
-        }
+        callbacks.onPairSummary?.(payload as SummaryEvent);
         return;
       }
 
@@ -113,7 +169,7 @@ export function subscribeToEvents(callbacks: EventCallbacks): () => void {
 
 
 export async function fetchBlocklist(): Promise<string[]> {
-  const res = await fetch(`${apiBase}/rules`);
+  const res = await apiFetch("/rules");
   if (!res.ok) {
     throw new Error("failed to load blocklist");
   }
@@ -122,7 +178,7 @@ export async function fetchBlocklist(): Promise<string[]> {
 }
 
 export async function updateBlocklist(domains: string[]): Promise<void> {
-  const res = await fetch(`${apiBase}/rules`, {
+  const res = await apiFetch("/rules", {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     body: JSON.stringify({ domains }),